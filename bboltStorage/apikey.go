@@ -0,0 +1,92 @@
+package bboltStorage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func (bb *BBolter) GetAPIKey(id string) (*types.APIKey, error) {
+	return Get[types.APIKey](bb, BucketAPIKey, id)
+}
+
+func (bb *BBolter) FindAPIKeys(max int, filter ...types.APIKey) (map[string]types.APIKey, error) {
+	return Find(bb, BucketAPIKey, max, func(k types.APIKey) bool {
+		if len(filter) == 0 {
+			return true
+		}
+		for _, f := range filter {
+			if f.OrganizationID != "" && f.OrganizationID != k.OrganizationID {
+				continue
+			}
+			if f.CreatedBy != "" && f.CreatedBy != k.CreatedBy {
+				continue
+			}
+			if f.ID != "" && f.ID != k.ID {
+				continue
+			}
+			return true
+		}
+		return false
+	})
+}
+
+func (bb *BBolter) CreateAPIKey(key types.APIKey) (types.APIKey, error) {
+	if key.Name == "" {
+		return key, fmt.Errorf("Name must be set")
+	}
+	if len(key.Hash) == 0 {
+		return key, fmt.Errorf("Hash must be set")
+	}
+	if len(key.Scopes) == 0 {
+		return key, fmt.Errorf("At least one scope must be set")
+	}
+	for _, s := range key.Scopes {
+		if !s.Valid() {
+			return key, fmt.Errorf("invalid scope: %s", s)
+		}
+	}
+	entity, err := bb.NewEntity(key.Entity)
+	if err != nil {
+		return key, err
+	}
+	key.Entity = entity
+
+	err = Create(bb, BucketAPIKey, key)
+	return key, err
+}
+
+func (bb *BBolter) SoftDeleteAPIKey(id string, byUser string, deleteTime *time.Time) (types.APIKey, error) {
+	if id == "" {
+		return types.APIKey{}, ErrMissingIdArg
+	}
+	if byUser == "" {
+		return types.APIKey{}, ErrMissingCreatedBy
+	}
+	return Update(bb, BucketAPIKey, id, byUser, func(k types.APIKey) (types.APIKey, error) {
+		if deleteTime == nil {
+			return k, fmt.Errorf("Revoked api-keys cannot be restored")
+		}
+		if k.Deleted != nil {
+			return k, fmt.Errorf("The api-key is already revoked")
+		}
+		k.Deleted = deleteTime
+		k.UpdatedBy = byUser
+		k.UpdatedAt = nowPointer()
+		return k, nil
+	})
+}
+
+// SetAPIKeyLastUsed records when the key was last used.
+// This happens on every request, so it is not published, and does not appear in the audit-log.
+func (bb *BBolter) SetAPIKeyLastUsed(id string, at time.Time) error {
+	return bb.updater(id, BucketAPIKey, func(b []byte) ([]byte, error) {
+		var k types.APIKey
+		if err := bb.Unmarshal(b, &k); err != nil {
+			return nil, err
+		}
+		k.LastUsedAt = &at
+		return bb.Marshal(k)
+	})
+}
@@ -0,0 +1,115 @@
+package bboltStorage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/r3labs/diff/v2"
+	"github.com/runar-rkmedia/skiver/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+// The audit-log is stored as json, and not with the marshaller of the database,
+// since the changes contain arbitrary values from the field-level diffs.
+//
+// For each entity, the latest version is kept in BucketAuditState, so that the next change
+// can be diffed against it.
+
+func auditKey(seq uint64) []byte {
+	return []byte(fmt.Sprintf("%020d", seq))
+}
+
+// Writes an entry to the audit-log for the change, within the transaction of the change itself,
+// so that a change is never stored without its entry.
+// byUser is the user making the change. For creations, it defaults to the creator of the entity.
+func (s *BBolter) audit(tx *bolt.Tx, kind PubType, variant PubVerb, byUser string, contents interface{}) error {
+	entry := types.AuditEntry{
+		CreatedAt:  time.Now(),
+		EntityKind: string(kind),
+		Verb:       string(variant),
+		UserID:     byUser,
+	}
+	if e, ok := contents.(types.EntityGetter); ok {
+		entity := e.GetEntity()
+		entry.EntityID = entity.ID
+		entry.OrganizationID = entity.OrganizationID
+		if kind == PubTypeOrganization {
+			entry.OrganizationID = entity.ID
+		}
+		if entry.UserID == "" && variant == PubVerbCreate {
+			entry.UserID = entity.CreatedBy
+		}
+		b, err := json.Marshal(contents)
+		if err != nil {
+			return fmt.Errorf("failed to marshal entity for the audit-log: %w", err)
+		}
+		var current map[string]interface{}
+		if err := json.Unmarshal(b, &current); err != nil {
+			return fmt.Errorf("failed to marshal entity for the audit-log: %w", err)
+		}
+		if kind == PubType(types.PubTypeSnapshot) {
+			// The contents of a snapshot never change, and are too large to keep a copy of.
+			delete(current, "project")
+		}
+		if entry.EntityID != "" {
+			state := tx.Bucket(BucketAuditState)
+			stateKey := []byte(string(kind) + "/" + entry.EntityID)
+			var previous map[string]interface{}
+			if b := state.Get(stateKey); b != nil {
+				if err := json.Unmarshal(b, &previous); err != nil {
+					return err
+				}
+			}
+			changes, err := diff.Diff(previous, current, diff.AllowTypeMismatch(true))
+			if err != nil {
+				return err
+			}
+			entry.Changes = changes
+			b, err := json.Marshal(current)
+			if err != nil {
+				return err
+			}
+			if err := state.Put(stateKey, b); err != nil {
+				return err
+			}
+		}
+	}
+	bucket := tx.Bucket(BucketAudit)
+	seq, err := bucket.NextSequence()
+	if err != nil {
+		return err
+	}
+	entry.ID = strconv.FormatUint(seq, 10)
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(auditKey(seq), b)
+}
+
+// IterateAuditEntries calls f for each entry in the audit-log that matches the filter, newest first.
+// Returning true from f stops the iteration.
+func (s *BBolter) IterateAuditEntries(filter types.AuditFilter, f func(entry types.AuditEntry) bool) error {
+	return s.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(BucketAudit).Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var entry types.AuditEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			// Entries are ordered by time, so there are no more matches.
+			if !filter.Since.IsZero() && entry.CreatedAt.Before(filter.Since) {
+				return nil
+			}
+			if !filter.Matches(entry) {
+				continue
+			}
+			if f(entry) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
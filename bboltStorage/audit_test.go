@@ -0,0 +1,112 @@
+package bboltStorage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/r3labs/diff/v2"
+	"github.com/runar-rkmedia/skiver/internal"
+	"github.com/runar-rkmedia/skiver/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestAuditLog(t *testing.T) {
+	db := NewMockDB(t)
+	internal.NewMockTimeNow()
+	testza.AssertNoError(t, db.StandardSeed())
+
+	base := types.Project{Title: "project", ShortName: "p"}
+	base.CreatedBy = "jimb"
+	base.OrganizationID = "org-abc"
+	project, err := db.CreateProject(base)
+	testza.AssertNoError(t, err)
+	base.ID = project.ID
+	cat, err := db.CreateCategory(newBaseCategoryFromProject(base, "General"))
+	testza.AssertNoError(t, err)
+	tr, err := db.CreateTranslation(types.Translation{Entity: base.Entity, Key: "Welcome", CategoryID: cat.ID})
+	testza.AssertNoError(t, err)
+	input := types.TranslationValue{Entity: base.Entity, LocaleID: "loc-en", TranslationID: tr.ID, Value: "Hello", Source: types.CreatorSourceUser}
+	input.ID = ""
+	tv, err := db.CreateTranslationValue(input)
+	testza.AssertNoError(t, err)
+	update := types.TranslationValue{Value: "Hi"}
+	update.ID = tv.ID
+	update.UpdatedBy = "bob"
+	_, err = db.UpdateTranslationValue(update)
+	testza.AssertNoError(t, err)
+
+	find := func(filter types.AuditFilter) (entries []types.AuditEntry) {
+		err := db.IterateAuditEntries(filter, func(entry types.AuditEntry) bool {
+			entries = append(entries, entry)
+			return false
+		})
+		testza.AssertNoError(t, err)
+		return entries
+	}
+
+	entries := find(types.AuditFilter{OrganizationID: "org-abc", Entity: tv.ID})
+	testza.AssertLen(t, entries, 2)
+	testza.AssertEqual(t, "update", entries[0].Verb, "The newest entry should be first")
+	testza.AssertEqual(t, "bob", entries[0].UserID)
+	testza.AssertEqual(t, "translationValue", entries[0].EntityKind)
+	testza.AssertContains(t, entries[0].Changes, diff.Change{Type: diff.UPDATE, Path: []string{"value"}, From: "Hello", To: "Hi"})
+	testza.AssertEqual(t, "create", entries[1].Verb)
+	testza.AssertEqual(t, "jimb", entries[1].UserID)
+	testza.AssertContains(t, entries[1].Changes, diff.Change{Type: diff.CREATE, Path: []string{"value"}, To: "Hello"})
+
+	testza.AssertLen(t, find(types.AuditFilter{OrganizationID: "org-abc", UserID: "bob"}), 1)
+	testza.AssertLen(t, find(types.AuditFilter{OrganizationID: "org-abc", Entity: "project"}), 2, "Created, and updated when the category was added")
+	testza.AssertLen(t, find(types.AuditFilter{OrganizationID: "org-other", Entity: tv.ID}), 0)
+	testza.AssertLen(t, find(types.AuditFilter{OrganizationID: "org-abc", Since: time.Now().Add(time.Hour)}), 0)
+}
+
+func TestAuditLogIsPartOfTheChange(t *testing.T) {
+	db := NewMockDB(t)
+	internal.NewMockTimeNow()
+	testza.AssertNoError(t, db.StandardSeed())
+
+	base := types.Project{Title: "project", ShortName: "p"}
+	base.CreatedBy = "jimb"
+	base.OrganizationID = "org-abc"
+	project, err := db.CreateProject(base)
+	testza.AssertNoError(t, err)
+	base.ID = project.ID
+	cat, err := db.CreateCategory(newBaseCategoryFromProject(base, "General"))
+	testza.AssertNoError(t, err)
+	tr, err := db.CreateTranslation(types.Translation{Entity: base.Entity, Key: "Welcome", CategoryID: cat.ID})
+	testza.AssertNoError(t, err)
+
+	update := types.Translation{Key: tr.Key, Title: "Welcome-message"}
+	update.UpdatedBy = "bob"
+	update.Version = tr.Version
+	tr, err = db.UpdateTranslation(tr.ID, update)
+	testza.AssertNoError(t, err)
+	// Changes that are not made by a user are not attributed to the previous one
+	update = types.Translation{Key: tr.Key, Description: "Shown on the front-page"}
+	update.Version = tr.Version
+	tr, err = db.UpdateTranslation(tr.ID, update)
+	testza.AssertNoError(t, err)
+	var users []string
+	err = db.IterateAuditEntries(types.AuditFilter{OrganizationID: "org-abc", Entity: tr.ID}, func(entry types.AuditEntry) bool {
+		users = append(users, entry.UserID)
+		return false
+	})
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, []string{"", "bob", "jimb"}, users)
+
+	// If the audit-log cannot be written, the change is not stored either
+	err = db.Storage.(*BBolter).Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(BucketAuditState).Put([]byte(string(PubTypeTranslation)+"/"+tr.ID), []byte("{"))
+	})
+	testza.AssertNoError(t, err)
+	update = types.Translation{Key: tr.Key, Title: "Greeting"}
+	update.UpdatedBy = "bob"
+	update.Version = tr.Version
+	_, err = db.UpdateTranslation(tr.ID, update)
+	testza.AssertNotNil(t, err)
+	stored, err := db.GetTranslation(tr.ID)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Welcome-message", stored.Title)
+	testza.AssertEqual(t, tr.Version, stored.Version)
+}
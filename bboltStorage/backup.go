@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	bolt "go.etcd.io/bbolt"
@@ -58,6 +59,20 @@ func (s *BBolter) Backup(w io.Writer) (int64, error) {
 	return written, err
 }
 
+// BackupPath returns a path that the database at path can be moved to, without overwriting an earlier backup.
+// This is path with a .bk-suffix, or with a timestamp before the suffix if that is taken.
+func BackupPath(path string) (string, error) {
+	backup := path + ".bk"
+	if !fileExists(backup) {
+		return backup, nil
+	}
+	backup = fmt.Sprintf("%s.%s.bk", path, time.Now().Format("20060102-150405"))
+	if fileExists(backup) {
+		return "", fmt.Errorf("Refusing to overwrite the existing backup %s", backup)
+	}
+	return backup, nil
+}
+
 func fileExists(filePath string) bool {
 	_, error := os.Stat(filePath)
 	return !errors.Is(error, os.ErrNotExist)
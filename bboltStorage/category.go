@@ -78,14 +78,18 @@ func (b *BBolter) UpdateCategory(id string, payload types.Category) (types.Categ
 			return ErrNoFieldsChanged
 		}
 		c.UpdatedAt = nowPointer()
+		if payload.UpdatedBy != "" {
+			c.UpdatedBy = payload.UpdatedBy
+		}
 
 		bytes, err := b.Marshal(c)
 		if err != nil {
 			return err
 		}
-		err = bucket.Put([]byte(c.ID), bytes)
-
-		return nil
+		if err := bucket.Put([]byte(c.ID), bytes); err != nil {
+			return err
+		}
+		return b.audit(tx, PubTypeCategory, PubVerbUpdate, payload.UpdatedBy, c)
 	})
 	if err != nil {
 		return c, err
@@ -144,12 +148,18 @@ func (b *BBolter) CreateCategory(category types.Category) (types.Category, error
 			if err != nil {
 				return err
 			}
+			if err := b.audit(tx, PubTypeProject, PubVerbUpdate, category.CreatedBy, p); err != nil {
+				return err
+			}
 		}
 		bytes, err := b.Marshal(category)
 		if err != nil {
 			return err
 		}
-		return bucketCategory.Put([]byte(category.ID), bytes)
+		if err := bucketCategory.Put([]byte(category.ID), bytes); err != nil {
+			return err
+		}
+		return b.audit(tx, PubTypeCategory, PubVerbCreate, category.CreatedBy, category)
 	})
 	if err != nil {
 		return category, err
@@ -1,6 +1,7 @@
 package bboltStorage
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -30,29 +31,41 @@ func (s *BBolter) copyCompact(path string) (*bolt.DB, error) {
 	}
 	return compactDb, nil
 }
-func (s *BBolter) compactDatabase() error {
-	path := "_compact.bbolt"
+
+// Compact replaces the database with a compacted copy of it. The original database is kept next to it,
+// at the returned path, which has a .bk-suffix. Earlier backups are never overwritten.
+func (s *BBolter) Compact() (string, error) {
+	originalPath := s.Path()
+	backup, err := BackupPath(originalPath)
+	if err != nil {
+		return "", err
+	}
+	// The copy is created next to the original, since it is renamed into its place.
+	// A copy left by an earlier, failed compaction would otherwise be compacted into.
+	path := originalPath + ".compact"
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
 	compactDb, err := s.copyCompact(path)
 	if compactDb != nil {
 		defer compactDb.Close()
 	}
 	if err != nil {
-		return err
+		return "", err
 	}
-	originalPath := s.Path()
 	compactDb.Close()
 	s.l.Warn().Msg("New database was compacted. Will now close existing database.")
 	s.Close()
 	s.l.Warn().Msg("Closed databases. Will now rename databases on disk")
-	err = os.Rename(originalPath, originalPath+".bk")
+	err = os.Rename(originalPath, backup)
 	if err != nil {
 		s.l.Error().Err(err).Msg("Failed to move original database")
-		return fmt.Errorf("Failed to move original database")
+		return "", fmt.Errorf("Failed to move original database")
 	}
 	err = os.Rename(path, originalPath)
 	if err != nil {
 		s.l.Error().Err(err).Msg("Failed to move compact database")
-		return fmt.Errorf("Failed to move compact database")
+		return "", fmt.Errorf("Failed to move compact database")
 	}
 	s.l.Warn().Msg("Databases renamed. WIll now reopen the database.")
 	db, err := bolt.Open(originalPath, 0666, &bolt.Options{
@@ -60,13 +73,14 @@ func (s *BBolter) compactDatabase() error {
 	})
 	if err != nil {
 		s.l.Error().Err(err).Msg("Failed to reopen the database")
-		return fmt.Errorf("Failed to reopen the database")
+		return "", fmt.Errorf("Failed to reopen the database")
 	}
 	s.DB = db
 
 	s.l.Info().Msg("Database was compacted and replaced successfully")
-	return nil
+	return backup, nil
 }
+
 func (s *BBolter) emptyBucket(bucket []byte) error {
 	s.l.Warn().Str("bucket", string(bucket)).Msg("Emptying bucket")
 	err := s.Update(func(tx *bolt.Tx) error {
@@ -98,7 +98,7 @@ type Identifyable interface {
 	Kind() string
 }
 
-// Creates an item in the assigned bucket
+// Creates an item in the assigned bucket, and writes it to the audit-log
 func Create[T Identifyable](bb *BBolter, bucket []byte, item T) error {
 	err := bb.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(bucket)
@@ -111,7 +111,10 @@ func Create[T Identifyable](bb *BBolter, bucket []byte, item T) error {
 		if err != nil {
 			return err
 		}
-		return bucket.Put([]byte(id), bytes)
+		if err := bucket.Put([]byte(id), bytes); err != nil {
+			return err
+		}
+		return bb.audit(tx, PubType(item.Kind()), PubVerbCreate, "", item)
 	})
 	if err != nil {
 		return err
@@ -120,8 +123,8 @@ func Create[T Identifyable](bb *BBolter, bucket []byte, item T) error {
 	return nil
 }
 
-// Updates an item in the assigned bucket
-func Update[T Identifyable](bb *BBolter, bucket []byte, id string, merge func(t T) (T, error)) (T, error) {
+// Updates an item in the assigned bucket, and writes the change to the audit-log, as made by byUser
+func Update[T Identifyable](bb *BBolter, bucket []byte, id string, byUser string, merge func(t T) (T, error)) (T, error) {
 	var t T
 	err := bb.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(bucket)
@@ -148,7 +151,10 @@ func Update[T Identifyable](bb *BBolter, bucket []byte, id string, merge func(t
 		if err != nil {
 			return err
 		}
-		return bucket.Put([]byte(idb), bytes)
+		if err := bucket.Put([]byte(idb), bytes); err != nil {
+			return err
+		}
+		return bb.audit(tx, PubType(t.Kind()), PubVerbUpdate, byUser, t)
 	})
 	if err != nil {
 		return t, err
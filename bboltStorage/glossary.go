@@ -0,0 +1,110 @@
+package bboltStorage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func (bb *BBolter) GetGlossaryEntry(id string) (*types.GlossaryEntry, error) {
+	return Get[types.GlossaryEntry](bb, BucketGlossary, id)
+}
+
+func (bb *BBolter) FindGlossaryEntries(max int, filter ...types.GlossaryEntry) (map[string]types.GlossaryEntry, error) {
+	return Find(bb, BucketGlossary, max, func(e types.GlossaryEntry) bool {
+		if len(filter) == 0 {
+			return true
+		}
+		for _, f := range filter {
+			if glossaryFilter(f, e) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func glossaryFilter(f, e types.GlossaryEntry) bool {
+	if f.OrganizationID != "" && f.OrganizationID != e.OrganizationID {
+		return false
+	}
+	if f.ID != "" && f.ID != e.ID {
+		return false
+	}
+	if f.Term != "" && !strings.EqualFold(f.Term, e.Term) {
+		return false
+	}
+	return true
+}
+
+func (bb *BBolter) CreateGlossaryEntry(entry types.GlossaryEntry) (types.GlossaryEntry, error) {
+	if entry.OrganizationID == "" {
+		return entry, ErrMissingOrganizationID
+	}
+	if strings.TrimSpace(entry.Term) == "" {
+		return entry, fmt.Errorf("Term must be set")
+	}
+	filter := types.GlossaryEntry{Term: entry.Term}
+	filter.OrganizationID = entry.OrganizationID
+	existing, err := bb.FindGlossaryEntries(0, filter)
+	if err != nil {
+		return entry, err
+	}
+	for _, e := range existing {
+		if e.Deleted == nil {
+			return e, fmt.Errorf("Glossary-create-error: %w", ErrDuplicate)
+		}
+	}
+	entity, err := bb.NewEntity(entry.Entity)
+	if err != nil {
+		return entry, err
+	}
+	entry.Entity = entity
+
+	err = Create(bb, BucketGlossary, entry)
+	return entry, err
+}
+
+// Replaces the term, description, translations and flags of the entry.
+func (bb *BBolter) UpdateGlossaryEntry(id string, payload types.GlossaryEntry) (types.GlossaryEntry, error) {
+	if id == "" {
+		return payload, ErrMissingIdArg
+	}
+	if strings.TrimSpace(payload.Term) == "" {
+		return payload, fmt.Errorf("Term must be set")
+	}
+	return Update(bb, BucketGlossary, id, payload.UpdatedBy, func(e types.GlossaryEntry) (types.GlossaryEntry, error) {
+		e.Term = payload.Term
+		e.Description = payload.Description
+		e.Translations = payload.Translations
+		e.DoNotTranslate = payload.DoNotTranslate
+		e.CaseSensitive = payload.CaseSensitive
+		e.UpdatedBy = payload.UpdatedBy
+		e.UpdatedAt = nowPointer()
+		return e, nil
+	})
+}
+
+func (bb *BBolter) SoftDeleteGlossaryEntry(id string, byUser string, deleteTime *time.Time) (types.GlossaryEntry, error) {
+	if id == "" {
+		return types.GlossaryEntry{}, ErrMissingIdArg
+	}
+	if byUser == "" {
+		return types.GlossaryEntry{}, ErrMissingCreatedBy
+	}
+	return Update(bb, BucketGlossary, id, byUser, func(e types.GlossaryEntry) (types.GlossaryEntry, error) {
+		if deleteTime == nil {
+			if e.Deleted == nil {
+				return e, fmt.Errorf("Cannot undelete a non-deleted item")
+			}
+		} else if e.Deleted != nil {
+			return e, fmt.Errorf("Cannot delete an already deleted item")
+		}
+		e.Deleted = deleteTime
+		e.UpdatedBy = byUser
+		e.UpdatedAt = nowPointer()
+		return e, nil
+	})
+}
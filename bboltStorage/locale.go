@@ -54,7 +54,10 @@ func (b *BBolter) CreateLocale(locale types.Locale) (types.Locale, error) {
 		if err != nil {
 			return err
 		}
-		return bucket.Put([]byte(locale.ID), bytes)
+		if err := bucket.Put([]byte(locale.ID), bytes); err != nil {
+			return err
+		}
+		return b.audit(tx, PubTypeLocale, PubVerbCreate, locale.CreatedBy, locale)
 	})
 	if err != nil {
 		return locale, err
@@ -0,0 +1,96 @@
+package bboltStorage
+
+import (
+	"fmt"
+
+	"github.com/runar-rkmedia/skiver/types"
+	"go.etcd.io/bbolt"
+)
+
+// FindLoginAttempts returns all the tracked login-attempts
+func (bb *BBolter) FindLoginAttempts() ([]types.LoginAttempts, error) {
+	var attempts []types.LoginAttempts
+	var innerErr error
+	err := bb.Iterate(BucketLoginAttempts, func(key, b []byte) bool {
+		var j types.LoginAttempts
+		if innerErr = bb.Unmarshal(b, &j); innerErr != nil {
+			return true
+		}
+		attempts = append(attempts, j)
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attempts, innerErr
+}
+
+// UpdateLoginAttempts updates the login-attempts for the keys within a single transaction,
+// so that concurrent logins cannot slip past each other.
+// Attempts that do not exist are passed as empty, and attempts that are empty afterwards are removed.
+func (bb *BBolter) UpdateLoginAttempts(keys []string, f func(attempts []*types.LoginAttempts) error) error {
+	return bb.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(BucketLoginAttempts)
+		attempts := make([]*types.LoginAttempts, len(keys))
+		for i, key := range keys {
+			attempts[i] = &types.LoginAttempts{Key: key}
+			if b := bucket.Get([]byte(key)); len(b) > 0 {
+				if err := bb.Unmarshal(b, attempts[i]); err != nil {
+					return fmt.Errorf("failed to unmarshal login-attempts: %w", err)
+				}
+			}
+		}
+		if err := f(attempts); err != nil {
+			return err
+		}
+		for i, key := range keys {
+			if attempts[i].Empty() {
+				if err := bucket.Delete([]byte(key)); err != nil {
+					return err
+				}
+				continue
+			}
+			b, err := bb.Marshal(*attempts[i])
+			if err != nil {
+				return fmt.Errorf("failed to marshal login-attempts: %w", err)
+			}
+			if err := bucket.Put([]byte(key), b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// EvictLoginAttempts removes the login-attempts that the function returns true for
+func (bb *BBolter) EvictLoginAttempts(f func(a types.LoginAttempts) bool) (int, error) {
+	var toEvict [][]byte
+	err := bb.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(BucketLoginAttempts)
+		err := bucket.ForEach(func(k, v []byte) error {
+			var j types.LoginAttempts
+			if err := bb.Unmarshal(v, &j); err != nil {
+				bb.l.Error().Err(err).Msg("failed to unmarshal login-attempts")
+				return nil
+			}
+			if f(j) {
+				toEvict = append(toEvict, append([]byte{}, k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// Deleting while iterating would skip items
+		for _, k := range toEvict {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(toEvict), nil
+}
@@ -80,7 +80,7 @@ func (bb *BBolter) Migrate(hooks ...func(state types.State, wantedMigrationPoint
 					if debug {
 						l.Debug().Interface("tv", tv).Msg("Updating translation: Moving refs from Variables to References")
 					}
-					_, err := Update(bb, BucketTranslation, tv.ID, func(t types.Translation) (types.Translation, error) {
+					_, err := Update(bb, BucketTranslation, tv.ID, "", func(t types.Translation) (types.Translation, error) {
 						return tv, nil
 					})
 					if err != nil {
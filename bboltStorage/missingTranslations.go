@@ -70,6 +70,9 @@ func (bb *BBolter) UpdateMissingWithNewIds(payload types.MissingTranslation) (ma
 			if err != nil {
 				return err
 			}
+			if err := bb.audit(tx, PubTypeMissingTranslation, PubVerbUpdate, "", m); err != nil {
+				return err
+			}
 			updated[string(k)] = m
 		}
 		return nil
@@ -181,7 +184,10 @@ func (b *BBolter) ReportMissing(key types.MissingTranslation) (*types.MissingTra
 		if err != nil {
 			return err
 		}
-		return bucket.Put([]byte(key.ID), bytes)
+		if err := bucket.Put([]byte(key.ID), bytes); err != nil {
+			return err
+		}
+		return b.audit(tx, PubTypeMissingTranslation, verb, "", key)
 	})
 	if err != nil {
 		return &key, err
@@ -46,7 +46,10 @@ func (b *BBolter) CreateOrganization(organization types.Organization) (types.Org
 		if err != nil {
 			return err
 		}
-		return bucket.Put([]byte(organization.ID), bytes)
+		if err := bucket.Put([]byte(organization.ID), bytes); err != nil {
+			return err
+		}
+		return b.audit(tx, PubTypeOrganization, PubVerbCreate, organization.CreatedBy, organization)
 	})
 	if err != nil {
 		return organization, err
@@ -98,7 +101,7 @@ func (bb *BBolter) UpdateOrganization(id string, payload types.UpdateOrganizatio
 	if payload.UpdatedBy == "" {
 		return types.Organization{}, fmt.Errorf("missing updatedBy")
 	}
-	return Update(bb, BucketOrganization, id, func(t types.Organization) (types.Organization, error) {
+	return Update(bb, BucketOrganization, id, payload.UpdatedBy, func(t types.Organization) (types.Organization, error) {
 		shouldUpdate := false
 		if payload.JoinID != nil && string(*payload.JoinID) != string(t.JoinID) {
 			t.JoinID = *payload.JoinID
@@ -108,6 +111,10 @@ func (bb *BBolter) UpdateOrganization(id string, payload types.UpdateOrganizatio
 			t.JoinIDExpires = *payload.JoinIDExpires
 			shouldUpdate = true
 		}
+		if payload.RequireMFA != nil && *payload.RequireMFA != t.RequireMFA {
+			t.RequireMFA = *payload.RequireMFA
+			shouldUpdate = true
+		}
 		if !shouldUpdate {
 			return t, ErrNoFieldsChanged
 		}
@@ -45,7 +45,10 @@ func (b *BBolter) CreateProject(project types.Project) (types.Project, error) {
 		if err != nil {
 			return err
 		}
-		return bucket.Put([]byte(project.ID), bytes)
+		if err := bucket.Put([]byte(project.ID), bytes); err != nil {
+			return err
+		}
+		return b.audit(tx, PubTypeProject, PubVerbCreate, project.CreatedBy, project)
 	})
 	if err != nil {
 		return project, err
@@ -103,6 +106,14 @@ func (b *BBolter) UpdateProject(id string, project types.Project) (types.Project
 			c.LocaleIDs = project.LocaleIDs
 			needsUpdate = true
 		}
+		if len(project.LintRules) != 0 && !reflect.DeepEqual(project.LintRules, c.LintRules) {
+			c.LintRules = project.LintRules
+			needsUpdate = true
+		}
+		if project.ReleaseGate != nil && !reflect.DeepEqual(project.ReleaseGate, c.ReleaseGate) {
+			c.ReleaseGate = project.ReleaseGate
+			needsUpdate = true
+		}
 		if len(project.Snapshots) != 0 && !reflect.DeepEqual(project.Snapshots, c.Snapshots) {
 			c.Snapshots = project.Snapshots
 			needsUpdate = true
@@ -120,9 +131,10 @@ func (b *BBolter) UpdateProject(id string, project types.Project) (types.Project
 		if err != nil {
 			return err
 		}
-		err = bucket.Put([]byte(c.ID), bytes)
-
-		return nil
+		if err := bucket.Put([]byte(c.ID), bytes); err != nil {
+			return err
+		}
+		return b.audit(tx, PubTypeProject, PubVerbUpdate, project.UpdatedBy, c)
 	})
 	if err != nil {
 		return c, err
@@ -0,0 +1,186 @@
+package bboltStorage
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Revisions are stored with the entity-id as prefix, so that the revisions of an entity
+// are sorted, and can be found with a seek.
+func revisionPrefix(entityID string) []byte {
+	return []byte(entityID + "/")
+}
+func revisionKey(entityID string, revision int) []byte {
+	return []byte(fmt.Sprintf("%s/%010d", entityID, revision))
+}
+
+// GetRevisions returns all the stored revisions for an entity, oldest first.
+func (bb *BBolter) GetRevisions(entityID string) ([]types.Revision, error) {
+	if entityID == "" {
+		return nil, ErrMissingIdArg
+	}
+	var revisions []types.Revision
+	err := bb.View(func(tx *bolt.Tx) error {
+		prefix := revisionPrefix(entityID)
+		c := tx.Bucket(BucketRevision).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var r types.Revision
+			if err := bb.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			revisions = append(revisions, r)
+		}
+		return nil
+	})
+	return revisions, err
+}
+
+func (bb *BBolter) GetRevision(entityID string, revision int) (*types.Revision, error) {
+	if entityID == "" {
+		return nil, ErrMissingIdArg
+	}
+	var r types.Revision
+	err := bb.GetItem(BucketRevision, string(revisionKey(entityID, revision)), &r)
+	if err == ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Stores the entity as a new revision, and removes revisions outside of the retention.
+// Failures are logged, and not returned, since the change itself has already been stored.
+func (bb *BBolter) addRevision(r types.Revision) {
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+	err := bb.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(BucketRevision)
+		prefix := revisionPrefix(r.EntityID)
+		var keys [][]byte
+		var createdAt []time.Time
+		c := bucket.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var existing types.Revision
+			if err := bb.Unmarshal(v, &existing); err != nil {
+				return err
+			}
+			keys = append(keys, append([]byte{}, k...))
+			createdAt = append(createdAt, existing.CreatedAt)
+			r.Revision = existing.Revision
+		}
+		r.Revision++
+		b, err := bb.Marshal(r)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(revisionKey(r.EntityID, r.Revision), b); err != nil {
+			return err
+		}
+		retention := bb.revisionRetention
+		remove := 0
+		if retention.MaxCount > 0 && len(keys)+1 > retention.MaxCount {
+			remove = len(keys) + 1 - retention.MaxCount
+		}
+		if retention.MaxAge > 0 {
+			cutoff := r.CreatedAt.Add(-retention.MaxAge)
+			for remove < len(keys) && createdAt[remove].Before(cutoff) {
+				remove++
+			}
+		}
+		for i := 0; i < remove; i++ {
+			if err := bucket.Delete(keys[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		bb.l.Error().Err(err).Str("id", r.EntityID).Str("kind", r.EntityKind).Msg("Failed to store revision")
+	}
+}
+
+func (bb *BBolter) addTranslationValueRevision(verb PubVerb, tv types.TranslationValue, byUser string) {
+	bb.addRevision(types.Revision{
+		EntityID:         tv.ID,
+		EntityKind:       tv.Kind(),
+		Verb:             string(verb),
+		CreatedBy:        byUser,
+		Source:           tv.Source,
+		TranslationValue: &tv,
+	})
+}
+
+func (bb *BBolter) addTranslationRevision(verb PubVerb, t types.Translation, byUser string) {
+	bb.addRevision(types.Revision{
+		EntityID:    t.ID,
+		EntityKind:  t.Kind(),
+		Verb:        string(verb),
+		CreatedBy:   byUser,
+		Translation: &t,
+	})
+}
+
+// RevertTranslationValue sets the value and context back to what they were in the revision.
+// The revert is itself stored as a new revision, so it can also be reverted.
+func (bb *BBolter) RevertTranslationValue(id string, revision int, byUser string) (types.TranslationValue, error) {
+	if byUser == "" {
+		return types.TranslationValue{}, ErrMissingCreatedBy
+	}
+	r, err := bb.GetRevision(id, revision)
+	if err != nil {
+		return types.TranslationValue{}, err
+	}
+	if r == nil || r.TranslationValue == nil {
+		return types.TranslationValue{}, ErrNotFound
+	}
+	old := *r.TranslationValue
+	tv, err := Update(bb, BucketTranslationValue, id, byUser, func(tv types.TranslationValue) (types.TranslationValue, error) {
+		context := map[string]string{}
+		for k, v := range old.Context {
+			context[k] = v
+		}
+		if !revertChangesContent(tv, old) {
+			return tv, ErrNoFieldsChanged
+		}
+		tv.ContentChanged(types.StateNeedsReview)
+		tv.Version++
+		tv.Value = old.Value
+		tv.Context = context
+		tv.Source = types.CreatorSourceUser
+		tv.UpdatedBy = byUser
+		tv.UpdatedAt = nowPointer()
+		return tv, nil
+	})
+	if err != nil {
+		return tv, err
+	}
+	bb.addRevision(types.Revision{
+		EntityID:         tv.ID,
+		EntityKind:       tv.Kind(),
+		Verb:             "revert",
+		CreatedBy:        byUser,
+		Source:           tv.Source,
+		RevertedFrom:     revision,
+		TranslationValue: &tv,
+	})
+	return tv, nil
+}
+
+func revertChangesContent(current, old types.TranslationValue) bool {
+	if current.Value != old.Value || len(current.Context) != len(old.Context) {
+		return true
+	}
+	for k, v := range old.Context {
+		if current.Context[k] != v {
+			return true
+		}
+	}
+	return false
+}
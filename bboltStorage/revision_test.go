@@ -0,0 +1,83 @@
+package bboltStorage
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/internal"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestTranslationValueRevisions(t *testing.T) {
+	db := NewMockDB(t)
+	internal.NewMockTimeNow()
+	testza.AssertNoError(t, db.StandardSeed())
+	db.Storage.(*BBolter).revisionRetention = types.RevisionRetention{MaxCount: 3}
+
+	base := types.Project{Title: "project", ShortName: "p"}
+	base.CreatedBy = "jimb"
+	base.OrganizationID = "org-abc"
+	project, err := db.CreateProject(base)
+	testza.AssertNoError(t, err)
+	base.ID = project.ID
+	cat, err := db.CreateCategory(newBaseCategoryFromProject(base, "General"))
+	testza.AssertNoError(t, err)
+	tr, err := db.CreateTranslation(types.Translation{Entity: base.Entity, Key: "Welcome", CategoryID: cat.ID})
+	testza.AssertNoError(t, err)
+	revisions, err := db.GetRevisions(tr.ID)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, revisions, 1)
+	testza.AssertEqual(t, "translation", revisions[0].EntityKind)
+
+	input := types.TranslationValue{Entity: base.Entity, LocaleID: "loc-en", TranslationID: tr.ID, Value: "Hello", Source: types.CreatorSourceUser}
+	input.ID = ""
+	tv, err := db.CreateTranslationValue(input)
+	testza.AssertNoError(t, err)
+
+	update := types.TranslationValue{Value: "Hi", Context: map[string]string{"formal": "Good day"}}
+	update.ID = tv.ID
+	update.UpdatedBy = "bob"
+	_, err = db.UpdateTranslationValue(update)
+	testza.AssertNoError(t, err)
+
+	revisions, err = db.GetRevisions(tv.ID)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, revisions, 2)
+	testza.AssertEqual(t, 1, revisions[0].Revision)
+	testza.AssertEqual(t, "create", revisions[0].Verb)
+	testza.AssertEqual(t, "jimb", revisions[0].CreatedBy)
+	testza.AssertEqual(t, "Hello", revisions[0].TranslationValue.Value)
+	testza.AssertEqual(t, 2, revisions[1].Revision)
+	testza.AssertEqual(t, "bob", revisions[1].CreatedBy)
+	testza.AssertEqual(t, "Hi", revisions[1].TranslationValue.Value)
+
+	tv, err = db.RevertTranslationValue(tv.ID, 1, "alice")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Hello", tv.Value)
+	testza.AssertLen(t, tv.Context, 0)
+	testza.AssertEqual(t, "alice", tv.UpdatedBy)
+	testza.AssertEqual(t, types.StateNeedsReview, tv.State)
+
+	_, err = db.RevertTranslationValue(tv.ID, 1, "alice")
+	testza.AssertErrorIs(t, err, ErrNoFieldsChanged, "Reverting to the current content should not create a revision")
+	_, err = db.RevertTranslationValue(tv.ID, 42, "alice")
+	testza.AssertErrorIs(t, err, ErrNotFound)
+
+	revisions, err = db.GetRevisions(tv.ID)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, revisions, 3)
+	testza.AssertEqual(t, "revert", revisions[2].Verb)
+	testza.AssertEqual(t, 1, revisions[2].RevertedFrom)
+
+	// The retention removes the oldest revisions
+	_, err = db.SetTranslationValueState(tv.ID, types.TranslationValueReview{State: types.StateApproved, By: "alice"})
+	testza.AssertNoError(t, err)
+	revisions, err = db.GetRevisions(tv.ID)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, revisions, 3)
+	testza.AssertEqual(t, 2, revisions[0].Revision)
+	testza.AssertEqual(t, 4, revisions[2].Revision)
+	r, err := db.GetRevision(tv.ID, 1)
+	testza.AssertNoError(t, err)
+	testza.AssertNil(t, r)
+}
@@ -1,6 +1,7 @@
 package bboltStorage
 
 import (
+	"crypto/rand"
 	"fmt"
 	"time"
 
@@ -68,3 +69,91 @@ func (bb *BBolter) EvictSession(key string) error {
 	}
 	return err
 }
+
+// GetSession returns the session with the id, or nil if it does not exist.
+func (bb *BBolter) GetSession(id string) (*types.Session, error) {
+	var session *types.Session
+	err := bb.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(BucketSession).Get([]byte(id))
+		if len(b) == 0 {
+			return nil
+		}
+		session = &types.Session{}
+		return bb.Unmarshal(b, session)
+	})
+	return session, err
+}
+
+// FindSessions returns the sessions of the user
+func (bb *BBolter) FindSessions(userID string) ([]types.Session, error) {
+	var sessions []types.Session
+	var innerErr error
+	err := bb.Iterate(BucketSession, func(key, b []byte) bool {
+		var j types.Session
+		if innerErr = bb.Unmarshal(b, &j); innerErr != nil {
+			return true
+		}
+		// Sessions that are stored by their token, from before sessions had ids, are skipped
+		if j.User.ID == userID && j.ID == string(key) {
+			sessions = append(sessions, j)
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sessions, innerErr
+}
+
+// EvictExpiredSessions removes the sessions that have expired, along with sessions that are stored by their token,
+// from before sessions had ids.
+func (bb *BBolter) EvictExpiredSessions(now time.Time) (int, error) {
+	var toEvict [][]byte
+	err := bb.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(BucketSession)
+		err := bucket.ForEach(func(k, v []byte) error {
+			var j types.Session
+			if err := bb.Unmarshal(v, &j); err != nil {
+				bb.l.Error().Err(err).Msg("failed to unmarshal session")
+				return nil
+			}
+			if j.ID != string(k) || j.Expires.Before(now) {
+				toEvict = append(toEvict, append([]byte{}, k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// Deleting while iterating would skip items
+		for _, k := range toEvict {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(toEvict), nil
+}
+
+// GetSessionSecret returns the secret that session-tokens are signed with.
+// The secret is created on first use.
+func (bb *BBolter) GetSessionSecret() ([]byte, error) {
+	var secret []byte
+	err := bb.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(BucketSys)
+		if s := bucket.Get([]byte("sessionSecret")); len(s) > 0 {
+			secret = append(secret, s...)
+			return nil
+		}
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		return bucket.Put([]byte("sessionSecret"), secret)
+	})
+	return secret, err
+}
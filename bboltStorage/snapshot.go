@@ -76,7 +76,7 @@ func (bb *BBolter) FindOneSnapshot(filter ...types.ProjectSnapshot) (*types.Proj
 }
 
 func (bb *BBolter) UpdateSnapshot(id string, payload types.ProjectSnapshot) (types.ProjectSnapshot, error) {
-	return Update(bb, BucketSnapshot, id, func(t types.ProjectSnapshot) (types.ProjectSnapshot, error) {
+	return Update(bb, BucketSnapshot, id, payload.UpdatedBy, func(t types.ProjectSnapshot) (types.ProjectSnapshot, error) {
 		if payload.Project.ID != "" && t.Project.ID != payload.Project.ID {
 			return t, fmt.Errorf("Cannot replace a projects snappshot with a different project")
 		}
@@ -22,14 +22,30 @@ var (
 	// deprecated. return pointer instead
 	ErrNotFound      = errors.New("Not found")
 	ErrMissingBucket = errors.New("Bucket not found")
+	// The entity has been changed since the version that the update was based on
+	ErrVersionConflict = errors.New("The entity has been changed by someone else")
 )
 
+// Returns ErrVersionConflict if an expected version is set, and it does not match the current version.
+// Entities created before versioning was introduced have version 0 until they are changed,
+// so an expected version of 0 is never checked.
+func checkVersion(current, expected int) error {
+	if expected != 0 && expected != current {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
 type PubSubPublisher interface {
 	Publish(kind, variant string, contents interface{})
 }
 
 type BBoltOptions struct {
 	IDGenerator IDGenerator
+	// Limits the revision-history that is kept for translations and translation-values
+	RevisionRetention types.RevisionRetention
+	// Limits the completed deliveries that are kept for each webhook
+	WebhookDeliveryRetention types.WebhookDeliveryRetention
 }
 
 // Caller must call close when ending
@@ -39,6 +55,8 @@ func NewBbolt(l logger.AppLogger, path string, pubsub PubSubPublisher, options .
 	if len(options) > 0 {
 		opts = options[0]
 		bb.idgenerator = opts.IDGenerator
+		bb.revisionRetention = opts.RevisionRetention
+		bb.webhookDeliveryRetention = opts.WebhookDeliveryRetention
 	}
 
 	bb.l = l
@@ -243,8 +261,10 @@ type BBolter struct {
 	pubsub PubSubPublisher
 	l      logger.AppLogger
 	Marshaller
-	idgenerator IDGenerator
-	writeStats  writeStats
+	idgenerator              IDGenerator
+	writeStats               writeStats
+	revisionRetention        types.RevisionRetention
+	webhookDeliveryRetention types.WebhookDeliveryRetention
 }
 
 type IDGenerator interface {
@@ -263,7 +283,19 @@ var (
 	BucketTranslationValue = []byte("translationValues")
 	BucketCategory         = []byte("categories")
 	BucketMissing          = []byte("missing")
-	allBuckets             = [][]byte{
+	BucketGlossary         = []byte("glossary")
+	BucketRevision         = []byte("revisions")
+	BucketAudit            = []byte("audit")
+	BucketAuditState       = []byte("auditState")
+	BucketWebhook          = []byte("webhooks")
+	BucketWebhookDelivery  = []byte("webhookDeliveries")
+	// Index of the deliveries of each webhook, by when they were created
+	BucketWebhookDeliveryByWebhook = []byte("webhookDeliveriesByWebhook")
+	// Index of the pending deliveries, by their next attempt
+	BucketWebhookDeliveryPending = []byte("webhookDeliveriesPending")
+	BucketAPIKey                 = []byte("apiKeys")
+	BucketLoginAttempts          = []byte("loginAttempts")
+	allBuckets                   = [][]byte{
 		BucketSession,
 		BucketUser,
 		BucketLocale,
@@ -274,6 +306,16 @@ var (
 		BucketTranslationValue,
 		BucketCategory,
 		BucketMissing,
+		BucketGlossary,
+		BucketRevision,
+		BucketAudit,
+		BucketAuditState,
+		BucketWebhook,
+		BucketWebhookDelivery,
+		BucketWebhookDeliveryByWebhook,
+		BucketWebhookDeliveryPending,
+		BucketAPIKey,
+		BucketLoginAttempts,
 		BucketSys,
 	}
 )
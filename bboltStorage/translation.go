@@ -29,6 +29,7 @@ func (b *BBolter) CreateTranslation(translation types.Translation) (types.Transl
 	if err != nil {
 		return translation, err
 	}
+	translation.Version = 1
 
 	var c types.Category
 	err = b.Update(func(tx *bolt.Tx) error {
@@ -58,12 +59,18 @@ func (b *BBolter) CreateTranslation(translation types.Translation) (types.Transl
 			if err != nil {
 				return err
 			}
+			if err := b.audit(tx, PubTypeCategory, PubVerbUpdate, translation.CreatedBy, c); err != nil {
+				return err
+			}
 		}
 		bytes, err := b.Marshal(translation)
 		if err != nil {
 			return err
 		}
-		return bucket.Put([]byte(translation.ID), bytes)
+		if err := bucket.Put([]byte(translation.ID), bytes); err != nil {
+			return err
+		}
+		return b.audit(tx, PubTypeTranslation, PubVerbCreate, translation.CreatedBy, translation)
 	})
 	if err != nil {
 		return translation, err
@@ -71,6 +78,7 @@ func (b *BBolter) CreateTranslation(translation types.Translation) (types.Transl
 
 	b.PublishChange(PubTypeTranslation, PubVerbCreate, translation)
 	b.PublishChange(PubTypeCategory, PubVerbUpdate, c)
+	b.addTranslationRevision(PubVerbCreate, translation, translation.CreatedBy)
 	go b.UpdateMissingWithNewIds(types.MissingTranslation{Translation: translation.Key, TranslationID: translation.ID})
 	return translation, err
 }
@@ -82,7 +90,7 @@ func (bb *BBolter) SoftDeleteTranslation(id string, byUser string, deleteTime *t
 	if byUser == "" {
 		return types.Translation{}, ErrMissingCreatedBy
 	}
-	tt, err := Update(bb, BucketTranslation, id, func(t types.Translation) (types.Translation, error) {
+	tt, err := Update(bb, BucketTranslation, id, byUser, func(t types.Translation) (types.Translation, error) {
 		if deleteTime == nil {
 			if t.Deleted == nil {
 				return t, fmt.Errorf("Cannot undelete a non-deleted item")
@@ -93,12 +101,16 @@ func (bb *BBolter) SoftDeleteTranslation(id string, byUser string, deleteTime *t
 			}
 		}
 		t.Deleted = deleteTime
+		t.Version++
 
 		t.UpdatedBy = byUser
 		t.UpdatedAt = nowPointer()
 
 		return t, nil
 	})
+	if err == nil {
+		bb.addTranslationRevision(PubVerbSoftDelete, tt, byUser)
+	}
 
 	return tt, err
 
@@ -125,6 +137,9 @@ func (b *BBolter) UpdateTranslation(id string, payload types.Translation) (types
 		if err != nil {
 			return err
 		}
+		if err := checkVersion(c.Version, payload.Version); err != nil {
+			return err
+		}
 		needsUpdate := false
 		// TODO: ensure key-uniqueness
 		if payload.Key != c.Key {
@@ -155,21 +170,27 @@ func (b *BBolter) UpdateTranslation(id string, payload types.Translation) (types
 		if !needsUpdate {
 			return ErrNoFieldsChanged
 		}
+		c.Version++
 		c.UpdatedAt = nowPointer()
+		if payload.UpdatedBy != "" {
+			c.UpdatedBy = payload.UpdatedBy
+		}
 
 		bytes, err := b.Marshal(c)
 		if err != nil {
 			return err
 		}
-		err = bucket.Put([]byte(c.ID), bytes)
-
-		return nil
+		if err := bucket.Put([]byte(c.ID), bytes); err != nil {
+			return err
+		}
+		return b.audit(tx, PubTypeTranslation, PubVerbUpdate, payload.UpdatedBy, c)
 	})
 	if err != nil {
 		return c, err
 	}
 
 	b.PublishChange(PubTypeTranslation, PubVerbUpdate, c)
+	b.addTranslationRevision(PubVerbUpdate, c, payload.UpdatedBy)
 	return c, err
 
 }
@@ -2,6 +2,7 @@ package bboltStorage
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/runar-rkmedia/skiver/types"
 	bolt "go.etcd.io/bbolt"
@@ -14,15 +15,28 @@ func (b *BBolter) GetTranslationValue(ID string) (*types.TranslationValue, error
 }
 
 func (bb *BBolter) UpdateTranslationValue(tv types.TranslationValue) (types.TranslationValue, error) {
-	var ex types.TranslationValue
-	err := bb.updater(tv.ID, BucketTranslationValue, func(b []byte) ([]byte, error) {
-		err := bb.Unmarshal(b, &ex)
-		if err != nil {
-			return nil, err
+	if tv.ID == "" {
+		return tv, ErrMissingIdArg
+	}
+	ex, err := Update(bb, BucketTranslationValue, tv.ID, tv.UpdatedBy, func(ex types.TranslationValue) (types.TranslationValue, error) {
+		if err := checkVersion(ex.Version, tv.Version); err != nil {
+			return ex, err
+		}
+		ex.Version++
+		if contentChanged(ex, tv) {
+			source := tv.Source
+			if source == "" {
+				source = ex.Source
+			}
+			state := tv.State
+			if state == "" || source == types.CreatorSourceTranslator {
+				state = types.StateNeedsReview
+			}
+			ex.ContentChanged(state)
 		}
-		err = ex.Entity.Update(tv.Entity)
+		err := ex.Entity.Update(tv.Entity)
 		if err != nil {
-			return nil, err
+			return ex, err
 		}
 		if tv.Value != "" {
 			ex.Value = tv.Value
@@ -39,12 +53,51 @@ func (bb *BBolter) UpdateTranslationValue(tv types.TranslationValue) (types.Tran
 				ex.Context[k] = v
 			}
 		}
-		return bb.Marshal(ex)
+		return ex, nil
 	})
-	bb.PublishChange(PubTypeTranslationValue, PubVerbUpdate, ex)
+	if err == nil {
+		bb.addTranslationValueRevision(PubVerbUpdate, ex, tv.UpdatedBy)
+	}
 
 	return ex, err
 }
+
+// Returns true if the payload would change the value, or any of its contexts
+func contentChanged(ex, payload types.TranslationValue) bool {
+	if payload.Value != "" && payload.Value != ex.Value {
+		return true
+	}
+	for k, v := range payload.Context {
+		if ex.Context[k] != v {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTranslationValueState changes the workflow-state of the value
+func (bb *BBolter) SetTranslationValueState(id string, review types.TranslationValueReview) (types.TranslationValue, error) {
+	if !review.State.Valid() {
+		return types.TranslationValue{}, fmt.Errorf("invalid state: %s", review.State)
+	}
+	if review.By == "" {
+		return types.TranslationValue{}, ErrMissingCreatedBy
+	}
+	tv, err := Update(bb, BucketTranslationValue, id, review.By, func(tv types.TranslationValue) (types.TranslationValue, error) {
+		if review.At.IsZero() {
+			review.At = time.Now()
+		}
+		tv.SetState(review)
+		tv.Version++
+		tv.UpdatedBy = review.By
+		tv.UpdatedAt = &review.At
+		return tv, nil
+	})
+	if err == nil {
+		bb.addTranslationValueRevision(PubVerbUpdate, tv, review.By)
+	}
+	return tv, err
+}
 func (b *BBolter) CreateTranslationValue(tv types.TranslationValue) (types.TranslationValue, error) {
 	if tv.LocaleID == "" {
 		return tv, fmt.Errorf("empty locale-id")
@@ -66,6 +119,11 @@ func (b *BBolter) CreateTranslationValue(tv types.TranslationValue) (types.Trans
 	if err != nil {
 		return tv, err
 	}
+	tv.Version = 1
+	// Values from the translator-services always need review
+	if tv.State == "" || tv.Source == types.CreatorSourceTranslator {
+		tv.State = types.StateNeedsReview
+	}
 
 	var t types.Translation
 	err = b.Update(func(tx *bolt.Tx) error {
@@ -95,12 +153,18 @@ func (b *BBolter) CreateTranslationValue(tv types.TranslationValue) (types.Trans
 			if err != nil {
 				return err
 			}
+			if err := b.audit(tx, PubTypeTranslation, PubVerbUpdate, tv.CreatedBy, t); err != nil {
+				return err
+			}
 		}
 		bytes, err := b.Marshal(tv)
 		if err != nil {
 			return err
 		}
-		return bucket.Put([]byte(tv.ID), bytes)
+		if err := bucket.Put([]byte(tv.ID), bytes); err != nil {
+			return err
+		}
+		return b.audit(tx, PubTypeTranslationValue, PubVerbCreate, tv.CreatedBy, tv)
 	})
 	if err != nil {
 		return tv, err
@@ -108,6 +172,7 @@ func (b *BBolter) CreateTranslationValue(tv types.TranslationValue) (types.Trans
 
 	b.PublishChange(PubTypeTranslationValue, PubVerbCreate, tv)
 	b.PublishChange(PubTypeTranslation, PubVerbUpdate, t)
+	b.addTranslationValueRevision(PubVerbCreate, tv, tv.CreatedBy)
 	return tv, err
 }
 
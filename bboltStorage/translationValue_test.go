@@ -0,0 +1,132 @@
+package bboltStorage
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/internal"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestTranslationValueWorkflow(t *testing.T) {
+	db := NewMockDB(t)
+	internal.NewMockTimeNow()
+	testza.AssertNoError(t, db.StandardSeed())
+
+	base := types.Project{Title: "project", ShortName: "p"}
+	base.CreatedBy = "jimb"
+	base.OrganizationID = "org-abc"
+	project, err := db.CreateProject(base)
+	testza.AssertNoError(t, err)
+	base.ID = project.ID
+	cat, err := db.CreateCategory(newBaseCategoryFromProject(base, "General"))
+	testza.AssertNoError(t, err)
+	tr, err := db.CreateTranslation(types.Translation{Entity: base.Entity, Key: "Welcome", CategoryID: cat.ID})
+	testza.AssertNoError(t, err)
+
+	newValue := func(localeID string, source types.CreatorSource) types.TranslationValue {
+		tv := types.TranslationValue{Entity: base.Entity, LocaleID: localeID, TranslationID: tr.ID, Value: "Hello", Source: source}
+		tv.ID = ""
+		tv.State = types.StateApproved
+		return tv
+	}
+
+	translated, err := db.CreateTranslationValue(newValue("loc-nb", types.CreatorSourceTranslator))
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, types.StateNeedsReview, translated.State, "Values from translator-services should always need review")
+	_, ok := translated.Approved()
+	testza.AssertFalse(t, ok)
+
+	tv, err := db.CreateTranslationValue(newValue("loc-en", types.CreatorSourceUser))
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, types.StateApproved, tv.State)
+
+	// Changing the content of an approved value requires a new review, but keeps the approved content
+	update := types.TranslationValue{Value: "Hi"}
+	update.ID = tv.ID
+	update.UpdatedBy = "bob"
+	tv, err = db.UpdateTranslationValue(update)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, types.StateNeedsReview, tv.State)
+	approved, ok := tv.Approved()
+	testza.AssertTrue(t, ok)
+	testza.AssertEqual(t, "Hello", approved.Value)
+
+	tv, err = db.SetTranslationValueState(tv.ID, types.TranslationValueReview{State: types.StateRejected, By: "alice", Comment: "Too informal"})
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, types.StateRejected, tv.State)
+	testza.AssertEqual(t, "alice", tv.Review.By)
+	testza.AssertEqual(t, "Too informal", tv.Review.Comment)
+	approved, _ = tv.Approved()
+	testza.AssertEqual(t, "Hello", approved.Value)
+
+	tv, err = db.SetTranslationValueState(tv.ID, types.TranslationValueReview{State: types.StateApproved, By: "alice"})
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Hi", tv.LastApproved.Value)
+	approved, _ = tv.Approved()
+	testza.AssertEqual(t, "Hi", approved.Value)
+
+	_, err = db.SetTranslationValueState(tv.ID, types.TranslationValueReview{State: "published", By: "alice"})
+	testza.AssertNotNil(t, err)
+}
+
+func TestVersionConflicts(t *testing.T) {
+	db := NewMockDB(t)
+	internal.NewMockTimeNow()
+	testza.AssertNoError(t, db.StandardSeed())
+
+	base := types.Project{Title: "project", ShortName: "p"}
+	base.CreatedBy = "jimb"
+	base.OrganizationID = "org-abc"
+	project, err := db.CreateProject(base)
+	testza.AssertNoError(t, err)
+	base.ID = project.ID
+	cat, err := db.CreateCategory(newBaseCategoryFromProject(base, "General"))
+	testza.AssertNoError(t, err)
+	tr, err := db.CreateTranslation(types.Translation{Entity: base.Entity, Key: "Welcome", CategoryID: cat.ID})
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, 1, tr.Version)
+	tvBase := types.TranslationValue{Entity: base.Entity, LocaleID: "loc-en", TranslationID: tr.ID, Value: "Hello"}
+	tvBase.ID = ""
+	tv, err := db.CreateTranslationValue(tvBase)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, 1, tv.Version)
+
+	// Two users start editing version 1 of the value
+	alice := types.TranslationValue{Value: "Hi", Version: tv.Version}
+	alice.ID = tv.ID
+	alice.UpdatedBy = "alice"
+	bob := alice
+	bob.Value = "Hey"
+	bob.UpdatedBy = "bob"
+
+	tv, err = db.UpdateTranslationValue(alice)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, 2, tv.Version)
+	_, err = db.UpdateTranslationValue(bob)
+	testza.AssertErrorIs(t, err, ErrVersionConflict)
+	current, err := db.GetTranslationValue(tv.ID)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Hi", current.Value, "The conflicting update should not be stored")
+	testza.AssertEqual(t, 2, current.Version)
+
+	// Updates without a version are not checked
+	bob.Version = 0
+	tv, err = db.UpdateTranslationValue(bob)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Hey", tv.Value)
+	testza.AssertEqual(t, 3, tv.Version)
+
+	tv, err = db.SetTranslationValueState(tv.ID, types.TranslationValueReview{State: types.StateApproved, By: "alice"})
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, 4, tv.Version, "Changes to the workflow-state are also versioned")
+
+	update := types.Translation{Key: tr.Key, Title: "Greeting", Version: 1}
+	update.UpdatedBy = "alice"
+	updated, err := db.UpdateTranslation(tr.ID, update)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, 2, updated.Version)
+	update.Title = "Welcome-message"
+	_, err = db.UpdateTranslation(tr.ID, update)
+	testza.AssertErrorIs(t, err, ErrVersionConflict)
+}
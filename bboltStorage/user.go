@@ -2,6 +2,8 @@ package bboltStorage
 
 import (
 	"fmt"
+	"reflect"
+	"time"
 
 	"github.com/runar-rkmedia/skiver/types"
 )
@@ -33,6 +35,12 @@ func userFilter(f, uu types.User) bool {
 	if f.ID != "" && f.ID != uu.ID {
 		return false
 	}
+	if f.Store != 0 && f.Store != uu.Store {
+		return false
+	}
+	if f.ExternalID != "" && f.ExternalID != uu.ExternalID {
+		return false
+	}
 	return true
 }
 
@@ -43,8 +51,18 @@ func (b *BBolter) CreateUser(user types.User) (types.User, error) {
 	if user.UserName == "" {
 		return user, fmt.Errorf("username must be set")
 	}
-	if len(user.PW) == 0 {
-		return user, fmt.Errorf("password must be set")
+	switch user.Store {
+	case types.UserStoreLocal:
+		// Invited users choose their password when they accept the invite
+		if len(user.PW) == 0 && user.Invite == nil {
+			return user, fmt.Errorf("password must be set")
+		}
+	case types.UserStoreOIDC:
+		if user.ExternalID == "" {
+			return user, fmt.Errorf("external id must be set")
+		}
+	default:
+		return user, fmt.Errorf("unknown store: %d", user.Store)
 	}
 	if v, err := b.FindUserByUserName(user.OrganizationID, user.UserName); err != nil {
 		return user, err
@@ -80,7 +98,7 @@ func (bb *BBolter) FindOneUser(filter ...types.User) (*types.User, error) {
 }
 
 func (bb *BBolter) UpdateUser(id string, payload types.UpdateUserPayload) (types.User, error) {
-	return Update(bb, BucketUser, id, func(t types.User) (types.User, error) {
+	return Update(bb, BucketUser, id, payload.UpdatedBy, func(t types.User) (types.User, error) {
 		shouldUpdate := false
 		if payload.UserName != nil && *payload.UserName != t.UserName {
 			t.UserName = *payload.UserName
@@ -94,6 +112,32 @@ func (bb *BBolter) UpdateUser(id string, payload types.UpdateUserPayload) (types
 			t.TemporaryPassword = *payload.TemporaryPassword
 			shouldUpdate = true
 		}
+		if payload.Roles != nil && !reflect.DeepEqual(*payload.Roles, t.Roles) {
+			t.Roles = *payload.Roles
+			shouldUpdate = true
+		}
+		if payload.MFA != nil && !reflect.DeepEqual(payload.MFA, t.MFA) {
+			t.MFA = payload.MFA
+			if payload.MFA.Secret == "" && !payload.MFA.Enabled() {
+				t.MFA = nil
+			}
+			shouldUpdate = true
+		}
+		if payload.Active != nil && *payload.Active != t.Active {
+			t.Active = *payload.Active
+			shouldUpdate = true
+		}
+		if payload.Permissions != nil && *payload.Permissions != t.Permissions() {
+			t.SetPermissions(*payload.Permissions)
+			shouldUpdate = true
+		}
+		if payload.Invite != nil && !reflect.DeepEqual(payload.Invite, t.Invite) {
+			t.Invite = payload.Invite
+			if len(payload.Invite.Hash) == 0 {
+				t.Invite = nil
+			}
+			shouldUpdate = true
+		}
 		if !shouldUpdate {
 			return t, ErrNoFieldsChanged
 		}
@@ -105,3 +149,46 @@ func (bb *BBolter) UpdateUser(id string, payload types.UpdateUserPayload) (types
 		return t, nil
 	})
 }
+
+// SoftDeleteUser removes the user from its organization. The user is also deactivated,
+// so that it cannot log in, and is kept, so that the changes it made can still be attributed to it.
+func (bb *BBolter) SoftDeleteUser(id string, byUser string, deleteTime *time.Time) (types.User, error) {
+	if id == "" {
+		return types.User{}, ErrMissingIdArg
+	}
+	if byUser == "" {
+		return types.User{}, ErrMissingCreatedBy
+	}
+	return Update(bb, BucketUser, id, byUser, func(u types.User) (types.User, error) {
+		if deleteTime == nil {
+			return u, fmt.Errorf("Removed users cannot be restored")
+		}
+		if u.Deleted != nil {
+			return u, fmt.Errorf("The user is already removed")
+		}
+		u.Deleted = deleteTime
+		u.Active = false
+		u.UpdatedBy = byUser
+		u.UpdatedAt = nowPointer()
+		return u, nil
+	})
+}
+
+// UpdateUserMFA lets f verify and update the multi-factor authentication of the user within a transaction,
+// so that codes cannot be used twice, even by concurrent requests. If f returns an error, nothing is changed.
+// This happens on every login, so it is not published, and does not appear in the audit-log.
+func (bb *BBolter) UpdateUserMFA(id string, f func(mfa *types.UserMFA) error) error {
+	return bb.updater(id, BucketUser, func(b []byte) ([]byte, error) {
+		var u types.User
+		if err := bb.Unmarshal(b, &u); err != nil {
+			return nil, err
+		}
+		if u.MFA == nil {
+			return nil, ErrNotFound
+		}
+		if err := f(u.MFA); err != nil {
+			return nil, err
+		}
+		return bb.Marshal(u)
+	})
+}
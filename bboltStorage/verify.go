@@ -0,0 +1,75 @@
+package bboltStorage
+
+import (
+	"fmt"
+
+	"github.com/runar-rkmedia/skiver/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+// VerifyReport is the result of verifying the database
+type VerifyReport struct {
+	// Inconsistencies within the database-file, as reported by bbolt, like pages that are unreachable.
+	Errors []string
+	// Items that could not be decoded into their type, as bucket/key: error
+	InvalidItems []string
+	// Number of items in each bucket
+	Counts map[string]int
+}
+
+func (r VerifyReport) Ok() bool {
+	return len(r.Errors) == 0 && len(r.InvalidItems) == 0
+}
+
+func decodeAs[T any](m Marshaller, b []byte) error {
+	var j T
+	return m.Unmarshal(b, &j)
+}
+
+// The buckets where every item is of a single type. The other buckets are only counted.
+var bucketDecoders = map[string]func(m Marshaller, b []byte) error{
+	string(BucketUser):             decodeAs[types.User],
+	string(BucketLocale):           decodeAs[types.Locale],
+	string(BucketSnapshot):         decodeAs[types.ProjectSnapshot],
+	string(BucketTranslation):      decodeAs[types.Translation],
+	string(BucketProject):          decodeAs[types.Project],
+	string(BucketOrganization):     decodeAs[types.Organization],
+	string(BucketTranslationValue): decodeAs[types.TranslationValue],
+	string(BucketCategory):         decodeAs[types.Category],
+	string(BucketMissing):          decodeAs[types.MissingTranslation],
+	string(BucketGlossary):         decodeAs[types.GlossaryEntry],
+	string(BucketWebhook):          decodeAs[types.Webhook],
+	string(BucketAPIKey):           decodeAs[types.APIKey],
+	string(BucketLoginAttempts):    decodeAs[types.LoginAttempts],
+}
+
+// Verify checks the consistency of the database-file, and that the items within it can be decoded.
+func (bb *BBolter) Verify() (VerifyReport, error) {
+	r := VerifyReport{Counts: map[string]int{}}
+	err := bb.View(func(tx *bolt.Tx) error {
+		for err := range tx.Check() {
+			r.Errors = append(r.Errors, err.Error())
+		}
+		for _, name := range allBuckets {
+			bucket := tx.Bucket(name)
+			if bucket == nil {
+				r.Errors = append(r.Errors, fmt.Sprintf("bucket %s is missing", name))
+				continue
+			}
+			decode := bucketDecoders[string(name)]
+			c := bucket.Cursor()
+			for k, v := c.First(); k != nil; k, v = c.Next() {
+				r.Counts[string(name)]++
+				// Nested buckets have no value
+				if decode == nil || v == nil {
+					continue
+				}
+				if err := decode(bb, v); err != nil {
+					r.InvalidItems = append(r.InvalidItems, fmt.Sprintf("%s/%s: %s", name, k, err))
+				}
+			}
+		}
+		return nil
+	})
+	return r, err
+}
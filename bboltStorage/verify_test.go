@@ -0,0 +1,33 @@
+package bboltStorage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestVerify(t *testing.T) {
+	mock := NewMockDB(t)
+	bb := mock.Storage.(*BBolter)
+	_, err := types.SeedUsers(bb, nil, func(s string) ([]byte, error) { return []byte("mock-" + s), nil })
+	testza.AssertNoError(t, err)
+
+	r, err := bb.Verify()
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, r.Ok(), r)
+	testza.AssertEqual(t, 1, r.Counts[string(BucketUser)])
+	testza.AssertEqual(t, 1, r.Counts[string(BucketOrganization)])
+
+	err = bb.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(BucketUser).Put([]byte("broken"), []byte("not gob"))
+	})
+	testza.AssertNoError(t, err)
+	r, err = bb.Verify()
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, r.Ok())
+	testza.AssertLen(t, r.InvalidItems, 1)
+	testza.AssertTrue(t, strings.HasPrefix(r.InvalidItems[0], "users/broken: "), r.InvalidItems)
+}
@@ -0,0 +1,263 @@
+package bboltStorage
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+func (bb *BBolter) GetWebhook(id string) (*types.Webhook, error) {
+	return Get[types.Webhook](bb, BucketWebhook, id)
+}
+
+func (bb *BBolter) FindWebhooks(max int, filter ...types.Webhook) (map[string]types.Webhook, error) {
+	return Find(bb, BucketWebhook, max, func(w types.Webhook) bool {
+		if len(filter) == 0 {
+			return true
+		}
+		for _, f := range filter {
+			if f.OrganizationID != "" && f.OrganizationID != w.OrganizationID {
+				continue
+			}
+			if f.ID != "" && f.ID != w.ID {
+				continue
+			}
+			return true
+		}
+		return false
+	})
+}
+
+func (bb *BBolter) CreateWebhook(webhook types.Webhook) (types.Webhook, error) {
+	if webhook.URL == "" {
+		return webhook, fmt.Errorf("URL must be set")
+	}
+	if webhook.Secret == "" {
+		return webhook, fmt.Errorf("Secret must be set")
+	}
+	entity, err := bb.NewEntity(webhook.Entity)
+	if err != nil {
+		return webhook, err
+	}
+	webhook.Entity = entity
+
+	err = Create(bb, BucketWebhook, webhook)
+	return webhook, err
+}
+
+// Replaces the url, events and disabled-flag of the webhook.
+// The secret is only replaced if set.
+func (bb *BBolter) UpdateWebhook(id string, payload types.Webhook) (types.Webhook, error) {
+	if id == "" {
+		return payload, ErrMissingIdArg
+	}
+	if payload.URL == "" {
+		return payload, fmt.Errorf("URL must be set")
+	}
+	return Update(bb, BucketWebhook, id, payload.UpdatedBy, func(w types.Webhook) (types.Webhook, error) {
+		w.URL = payload.URL
+		w.Events = payload.Events
+		w.Disabled = payload.Disabled
+		if payload.Secret != "" {
+			w.Secret = payload.Secret
+		}
+		w.UpdatedBy = payload.UpdatedBy
+		w.UpdatedAt = nowPointer()
+		return w, nil
+	})
+}
+
+func (bb *BBolter) SoftDeleteWebhook(id string, byUser string, deleteTime *time.Time) (types.Webhook, error) {
+	if id == "" {
+		return types.Webhook{}, ErrMissingIdArg
+	}
+	if byUser == "" {
+		return types.Webhook{}, ErrMissingCreatedBy
+	}
+	return Update(bb, BucketWebhook, id, byUser, func(w types.Webhook) (types.Webhook, error) {
+		if deleteTime == nil {
+			if w.Deleted == nil {
+				return w, fmt.Errorf("Cannot undelete a non-deleted item")
+			}
+		} else if w.Deleted != nil {
+			return w, fmt.Errorf("Cannot delete an already deleted item")
+		}
+		w.Deleted = deleteTime
+		w.UpdatedBy = byUser
+		w.UpdatedAt = nowPointer()
+		return w, nil
+	})
+}
+
+func (bb *BBolter) GetWebhookDelivery(id string) (*types.WebhookDelivery, error) {
+	return Get[types.WebhookDelivery](bb, BucketWebhookDelivery, id)
+}
+
+// Deliveries are stored by their id, and are indexed by their webhook, and while pending, by their next attempt.
+// The keys of the indexes sort by time, so that the dispatcher and the listing of deliveries
+// do not need to read all the deliveries.
+
+func deliveryTimeKey(t time.Time) string {
+	return fmt.Sprintf("%020d", t.UnixNano())
+}
+
+func deliveryWebhookPrefix(webhookID string) []byte {
+	return []byte(webhookID + "/")
+}
+
+func deliveryWebhookKey(d types.WebhookDelivery) []byte {
+	return []byte(fmt.Sprintf("%s/%s/%s", d.WebhookID, deliveryTimeKey(d.CreatedAt), d.ID))
+}
+
+// Returns the key of the delivery in the index of pending deliveries, or nil if it is not pending
+func deliveryPendingKey(d types.WebhookDelivery) []byte {
+	if d.Status != types.WebhookDeliveryPending || d.NextAttempt == nil {
+		return nil
+	}
+	return []byte(fmt.Sprintf("%s/%s", deliveryTimeKey(*d.NextAttempt), d.ID))
+}
+
+// FindWebhookDeliveries returns the deliveries of the webhook, newest first
+func (bb *BBolter) FindWebhookDeliveries(webhookID string, max int) ([]types.WebhookDelivery, error) {
+	deliveries := []types.WebhookDelivery{}
+	err := bb.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(BucketWebhookDelivery)
+		prefix := deliveryWebhookPrefix(webhookID)
+		c := tx.Bucket(BucketWebhookDeliveryByWebhook).Cursor()
+		// The time-keys are digits, so this seeks past the last delivery of the webhook
+		k, v := c.Seek(append(append([]byte{}, prefix...), '~'))
+		if k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+		for ; k != nil && bytes.HasPrefix(k, prefix); k, v = c.Prev() {
+			if max > 0 && len(deliveries) >= max {
+				return nil
+			}
+			var d types.WebhookDelivery
+			if err := bb.Unmarshal(bucket.Get(v), &d); err != nil {
+				return err
+			}
+			deliveries = append(deliveries, d)
+		}
+		return nil
+	})
+	return deliveries, err
+}
+
+// FindDueWebhookDeliveries returns the pending deliveries that are due for an attempt at the time, oldest first
+func (bb *BBolter) FindDueWebhookDeliveries(at time.Time) ([]types.WebhookDelivery, error) {
+	var deliveries []types.WebhookDelivery
+	due := []byte(deliveryTimeKey(at))
+	err := bb.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(BucketWebhookDelivery)
+		c := tx.Bucket(BucketWebhookDeliveryPending).Cursor()
+		for k, v := c.First(); k != nil && bytes.Compare(k[:len(due)], due) <= 0; k, v = c.Next() {
+			var d types.WebhookDelivery
+			if err := bb.Unmarshal(bucket.Get(v), &d); err != nil {
+				return err
+			}
+			deliveries = append(deliveries, d)
+		}
+		return nil
+	})
+	return deliveries, err
+}
+
+// SaveWebhookDelivery creates or replaces the delivery, and removes the completed deliveries of the webhook
+// that are outside of the retention.
+// Deliveries are only a log of the webhook, and changes to them are not published.
+func (bb *BBolter) SaveWebhookDelivery(delivery types.WebhookDelivery) (types.WebhookDelivery, error) {
+	if delivery.WebhookID == "" {
+		return delivery, fmt.Errorf("Missing WebhookID: %w", ErrMissingIdArg)
+	}
+	if delivery.ID == "" {
+		delivery.ID = bb.newUniqueID()
+	}
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now()
+	}
+	err := bb.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(BucketWebhookDelivery)
+		pending := tx.Bucket(BucketWebhookDeliveryPending)
+		if b := bucket.Get([]byte(delivery.ID)); b != nil {
+			var existing types.WebhookDelivery
+			if err := bb.Unmarshal(b, &existing); err != nil {
+				return err
+			}
+			if k := deliveryPendingKey(existing); k != nil {
+				if err := pending.Delete(k); err != nil {
+					return err
+				}
+			}
+		}
+		b, err := bb.Marshal(delivery)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put([]byte(delivery.ID), b); err != nil {
+			return err
+		}
+		if err := tx.Bucket(BucketWebhookDeliveryByWebhook).Put(deliveryWebhookKey(delivery), []byte(delivery.ID)); err != nil {
+			return err
+		}
+		if k := deliveryPendingKey(delivery); k != nil {
+			return pending.Put(k, []byte(delivery.ID))
+		}
+		return bb.pruneWebhookDeliveries(tx, delivery.WebhookID, time.Now())
+	})
+	return delivery, err
+}
+
+// Removes the completed deliveries of the webhook that are outside of the retention, oldest first.
+// Pending deliveries are kept until they complete.
+func (bb *BBolter) pruneWebhookDeliveries(tx *bolt.Tx, webhookID string, now time.Time) error {
+	retention := bb.webhookDeliveryRetention
+	if retention.MaxCount <= 0 && retention.MaxAge <= 0 {
+		return nil
+	}
+	bucket := tx.Bucket(BucketWebhookDelivery)
+	index := tx.Bucket(BucketWebhookDeliveryByWebhook)
+	prefix := deliveryWebhookPrefix(webhookID)
+	var keys [][]byte
+	c := index.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		keys = append(keys, append([]byte{}, k...))
+	}
+	excess := 0
+	if retention.MaxCount > 0 && len(keys) > retention.MaxCount {
+		excess = len(keys) - retention.MaxCount
+	}
+	var cutoff []byte
+	if retention.MaxAge > 0 {
+		cutoff = []byte(deliveryTimeKey(now.Add(-retention.MaxAge)))
+	}
+	for _, k := range keys {
+		expired := cutoff != nil && bytes.Compare(k[len(prefix):len(prefix)+len(cutoff)], cutoff) < 0
+		if excess == 0 && !expired {
+			break
+		}
+		id := index.Get(k)
+		var d types.WebhookDelivery
+		if err := bb.Unmarshal(bucket.Get(id), &d); err != nil {
+			return err
+		}
+		if d.Status == types.WebhookDeliveryPending {
+			continue
+		}
+		if err := bucket.Delete(id); err != nil {
+			return err
+		}
+		if err := index.Delete(k); err != nil {
+			return err
+		}
+		if excess > 0 {
+			excess--
+		}
+	}
+	return nil
+}
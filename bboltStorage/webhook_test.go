@@ -0,0 +1,67 @@
+package bboltStorage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	db := NewMockDB(t)
+	db.Storage.(*BBolter).webhookDeliveryRetention = types.WebhookDeliveryRetention{MaxCount: 3}
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+	save := func(d types.WebhookDelivery) types.WebhookDelivery {
+		t.Helper()
+		d, err := db.SaveWebhookDelivery(d)
+		testza.AssertNoError(t, err)
+		return d
+	}
+	ids := func(deliveries []types.WebhookDelivery, err error) []string {
+		t.Helper()
+		testza.AssertNoError(t, err)
+		var ids []string
+		for _, d := range deliveries {
+			ids = append(ids, d.ID)
+		}
+		return ids
+	}
+
+	first := save(types.WebhookDelivery{ID: "first", WebhookID: "hook", CreatedAt: now.Add(-4 * time.Minute), Status: types.WebhookDeliveryPending, NextAttempt: at(time.Minute)})
+	save(types.WebhookDelivery{ID: "second", WebhookID: "hook", CreatedAt: now.Add(-3 * time.Minute), Status: types.WebhookDeliveryPending, NextAttempt: at(-time.Minute)})
+	save(types.WebhookDelivery{ID: "other", WebhookID: "other-hook", CreatedAt: now.Add(-3 * time.Minute), Status: types.WebhookDeliveryPending, NextAttempt: at(-2 * time.Minute)})
+
+	testza.AssertEqual(t, []string{"other", "second"}, ids(db.FindDueWebhookDeliveries(now)), "Only due deliveries, oldest attempt first")
+	testza.AssertEqual(t, []string{"other", "second", "first"}, ids(db.FindDueWebhookDeliveries(now.Add(time.Hour))))
+
+	first.NextAttempt = at(-3 * time.Minute)
+	first = save(first)
+	testza.AssertEqual(t, []string{"first", "other", "second"}, ids(db.FindDueWebhookDeliveries(now)), "A rescheduled delivery should only be indexed by its new attempt")
+	first.Status = types.WebhookDeliverySuccess
+	first.NextAttempt = nil
+	save(first)
+	testza.AssertEqual(t, []string{"other", "second"}, ids(db.FindDueWebhookDeliveries(now)), "Completed deliveries are no longer due")
+
+	save(types.WebhookDelivery{ID: "third", WebhookID: "hook", CreatedAt: now.Add(-2 * time.Minute), Status: types.WebhookDeliveryFailed})
+	testza.AssertEqual(t, []string{"third", "second", "first"}, ids(db.FindWebhookDeliveries("hook", 0)), "Newest first")
+	testza.AssertEqual(t, []string{"third", "second"}, ids(db.FindWebhookDeliveries("hook", 2)))
+	testza.AssertEqual(t, []string{"other"}, ids(db.FindWebhookDeliveries("other-hook", 0)))
+
+	save(types.WebhookDelivery{ID: "fourth", WebhookID: "hook", CreatedAt: now.Add(-time.Minute), Status: types.WebhookDeliverySuccess})
+	testza.AssertEqual(t, []string{"fourth", "third", "second"}, ids(db.FindWebhookDeliveries("hook", 0)), "The oldest completed delivery should be removed")
+	removed, err := db.GetWebhookDelivery("first")
+	testza.AssertNoError(t, err)
+	testza.AssertNil(t, removed)
+
+	save(types.WebhookDelivery{ID: "fifth", WebhookID: "hook", CreatedAt: now, Status: types.WebhookDeliverySuccess})
+	testza.AssertEqual(t, []string{"fifth", "fourth", "second"}, ids(db.FindWebhookDeliveries("hook", 0)), "Pending deliveries should be kept")
+
+	db.Storage.(*BBolter).webhookDeliveryRetention = types.WebhookDeliveryRetention{MaxAge: 30 * time.Second}
+	save(types.WebhookDelivery{ID: "sixth", WebhookID: "hook", CreatedAt: now, Status: types.WebhookDeliverySuccess})
+	testza.AssertEqual(t, []string{"sixth", "fifth", "second"}, ids(db.FindWebhookDeliveries("hook", 0)), "Old completed deliveries should be removed")
+}
@@ -0,0 +1,910 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/runar-rkmedia/go-common/logger"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	cfg "github.com/runar-rkmedia/skiver/config"
+	"github.com/runar-rkmedia/skiver/handlers"
+	"github.com/runar-rkmedia/skiver/importexport"
+	"github.com/runar-rkmedia/skiver/localuser"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Used as CreatedBy and UpdatedBy for changes made from the command-line, like "seeder" is for seeded items
+const cliUserID = "cli"
+
+// A command in the command-line-interface. A command either runs, or has subcommands.
+type command struct {
+	name        string
+	description string
+	// Declares the flags of the command, and returns the function that runs it.
+	flags       func(fs *flag.FlagSet) func(c *cli) error
+	subcommands []command
+}
+
+// cli holds the global options, which are shared by all the commands
+type cli struct {
+	out        io.Writer
+	configFile string
+	dbPath     string
+	logLevel   string
+	config     *cfg.Config
+}
+
+func (c *cli) getConfig() (*cfg.Config, error) {
+	if c.config != nil {
+		return c.config, nil
+	}
+	if c.configFile != "" {
+		cfg.SetConfigFile(c.configFile)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if c.dbPath != "" {
+		config.Api.DBLocation = c.dbPath
+	}
+	c.config = config
+	return config, nil
+}
+
+func (c *cli) logger() logger.AppLogger {
+	logger.InitLogger(logger.LogConfig{Level: c.logLevel, Format: "human"})
+	return logger.GetLogger("cli")
+}
+
+// Opens the database for a command that operates directly on it.
+// bbolt only allows a single process to open the database, so this fails while the server is running.
+func (c *cli) openDB() (*bboltStorage.BBolter, error) {
+	config, err := c.getConfig()
+	if err != nil {
+		return nil, err
+	}
+	path := config.Api.DBLocation
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("No database found at %s: %w", path, err)
+	}
+	events := NewMultiPublisher()
+	db, err := openDatabase(c.logger(), config, &events)
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, fmt.Errorf("The database at %s is in use. Stop the server before running this command", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open the database at %s: %w", path, err)
+	}
+	return &db, nil
+}
+
+func (c *cli) table() *tabwriter.Writer {
+	return tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+}
+
+var commands = []command{
+	{
+		name:        "serve",
+		description: "Runs the api-server. This is the default command",
+		flags:       serveCommand,
+	},
+	{
+		name:        "user",
+		description: "Manages users",
+		subcommands: []command{
+			{name: "create", description: "Creates a local user", flags: userCreateCommand},
+			{name: "reset-password", description: "Replaces the password of a local user, clears its multi-factor authentication, and revokes its sessions", flags: userResetPasswordCommand},
+			{name: "list", description: "Lists users", flags: userListCommand},
+		},
+	},
+	{
+		name:        "org",
+		description: "Manages organizations",
+		subcommands: []command{
+			{name: "create", description: "Creates an organization", flags: orgCreateCommand},
+			{name: "list", description: "Lists organizations", flags: orgListCommand},
+		},
+	},
+	{
+		name:        "db",
+		description: "Maintains the database",
+		subcommands: []command{
+			{name: "backup", description: "Writes a compacted copy of the database to a file", flags: dbBackupCommand},
+			{name: "restore", description: "Replaces the database with a backup. The current database is kept with a .bk-suffix, which never overwrites an earlier one", flags: dbRestoreCommand},
+			{name: "compact", description: "Compacts the database, to reclaim the space of deleted items", flags: dbCompactCommand},
+			{name: "migrate", description: "Runs the migrations that the database needs. The server also runs these on start", flags: dbMigrateCommand},
+			{name: "verify", description: "Checks the consistency of the database, and that all items can be read", flags: dbVerifyCommand},
+		},
+	},
+	{
+		name:        "snapshot",
+		description: "Manages snapshots of projects",
+		subcommands: []command{
+			{name: "list", description: "Lists the tagged snapshots", flags: snapshotListCommand},
+			{name: "create", description: "Creates a snapshot of a project, and tags it", flags: snapshotCreateCommand},
+		},
+	},
+	{
+		name:        "export",
+		description: "Exports a project, like the export-endpoint does",
+		flags:       exportCommand,
+	},
+	{
+		name:        "config",
+		description: "Works with the configuration",
+		subcommands: []command{
+			{name: "validate", description: "Checks the configuration for unknown keys and invalid values", flags: configValidateCommand},
+			{name: "sample", description: "Outputs a sample configuration", flags: configSampleCommand},
+		},
+	},
+}
+
+func printCommands(w io.Writer, path string, cmds []command) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags]\n\nCommands:\n", path)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, cmd := range cmds {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.description)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\nRun '%s <command> -h' for the flags of a command.\n", path)
+}
+
+// runCli runs the command in the arguments. Without a command, the server is started.
+func runCli(args []string, out io.Writer) error {
+	c := &cli{out: out}
+	fs := flag.NewFlagSet("skiver", flag.ContinueOnError)
+	fs.StringVar(&c.configFile, "config", "", "Path to the configuration-file. Defaults to skiver.toml in the default paths")
+	fs.StringVar(&c.dbPath, "db", "", "Path to the database, instead of the one in the configuration")
+	fs.StringVar(&c.logLevel, "log-level", "error", "Log-level for the commands, except serve, which uses the configuration")
+	fs.Usage = func() {
+		printCommands(fs.Output(), "skiver [global flags]", commands)
+		fmt.Fprintln(fs.Output(), "\nGlobal flags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	args = fs.Args()
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+	return runCommand(c, "skiver", commands, args)
+}
+
+func runCommand(c *cli, path string, cmds []command, args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printCommands(c.out, path, cmds)
+		return nil
+	}
+	for _, cmd := range cmds {
+		if cmd.name != args[0] {
+			continue
+		}
+		path := path + " " + cmd.name
+		if len(cmd.subcommands) > 0 {
+			return runCommand(c, path, cmd.subcommands, args[1:])
+		}
+		fs := flag.NewFlagSet(path, flag.ContinueOnError)
+		run := cmd.flags(fs)
+		fs.Usage = func() {
+			fmt.Fprintf(fs.Output(), "Usage: %s [flags]\n\n%s\n\nFlags:\n", path, cmd.description)
+			fs.PrintDefaults()
+		}
+		if err := fs.Parse(args[1:]); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("Unexpected arguments for %s: %s", path, strings.Join(fs.Args(), " "))
+		}
+		return run(c)
+	}
+	printCommands(c.out, path, cmds)
+	return fmt.Errorf("Unknown command: %s %s", path, args[0])
+}
+
+// Returns an error for flags that are required, but not set
+func requireFlags(flags map[string]string) error {
+	var missing []string
+	for name, value := range flags {
+		if value == "" {
+			missing = append(missing, "-"+name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("Missing required flags: %s", strings.Join(missing, ", "))
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+func serveCommand(fs *flag.FlagSet) func(c *cli) error {
+	return func(c *cli) error {
+		config, err := c.getConfig()
+		if err != nil {
+			return err
+		}
+		serve(config)
+		return nil
+	}
+}
+
+func configValidateCommand(fs *flag.FlagSet) func(c *cli) error {
+	return func(c *cli) error {
+		// The configuration is not decoded through getConfig, since it panics on values of the wrong type
+		if c.configFile != "" {
+			cfg.SetConfigFile(c.configFile)
+		}
+		if err := cfg.InitConfig(); err != nil {
+			return err
+		}
+		errs := cfg.Validate()
+		if len(errs) == 0 {
+			fmt.Fprintln(c.out, "The configuration is valid")
+			return nil
+		}
+		for _, err := range errs {
+			fmt.Fprintln(c.out, err)
+		}
+		return fmt.Errorf("The configuration has %d problems", len(errs))
+	}
+}
+
+func configSampleCommand(fs *flag.FlagSet) func(c *cli) error {
+	return func(c *cli) error {
+		return cfg.WriteToml(c.out, cfg.CreateSampleComfig())
+	}
+}
+
+// Returns the organization by id or title. If not set, the organization is only returned if there is only one.
+func findOrganization(db *bboltStorage.BBolter, idOrTitle string) (*types.Organization, error) {
+	if idOrTitle != "" {
+		org, err := db.FindOrganizationByIdOrTitle(idOrTitle)
+		if err != nil {
+			return nil, err
+		}
+		if org == nil {
+			return nil, fmt.Errorf("Organization not found: %s", idOrTitle)
+		}
+		return org, nil
+	}
+	orgs, err := db.GetOrganizations()
+	if err != nil {
+		return nil, err
+	}
+	if len(orgs) != 1 {
+		return nil, fmt.Errorf("There are %d organizations, so -org must be set", len(orgs))
+	}
+	for _, org := range orgs {
+		return &org, nil
+	}
+	return nil, nil
+}
+
+// Returns the password, or a new one if it is not set
+func passwordOrGenerated(password string) (string, bool, error) {
+	if password != "" {
+		return password, false, nil
+	}
+	password, err := utils.GenerateSecret(9)
+	return password, true, err
+}
+
+func userCreateCommand(fs *flag.FlagSet) func(c *cli) error {
+	username := fs.String("username", "", "The username (required)")
+	password := fs.String("password", "", "The password. If not set, a temporary password is created, which the user must change on the first login")
+	org := fs.String("org", "", "Id or title of the organization. Not required if there is only one")
+	admin := fs.Bool("admin", false, "Gives the user all permissions, including creating organizations")
+	return func(c *cli) error {
+		if err := requireFlags(map[string]string{"username": *username}); err != nil {
+			return err
+		}
+		db, err := c.openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		organization, err := findOrganization(db, *org)
+		if err != nil {
+			return err
+		}
+		// Logins find users by their username alone, so it must be unique across organizations
+		existing, err := db.FindUserByUserName("", *username)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return fmt.Errorf("The username is taken: %s", *username)
+		}
+		pass, generated, err := passwordOrGenerated(*password)
+		if err != nil {
+			return err
+		}
+		pw := localuser.NewPwHasher([]byte(pwsalt))
+		hashed, err := pw.Hash(pass)
+		if err != nil {
+			return err
+		}
+		permissions := handlers.DefaultUserPermissions
+		if *admin {
+			permissions = types.UserPermissions{
+				CanCreateOrganization: true,
+				CanUpdateOrganization: true,
+				CanCreateUsers:        true,
+				CanUpdateUsers:        true,
+				CanCreateProjects:     true,
+				CanUpdateProjects:     true,
+				CanCreateLocales:      true,
+				CanUpdateLocales:      true,
+				CanCreateTranslations: true,
+				CanUpdateTranslations: true,
+				CanManageSnapshots:    true,
+				CanReviewTranslations: true,
+			}
+		}
+		u := types.User{
+			UserName:          *username,
+			Active:            true,
+			Store:             types.UserStoreLocal,
+			PW:                hashed,
+			TemporaryPassword: generated,
+		}
+		u.SetPermissions(permissions)
+		u.CreatedBy = cliUserID
+		u.OrganizationID = organization.ID
+		user, err := db.CreateUser(u)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "Created user %s (%s) in %s\n", user.UserName, user.ID, organization.Title)
+		if generated {
+			fmt.Fprintf(c.out, "Temporary password: %s\n", pass)
+		}
+		return nil
+	}
+}
+
+func userResetPasswordCommand(fs *flag.FlagSet) func(c *cli) error {
+	username := fs.String("username", "", "The username (required)")
+	password := fs.String("password", "", "The new password. If not set, a temporary password is created")
+	return func(c *cli) error {
+		if err := requireFlags(map[string]string{"username": *username}); err != nil {
+			return err
+		}
+		db, err := c.openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		user, err := db.FindUserByUserName("", *username)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return fmt.Errorf("User not found: %s", *username)
+		}
+		if user.Store != types.UserStoreLocal {
+			return fmt.Errorf("The password of %s is handled by its identity-provider", *username)
+		}
+		pass, generated, err := passwordOrGenerated(*password)
+		if err != nil {
+			return err
+		}
+		pw := localuser.NewPwHasher([]byte(pwsalt))
+		hashed, err := pw.Hash(pass)
+		if err != nil {
+			return err
+		}
+		// The user must always choose a new password, since the one that reset it knows this one
+		temporary := true
+		payload := types.UpdateUserPayload{PW: &hashed, TemporaryPassword: &temporary}
+		payload.UpdatedBy = cliUserID
+		// The user may have lost their authenticator-app as well.
+		if user.MFA != nil {
+			payload.MFA = &types.UserMFA{}
+		}
+		if _, err := db.UpdateUser(user.ID, payload); err != nil {
+			return err
+		}
+		config, err := c.getConfig()
+		if err != nil {
+			return err
+		}
+		sessions, err := newUserSessions(config, db)
+		if err != nil {
+			return err
+		}
+		if err := sessions.ClearAllSessionsForUser(user.ID); err != nil {
+			return fmt.Errorf("Failed to clear the sessions for the user: %w", err)
+		}
+		// Sessions in memory belong to the running server, so only those in the database can be revoked from here
+		if config.Authentication.SessionStore == "memory" {
+			fmt.Fprintf(c.out, "The password of %s was reset\n", user.UserName)
+			fmt.Fprintln(c.out, "Warning: The sessions are stored in memory, so those held by a running server are not revoked until it is restarted")
+		} else {
+			fmt.Fprintf(c.out, "The password of %s was reset, and its sessions were revoked\n", user.UserName)
+		}
+		if user.MFA != nil {
+			fmt.Fprintln(c.out, "Multi-factor authentication was cleared")
+		}
+		if generated {
+			fmt.Fprintf(c.out, "Temporary password: %s\n", pass)
+		}
+		return nil
+	}
+}
+
+func userListCommand(fs *flag.FlagSet) func(c *cli) error {
+	org := fs.String("org", "", "Id or title of an organization, to only list its users")
+	return func(c *cli) error {
+		db, err := c.openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		var filter []types.User
+		if *org != "" {
+			organization, err := findOrganization(db, *org)
+			if err != nil {
+				return err
+			}
+			f := types.User{}
+			f.OrganizationID = organization.ID
+			filter = append(filter, f)
+		}
+		users, err := db.FindUsers(-1, filter...)
+		if err != nil {
+			return err
+		}
+		orgs, err := db.GetOrganizations()
+		if err != nil {
+			return err
+		}
+		list := make([]types.User, 0, len(users))
+		for _, u := range users {
+			list = append(list, u)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].UserName < list[j].UserName })
+		tw := c.table()
+		fmt.Fprintln(tw, "ID\tUSERNAME\tORGANIZATION\tSTORE\tACTIVE\tMFA\tCREATED")
+		for _, u := range list {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\t%t\t%s\n", u.ID, u.UserName, orgs[u.OrganizationID].Title, u.Store, u.Active, u.MFA.Enabled(), formatTime(u.CreatedAt))
+		}
+		return tw.Flush()
+	}
+}
+
+func orgCreateCommand(fs *flag.FlagSet) func(c *cli) error {
+	title := fs.String("title", "", "The title (required)")
+	description := fs.String("description", "", "The description")
+	return func(c *cli) error {
+		if err := requireFlags(map[string]string{"title": *title}); err != nil {
+			return err
+		}
+		db, err := c.openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		org, err := db.CreateOrganization(types.Organization{Title: *title, Description: *description, CreatedBy: cliUserID})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "Created organization %s (%s)\n", org.Title, org.ID)
+		return nil
+	}
+}
+
+func orgListCommand(fs *flag.FlagSet) func(c *cli) error {
+	return func(c *cli) error {
+		db, err := c.openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		orgs, err := db.GetOrganizations()
+		if err != nil {
+			return err
+		}
+		users, err := db.FindUsers(-1)
+		if err != nil {
+			return err
+		}
+		userCount := map[string]int{}
+		for _, u := range users {
+			userCount[u.OrganizationID]++
+		}
+		list := make([]types.Organization, 0, len(orgs))
+		for _, o := range orgs {
+			list = append(list, o)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Title < list[j].Title })
+		tw := c.table()
+		fmt.Fprintln(tw, "ID\tTITLE\tUSERS\tCREATED")
+		for _, o := range list {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", o.ID, o.Title, userCount[o.ID], formatTime(o.CreatedAt))
+		}
+		return tw.Flush()
+	}
+}
+
+func dbBackupCommand(fs *flag.FlagSet) func(c *cli) error {
+	out := fs.String("out", "", "The file to write the backup to. Defaults to skiver-<time>.bbolt")
+	return func(c *cli) error {
+		path := *out
+		if path == "" {
+			path = fmt.Sprintf("skiver-%s.bbolt", time.Now().Format("20060102-150405"))
+		}
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("The file %s already exists", path)
+		}
+		db, err := c.openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return err
+		}
+		n, err := db.Backup(f)
+		if err != nil {
+			f.Close()
+			os.Remove(path)
+			return fmt.Errorf("Failed to create the backup: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "Wrote a backup of %s to %s\n", humanize.Bytes(uint64(n)), path)
+		return nil
+	}
+}
+
+// Returns an error if the file is not a consistent bbolt-database
+func checkDatabaseFile(path string) error {
+	db, err := bolt.Open(path, 0400, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("%s is not a readable database: %w", path, err)
+	}
+	defer db.Close()
+	return db.View(func(tx *bolt.Tx) error {
+		for err := range tx.Check() {
+			return fmt.Errorf("%s is not consistent: %w", path, err)
+		}
+		return nil
+	})
+}
+
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func dbRestoreCommand(fs *flag.FlagSet) func(c *cli) error {
+	from := fs.String("from", "", "The backup to restore (required)")
+	return func(c *cli) error {
+		if err := requireFlags(map[string]string{"from": *from}); err != nil {
+			return err
+		}
+		config, err := c.getConfig()
+		if err != nil {
+			return err
+		}
+		target := config.Api.DBLocation
+		if err := checkDatabaseFile(*from); err != nil {
+			return err
+		}
+		// The restored database is copied next to the target first, so that the target is only replaced by a complete copy.
+		tmp := target + ".restore"
+		os.Remove(tmp)
+		if err := copyFile(tmp, *from); err != nil {
+			return fmt.Errorf("Failed to copy the backup: %w", err)
+		}
+		defer os.Remove(tmp)
+		backup := ""
+		if _, err := os.Stat(target); err == nil {
+			// Opening the database ensures that the server is not using it
+			db, err := c.openDB()
+			if err != nil {
+				return err
+			}
+			db.Close()
+			backup, err = bboltStorage.BackupPath(target)
+			if err != nil {
+				return err
+			}
+			if err := os.Rename(target, backup); err != nil {
+				return fmt.Errorf("Failed to move the current database: %w", err)
+			}
+		}
+		if err := os.Rename(tmp, target); err != nil {
+			return fmt.Errorf("Failed to move the restored database into place: %w", err)
+		}
+		fmt.Fprintf(c.out, "Restored %s to %s\n", *from, target)
+		if backup != "" {
+			fmt.Fprintf(c.out, "The previous database was moved to %s\n", backup)
+		}
+		return nil
+	}
+}
+
+func fileSize(path string) uint64 {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return uint64(stat.Size())
+}
+
+func dbCompactCommand(fs *flag.FlagSet) func(c *cli) error {
+	return func(c *cli) error {
+		db, err := c.openDB()
+		if err != nil {
+			return err
+		}
+		// Compacting replaces the underlying database, so the one that is closed must be looked up when returning
+		defer func() { db.Close() }()
+		path := db.Path()
+		before := fileSize(path)
+		backup, err := db.Compact()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "Compacted %s from %s to %s. The previous database was moved to %s\n", path, humanize.Bytes(before), humanize.Bytes(fileSize(path)), backup)
+		return nil
+	}
+}
+
+func dbMigrateCommand(fs *flag.FlagSet) func(c *cli) error {
+	return func(c *cli) error {
+		db, err := c.openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		before, err := db.GetState()
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			before = &types.State{}
+		}
+		state, err := migrateDatabase(db)
+		if err != nil {
+			return fmt.Errorf("Failed during db-migration: %w", err)
+		}
+		if state.MigrationPoint == before.MigrationPoint {
+			fmt.Fprintf(c.out, "The database is already at migration-point %d\n", state.MigrationPoint)
+			return nil
+		}
+		fmt.Fprintf(c.out, "Migrated the database from migration-point %d to %d\n", before.MigrationPoint, state.MigrationPoint)
+		return nil
+	}
+}
+
+func dbVerifyCommand(fs *flag.FlagSet) func(c *cli) error {
+	return func(c *cli) error {
+		db, err := c.openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		report, err := db.Verify()
+		if err != nil {
+			return err
+		}
+		state, err := db.GetState()
+		if err != nil {
+			return err
+		}
+		tw := c.table()
+		fmt.Fprintln(tw, "BUCKET\tITEMS")
+		for _, name := range utils.SortedMapKeys(report.Counts) {
+			fmt.Fprintf(tw, "%s\t%d\n", name, report.Counts[name])
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		if state != nil {
+			fmt.Fprintf(c.out, "\nMigration-point: %d\n", state.MigrationPoint)
+		}
+		for _, e := range report.Errors {
+			fmt.Fprintf(c.out, "Error: %s\n", e)
+		}
+		for _, e := range report.InvalidItems {
+			fmt.Fprintf(c.out, "Invalid item: %s\n", e)
+		}
+		if !report.Ok() {
+			return fmt.Errorf("The database has %d errors and %d invalid items", len(report.Errors), len(report.InvalidItems))
+		}
+		fmt.Fprintln(c.out, "The database is ok")
+		return nil
+	}
+}
+
+func snapshotListCommand(fs *flag.FlagSet) func(c *cli) error {
+	project := fs.String("project", "", "Id or short-name of a project, to only list its snapshots")
+	return func(c *cli) error {
+		db, err := c.openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		projects, err := db.GetProjects()
+		if err != nil {
+			return err
+		}
+		if *project != "" {
+			p, err := db.GetProjectByIDOrShortName(*project)
+			if err != nil {
+				return err
+			}
+			if p == nil {
+				return fmt.Errorf("Project not found: %s", *project)
+			}
+			projects = map[string]types.Project{p.ID: *p}
+		}
+		type row struct {
+			project string
+			tag     string
+			meta    types.ProjectSnapshotMeta
+		}
+		var rows []row
+		for _, p := range projects {
+			for tag, meta := range p.Snapshots {
+				rows = append(rows, row{p.ShortName, tag, meta})
+			}
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			if rows[i].project != rows[j].project {
+				return rows[i].project < rows[j].project
+			}
+			return rows[i].meta.CreatedAt.Before(rows[j].meta.CreatedAt)
+		})
+		tw := c.table()
+		fmt.Fprintln(tw, "PROJECT\tTAG\tSNAPSHOT\tCREATED\tDESCRIPTION")
+		for _, r := range rows {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.project, r.tag, r.meta.SnapshotID, formatTime(r.meta.CreatedAt), r.meta.Description)
+		}
+		return tw.Flush()
+	}
+}
+
+func snapshotCreateCommand(fs *flag.FlagSet) func(c *cli) error {
+	project := fs.String("project", "", "Id or short-name of the project (required)")
+	tag := fs.String("tag", "", "The tag of the snapshot, like v1.2.0 (required)")
+	description := fs.String("description", "", "The description")
+	force := fs.Bool("force", false, "Creates the snapshot even if the release-gate of the project refuses it")
+	return func(c *cli) error {
+		if err := requireFlags(map[string]string{"project": *project, "tag": *tag}); err != nil {
+			return err
+		}
+		config, err := c.getConfig()
+		if err != nil {
+			return err
+		}
+		db, err := c.openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		p, err := db.GetProjectByIDOrShortName(*project)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			return fmt.Errorf("Project not found: %s", *project)
+		}
+		_, snap, err := handlers.CreateProjectSnapshot(db, *p, *tag, *description, cliUserID, func(ep types.ExtendedProject) error {
+			if *force {
+				return nil
+			}
+			return handlers.ReleaseGate(ep)
+		})
+		if err != nil {
+			if apiErr, ok := err.(requestContext.APIError); ok {
+				if details, ok := apiErr.Details.(handlers.ReleaseGateDetails); ok {
+					for _, key := range details.Keys {
+						fmt.Fprintln(c.out, key)
+					}
+				}
+			}
+			return err
+		}
+		fmt.Fprintf(c.out, "Created the snapshot %s of %s\n", *tag, p.ShortName)
+		l := c.logger()
+		uploaders, err := newUploaders(l, config)
+		if err != nil {
+			return err
+		}
+		if len(uploaders) > 0 {
+			handlers.UploadSnapshotForProjectAndUpdateIt(db, l, uploaders, *tag, snap)
+		}
+		return nil
+	}
+}
+
+func exportCommand(fs *flag.FlagSet) func(c *cli) error {
+	var opt importexport.ExportOptions
+	fs.StringVar(&opt.Project, "project", "", "Id or short-name of the project (required)")
+	fs.StringVar(&opt.Format, "format", "i18n", "One of i18n, raw, typescript, po, pot, xliff, xliff2, android, strings or stringsdict")
+	locales := fs.String("locales", "", "Comma-separated locales to export, like en,nb. Defaults to all published locales")
+	fs.StringVar(&opt.LocaleKey, "locale-key", "", "How locales are keyed in the export, like ietf or iso_639_1")
+	fs.StringVar(&opt.Tag, "tag", "", "Exports the snapshot with this tag, instead of the current translations")
+	fs.StringVar(&opt.SourceLocale, "source-locale", "", "The source-locale, required for the xliff-formats")
+	noFlatten := fs.Bool("no-flatten", false, "Does not flatten the keys")
+	fs.BoolVar(&opt.ICU, "icu", false, "Joins plurals and contexts into ICU MessageFormat-messages")
+	fs.BoolVar(&opt.ApprovedOnly, "approved", false, "Only exports approved values")
+	out := fs.String("out", "", "The file to write the export to. Defaults to stdout")
+	return func(c *cli) error {
+		if err := requireFlags(map[string]string{"project": opt.Project}); err != nil {
+			return err
+		}
+		if *locales != "" {
+			opt.Locales = strings.Split(*locales, ",")
+		}
+		opt.NoFlatten = *noFlatten
+		db, err := c.openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		export, contentType, err := handlers.Export(c.logger(), db, opt)
+		if err != nil {
+			return err
+		}
+		var b []byte
+		if contentType != "" {
+			b = export.([]byte)
+		} else {
+			b, err = json.MarshalIndent(export, "", "  ")
+			if err != nil {
+				return err
+			}
+			b = append(b, '\n')
+		}
+		if *out == "" {
+			_, err := c.out.Write(b)
+			return err
+		}
+		return os.WriteFile(*out, b, 0644)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/go-common/logger"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/localuser"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestCli(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "skiver.toml")
+	dbPath := filepath.Join(dir, "skiver.bbolt")
+	testza.AssertNoError(t, os.WriteFile(configFile, nil, 0600))
+	pw := localuser.NewPwHasher([]byte(pwsalt))
+
+	// The database is usually created by the server
+	db, err := bboltStorage.NewBbolt(logger.GetLoggerWithLevel("test", "fatal"), dbPath, nil)
+	testza.AssertNoError(t, err)
+	org, err := types.SeedUsers(&db, nil, pw.Hash)
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, types.SeedLocales(&db, org.ID, nil))
+	locales, err := db.GetLocales()
+	testza.AssertNoError(t, err)
+	project := types.Project{ShortName: "web", Title: "Web", LocaleIDs: map[string]types.LocaleSetting{}}
+	for id := range locales {
+		project.LocaleIDs[id] = types.LocaleSetting{Enabled: true, Publish: true}
+	}
+	project.CreatedBy = "test"
+	project.OrganizationID = org.ID
+	_, err = db.CreateProject(project)
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, db.Close())
+
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		err := runCli(append([]string{"-config", configFile, "-db", dbPath, "-log-level", "fatal"}, args...), &out)
+		return out.String(), err
+	}
+
+	_, err = run("user", "create", "-username", "jane", "-org", "nope")
+	testza.AssertNotNil(t, err, "The organization must exist")
+	_, err = run("org", "create", "-title", "acme")
+	testza.AssertNoError(t, err)
+	_, err = run("user", "create", "-username", "jane")
+	testza.AssertNotNil(t, err, "The organization is required when there are several")
+	out, err := run("user", "create", "-username", "jane", "-org", "acme")
+	testza.AssertNoError(t, err)
+	password := regexp.MustCompile(`Temporary password: (\S+)`).FindStringSubmatch(out)
+	testza.AssertLen(t, password, 2, out)
+	_, err = run("user", "create", "-username", "jane", "-org", "acme")
+	testza.AssertNotNil(t, err, "Usernames are unique")
+
+	out, err = run("user", "list", "-org", "acme")
+	testza.AssertNoError(t, err)
+	testza.AssertContains(t, out, "jane")
+	testza.AssertNotContains(t, out, "admin")
+	out, err = run("org", "list")
+	testza.AssertNoError(t, err)
+	testza.AssertContains(t, out, "acme")
+	testza.AssertContains(t, out, "Initial organization")
+
+	out, err = run("user", "reset-password", "-username", "jane", "-password", "new-password")
+	testza.AssertNoError(t, err)
+	testza.AssertContains(t, out, "sessions were revoked")
+	db, err = bboltStorage.NewBbolt(logger.GetLoggerWithLevel("test", "fatal"), dbPath, nil)
+	testza.AssertNoError(t, err)
+	jane, err := db.FindUserByUserName("", "jane")
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, db.Close())
+	testza.AssertTrue(t, jane.TemporaryPassword)
+	ok, _ := pw.Verify(jane.PW, "new-password")
+	testza.AssertTrue(t, ok)
+	ok, _ = pw.Verify(jane.PW, password[1])
+	testza.AssertFalse(t, ok)
+	// Sessions in the memory of a running server cannot be revoked by the cli
+	testza.AssertNoError(t, os.WriteFile(configFile, []byte("[Authentication]\nSessionStore = \"memory\"\n"), 0600))
+	out, err = run("user", "reset-password", "-username", "jane", "-password", "new-password")
+	testza.AssertNoError(t, err)
+	testza.AssertNotContains(t, out, "sessions were revoked")
+	testza.AssertContains(t, out, "Warning")
+	testza.AssertNoError(t, os.WriteFile(configFile, nil, 0600))
+
+	out, err = run("snapshot", "create", "-project", "web", "-tag", "v1.0.0", "-description", "First")
+	testza.AssertNoError(t, err, out)
+	_, err = run("snapshot", "create", "-project", "web", "-tag", "v1.0.0")
+	testza.AssertNotNil(t, err, "Tags are unique")
+	out, err = run("snapshot", "list")
+	testza.AssertNoError(t, err)
+	testza.AssertContains(t, out, "v1.0.0")
+	testza.AssertContains(t, out, "First")
+	out, err = run("export", "-project", "web", "-format", "raw", "-tag", "v1.0.0")
+	testza.AssertNoError(t, err)
+	testza.AssertContains(t, out, `"short_name": "web"`)
+
+	out, err = run("db", "verify")
+	testza.AssertNoError(t, err, out)
+	backup := filepath.Join(dir, "backup.bbolt")
+	_, err = run("db", "backup", "-out", backup)
+	testza.AssertNoError(t, err)
+	_, err = run("db", "backup", "-out", backup)
+	testza.AssertNotNil(t, err, "Existing files are not overwritten")
+	_, err = run("org", "create", "-title", "after-backup")
+	testza.AssertNoError(t, err)
+	_, err = run("db", "restore", "-from", backup)
+	testza.AssertNoError(t, err)
+	out, err = run("org", "list")
+	testza.AssertNoError(t, err)
+	testza.AssertContains(t, out, "acme")
+	testza.AssertNotContains(t, out, "after-backup", "The backup was restored")
+	testza.AssertTrue(t, fileSize(dbPath+".bk") > 0, "The previous database is kept")
+	previous, err := os.ReadFile(dbPath + ".bk")
+	testza.AssertNoError(t, err)
+	_, err = run("db", "compact")
+	testza.AssertNoError(t, err)
+	kept, err := os.ReadFile(dbPath + ".bk")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, previous, kept, "Earlier backups are not overwritten")
+	backups, err := filepath.Glob(dbPath + ".*.bk")
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, backups, 1, "The database before compacting is kept with a timestamp")
+	_, err = run("db", "migrate")
+	testza.AssertNoError(t, err)
+
+	_, err = run("config", "validate")
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, os.WriteFile(configFile, []byte("LogFormat = \"xml\"\nUnknownKey = true\n"), 0600))
+	out, err = run("config", "validate")
+	testza.AssertNotNil(t, err)
+	testza.AssertContains(t, out, "LogFormat")
+	testza.AssertContains(t, strings.ToLower(out), "unknownkey")
+
+	_, err = run("nope")
+	testza.AssertNotNil(t, err)
+	_, err = run("user", "create", "unexpected")
+	testza.AssertNotNil(t, err)
+}
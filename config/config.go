@@ -45,6 +45,34 @@ type Config struct {
 	// Used to upload backups of the database.
 	// Can optionally also be used as a source to retreve a backup from on startup, if there is no database.
 	DatabaseBackups map[string]BackupConfig `help:"Optional backupendpoints for databases" json:"databaseBackups"`
+
+	// Limits the revision-history that is kept for translations and translation-values
+	Revisions RevisionConfig
+
+	// Settings for the webhooks of the organizations
+	Webhooks WebhookConfig
+}
+
+type RevisionConfig struct {
+	// Maximum number of revisions to keep for each translation and translation-value.
+	// Defaults to 100. Set to a negative number to keep all revisions.
+	MaxCount int `json:"maxCount" help:"Maximum number of revisions to keep for each translation and translation-value. Defaults to 100. Set to a negative number to keep all revisions."`
+	// Revisions older than this are removed, except for the newest revision.
+	// If not set, revisions are kept regardless of age.
+	MaxAge Duration `json:"maxAge" help:"Revisions older than this are removed, except for the newest revision."`
+}
+
+type WebhookConfig struct {
+	// Maximum number of completed deliveries to keep for each webhook.
+	// Defaults to 100. Set to a negative number to keep all deliveries.
+	MaxDeliveries int `json:"maxDeliveries" help:"Maximum number of completed deliveries to keep for each webhook. Defaults to 100. Set to a negative number to keep all deliveries."`
+	// Completed deliveries older than this are removed.
+	// If not set, deliveries are kept regardless of age.
+	MaxDeliveryAge Duration `json:"maxDeliveryAge" help:"Completed deliveries older than this are removed."`
+	// Webhooks are only delivered to public addresses, so that they cannot be used to reach the network of the server,
+	// like 127.0.0.1, 10.0.0.1, or the metadata-service at 169.254.169.254.
+	// This allows them for installations where the webhooks are within the same network.
+	AllowPrivateNetworks bool `json:"allowPrivateNetworks" help:"Allow webhooks to loopback-, link-local- and private addresses, for installations where the webhooks are within the same network."`
 }
 
 type BackupConfig struct {
@@ -109,7 +137,87 @@ type Metrics struct {
 
 type AuthConfig struct {
 	// Defines how long a Session should be valid for.
-	SessionLifeTime Duration `jsonschema="title=Defines how long a session should be valid for"`
+	SessionLifeTime Duration `jsonschema:"title=Defines how long a session should be valid for"`
+	// Where sessions are kept.
+	// bbolt stores them in the database, with signed tokens, so that they survive restarts, and can be listed and revoked.
+	// memory keeps them in memory only, and is mostly useful for testing.
+	// Defaults to bbolt
+	SessionStore string `jsonschema:"enum=bbolt,enum=memory"`
+	// The secret that session-tokens are signed with, of at least 32 characters.
+	// Replicas that share sessions must use the same secret.
+	// If not set, a secret is created and stored in the database.
+	SessionSecret string
+	// How often expired sessions are removed. Defaults to 10 minutes
+	SessionSweepInterval Duration
+	// If set, users can log in through an OpenID Connect-provider.
+	OIDC *OIDCConfig
+	// Limits for failed logins, per username and per ip
+	LoginThrottle LoginThrottleConfig
+}
+
+// After a few failed logins, each new attempt is delayed, with a delay that doubles for every failure.
+// After too many failed logins, logins are locked for a while, which admins can unlock.
+type LoginThrottleConfig struct {
+	// Disables the throttling of logins. Failed logins are still counted in the metrics
+	Disabled bool
+	// Failed logins for a username before each new attempt is delayed. Defaults to 3
+	FreeAttempts int
+	// Failed logins for a username before it is locked. Defaults to 10
+	LockoutThreshold int
+	// Failed logins from an ip before each new attempt is delayed. Defaults to 10
+	IPFreeAttempts int
+	// Failed logins from an ip before it is locked. Defaults to 50, since ips are often shared
+	IPLockoutThreshold int
+	// The delay after the first failure beyond the free attempts. Defaults to 1 second
+	BaseDelay Duration
+	// Defaults to 5 minutes
+	MaxDelay Duration
+	// The duration of the first lockout, which doubles for every lockout, up to a day. Defaults to 15 minutes
+	LockoutDuration Duration
+	// Failed logins are forgotten after this long without failures. Defaults to 24 hours
+	ResetAfter Duration
+	// Ips or cidr-ranges of reverse-proxies, whose Forwarded and X-Forwarded-For-headers are trusted for the ip of the client.
+	// Without any, the remote address of the connection is used, since the headers are set by the client.
+	TrustedProxies []string
+}
+
+type OIDCConfig struct {
+	// The url of the provider, which must serve /.well-known/openid-configuration
+	Issuer       string `json:"issuer" help:"The url of the provider, which must serve /.well-known/openid-configuration"`
+	ClientID     string `json:"clientID"`
+	ClientSecret string `json:"clientSecret"`
+	// The url that the provider redirects back to, which is the url of skiver, followed by /api/auth/oidc/callback
+	RedirectURL string `json:"redirectURL" help:"The url that the provider redirects back to, which is the url of skiver, followed by /api/auth/oidc/callback"`
+	// Scopes to request, in addition to openid.
+	// Defaults to profile and email
+	Scopes []string `json:"scopes"`
+	// The claim that is used as the username.
+	// Defaults to preferred_username, and falls back to email
+	UserNameClaim string `json:"userNameClaim"`
+	// The claim that maps users to organizations, through Organizations.
+	OrganizationClaim string `json:"organizationClaim"`
+	// Maps values of the OrganizationClaim to organization-ids.
+	Organizations map[string]string `json:"organizations"`
+	// The id of the organization for users that are not mapped to an organization.
+	// If not set, these users cannot log in.
+	DefaultOrganization string `json:"defaultOrganization"`
+	// The claim that holds the groups of the user.
+	// Defaults to groups
+	GroupsClaim string `json:"groupsClaim"`
+	// Maps groups to roles. The roles of the user are replaced by these on every login,
+	// so that changes at the provider apply the next time the user logs in.
+	// Group-names are matched case-insensitively.
+	Groups map[string][]OIDCRole `json:"groups"`
+}
+
+type OIDCRole struct {
+	// One of admin, developer, translator, reviewer or viewer
+	// Enum: [admin developer translator reviewer viewer]
+	Role string `json:"role" jsonschema:"enum=admin,enum=developer,enum=translator,enum=reviewer,enum=viewer"`
+	// If set, the role only applies to this project, by id or short-name
+	Project string `json:"project"`
+	// If set, the role only applies to translation-values of these locales, by id or short-name
+	Locales []string `json:"locales"`
 }
 
 // TDB
@@ -256,6 +364,12 @@ func CreateSampleComfig() Config {
 		TranslatorServices: []TranslatorService{
 			{},
 		},
+		Revisions: RevisionConfig{
+			MaxCount: 100,
+		},
+		Webhooks: WebhookConfig{
+			MaxDeliveries: 100,
+		},
 		DatabaseBackups: map[string]BackupConfig{
 			"example": {
 				MaxInterval: Duration(time.Minute * 10),
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// SetConfigFile sets the file that InitConfig reads, instead of looking for skiver.toml in the default paths.
+func SetConfigFile(path string) {
+	cfgFile = path
+}
+
+func oneOf(value string, valid ...string) bool {
+	for _, v := range valid {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns the problems with the configuration, like unknown keys and values that the server would refuse.
+// InitConfig must be called first.
+func Validate() []error {
+	var errs []error
+	add := func(format string, a ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, a...))
+	}
+	var cfg Config
+	// Unknown keys are most likely typos, which would otherwise silently be ignored.
+	// The rest of the configuration is still decoded, so that it can be checked below.
+	if err := viper.UnmarshalExact(&cfg, viper.DecodeHook(DurationViperHookFunc())); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cfg.LogLevel != "" && !oneOf(cfg.LogLevel, "trace", "debug", "info", "warn", "warning", "error", "fatal", "panic") {
+		add("LogLevel %q must be one of trace, debug, info, warn, error, fatal or panic", cfg.LogLevel)
+	}
+	if cfg.LogFormat != "" && !oneOf(cfg.LogFormat, "human", "json") {
+		add("LogFormat %q must be one of human or json", cfg.LogFormat)
+	}
+	for name, port := range map[string]int{"Api.Port": cfg.Api.Port, "Api.RedirectPort": cfg.Api.RedirectPort, "Metrics.Port": cfg.Metrics.Port} {
+		if port < 0 || port > 65535 {
+			add("%s %d is not a valid port", name, port)
+		}
+	}
+	if (cfg.Api.CertFile == "") != (cfg.Api.CertKey == "") {
+		add("Api.CertFile and Api.CertKey must be set together")
+	}
+
+	auth := cfg.Authentication
+	if auth.SessionLifeTime != 0 && auth.SessionLifeTime < Duration(time.Minute) {
+		add("Authentication.SessionLifeTime %s cannot be shorter than a minute", auth.SessionLifeTime)
+	}
+	if !oneOf(auth.SessionStore, "", "bbolt", "memory") {
+		add("Authentication.SessionStore %q must be one of bbolt or memory", auth.SessionStore)
+	}
+	if auth.SessionSecret != "" && len(auth.SessionSecret) < 32 {
+		add("Authentication.SessionSecret must be at least 32 characters")
+	}
+	for _, p := range auth.LoginThrottle.TrustedProxies {
+		if _, _, err := net.ParseCIDR(p); err != nil && net.ParseIP(p) == nil {
+			add("Authentication.LoginThrottle.TrustedProxies: %q is not an ip or cidr-range", p)
+		}
+	}
+	if o := auth.OIDC; o != nil {
+		if o.Issuer == "" || o.ClientID == "" || o.RedirectURL == "" {
+			add("Authentication.OIDC requires issuer, clientID and redirectURL")
+		}
+		for group, roles := range o.Groups {
+			for _, r := range roles {
+				if !oneOf(r.Role, "admin", "developer", "translator", "reviewer", "viewer") {
+					add("Authentication.OIDC.Groups.%s: role %q must be one of admin, developer, translator, reviewer or viewer", group, r.Role)
+				}
+			}
+		}
+	}
+
+	if len(cfg.TranslatorServices) > 1 {
+		add("TranslatorServices: currently, only a single translator-service can be used")
+	}
+	for key, u := range cfg.UploadSnapShots {
+		if u.S3 == nil {
+			add("UploadSnapShots.%s has no target, like s3", key)
+		}
+	}
+	for key, b := range cfg.DatabaseBackups {
+		if b.S3 == nil {
+			add("DatabaseBackups.%s has no target, like s3", key)
+		}
+	}
+	return errs
+}
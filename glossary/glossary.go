@@ -0,0 +1,286 @@
+// Package glossary matches the terms of an organization's glossary within translation-values,
+// and checks that the terms are translated with their approved translations.
+//
+// Terms only match whole words, so the term `work` does not match within `workspace`.
+package glossary
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+// A Match is an occurance of a glossary-term, or one of its approved translations, within a text.
+// swagger:model GlossaryMatch
+type Match struct {
+	EntryID string `json:"entry_id"`
+	Term    string `json:"term"`
+	// The matched text, as written in the text
+	Text string `json:"text"`
+	// The position of the first character of the match (in characters, not bytes)
+	Start int `json:"start"`
+	// The position after the last character of the match (in characters, not bytes)
+	End int `json:"end"`
+	// The approved translation of the term for the locale, if any.
+	Translation    string `json:"translation,omitempty"`
+	DoNotTranslate bool   `json:"do_not_translate,omitempty"`
+	// If set, the match is the approved translation, not the term itself
+	Translated bool `json:"translated,omitempty"`
+}
+
+// A Violation is a glossary-term that is used in the source, but not translated as approved.
+// swagger:model GlossaryViolation
+type Violation struct {
+	EntryID string `json:"entry_id"`
+	Term    string `json:"term"`
+	// The text that was expected within the value
+	Expected string `json:"expected"`
+	Message  string `json:"message"`
+	// The context-key of the value, if the violation is within a context
+	Context string `json:"context,omitempty"`
+}
+
+type Glossary struct {
+	entries []types.GlossaryEntry
+}
+
+// New creates a glossary from the entries. Deleted entries are ignored.
+func New(entries map[string]types.GlossaryEntry) Glossary {
+	g := Glossary{}
+	for _, e := range entries {
+		if e.Deleted != nil || strings.TrimSpace(e.Term) == "" {
+			continue
+		}
+		g.entries = append(g.entries, e)
+	}
+	// Longer terms are matched first, so that `user account` wins over `user`
+	sort.Slice(g.entries, func(i, j int) bool {
+		a, b := g.entries[i], g.entries[j]
+		if len(a.Term) != len(b.Term) {
+			return len(a.Term) > len(b.Term)
+		}
+		if a.Term != b.Term {
+			return a.Term < b.Term
+		}
+		return a.ID < b.ID
+	})
+	return g
+}
+
+func (g Glossary) Len() int {
+	return len(g.entries)
+}
+
+// Matches returns the occurances of the terms within the text.
+// If the locale is set, the approved translations for the locale are matched too, and the matches
+// include the approved translation.
+func (g Glossary) Matches(text string, localeID string) []Match {
+	runes := []rune(text)
+	taken := make([]bool, len(runes))
+	var matches []Match
+	add := func(e types.GlossaryEntry, s string, translated bool) {
+		for _, pos := range find(runes, s, e.CaseSensitive) {
+			if overlaps(taken, pos[0], pos[1]) {
+				continue
+			}
+			for i := pos[0]; i < pos[1]; i++ {
+				taken[i] = true
+			}
+			matches = append(matches, Match{
+				EntryID:        e.ID,
+				Term:           e.Term,
+				Text:           string(runes[pos[0]:pos[1]]),
+				Start:          pos[0],
+				End:            pos[1],
+				Translation:    e.Translations[localeID],
+				DoNotTranslate: e.DoNotTranslate,
+				Translated:     translated,
+			})
+		}
+	}
+	for _, e := range g.entries {
+		add(e, e.Term, false)
+		if localeID == "" || e.DoNotTranslate {
+			continue
+		}
+		if tr := e.Translations[localeID]; tr != "" && tr != e.Term {
+			add(e, tr, true)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+	return matches
+}
+
+// Check returns violations for the value in the locale, where a term is used within any of the sources,
+// but the value does not contain the approved translation of the term.
+// The sources are normally the values of the same translation in the other locales.
+func (g Glossary) Check(value string, localeID string, sources ...string) []Violation {
+	var violations []Violation
+	runes := []rune(value)
+	for _, e := range g.entries {
+		expected := e.Term
+		if !e.DoNotTranslate {
+			expected = e.Translations[localeID]
+			if expected == "" {
+				continue
+			}
+		}
+		used := false
+		for _, s := range sources {
+			if len(find([]rune(s), e.Term, e.CaseSensitive)) > 0 {
+				used = true
+				break
+			}
+		}
+		if !used || len(find(runes, expected, e.CaseSensitive)) > 0 {
+			continue
+		}
+		v := Violation{EntryID: e.ID, Term: e.Term, Expected: expected}
+		if e.DoNotTranslate {
+			v.Message = fmt.Sprintf("The term '%s' should not be translated", e.Term)
+		} else {
+			v.Message = fmt.Sprintf("The term '%s' should be translated as '%s'", e.Term, expected)
+		}
+		violations = append(violations, v)
+	}
+	return violations
+}
+
+// CheckTranslationValue checks the value, and each of its contexts, against the other values of the translation.
+func (g Glossary) CheckTranslationValue(tv types.TranslationValue, others []types.TranslationValue) []Violation {
+	var sources []string
+	for _, o := range others {
+		if o.ID != tv.ID && o.LocaleID != tv.LocaleID && o.Value != "" {
+			sources = append(sources, o.Value)
+		}
+	}
+	violations := g.Check(tv.Value, tv.LocaleID, sources...)
+	ctxKeys := make([]string, 0, len(tv.Context))
+	for k := range tv.Context {
+		ctxKeys = append(ctxKeys, k)
+	}
+	sort.Strings(ctxKeys)
+	for _, ctx := range ctxKeys {
+		var sources []string
+		for _, o := range others {
+			if o.ID == tv.ID || o.LocaleID == tv.LocaleID {
+				continue
+			}
+			if s, ok := o.Context[ctx]; ok {
+				sources = append(sources, s)
+			} else if o.Value != "" {
+				sources = append(sources, o.Value)
+			}
+		}
+		for _, v := range g.Check(tv.Context[ctx], tv.LocaleID, sources...) {
+			v.Context = ctx
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+var placeholderRegex = regexp.MustCompile(`\{\{\s*__glossary_(\d+)__\s*\}\}`)
+
+// Protect replaces the terms within the text with placeholders, so that they are not changed by machine-translation.
+// The returned function restores the placeholders within the translated text, with the approved translation
+// for the target-locale, or the term as written if it should not be translated.
+// Terms without an approved translation for the target-locale are left as is.
+func (g Glossary) Protect(text string, targetLocaleID string) (string, func(translated string) string) {
+	var replacements []string
+	var b strings.Builder
+	runes := []rune(text)
+	last := 0
+	for _, m := range g.Matches(text, "") {
+		var replacement string
+		if m.DoNotTranslate {
+			replacement = m.Text
+		} else {
+			replacement = g.translationOf(m.EntryID, targetLocaleID)
+			if replacement == "" {
+				continue
+			}
+		}
+		b.WriteString(string(runes[last:m.Start]))
+		b.WriteString("{{__glossary_" + strconv.Itoa(len(replacements)) + "__}}")
+		replacements = append(replacements, replacement)
+		last = m.End
+	}
+	if len(replacements) == 0 {
+		return text, func(translated string) string { return translated }
+	}
+	b.WriteString(string(runes[last:]))
+	return b.String(), func(translated string) string {
+		return placeholderRegex.ReplaceAllStringFunc(translated, func(s string) string {
+			i, err := strconv.Atoi(placeholderRegex.FindStringSubmatch(s)[1])
+			if err != nil || i >= len(replacements) {
+				return s
+			}
+			return replacements[i]
+		})
+	}
+}
+
+func (g Glossary) translationOf(entryID, localeID string) string {
+	for _, e := range g.entries {
+		if e.ID == entryID {
+			return e.Translations[localeID]
+		}
+	}
+	return ""
+}
+
+func overlaps(taken []bool, start, end int) bool {
+	for i := start; i < end; i++ {
+		if taken[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the start- and end-positions of all whole-word occurances of the term within the text
+func find(text []rune, term string, caseSensitive bool) [][2]int {
+	t := []rune(term)
+	if len(t) == 0 || len(t) > len(text) {
+		return nil
+	}
+	var found [][2]int
+	for i := 0; i+len(t) <= len(text); i++ {
+		if !equalAt(text, t, i, caseSensitive) {
+			continue
+		}
+		end := i + len(t)
+		if (i > 0 && isWordRune(text[i-1]) && isWordRune(t[0])) ||
+			(end < len(text) && isWordRune(text[end]) && isWordRune(t[len(t)-1])) {
+			continue
+		}
+		found = append(found, [2]int{i, end})
+		i = end - 1
+	}
+	return found
+}
+
+func equalAt(text, term []rune, offset int, caseSensitive bool) bool {
+	for j, r := range term {
+		c := text[offset+j]
+		if c == r {
+			continue
+		}
+		if caseSensitive || unicode.ToLower(c) != unicode.ToLower(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
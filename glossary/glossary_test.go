@@ -0,0 +1,82 @@
+package glossary
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func entry(id, term string, translations map[string]string) types.GlossaryEntry {
+	e := types.GlossaryEntry{Term: term, Translations: translations}
+	e.ID = id
+	return e
+}
+
+func testGlossary() Glossary {
+	brand := entry("brand", "Skiver", nil)
+	brand.DoNotTranslate = true
+	brand.CaseSensitive = true
+	deleted := entry("deleted", "application", map[string]string{"nb": "applikasjon"})
+	deleted.Deleted = &deleted.CreatedAt
+	return New(map[string]types.GlossaryEntry{
+		"workspace": entry("workspace", "workspace", map[string]string{"nb": "arbeidsområde"}),
+		"work":      entry("work", "work", map[string]string{"nb": "arbeid"}),
+		"brand":     brand,
+		"deleted":   deleted,
+	})
+}
+
+func TestMatches(t *testing.T) {
+	g := testGlossary()
+	testza.AssertEqual(t, 3, g.Len())
+	testza.AssertEqual(t, []Match{
+		{EntryID: "workspace", Term: "workspace", Text: "Workspace", Start: 0, End: 9, Translation: "arbeidsområde"},
+		{EntryID: "brand", Term: "Skiver", Text: "Skiver", Start: 13, End: 19, DoNotTranslate: true},
+		{EntryID: "work", Term: "work", Text: "work", Start: 24, End: 28, Translation: "arbeid"},
+	}, g.Matches("Workspace in Skiver for work, not skiver or workers", "nb"))
+	testza.AssertEqual(t, []Match{
+		{EntryID: "workspace", Term: "workspace", Text: "arbeidsområde", Start: 5, End: 18, Translation: "arbeidsområde", Translated: true},
+	}, g.Matches("Ditt arbeidsområde", "nb"))
+}
+
+func TestCheck(t *testing.T) {
+	g := testGlossary()
+	tests := []struct {
+		name    string
+		value   string
+		sources []string
+		want    []Violation
+	}{
+		{"Approved translation", "Ditt arbeidsområde", []string{"Your workspace"}, nil},
+		{"Term not used in source", "Ditt område", []string{"Your area"}, nil},
+		{
+			"Wrong translation",
+			"Ditt område",
+			[]string{"Your workspace"},
+			[]Violation{{EntryID: "workspace", Term: "workspace", Expected: "arbeidsområde", Message: "The term 'workspace' should be translated as 'arbeidsområde'"}},
+		},
+		{
+			"Do not translate",
+			"Velkommen til skiver",
+			[]string{"Welcome to Skiver"},
+			[]Violation{{EntryID: "brand", Term: "Skiver", Expected: "Skiver", Message: "The term 'Skiver' should not be translated"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testza.AssertEqual(t, tt.want, g.Check(tt.value, "nb", tt.sources...))
+		})
+	}
+}
+
+func TestProtect(t *testing.T) {
+	g := testGlossary()
+	protected, restore := g.Protect("Open Skiver in your workspace", "nb")
+	testza.AssertEqual(t, "Open {{__glossary_0__}} in your {{__glossary_1__}}", protected)
+	testza.AssertEqual(t, "Åpne Skiver i ditt arbeidsområde", restore("Åpne {{ __glossary_0__ }} i ditt {{__glossary_1__}}"))
+
+	protected, restore = g.Protect("Your workspace", "de")
+	testza.AssertEqual(t, "Your workspace", protected, "Terms without an approved translation are left as is")
+	testza.AssertEqual(t, "Ihr Arbeitsbereich", restore("Ihr Arbeitsbereich"))
+}
@@ -1,11 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
@@ -42,6 +42,8 @@ func EndpointsHandler(
 	ctx requestContext.Context,
 	userSessions SessionManager,
 	pw localuser.PwHasher,
+	mfa *MFA,
+	throttle *localuser.LoginThrottle,
 	swaggerYml []byte,
 ) http.HandlerFunc {
 
@@ -64,7 +66,8 @@ func EndpointsHandler(
 
 		isPost := r.Method == http.MethodPost
 		isPut := r.Method == http.MethodPut
-		shouldReadBody := rc.ContentKind > 0 && (isPost || isPut)
+		// Imports may be in formats that are not marshallable, like gettext
+		shouldReadBody := (rc.ContentKind > 0 || paths[0] == "import") && (isPost || isPut)
 
 		if shouldReadBody {
 			body, err = io.ReadAll(r.Body)
@@ -90,10 +93,7 @@ func EndpointsHandler(
 		if authValue != "" {
 			sess, err := userSessions.GetSession(authValue)
 			if err == nil {
-				expiresD := sess.Expires.Sub(time.Now())
-				rw.Header().Add("session-expires", sess.Expires.String())
-				rw.Header().Add("session-expires-in", expiresD.String())
-				rw.Header().Add("session-expires-in-seconds", strconv.Itoa(int(expiresD.Seconds())))
+				addSessionExpiryHeaders(rw, sess)
 				session = &sess
 			} else {
 				details := map[string]any{"authSource": authSource}
@@ -174,21 +174,47 @@ func EndpointsHandler(
 				rc.WriteErr(err, "Err:login")
 				return
 			}
-			if user == nil {
+			// The attempt counts as failed until the password is verified
+			attempt, err := throttle.Attempt(*j.Username, throttle.ClientIP(r), user)
+			if err != nil {
+				rc.WriteErr(loginThrottledError(rw, err), "")
+				return
+			}
+			// Users from other stores, like OpenID Connect, have no password
+			if user == nil || user.Store != types.UserStoreLocal {
+				attempt.Fail(localuser.LoginFailureUnknownUser)
 				rc.WriteError("The supplied username/password is incorrect", "incorrect-user-password")
 				return
 			}
 
 			ok, err := pw.Verify(user.PW, *j.Password)
-			if err != nil {
+			if err != nil || !ok {
+				attempt.Fail(localuser.LoginFailureIncorrectPassword)
 				rc.WriteError("The supplied username/password is incorrect", "incorrect-user-password")
 				return
 			}
-			if !ok {
-				rc.WriteError("The supplied username/password is incorrect", "incorrect-user-password")
+			if !user.Active {
+				if err := attempt.Succeed(); err != nil {
+					rc.L.Error().Err(err).Msg("Failed to reset the login-attempts")
+				}
+				rc.WriteErr(NewApiError("The user is not active", http.StatusForbidden, string(requestContext.CodeErrAuthoriziation)), "")
 				return
 			}
 			userAgent := r.UserAgent() + ";" + rc.RemoteIP
+			// Users with multi-factor authentication complete the login with a code, through /api/auth/mfa.
+			// Until then, the attempt counts as failed, so that codes cannot be guessed by repeating the login.
+			challenge, err := mfa.Challenge(*user, userAgent, attempt)
+			if err != nil {
+				rc.WriteErr(err, "Err:login")
+				return
+			}
+			if challenge != nil {
+				rc.WriteOutput(types.LoginResponse{MFA: challenge}, http.StatusOK)
+				return
+			}
+			if err := attempt.Succeed(); err != nil {
+				rc.L.Error().Err(err).Msg("Failed to reset the login-attempts")
+			}
 
 			var session types.Session
 			sessions := userSessions.SessionsForUser(user.ID)
@@ -221,35 +247,13 @@ func EndpointsHandler(
 					return
 				}
 				session = userSessions.NewSession(*user, *organization, userAgent)
+				if session.Token == "" {
+					rc.WriteError("Failed to create a session", requestContext.CodeErrUnknown)
+					return
+				}
 			}
 
-			expiresD := session.Expires.Sub(now)
-
-			// TODO: move some of these settings to global config, organization settings and/or project settings.
-			cookie := &http.Cookie{
-				Name: "token",
-				// TODO: when the server is behind a subpath (e.g.
-				// exmaple.com/skiver/), the reverse-proxy in front may not return our
-				// path, and we probably need to get it from the config
-				Path:     "/",
-				Value:    session.Token,
-				MaxAge:   int(expiresD.Seconds()),
-				Secure:   r.TLS != nil,
-				HttpOnly: true,
-			}
-			xproto := r.Header.Get("X-Forwarded-Proto")
-			switch xproto {
-			case "http":
-				cookie.Secure = false
-				// does not work with http
-			case "https":
-				cookie.Secure = true
-				cookie.SameSite = http.SameSiteNoneMode
-			}
-			rw.Header().Add("session-expires", session.Expires.String())
-			rw.Header().Add("session-expires-in", expiresD.String())
-			rw.Header().Add("session-expires-in-seconds", strconv.Itoa(int(expiresD.Seconds())))
-			http.SetCookie(rw, cookie)
+			expiresD := setSessionCookie(rw, r, session)
 			r := types.LoginResponse{
 				User:      session.User,
 				Ok:        true,
@@ -272,26 +276,27 @@ func EndpointsHandler(
 			return
 		}
 		orgId := session.Organization.ID
+		// Api-keys can only be used for imports among these routes
+		if session.APIKey != nil && paths[0] != "import" {
+			rc.WriteErr(AuthorizeAPIKey(ctx.DB, *session, "", ""), "")
+			return
+		}
 
 		switch paths[0] {
 		case "import":
 			if isPost {
-				if !session.User.CanCreateTranslations {
-					rc.WriteError("You are not authorizatiod to create translations", requestContext.CodeErrAuthoriziation)
-					return
-				}
 				kind := getStringSliceIndex(paths, 1)
 				projectLike := getStringSliceIndex(paths, 2)
 				localeLike := getStringSliceIndex(paths, 3)
 				dry := utils.HasDryRun(r)
 				switch kind {
 				case "":
-					rc.WriteError("empty value for kind, allowed values: i18n, describe, auto", requestContext.CodeErrInputValidation)
+					rc.WriteError("empty value for kind, allowed values: i18n, po, xliff, android, strings, stringsdict, describe, auto", requestContext.CodeErrInputValidation)
 					return
-				case "i18n", "auto", "describe":
+				case "i18n", "auto", "describe", "po", "xliff", "android", "strings", "stringsdict":
 					break
 				default:
-					rc.WriteError("Invalid value for kind, allowed values: i18n, describe, auto", requestContext.CodeErrInputValidation)
+					rc.WriteError("Invalid value for kind, allowed values: i18n, po, xliff, android, strings, stringsdict, describe, auto", requestContext.CodeErrInputValidation)
 					return
 				}
 				if projectLike == "" {
@@ -316,8 +321,16 @@ func EndpointsHandler(
 					rc.WriteErr(err, requestContext.CodeErrProject)
 					return
 				}
+				if err := AuthorizeAPIKey(ctx.DB, *session, types.APIKeyScopeImport, project.ID); err != nil {
+					rc.WriteErr(err, "")
+					return
+				}
+				if err := Authorize(rc.Context.DB, *session, types.ActionCreateTranslations, types.AccessScope{ProjectID: project.ID}); err != nil {
+					rc.WriteErr(err, "")
+					return
+				}
 
-				out, Err := ImportIntoProject(ctx.L, ctx.DB, kind, session.User.ID, *project, localeLike, body, r, ImportIntoProjectOptions{NoDryRun: !dry})
+				out, Err := ImportIntoProject(ctx.L, ctx.DB, kind, session.User.ID, *project, localeLike, body, r, ImportIntoProjectOptions{NoDryRun: !dry, Session: session})
 				if Err != nil {
 					rc.WriteErr(Err, Err.GetCode())
 					return
@@ -336,10 +349,6 @@ func EndpointsHandler(
 				return
 			}
 			if isPost {
-				if !session.User.CanCreateTranslations {
-					rc.WriteError("You are not authorizatiod to create translation-values", requestContext.CodeErrAuthoriziation)
-					return
-				}
 				var j models.TranslationValueInput
 				if err := rc.ValidateBytes(body, &j); err != nil {
 					return
@@ -358,14 +367,19 @@ func EndpointsHandler(
 					rc.WriteErr(err, requestContext.CodeErrTranslation)
 					return
 				}
+				if t == nil {
+					rc.WriteErr(ErrApiNotFound("Translation", tv.TranslationID), "")
+					return
+				}
 				p, err := t.GetProject(ctx.DB)
 				if err != nil {
 					ctx.L.Error().Err(err).Msg("Project was not found for translation")
 					rc.WriteErr(err, requestContext.CodeErrTranslation)
 					return
 				}
-				if t == nil {
-					rc.WriteErr(ErrApiNotFound("Translation", tv.TranslationID), "")
+				if err := Authorize(rc.Context.DB, *session, types.ActionEditTranslationValues, types.AccessScope{ProjectID: p.ID, LocaleID: tv.LocaleID}); err != nil {
+					rc.WriteErr(err, "")
+					return
 				}
 				et, err := t.Extend(ctx.DB)
 				if err != nil {
@@ -392,16 +406,19 @@ func EndpointsHandler(
 				if err != nil {
 					ctx.L.Error().Err(err).Msg("Failed in updateTranslationFromInferrence")
 				}
+				issues, err := lintTranslationValue(ctx.DB, p, translationValue)
+				if err != nil {
+					ctx.L.Error().Err(err).Msg("Failed to lint the translation-value")
+				}
+				violations, err := glossaryViolations(ctx.DB, translationValue)
+				if err != nil {
+					ctx.L.Error().Err(err).Msg("Failed to check the translation-value against the glossary")
+				}
 
-				rc.WriteOutput(translationValue, http.StatusOK)
+				rc.WriteOutput(lintedTranslationValue{translationValue, issues, violations}, http.StatusOK)
 				return
 			}
 			if isPut {
-				if !session.User.CanCreateTranslations {
-					rc.WriteError("You are not authorizatiod to update translation-values", requestContext.CodeErrAuthoriziation)
-					return
-				}
-
 				id := getStringSliceIndex(paths, 1)
 				var j models.UpdateTranslationValueInput
 				if err := rc.ValidateBytes(body, &j); err != nil {
@@ -423,9 +440,15 @@ func EndpointsHandler(
 				}
 				tv.Source = types.CreatorSourceUser
 				tv.UpdatedBy = session.User.ID
+				tv.Version, err = expectedVersion(r, j.Version)
+				if err != nil {
+					rc.WriteErr(err, "")
+					return
+				}
 				exTV, err := ctx.DB.GetTranslationValue(id)
 				if exTV == nil {
 					rc.WriteErr(ErrApiNotFound("TranslationValue", id), "")
+					return
 				}
 				t, err := ctx.DB.GetTranslation(exTV.TranslationID)
 				if err != nil {
@@ -434,6 +457,7 @@ func EndpointsHandler(
 				}
 				if t == nil {
 					rc.WriteErr(ErrApiNotFound("Translation", exTV.TranslationID), "")
+					return
 				}
 				p, err := t.GetProject(ctx.DB)
 				if err != nil {
@@ -441,6 +465,10 @@ func EndpointsHandler(
 					rc.WriteErr(err, requestContext.CodeErrTranslation)
 					return
 				}
+				if err := Authorize(rc.Context.DB, *session, types.ActionEditTranslationValues, types.AccessScope{ProjectID: p.ID, LocaleID: exTV.LocaleID}); err != nil {
+					rc.WriteErr(err, "")
+					return
+				}
 				et, err := t.Extend(ctx.DB)
 				if err != nil {
 					rc.WriteErr(err, requestContext.CodeErrTranslation)
@@ -448,9 +476,15 @@ func EndpointsHandler(
 				}
 				translationValue, err := ctx.DB.UpdateTranslationValue(tv)
 				if err != nil {
+					if errors.Is(err, bboltStorage.ErrVersionConflict) {
+						current, _ := ctx.DB.GetTranslationValue(id)
+						rc.WriteErr(ErrApiVersionConflict("TranslationValue", current), "")
+						return
+					}
 					rc.WriteErr(ErrApiDatabase("translation", err), "translation")
 					return
 				}
+				setETag(rw, translationValue.Version)
 				o, err := importexport.CreateInterpolationMapForOrganization(ctx.DB, session.Organization.ID)
 				if err != nil {
 					ctx.L.Error().Err(err).Msg("Failed during CreateInterpolationMapForOrganization")
@@ -466,8 +500,12 @@ func EndpointsHandler(
 				if err != nil {
 					ctx.L.Error().Err(err).Msg("Failed in updateTranslationFromInferrence")
 				}
+				violations, err := glossaryViolations(ctx.DB, translationValue)
+				if err != nil {
+					ctx.L.Error().Err(err).Msg("Failed to check the translation-value against the glossary")
+				}
 
-				rc.WriteOutput(translationValue, http.StatusOK)
+				rc.WriteOutput(lintedTranslationValue{TranslationValue: translationValue, GlossaryViolations: violations}, http.StatusOK)
 				return
 
 			}
@@ -487,8 +525,8 @@ func EndpointsHandler(
 				return
 			}
 			if isPost {
-				if !session.User.CanCreateTranslations {
-					rc.WriteError("You are not authorizatiod to create locales", requestContext.CodeErrAuthoriziation)
+				if err := Authorize(rc.Context.DB, *session, types.ActionCreateLocales, types.AccessScope{}); err != nil {
+					rc.WriteErr(err, "")
 					return
 				}
 				var j models.LocaleInput
@@ -603,7 +641,10 @@ func UpdateTranslationFromInferrence(db types.Storage, et types.ExtendedTranslat
 		}
 	}
 	if needsUpdate {
-		t, err := db.UpdateTranslation(et.ID, et.Translation)
+		// Inferred changes are not made by any user
+		payload := et.Translation
+		payload.UpdatedBy = ""
+		t, err := db.UpdateTranslation(et.ID, payload)
 		return &t, err
 	}
 	return nil, nil
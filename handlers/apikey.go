@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/models"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+// Api-keys are formatted as skiver_<id>.<secret>, so that they can be told apart from session-tokens,
+// and be found by their id.
+const apiKeyPrefix = "skiver_"
+
+var ErrAPIKeyInvalid = errors.New("The api-key is invalid, expired or revoked")
+
+// CreatedAPIKey is the api-key, with the key itself.
+// The key is only returned when it is created.
+// swagger:model CreatedAPIKey
+type CreatedAPIKey struct {
+	types.APIKey
+	// Used as the Authorization-header
+	Key string `json:"key"`
+}
+
+// APIKeySessions resolves api-keys into sessions, and passes all other tokens on to the SessionManager.
+type APIKeySessions struct {
+	SessionManager
+	db types.Storage
+}
+
+func NewAPIKeySessions(sessions SessionManager, db types.Storage) APIKeySessions {
+	return APIKeySessions{sessions, db}
+}
+
+func (s APIKeySessions) GetSession(token string) (types.Session, error) {
+	if !strings.HasPrefix(token, apiKeyPrefix) {
+		return s.SessionManager.GetSession(token)
+	}
+	id, secret, ok := parseAPIKey(token)
+	if !ok {
+		return types.Session{}, ErrAPIKeyInvalid
+	}
+	key, err := s.db.GetAPIKey(id)
+	if err != nil || key == nil {
+		return types.Session{}, ErrAPIKeyInvalid
+	}
+	now := time.Now()
+	if key.Invalid(now) || subtle.ConstantTimeCompare(hashAPIKeySecret(secret), key.Hash) != 1 {
+		return types.Session{}, ErrAPIKeyInvalid
+	}
+	user, err := s.db.GetUser(key.CreatedBy)
+	if err != nil || user == nil || !user.Active {
+		return types.Session{}, ErrAPIKeyInvalid
+	}
+	org, err := s.db.GetOrganization(key.OrganizationID)
+	if err != nil || org == nil || org.ID == "" {
+		return types.Session{}, ErrAPIKeyInvalid
+	}
+	// Recording every use would mean a write on every request
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > time.Minute {
+		if err := s.db.SetAPIKeyLastUsed(key.ID, now); err == nil {
+			key.LastUsedAt = &now
+		}
+	}
+	session := types.Session{
+		Token:        token,
+		User:         key.RestrictUser(*user),
+		Organization: *org,
+		UserAgent:    "api-key: " + key.Name,
+		Issued:       key.CreatedAt,
+		APIKey:       key,
+	}
+	if key.ExpiresAt != nil {
+		session.Expires = *key.ExpiresAt
+	}
+	return session, nil
+}
+
+func parseAPIKey(token string) (id, secret string, ok bool) {
+	s := strings.TrimPrefix(token, apiKeyPrefix)
+	i := strings.LastIndex(s, ".")
+	if i <= 0 || i == len(s)-1 {
+		return "", "", false
+	}
+	return s[:i], s[i+1:], true
+}
+
+func hashAPIKeySecret(secret string) []byte {
+	h := sha256.Sum256([]byte(secret))
+	return h[:]
+}
+
+// AuthorizeAPIKey checks that the api-key of the session, if any, has the scope, and is allowed to use the project.
+// Sessions that are not created from api-keys are always authorized.
+func AuthorizeAPIKey(db types.Storage, s types.Session, scope types.APIKeyScope, projectKey string) error {
+	key := s.APIKey
+	if key == nil {
+		return nil
+	}
+	if scope == "" || !key.Allows(scope) {
+		return NewApiError("The api-key is not allowed to be used here", http.StatusForbidden, string(requestContext.CodeErrAuthoriziation))
+	}
+	if len(key.ProjectIDs) == 0 {
+		return nil
+	}
+	if projectKey == "" {
+		return NewApiError("The api-key is limited to specific projects, so the project must be set", http.StatusForbidden, string(requestContext.CodeErrAuthoriziation))
+	}
+	p, err := db.GetProjectByIDOrShortName(projectKey)
+	if err != nil || p == nil || !key.AllowsProject(p.ID) {
+		return NewApiError("The api-key is not allowed to be used with this project", http.StatusForbidden, string(requestContext.CodeErrAuthoriziation))
+	}
+	return nil
+}
+
+// GetAPIKeys returns the api-keys of the user.
+// Users that can update the organization see all the keys within the organization.
+func GetAPIKeys() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		filter := types.APIKey{}
+		filter.OrganizationID = session.Organization.ID
+		if Authorize(rc.Context.DB, session, types.ActionUpdateOrganization, types.AccessScope{}) != nil {
+			filter.CreatedBy = session.User.ID
+		}
+		keys, err := rc.Context.DB.FindAPIKeys(0, filter)
+		if err != nil {
+			return nil, ErrApiDatabase("APIKey", err)
+		}
+		return keys, nil
+	}
+}
+
+func CreateAPIKey() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		var j models.APIKeyInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		key := types.APIKey{Name: *j.Name}
+		for _, s := range j.Scopes {
+			scope := types.APIKeyScope(s)
+			if !scope.Valid() {
+				return nil, ErrApiInputValidation(fmt.Sprintf("Invalid scope '%s', allowed values: %v", s, types.APIKeyScopes), "APIKey")
+			}
+			key.Scopes = append(key.Scopes, scope)
+		}
+		db := rc.Context.DB
+		for _, projectKey := range j.ProjectIds {
+			p, err := db.GetProjectByIDOrShortName(projectKey)
+			if err != nil || p == nil || p.OrganizationID != session.Organization.ID {
+				return nil, ErrApiNotFound("Project", projectKey)
+			}
+			key.ProjectIDs = append(key.ProjectIDs, p.ID)
+		}
+		// The key cannot be given permissions that the user does not have
+		if key.Allows(types.APIKeyScopeImport) {
+			scopes := []types.AccessScope{{}}
+			if len(key.ProjectIDs) > 0 {
+				scopes = nil
+				for _, id := range key.ProjectIDs {
+					scopes = append(scopes, types.AccessScope{ProjectID: id})
+				}
+			}
+			for _, scope := range scopes {
+				if err := Authorize(rc.Context.DB, session, types.ActionCreateTranslations, scope); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if !time.Time(j.ExpiresAt).IsZero() {
+			expires := time.Time(j.ExpiresAt)
+			if expires.Before(time.Now()) {
+				return nil, ErrApiInputValidation("The expiry must be in the future", "APIKey")
+			}
+			key.ExpiresAt = &expires
+		}
+		secret, err := utils.GenerateSecret(32)
+		if err != nil {
+			return nil, ErrApiInternalError("Failed to generate the api-key", "APIKey", err)
+		}
+		key.Hash = hashAPIKeySecret(secret)
+		key.CreatedBy = session.User.ID
+		key.OrganizationID = session.Organization.ID
+		key, err = db.CreateAPIKey(key)
+		if err != nil {
+			return nil, ErrApiDatabase("APIKey", err)
+		}
+		return CreatedAPIKey{key, apiKeyPrefix + key.ID + "." + secret}, nil
+	}
+}
+
+// DeleteAPIKey revokes the api-key. Users can revoke their own keys,
+// and users that can update the organization can revoke any key within the organization.
+func DeleteAPIKey() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		id := GetParams(r).ByName("id")
+		if id == "" {
+			return nil, ErrApiMissingArgument("id")
+		}
+		db := rc.Context.DB
+		key, err := db.GetAPIKey(id)
+		if err != nil {
+			return nil, ErrApiDatabase("APIKey", err)
+		}
+		if key == nil || key.OrganizationID != session.Organization.ID {
+			return nil, ErrApiNotFound("APIKey", id)
+		}
+		if key.CreatedBy != session.User.ID {
+			if err := Authorize(rc.Context.DB, session, types.ActionUpdateOrganization, types.AccessScope{}); err != nil {
+				return nil, err
+			}
+		}
+		now := time.Now()
+		revoked, err := db.SoftDeleteAPIKey(id, session.User.ID, &now)
+		if err != nil {
+			return nil, NewApiErr(err, http.StatusBadRequest, string(requestContext.CodeErrAuthoriziation))
+		}
+		return revoked, nil
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/internal"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+type mockSessionManager struct {
+	SessionManager
+	sessions mockSessions
+}
+
+func (m mockSessionManager) GetSession(token string) (types.Session, error) {
+	return m.sessions.GetSession(token)
+}
+
+func TestAPIKeySessions(t *testing.T) {
+	internal.NewMockTimeNow()
+	bb := bboltStorage.NewMockDB(t)
+	org, err := bb.CreateOrganization(types.Organization{Title: "org", CreatedBy: "test"})
+	testza.AssertNoError(t, err)
+	u := types.User{UserName: "ci", Active: true, Store: types.UserStoreLocal, PW: []byte("pw"), CanCreateTranslations: true, CanUpdateTranslations: true}
+	u.CreatedBy = "test"
+	u.OrganizationID = org.ID
+	user, err := bb.CreateUser(u)
+	testza.AssertNoError(t, err)
+	createProject := func(shortName string) types.Project {
+		p := types.Project{Title: shortName, ShortName: shortName}
+		p.CreatedBy = user.ID
+		p.OrganizationID = org.ID
+		project, err := bb.CreateProject(p)
+		testza.AssertNoError(t, err)
+		return project
+	}
+	allowed := createProject("allowed")
+	other := createProject("other")
+
+	createKey := func(secret string, expires *time.Time, scopes ...types.APIKeyScope) types.APIKey {
+		k := types.APIKey{Name: "ci", Scopes: scopes, ProjectIDs: []string{allowed.ID}, Hash: hashAPIKeySecret(secret), ExpiresAt: expires}
+		k.CreatedBy = user.ID
+		k.OrganizationID = org.ID
+		key, err := bb.CreateAPIKey(k)
+		testza.AssertNoError(t, err)
+		return key
+	}
+	key := createKey("s3cret", nil, types.APIKeyScopeExport)
+	sessions := NewAPIKeySessions(mockSessionManager{sessions: mockSessions{"token-a": session("org-a")}}, bb)
+
+	s, err := sessions.GetSession(apiKeyPrefix + key.ID + ".s3cret")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, user.ID, s.User.ID)
+	testza.AssertEqual(t, org.ID, s.Organization.ID)
+	testza.AssertNotNil(t, s.APIKey)
+	testza.AssertFalse(t, s.User.CanCreateTranslations, "The export-scope should not allow creating translations")
+	testza.AssertFalse(t, s.User.CanUpdateTranslations, "Api-keys should never be able to update or delete translations")
+	stored, err := bb.GetAPIKey(key.ID)
+	testza.AssertNoError(t, err)
+	testza.AssertNotNil(t, stored.LastUsedAt)
+
+	testza.AssertNoError(t, AuthorizeAPIKey(bb, s, types.APIKeyScopeExport, "allowed"))
+	testza.AssertNoError(t, AuthorizeAPIKey(bb, s, types.APIKeyScopeExport, allowed.ID))
+	testza.AssertNotNil(t, AuthorizeAPIKey(bb, s, types.APIKeyScopeExport, other.ID), "The key is limited to the allowed project")
+	testza.AssertNotNil(t, AuthorizeAPIKey(bb, s, types.APIKeyScopeExport, ""), "The project must be known for keys limited to projects")
+	testza.AssertNotNil(t, AuthorizeAPIKey(bb, s, types.APIKeyScopeImport, allowed.ID), "The key does not have the import-scope")
+	testza.AssertNotNil(t, AuthorizeAPIKey(bb, s, "", allowed.ID), "Routes without a scope cannot be used with api-keys")
+	testza.AssertNoError(t, AuthorizeAPIKey(bb, session("org-a"), "", ""), "Regular sessions should not be affected")
+
+	importer := createKey("importer", nil, types.APIKeyScopeImport)
+	s, err = sessions.GetSession(apiKeyPrefix + importer.ID + ".importer")
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, s.User.CanCreateTranslations)
+	testza.AssertFalse(t, s.User.CanUpdateTranslations)
+	testza.AssertNoError(t, Authorize(bb, s, types.ActionCreateTranslations, types.AccessScope{ProjectID: allowed.ID}))
+	testza.AssertNotNil(t, Authorize(bb, s, types.ActionUpdateTranslations, types.AccessScope{ProjectID: allowed.ID}), "Api-keys should never be able to update translations")
+	withRoles := s
+	withRoles.User.Roles = []types.RoleAssignment{{Role: types.RoleAdmin}}
+	testza.AssertNotNil(t, Authorize(bb, withRoles, types.ActionUpdateProjects, types.AccessScope{}), "The roles of the user should not extend the scopes of the key")
+
+	for _, token := range []string{
+		apiKeyPrefix + key.ID + ".wrong",
+		apiKeyPrefix + key.ID,
+		apiKeyPrefix + "unknown.s3cret",
+	} {
+		_, err = sessions.GetSession(token)
+		testza.AssertErrorIs(t, err, ErrAPIKeyInvalid, token)
+	}
+
+	expired := time.Now().Add(-time.Hour)
+	expiredKey := createKey("expired", &expired, types.APIKeyScopeExport)
+	_, err = sessions.GetSession(apiKeyPrefix + expiredKey.ID + ".expired")
+	testza.AssertErrorIs(t, err, ErrAPIKeyInvalid)
+
+	now := time.Now()
+	_, err = bb.SoftDeleteAPIKey(key.ID, user.ID, &now)
+	testza.AssertNoError(t, err)
+	_, err = sessions.GetSession(apiKeyPrefix + key.ID + ".s3cret")
+	testza.AssertErrorIs(t, err, ErrAPIKeyInvalid, "Revoked keys should not be usable")
+
+	s, err = sessions.GetSession("token-a")
+	testza.AssertNoError(t, err, "Other tokens should be passed on to the session-manager")
+	testza.AssertNil(t, s.APIKey)
+}
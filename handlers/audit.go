@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+const (
+	auditDefaultLimit = 100
+	auditMaxLimit     = 1000
+)
+
+// AuditLog is a page of entries from the audit-log, newest first
+// swagger:model AuditLog
+type AuditLog struct {
+	Entries []types.AuditEntry `json:"entries"`
+	Offset  int                `json:"offset"`
+	Limit   int                `json:"limit"`
+	// Set if there are more entries after this page
+	HasMore bool `json:"has_more"`
+}
+
+// GetAuditLog returns the entries in the organization's audit-log, with pagination
+func GetAuditLog() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		filter, err := auditFilterFromRequest(r, session.Organization.ID)
+		if err != nil {
+			return nil, err
+		}
+		q := r.URL.Query()
+		result := AuditLog{Entries: []types.AuditEntry{}, Limit: auditDefaultLimit}
+		if s := q.Get("limit"); s != "" {
+			result.Limit, err = strconv.Atoi(s)
+			if err != nil || result.Limit < 1 || result.Limit > auditMaxLimit {
+				return nil, ErrApiInputValidation("The limit must be a number between 1 and "+strconv.Itoa(auditMaxLimit), "Audit")
+			}
+		}
+		if s := q.Get("offset"); s != "" {
+			result.Offset, err = strconv.Atoi(s)
+			if err != nil || result.Offset < 0 {
+				return nil, ErrApiInputValidation("The offset must be a positive number", "Audit")
+			}
+		}
+		skipped := 0
+		err = rc.Context.DB.IterateAuditEntries(filter, func(entry types.AuditEntry) bool {
+			if skipped < result.Offset {
+				skipped++
+				return false
+			}
+			if len(result.Entries) == result.Limit {
+				result.HasMore = true
+				return true
+			}
+			result.Entries = append(result.Entries, entry)
+			return false
+		})
+		if err != nil {
+			return nil, ErrApiDatabase("Audit", err)
+		}
+		return result, nil
+	}
+}
+
+// ExportAuditLog writes all the matching entries in the organization's audit-log as JSON Lines, newest first
+func ExportAuditLog() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		filter, err := auditFilterFromRequest(r, session.Organization.ID)
+		if err != nil {
+			return nil, err
+		}
+		rw.Header().Set("Content-Type", "application/x-ndjson")
+		rw.Header().Set("Content-Disposition", `attachment; filename="audit.jsonl"`)
+		enc := json.NewEncoder(rw)
+		var writeErr error
+		err = rc.Context.DB.IterateAuditEntries(filter, func(entry types.AuditEntry) bool {
+			writeErr = enc.Encode(entry)
+			return writeErr != nil
+		})
+		if err != nil {
+			rc.L.Error().Err(err).Msg("Failed to export the audit-log")
+		}
+		if writeErr != nil {
+			rc.L.Warn().Err(writeErr).Msg("Failed to write the audit-log")
+		}
+		return nil, nil
+	}
+}
+
+func auditFilterFromRequest(r *http.Request, organizationID string) (types.AuditFilter, error) {
+	q := r.URL.Query()
+	filter := types.AuditFilter{
+		OrganizationID: organizationID,
+		Entity:         q.Get("entity"),
+		UserID:         q.Get("user"),
+	}
+	if s := q.Get("since"); s != "" {
+		since, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return filter, ErrApiInputValidation("since must be a RFC3339-formatted time, like 2006-01-02T15:04:05Z", "Audit")
+		}
+		filter.Since = since
+	}
+	return filter, nil
+}
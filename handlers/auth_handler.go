@@ -21,32 +21,16 @@ func NewAuthHandler(
 
 	return func(rw http.ResponseWriter, r *http.Request) (*http.Request, error) {
 
-		token := r.Header.Get("Authorization")
-		if token == "" {
-			cookie, err := r.Cookie("token")
-			if err != nil {
-				if !errors.Is(err, http.ErrNoCookie) {
-					return r, err
-				}
-			}
-			if cookie != nil {
-				token = cookie.Value
-			}
-
-		}
-		if token == "" {
-			// Using tokens within the url's query-paramaters is not recommended, but Skivers api does not restrict the usage.
-			token = r.URL.Query().Get("token")
+		token, err := tokenFromRequest(r)
+		if err != nil {
+			return r, err
 		}
 		if token == "" {
 			return r, nil
 		}
 		sess, err := userSessions.GetSession(token)
 		if err == nil {
-			expiresD := sess.Expires.Sub(time.Now())
-			rw.Header().Add("session-expires", sess.Expires.String())
-			rw.Header().Add("session-expires-in", expiresD.String())
-			rw.Header().Add("session-expires-in-seconds", strconv.Itoa(int(expiresD.Seconds())))
+			addSessionExpiryHeaders(rw, sess)
 			// r = r.WithContext(context.WithValue(r.Context(), ContextKeySession, session))
 			r = setValue(r, ContextKeySession, sess)
 			return r, nil
@@ -55,6 +39,71 @@ func NewAuthHandler(
 		return r, nil
 	}
 }
+
+// Returns the session-token from the Authorization-header, the token-cookie or the query-parameter, in that order.
+func tokenFromRequest(r *http.Request) (string, error) {
+	token := r.Header.Get("Authorization")
+	if token == "" {
+		cookie, err := r.Cookie("token")
+		if err != nil {
+			if !errors.Is(err, http.ErrNoCookie) {
+				return "", err
+			}
+		}
+		if cookie != nil {
+			token = cookie.Value
+		}
+
+	}
+	if token == "" {
+		// Using tokens within the url's query-paramaters is not recommended, but Skivers api does not restrict the usage.
+		token = r.URL.Query().Get("token")
+	}
+	return token, nil
+}
+
+// Api-keys without an expiry have no expiry-headers
+func addSessionExpiryHeaders(rw http.ResponseWriter, sess types.Session) {
+	if sess.Expires.IsZero() {
+		return
+	}
+	expiresD := sess.Expires.Sub(time.Now())
+	rw.Header().Add("session-expires", sess.Expires.String())
+	rw.Header().Add("session-expires-in", expiresD.String())
+	rw.Header().Add("session-expires-in-seconds", strconv.Itoa(int(expiresD.Seconds())))
+}
+
+// Sets the session-cookie, and the headers for when the session expires.
+// Returns the duration until the session expires.
+func setSessionCookie(rw http.ResponseWriter, r *http.Request, session types.Session) time.Duration {
+	expiresD := session.Expires.Sub(time.Now())
+
+	// TODO: move some of these settings to global config, organization settings and/or project settings.
+	cookie := &http.Cookie{
+		Name: "token",
+		// TODO: when the server is behind a subpath (e.g.
+		// exmaple.com/skiver/), the reverse-proxy in front may not return our
+		// path, and we probably need to get it from the config
+		Path:     "/",
+		Value:    session.Token,
+		MaxAge:   int(expiresD.Seconds()),
+		Secure:   r.TLS != nil,
+		HttpOnly: true,
+	}
+	xproto := r.Header.Get("X-Forwarded-Proto")
+	switch xproto {
+	case "http":
+		cookie.Secure = false
+		// does not work with http
+	case "https":
+		cookie.Secure = true
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+	addSessionExpiryHeaders(rw, session)
+	http.SetCookie(rw, cookie)
+	return expiresD
+}
+
 func setValue(r *http.Request, key string, val interface{}) *http.Request {
 	return r.WithContext(context.WithValue(r.Context(), key, val))
 }
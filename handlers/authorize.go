@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+// ProjectGetter is used by Authorize to resolve the project of the scope
+type ProjectGetter interface {
+	GetProject(ID string) (*types.Project, error)
+}
+
+// Authorize is where all permissions are checked.
+// It returns an error unless the user of the session can perform the action within the scope,
+// either through its permissions for the whole organization, or through its roles for the project and locale.
+// Sessions created from api-keys are further limited to the scopes of the key.
+// Projects that do not belong to the organization of the session are reported as not found.
+func Authorize(db ProjectGetter, s types.Session, action types.Action, scope types.AccessScope) error {
+	if scope.ProjectID != "" {
+		p, err := db.GetProject(scope.ProjectID)
+		if err != nil && !errors.Is(err, bboltStorage.ErrNotFound) {
+			return ErrApiDatabase("Project", err)
+		}
+		if p == nil || p.ID == "" || p.OrganizationID != s.Organization.ID {
+			return ErrApiNotFound("Project", scope.ProjectID)
+		}
+	}
+	if s.APIKey != nil && !s.APIKey.AllowsAction(action) {
+		return NewApiError("The api-key is not allowed to "+string(action), http.StatusForbidden, string(requestContext.CodeErrAuthoriziation))
+	}
+	if !s.User.Can(action, scope) {
+		return NewApiError("You are not authorized to "+string(action), http.StatusUnauthorized, string(requestContext.CodeErrAuthoriziation))
+	}
+	return nil
+}
+
+// AuthorizeGrant returns an error unless the user of the session can perform the action everywhere the role-assignment applies.
+// An assignment without a project applies to the whole organization.
+// This is used when giving users permissions and roles, so that users cannot give anyone more access than they have themselves.
+func AuthorizeGrant(db ProjectGetter, s types.Session, action types.Action, ra types.RoleAssignment) error {
+	if err := Authorize(db, s, action, types.AccessScope{ProjectID: ra.ProjectID}); err != nil {
+		return err
+	}
+	if !s.User.CanGrant(action, ra) {
+		return NewApiError("You are not authorized to grant others access to "+string(action), http.StatusForbidden, string(requestContext.CodeErrAuthoriziation))
+	}
+	return nil
+}
+
+// Returns the scope of the translation, which is the project it belongs to
+func translationScope(db types.Storage, t types.Translation) (types.AccessScope, error) {
+	p, err := t.GetProject(db)
+	if err != nil {
+		return types.AccessScope{}, ErrApiDatabase("Project", err)
+	}
+	return types.AccessScope{ProjectID: p.ID}, nil
+}
+
+// Returns the scope of the translation-value, which is the project of its translation, and its locale
+func translationValueScope(db types.Storage, tv types.TranslationValue) (types.AccessScope, error) {
+	t, err := db.GetTranslation(tv.TranslationID)
+	if err != nil {
+		return types.AccessScope{}, ErrApiDatabase("Translation", err)
+	}
+	if t == nil {
+		return types.AccessScope{}, ErrApiNotFound("Translation", tv.TranslationID)
+	}
+	scope, err := translationScope(db, *t)
+	scope.LocaleID = tv.LocaleID
+	return scope, err
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestAuthorizeRejectsProjectsInOtherOrganizations(t *testing.T) {
+	bb := bboltStorage.NewMockDB(t)
+	org, err := bb.CreateOrganization(types.Organization{Title: "acme", CreatedBy: "test"})
+	testza.AssertNoError(t, err)
+	project := func(organizationID, shortName string) types.Project {
+		p := types.Project{Title: shortName, ShortName: shortName}
+		p.CreatedBy = "test"
+		p.OrganizationID = organizationID
+		project, err := bb.CreateProject(p)
+		testza.AssertNoError(t, err)
+		return project
+	}
+	own := project(org.ID, "own")
+	theirs := project("org-other", "theirs")
+
+	// Permissions and organization-wide roles apply to any project, so the project itself must be checked
+	for name, user := range map[string]types.User{
+		"permissions": {CanUpdateProjects: true},
+		"roles":       {Roles: []types.RoleAssignment{{Role: types.RoleAdmin}}},
+	} {
+		user.OrganizationID = org.ID
+		s := types.Session{User: user, Organization: org}
+		testza.AssertNoError(t, Authorize(bb, s, types.ActionCreateSnapshots, types.AccessScope{ProjectID: own.ID}), name)
+		err := Authorize(bb, s, types.ActionCreateSnapshots, types.AccessScope{ProjectID: theirs.ID})
+		testza.AssertNotNil(t, err, name)
+		testza.AssertEqual(t, http.StatusNotFound, err.(requestContext.APIError).ErrHttpStatus(), name)
+		testza.AssertNotNil(t, Authorize(bb, s, types.ActionCreateSnapshots, types.AccessScope{ProjectID: "does-not-exist"}), name)
+	}
+}
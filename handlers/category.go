@@ -29,6 +29,10 @@ func PostCategory(db types.Storage) AppHandler {
 			return nil, err
 		}
 
+		if err := Authorize(rc.Context.DB, session, types.ActionCreateTranslations, types.AccessScope{ProjectID: *j.ProjectID}); err != nil {
+			return nil, err
+		}
+
 		c := types.Category{
 			ProjectID:   *j.ProjectID,
 			Key:         *j.Key,
@@ -64,13 +68,23 @@ func UpdateCategory(db types.Storage) AppHandler {
 		if cid == "" {
 			return nil, ErrApiMissingArgument("ID")
 		}
+		existing, err := db.GetCategory(cid)
+		if err != nil {
+			return nil, ErrApiDatabase("Category", err)
+		}
+		if existing == nil || existing.OrganizationID != session.Organization.ID {
+			return nil, ErrApiNotFound("Category", cid)
+		}
+		if err := Authorize(rc.Context.DB, session, types.ActionCreateTranslations, types.AccessScope{ProjectID: existing.ProjectID}); err != nil {
+			return nil, err
+		}
 
 		c := types.Category{
 			Key:         j.Key,
 			Description: j.Description,
 			Title:       j.Title,
 		}
-		c.CreatedBy = session.User.ID
+		c.UpdatedBy = session.User.ID
 		c.OrganizationID = session.Organization.ID
 		category, err := db.UpdateCategory(cid, c)
 		if err != nil {
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/runar-rkmedia/skiver/requestContext"
+)
+
+// Returns the version that an update is based on, from the input or the If-Match-header.
+// Zero means that the update should not be checked for conflicts.
+func expectedVersion(r *http.Request, inputVersion int64) (int, error) {
+	if inputVersion != 0 {
+		return int(inputVersion), nil
+	}
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, nil
+	}
+	// Only a single entity-tag is supported, weak or strong
+	tag := strings.TrimPrefix(ifMatch, "W/")
+	version, err := strconv.Atoi(strings.Trim(tag, `"`))
+	if err != nil {
+		return 0, ErrApiInputValidation("The If-Match-header must be the ETag of the entity, like \"3\"", "If-Match")
+	}
+	return version, nil
+}
+
+// Sets the ETag-header of the response to the version of the entity
+func setETag(rw http.ResponseWriter, version int) {
+	if version == 0 {
+		return
+	}
+	rw.Header().Set("ETag", fmt.Sprintf(`"%d"`, version))
+}
+
+// ErrApiVersionConflict is returned when an update is based on an outdated version of an entity.
+// The current entity is included, so that the client can merge the changes.
+func ErrApiVersionConflict(key string, current interface{}) error {
+	return NewApiError(
+		fmt.Sprintf("The %s has been changed by someone else since the version that the update was based on", key),
+		http.StatusConflict,
+		string(requestContext.CodeErrVersionConflict),
+		current,
+	)
+}
@@ -28,9 +28,18 @@ func getExport(l logger.AppLogger, exportCache Cache, db types.Storage, opt impo
 		break
 	case "i18n":
 		break
+	case "po", "pot":
+		break
+	case "android", "strings", "stringsdict":
+		break
+	case "xliff", "xliff2":
+		if opt.SourceLocale == "" {
+			err = NewApiError("The source_locale must be set for the xliff-formats", http.StatusBadRequest, string(requestContext.CodeErrInputValidation))
+			return
+		}
 	default:
 
-		validFormats := []string{"i18n", "raw", "typescript"}
+		validFormats := []string{"i18n", "raw", "typescript", "po", "pot", "xliff", "xliff2", "android", "strings", "stringsdict"}
 		valid := false
 		for _, v := range validFormats {
 			if format == v {
@@ -46,7 +55,7 @@ func getExport(l logger.AppLogger, exportCache Cache, db types.Storage, opt impo
 		err = NewApiError("A project must be selected", http.StatusBadRequest, string(requestContext.CodeErrInputValidation))
 		return
 	}
-	cacheKeys := []string{opt.InOrg, format, localeKey, tag}
+	cacheKeys := []string{opt.InOrg, format, localeKey, tag, opt.SourceLocale}
 	cacheKeys = append(cacheKeys, locales...)
 	cacheKeys = append(cacheKeys, projectKey)
 	sort.Strings(cacheKeys)
@@ -54,8 +63,17 @@ func getExport(l logger.AppLogger, exportCache Cache, db types.Storage, opt impo
 	if flatten {
 		cacheKey += "F"
 	}
+	if opt.ICU {
+		cacheKey += "I"
+	}
+	if opt.ApprovedOnly {
+		cacheKey += "A"
+	}
 	if exportCache != nil {
 		if v, ok := exportCache.Get(cacheKey); ok {
+			if c, ok := v.(cachedExport); ok {
+				return c.out, c.contentType, nil
+			}
 			return v, "", nil
 		}
 	}
@@ -67,11 +85,15 @@ func getExport(l logger.AppLogger, exportCache Cache, db types.Storage, opt impo
 		if err != nil {
 			return
 		}
+		var cached interface{} = toWriter
+		if contentType != "" {
+			cached = cachedExport{toWriter, contentType}
+		}
 		if tag != "" {
-			exportCache.SetDefault(cacheKey, toWriter)
+			exportCache.SetDefault(cacheKey, cached)
 		} else {
 			// A very short cache-time for exports that are pulled directly from live-data.
-			exportCache.Set(cacheKey, toWriter, time.Second*3)
+			exportCache.Set(cacheKey, cached, time.Second*3)
 		}
 	}()
 
@@ -127,9 +149,13 @@ func getExport(l logger.AppLogger, exportCache Cache, db types.Storage, opt impo
 		ep = s.Project
 
 	} else {
+		localeFilter := locales
+		if opt.SourceLocale != "" && len(locales) > 0 {
+			localeFilter = append([]string{opt.SourceLocale}, locales...)
+		}
 		// These options shouild be the same as when creating snapshots.
 		ep, err = ps.Extend(db, types.ExtendOptions{
-			LocaleFilter:   locales,
+			LocaleFilter:   localeFilter,
 			ByKeyLike:      false,
 			ByID:           true,
 			ErrOnNoLocales: true,
@@ -151,15 +177,31 @@ func getExport(l logger.AppLogger, exportCache Cache, db types.Storage, opt impo
 		err = fmt.Errorf("Error extending project '%s' (%s): %w", ep.Title, ep.ID, err)
 		return
 	}
+	if opt.ApprovedOnly {
+		ep = importexport.OnlyApproved(ep)
+	}
 	writer, contentType, err := importexport.ExportExtendedProject(l, ep, opt.Locales, importexport.LocaleKeyEnum{}.From(opt.LocaleKey),
 		importexport.Format{}.From(opt.Format),
-		opt.Locales)
+		opt.Locales,
+		importexport.ExportFormatOptions{SourceLocale: opt.SourceLocale, ICU: opt.ICU})
 	if err != nil {
 		err = NewApiErr(err, http.StatusBadGateway, "ExportExtended")
 	}
 	return writer, contentType, err
 }
 
+// Export creates the export, in the same way as the export-endpoint does, but without any caching.
+// If the content-type is set, the export is already marshalled to bytes.
+func Export(l logger.AppLogger, db types.Storage, opt importexport.ExportOptions) (interface{}, string, error) {
+	return getExport(l, nil, db, opt)
+}
+
+// Exports that are not marshallable needs to keep their content-type in the cache
+type cachedExport struct {
+	out         interface{}
+	contentType string
+}
+
 func GetExport(
 	exportCache Cache,
 ) AppHandler {
@@ -195,9 +237,12 @@ func GetExport(
 		q, err := ExtractParams(r)
 		format := "i18n" // Default format
 		localeKey := ""
+		sourceLocale := ""
 		tag := ""
 		var locales []string
 		flatten := true
+		icu := false
+		approvedOnly := false
 		for k, v := range q {
 			switch strings.ToLower(k) {
 			case "locale", "l":
@@ -236,19 +281,32 @@ func GetExport(
 					return
 				}
 				localeKey = v[0]
+			case "source_locale", "sl":
+				if len(v) > 1 {
+					rc.WriteError("source_locale specified more than once", requestContext.CodeErrInputValidation)
+					return
+				}
+				sourceLocale = v[0]
 			case "no_flatten":
 				flatten = false
+			case "icu":
+				icu = true
+			case "approved":
+				approvedOnly = true
 			}
 		}
 
 		toWriter, contentType, err := getExport(rc.L, exportCache, rc.Context.DB, importexport.ExportOptions{
-			InOrg:     orgKey,
-			Project:   projectKey,
-			Locales:   locales,
-			LocaleKey: localeKey,
-			Format:    format,
-			Tag:       tag,
-			NoFlatten: !flatten,
+			InOrg:        orgKey,
+			Project:      projectKey,
+			Locales:      locales,
+			LocaleKey:    localeKey,
+			Format:       format,
+			Tag:          tag,
+			NoFlatten:    !flatten,
+			SourceLocale: sourceLocale,
+			ICU:          icu,
+			ApprovedOnly: approvedOnly,
 		})
 		if err != nil {
 			return toWriter, err
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/go-common/logger"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/importexport"
+	"github.com/runar-rkmedia/skiver/internal"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestExportWithMultipleLocales(t *testing.T) {
+	internal.NewMockTimeNow()
+	l := logger.GetLoggerWithLevel("test", "fatal")
+	bb := bboltStorage.NewMockDB(t)
+	err := bb.StandardSeed()
+	testza.AssertNoError(t, err)
+	base := types.Project{}
+	base.CreatedBy = "jim"
+	base.OrganizationID = "org-123"
+	base.ID = "proj-123"
+	base.ShortName = "proj"
+	base.Title = "proj"
+	project, err := bb.CreateProject(base)
+	testza.AssertNoError(t, err)
+
+	input := `
+en:
+  Welcome: Welcome
+nb:
+  Welcome: Velkommen
+nn:
+  Welcome: Velkomen
+`
+	r, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(input))
+	r.Header.Set("Content-Type", "text/vnd.yaml")
+	impo, Err := ImportIntoProject(l, bb, "i18n", base.CreatedBy, project, "", []byte(input), r, ImportIntoProjectOptions{NoDryRun: true})
+	testza.AssertNil(t, Err)
+	testza.AssertNotNil(t, impo)
+	p, err := bb.GetProject(project.ID)
+	testza.AssertNoError(t, err)
+
+	// Each locale must be kept if it matches any of the filters, and not only the first one
+	for name, filter := range map[string][]string{
+		"By IETF":     {"en-GB", "nb-NO"},
+		"By iso-code": {"nob", "en"},
+		"Mixed":       {"nb", "eng"},
+	} {
+		ep, err := p.Extend(bb, types.ExtendOptions{ByID: true, LocaleFilter: filter})
+		testza.AssertNoError(t, err, name)
+		export, err := importexport.ExportI18N(ep, importexport.ExportI18NOptions{})
+		testza.AssertNoError(t, err, name)
+		want := map[string]interface{}{
+			"en": map[string]interface{}{"Welcome": "Welcome"},
+			"nb": map[string]interface{}{"Welcome": "Velkommen"},
+		}
+		if err := internal.Compare(name, export.ToMap(), want); err != nil {
+			t.Error(err)
+		}
+	}
+}
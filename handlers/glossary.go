@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/glossary"
+	"github.com/runar-rkmedia/skiver/models"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+// GetGlossary returns all the entries within the organization's glossary
+func GetGlossary() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		filter := types.GlossaryEntry{}
+		filter.OrganizationID = session.Organization.ID
+		entries, err := rc.Context.DB.FindGlossaryEntries(0, filter)
+		if err != nil {
+			return nil, ErrApiDatabase("Glossary", err)
+		}
+		return entries, nil
+	}
+}
+
+func CreateGlossaryEntry() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		var j models.GlossaryEntryInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		e, err := glossaryEntryFromInput(rc.Context.DB, j)
+		if err != nil {
+			return nil, err
+		}
+		e.CreatedBy = session.User.ID
+		e.OrganizationID = session.Organization.ID
+		entry, err := rc.Context.DB.CreateGlossaryEntry(e)
+		if err != nil {
+			if errors.Is(err, bboltStorage.ErrDuplicate) {
+				return nil, NewApiErr(err, http.StatusConflict, string(requestContext.CodeErrGlossary))
+			}
+			return nil, ErrApiDatabase("Glossary", err)
+		}
+		return entry, nil
+	}
+}
+
+func UpdateGlossaryEntry() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		id := GetParams(r).ByName("id")
+		if id == "" {
+			return nil, ErrApiMissingArgument("id")
+		}
+		var j models.GlossaryEntryInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		db := rc.Context.DB
+		existing, err := db.GetGlossaryEntry(id)
+		if err != nil {
+			return nil, ErrApiDatabase("Glossary", err)
+		}
+		if existing == nil || existing.OrganizationID != session.Organization.ID {
+			return nil, ErrApiNotFound("Glossary", id)
+		}
+		e, err := glossaryEntryFromInput(db, j)
+		if err != nil {
+			return nil, err
+		}
+		e.UpdatedBy = session.User.ID
+		entry, err := db.UpdateGlossaryEntry(id, e)
+		if err != nil {
+			return nil, ErrApiDatabase("Glossary", err)
+		}
+		return entry, nil
+	}
+}
+
+func DeleteGlossaryEntry() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		id := GetParams(r).ByName("id")
+		if id == "" {
+			return nil, ErrApiMissingArgument("id")
+		}
+		var j models.DeleteInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		db := rc.Context.DB
+		existing, err := db.GetGlossaryEntry(id)
+		if err != nil {
+			return nil, ErrApiDatabase("Glossary", err)
+		}
+		if existing == nil || existing.OrganizationID != session.Organization.ID {
+			return nil, ErrApiNotFound("Glossary", id)
+		}
+		var deleteTime *time.Time
+		if !j.Undelete {
+			t := time.Now()
+			deleteTime = &t
+		}
+		entry, err := db.SoftDeleteGlossaryEntry(id, session.User.ID, deleteTime)
+		if err != nil {
+			return nil, NewApiErr(err, http.StatusBadRequest, string(requestContext.CodeErrGlossary))
+		}
+		return entry, nil
+	}
+}
+
+// GlossaryHighlights are the glossary-matches and -violations for each of the values of a translation
+// swagger:model GlossaryHighlights
+type GlossaryHighlights struct {
+	// The highlights, by translation-value-id
+	Values map[string]GlossaryValueHighlights `json:"values"`
+}
+
+type GlossaryValueHighlights struct {
+	LocaleID   string               `json:"locale_id"`
+	Matches    []glossary.Match     `json:"matches"`
+	Violations []glossary.Violation `json:"violations,omitempty"`
+	// The matches within the contexts of the value, by context-key
+	ContextMatches map[string][]glossary.Match `json:"context_matches,omitempty"`
+}
+
+// GetTranslationGlossary returns the glossary-terms that are used within the values of the translation,
+// so that they can be highlighted in the editor.
+func GetTranslationGlossary() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		id := GetParams(r).ByName("id")
+		if id == "" {
+			return nil, ErrApiMissingArgument("id")
+		}
+		db := rc.Context.DB
+		t, err := db.GetTranslation(id)
+		if err != nil {
+			return nil, ErrApiDatabase("Translation", err)
+		}
+		if t == nil || t.OrganizationID != session.Organization.ID {
+			return nil, ErrApiNotFound("Translation", id)
+		}
+		g, err := organizationGlossary(db, session.Organization.ID)
+		if err != nil {
+			return nil, ErrApiDatabase("Glossary", err)
+		}
+		values, err := translationValuesOf(db, *t)
+		if err != nil {
+			return nil, ErrApiDatabase("TranslationValue", err)
+		}
+		result := GlossaryHighlights{Values: map[string]GlossaryValueHighlights{}}
+		for _, tv := range values {
+			h := GlossaryValueHighlights{
+				LocaleID:   tv.LocaleID,
+				Matches:    g.Matches(tv.Value, tv.LocaleID),
+				Violations: g.CheckTranslationValue(tv, values),
+			}
+			if h.Matches == nil {
+				h.Matches = []glossary.Match{}
+			}
+			for ctx, v := range tv.Context {
+				if m := g.Matches(v, tv.LocaleID); len(m) > 0 {
+					if h.ContextMatches == nil {
+						h.ContextMatches = map[string][]glossary.Match{}
+					}
+					h.ContextMatches[ctx] = m
+				}
+			}
+			result.Values[tv.ID] = h
+		}
+		return result, nil
+	}
+}
+
+// Maps the input to an entry, where the keys of the translations are resolved to locale-ids.
+func glossaryEntryFromInput(db types.Storage, j models.GlossaryEntryInput) (types.GlossaryEntry, error) {
+	e := types.GlossaryEntry{
+		Term:           strings.TrimSpace(*j.Term),
+		Description:    j.Description,
+		DoNotTranslate: j.DoNotTranslate,
+		CaseSensitive:  j.CaseSensitive,
+	}
+	if e.Term == "" {
+		return e, ErrApiInputValidation("The term cannot be empty", "Glossary")
+	}
+	if len(j.Translations) == 0 {
+		return e, nil
+	}
+	locales, err := db.GetLocales()
+	if err != nil {
+		return e, ErrApiDatabase("Locale", err)
+	}
+	e.Translations = map[string]string{}
+	for key, v := range j.Translations {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		localeID := ""
+		for _, l := range locales {
+			if l.Matches(key) {
+				localeID = l.ID
+				break
+			}
+		}
+		if localeID == "" {
+			return e, ErrApiNotFound("Locale", key)
+		}
+		e.Translations[localeID] = v
+	}
+	return e, nil
+}
+
+func organizationGlossary(db types.Storage, organizationID string) (glossary.Glossary, error) {
+	filter := types.GlossaryEntry{}
+	filter.OrganizationID = organizationID
+	entries, err := db.FindGlossaryEntries(0, filter)
+	if err != nil {
+		return glossary.Glossary{}, err
+	}
+	return glossary.New(entries), nil
+}
+
+// Returns the values of the translation that are not deleted, ordered by locale
+func translationValuesOf(db types.Storage, t types.Translation) ([]types.TranslationValue, error) {
+	var values []types.TranslationValue
+	for _, id := range t.ValueIDs {
+		tv, err := db.GetTranslationValue(id)
+		if err != nil {
+			return values, err
+		}
+		if tv != nil && tv.Deleted == nil {
+			values = append(values, *tv)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].LocaleID < values[j].LocaleID
+	})
+	return values, nil
+}
+
+// Checks the translation-value against the organization's glossary, with the other values of the translation as source.
+func glossaryViolations(db types.Storage, tv types.TranslationValue) ([]glossary.Violation, error) {
+	g, err := organizationGlossary(db, tv.OrganizationID)
+	if err != nil || g.Len() == 0 {
+		return nil, err
+	}
+	t, err := db.GetTranslation(tv.TranslationID)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	values, err := translationValuesOf(db, *t)
+	if err != nil {
+		return nil, err
+	}
+	return g.CheckTranslationValue(tv, values), nil
+}
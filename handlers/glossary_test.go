@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/go-common/logger"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/glossary"
+	"github.com/runar-rkmedia/skiver/internal"
+	"github.com/runar-rkmedia/skiver/models"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestGlossaryViolations(t *testing.T) {
+	internal.NewMockTimeNow()
+	l := logger.GetLoggerWithLevel("test", "fatal")
+	bb := bboltStorage.NewMockDB(t)
+	err := bb.StandardSeed()
+	testza.AssertNoError(t, err)
+	base := types.Project{}
+	base.CreatedBy = "jim"
+	base.OrganizationID = "org-123"
+	base.ShortName = "proj"
+	base.Title = "proj"
+	project, err := bb.CreateProject(base)
+	testza.AssertNoError(t, err)
+
+	input := `
+en-GB:
+  General:
+    Workspace: Open your workspace
+nb-NO:
+  General:
+    Workspace: Åpne ditt område
+`
+	r, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(input))
+	r.Header.Set("Content-Type", "text/vnd.yaml")
+	_, Err := ImportIntoProject(l, bb, "i18n", base.CreatedBy, project, "", []byte(input), r, ImportIntoProjectOptions{NoDryRun: true})
+	testza.AssertNil(t, Err)
+
+	term := "workspace"
+	e, err := glossaryEntryFromInput(bb, models.GlossaryEntryInput{Term: &term, Translations: map[string]string{"nb-NO": "arbeidsområde"}})
+	testza.AssertNoError(t, err)
+	e.CreatedBy = base.CreatedBy
+	e.OrganizationID = base.OrganizationID
+	entry, err := bb.CreateGlossaryEntry(e)
+	testza.AssertNoError(t, err)
+	_, err = bb.CreateGlossaryEntry(e)
+	testza.AssertErrorIs(t, err, bboltStorage.ErrDuplicate)
+
+	nb, err := bb.GetLocaleByIDOrShortName("nb-NO")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, map[string]string{nb.ID: "arbeidsområde"}, entry.Translations)
+
+	tvs, err := bb.GetTranslationValues()
+	testza.AssertNoError(t, err)
+	for _, tv := range tvs {
+		violations, err := glossaryViolations(bb, tv)
+		testza.AssertNoError(t, err)
+		if tv.LocaleID != nb.ID {
+			testza.AssertLen(t, violations, 0)
+			continue
+		}
+		testza.AssertEqual(t, []glossary.Violation{{
+			EntryID:  entry.ID,
+			Term:     "workspace",
+			Expected: "arbeidsområde",
+			Message:  "The term 'workspace' should be translated as 'arbeidsområde'",
+		}}, violations)
+	}
+}
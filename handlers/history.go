@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+// GetTranslationValueHistory returns the stored revisions of the translation-value, oldest first.
+func GetTranslationValueHistory() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		id := GetParams(r).ByName("id")
+		if id == "" {
+			return nil, ErrApiMissingArgument("id")
+		}
+		db := rc.Context.DB
+		if _, err := organizationTranslationValue(db, id, session.Organization.ID); err != nil {
+			return nil, err
+		}
+		revisions, err := db.GetRevisions(id)
+		if err != nil {
+			return nil, ErrApiDatabase("Revision", err)
+		}
+		return revisions, nil
+	}
+}
+
+// RevertTranslationValue sets the value and context of the translation-value back to an earlier revision.
+func RevertTranslationValue() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		params := GetParams(r)
+		id := params.ByName("id")
+		if id == "" {
+			return nil, ErrApiMissingArgument("id")
+		}
+		revision, err := strconv.Atoi(params.ByName("revision"))
+		if err != nil || revision < 1 {
+			return nil, ErrApiInputValidation("The revision must be a positive number", "Revision")
+		}
+		db := rc.Context.DB
+		existing, err := organizationTranslationValue(db, id, session.Organization.ID)
+		if err != nil {
+			return nil, err
+		}
+		scope, err := translationValueScope(db, *existing)
+		if err != nil {
+			return nil, err
+		}
+		if err := Authorize(rc.Context.DB, session, types.ActionEditTranslationValues, scope); err != nil {
+			return nil, err
+		}
+		tv, err := db.RevertTranslationValue(id, revision, session.User.ID)
+		switch {
+		case err == nil:
+			return tv, nil
+		case errors.Is(err, bboltStorage.ErrNotFound):
+			return nil, ErrApiNotFound("Revision", params.ByName("revision"))
+		case errors.Is(err, bboltStorage.ErrNoFieldsChanged):
+			return nil, NewApiErr(err, http.StatusBadRequest, string(requestContext.CodeErrUpdateTranslationValue))
+		}
+		return nil, ErrApiDatabase("TranslationValue", err)
+	}
+}
@@ -120,9 +120,7 @@ func ImportDescriptionsIntoProject(l logger.AppLogger, db types.Storage, created
 			if !ok {
 				return nil, NewError("failed to convert changerequest-payload to Category", "ChangeRequest:To:Category")
 			}
-			if payload.UpdatedBy == "" {
-				payload.UpdatedBy = createdBy
-			}
+			payload.UpdatedBy = createdBy
 			_, err := db.UpdateCategory(payload.ID, payload)
 			if err != nil {
 				l.Error().Err(err).Interface("payload", payload).Msg("Failed to update category")
@@ -136,9 +134,6 @@ func ImportDescriptionsIntoProject(l logger.AppLogger, db types.Storage, created
 			if !ok {
 				return nil, NewError("failed to convert changerequest-field to string", "ChangeRequest:To:String")
 			}
-			if payload.UpdatedBy == "" {
-				payload.UpdatedBy = createdBy
-			}
 			payload.UpdatedBy = createdBy
 			_, err := db.UpdateTranslation(payload.ID, payload)
 			if err != nil {
@@ -158,6 +153,8 @@ func ImportDescriptionsIntoProject(l logger.AppLogger, db types.Storage, created
 type ImportIntoProjectOptions struct {
 	NoDryRun    bool
 	ErrOnNoDiff bool
+	// The session that imports. Imported values are approved if the session may review them, and need review otherwise.
+	Session *types.Session
 }
 
 func ImportIntoProject(
@@ -173,21 +170,111 @@ func ImportIntoProject(
 	opts ...ImportIntoProjectOptions,
 ) (*ImportResult, *Error) {
 	var input map[string]interface{}
-	err := requestContext.UnmarshalRequestBytes(r, body, &input)
-	if err != nil {
-		return nil, NewError("failed to unmarshal body", "unmarshal").AddError(err)
-	}
+	var meta map[string]importexport.TranslationMeta
+	var warnings []importexport.Warning
+	source := types.CreatorSourceImport
+	// Some formats are always for a single locale, and does not include the locale as the root-key
+	singleLocaleFormat := false
 	options := utils.GetFirst(opts)
+	if options == nil {
+		options = &ImportIntoProjectOptions{}
+	}
 	dry := !options.NoDryRun
 	switch kind {
 	case "":
-		return nil, NewError("empty value for kind, allowed values: i18n, describe, auto", requestContext.CodeErrInputValidation)
+		return nil, NewError("empty value for kind, allowed values: i18n, po, xliff, android, strings, stringsdict, describe, auto", requestContext.CodeErrInputValidation)
+	case "po":
+		po, err := importexport.ParseGettext(body)
+		if err != nil {
+			return nil, NewError("failed to parse gettext-file", "import:gettext").AddError(err)
+		}
+		tree, m, w, err := po.ToI18Next()
+		warnings = append(warnings, w...)
+		if err != nil {
+			return nil, NewError("failed to convert gettext-file", "import:gettext").AddError(err)
+		}
+		if localeLike == "" {
+			localeLike = po.Language()
+		}
+		if localeLike == "" {
+			return nil, NewError("The locale must be set, either in the url or in the Language-header of the file", requestContext.CodeErrInputValidation)
+		}
+		meta = m
+		input = tree
+		singleLocaleFormat = true
+	case "xliff":
+		x, err := importexport.ParseXliff(body)
+		if err != nil {
+			return nil, NewError("failed to parse xliff-file", "import:xliff").AddError(err)
+		}
+		tree, w, err := x.ToI18Next()
+		warnings = append(warnings, w...)
+		if err != nil {
+			return nil, NewError("failed to convert xliff-file", "import:xliff").AddError(err)
+		}
+		if localeLike == "" {
+			localeLike = x.TargetLanguage
+		}
+		if localeLike == "" {
+			return nil, NewError("The locale must be set, either in the url or as the target-language of the file", requestContext.CodeErrInputValidation)
+		}
+		input = tree
+		singleLocaleFormat = true
+		source = types.CreatorSourceTranslationAgency
+	case "android":
+		f, err := importexport.ParseAndroid(body)
+		if err != nil {
+			return nil, NewError("failed to parse android-file", "import:android").AddError(err)
+		}
+		tree, m, w, err := f.ToI18Next()
+		warnings = append(warnings, w...)
+		if err != nil {
+			return nil, NewError("failed to convert android-file", "import:android").AddError(err)
+		}
+		meta = m
+		input = tree
+		singleLocaleFormat = true
+	case "strings":
+		f, err := importexport.ParseIOSStrings(body)
+		if err != nil {
+			return nil, NewError("failed to parse strings-file", "import:strings").AddError(err)
+		}
+		tree, m, w, err := f.ToI18Next()
+		warnings = append(warnings, w...)
+		if err != nil {
+			return nil, NewError("failed to convert strings-file", "import:strings").AddError(err)
+		}
+		meta = m
+		input = tree
+		singleLocaleFormat = true
+	case "stringsdict":
+		f, err := importexport.ParseIOSStringsDict(body)
+		if err != nil {
+			return nil, NewError("failed to parse stringsdict-file", "import:stringsdict").AddError(err)
+		}
+		tree, w, err := f.ToI18Next()
+		warnings = append(warnings, w...)
+		if err != nil {
+			return nil, NewError("failed to convert stringsdict-file", "import:stringsdict").AddError(err)
+		}
+		input = tree
+		singleLocaleFormat = true
+	default:
+		err := requestContext.UnmarshalRequestBytes(r, body, &input)
+		if err != nil {
+			return nil, NewError("failed to unmarshal body", "unmarshal").AddError(err)
+		}
+	}
+	if singleLocaleFormat && localeLike == "" {
+		return nil, NewError(fmt.Sprintf("The locale must be set in the url for the %s-format", kind), requestContext.CodeErrInputValidation)
+	}
+	switch kind {
 	case "describe":
 		return ImportDescriptionsIntoProject(l, db, createdBy, project, dry, input)
-	case "i18n", "auto":
+	case "i18n", "auto", "po", "xliff", "android", "strings", "stringsdict":
 		break
 	default:
-		return nil, NewError("Invalid value for kind, allowed values: i18n, auto", requestContext.CodeErrInputValidation)
+		return nil, NewError("Invalid value for kind, allowed values: i18n, po, xliff, android, strings, stringsdict, auto", requestContext.CodeErrInputValidation)
 	}
 	locales, err := db.GetLocales()
 	if err != nil {
@@ -243,13 +330,15 @@ func ImportIntoProject(
 	base.ID = project.ID
 	base.CreatedBy = createdBy
 	base.OrganizationID = project.OrganizationID
-	imp, warnings, err := importexport.ImportI18NTranslation(localesSorted, &locale, base, types.CreatorSourceImport, input)
+	imp, w, err := importexport.ImportI18NTranslation(localesSorted, &locale, base, source, input)
+	warnings = append(warnings, w...)
 	if err != nil {
 		return nil, NewError("failed to import", requestContext.CodeErrImport).AddError(err)
 	}
 	if imp == nil {
 		return nil, NewError("Import resulted in null", requestContext.CodeErrImport)
 	}
+	imp.AddTranslationMeta(meta)
 	matchedLocales := make([]string, len(localeMatches))
 	for i, m := range localeMatches {
 		matchedLocales[i] = m.KeyType.FromLocale(m.Locale)
@@ -283,7 +372,13 @@ func ImportIntoProject(
 		Updates:   make(map[string]DiffChangeWithOffset),
 		Creations: make(map[string]DiffChangeWithOffset),
 	}
-	changelog, err := DiffOfObjects(existingI18n, input)
+	diffInput := input
+	if singleLocaleFormat {
+		// These files are for a single locale. By using the locale as the root-key,
+		// the input can be compared to the existing export.
+		diffInput = map[string]interface{}{localeKey.FromLocale(locale): input}
+	}
+	changelog, err := DiffOfObjects(existingI18n, diffInput)
 	if err != nil {
 		l.Warn().Err(err).Msg("Failed to create diff during import")
 		if options.ErrOnNoDiff {
@@ -365,6 +460,17 @@ func ImportIntoProject(
 		}
 	}
 
+	// Imports are reviewed like any other change, unless the importer is a reviewer
+	importedState := func(localeID string) types.TranslationValueState {
+		if options.Session == nil {
+			return types.StateNeedsReview
+		}
+		if Authorize(db, *options.Session, types.ActionReviewTranslationValues, types.AccessScope{ProjectID: project.ID, LocaleID: localeID}) != nil {
+			return types.StateNeedsReview
+		}
+		return types.StateApproved
+	}
+
 	updates := Updates{
 		map[string]types.TranslationValue{},
 		map[string]types.TranslationValue{},
@@ -412,6 +518,14 @@ func ImportIntoProject(
 				t.CategoryID = exCat.ID
 				if tExists {
 					exT = &ex
+					if payload, ok := translationMetaChanges(ex.Translation, t.Translation); ok {
+						payload.UpdatedBy = createdBy
+						if !dry {
+							if _, err := db.UpdateTranslation(payload.ID, payload); err != nil {
+								return nil, NewError(err.Error(), requestContext.CodeErrTranslation, payload)
+							}
+						}
+					}
 				} else {
 					if !dry {
 						created, err := db.CreateTranslation(t.Translation)
@@ -446,8 +560,27 @@ func ImportIntoProject(
 				tv.TranslationID = exT.ID
 				exTv, existsTV := exT.Values[tv.LocaleID]
 				if existsTV {
-					if exTv.Value != tv.Value {
+					contextChanged := false
+					for ck, cv := range tv.Context {
+						if exTv.Context[ck] != cv {
+							contextChanged = true
+						}
+					}
+					if exTv.Value != tv.Value || contextChanged {
 						exTv.Value = tv.Value
+						exTv.Source = tv.Source
+						exTv.State = importedState(tv.LocaleID)
+						exTv.UpdatedBy = createdBy
+						if contextChanged {
+							ctx := map[string]string{}
+							for ck, cv := range exTv.Context {
+								ctx[ck] = cv
+							}
+							for ck, cv := range tv.Context {
+								ctx[ck] = cv
+							}
+							exTv.Context = ctx
+						}
 						if !dry {
 							updated, err := db.UpdateTranslationValue(exTv)
 							if err != nil {
@@ -459,6 +592,7 @@ func ImportIntoProject(
 						}
 					}
 				} else {
+					tv.State = importedState(tv.LocaleID)
 					if !dry {
 						created, err := db.CreateTranslationValue(tv)
 						if err != nil {
@@ -487,3 +621,23 @@ func ImportIntoProject(
 	return &out, nil
 
 }
+
+// Returns the existing translation with the description and references from the import applied, if any of these changed.
+func translationMetaChanges(existing, imported types.Translation) (types.Translation, bool) {
+	changed := false
+	if imported.Description != "" && imported.Description != existing.Description {
+		existing.Description = imported.Description
+		changed = true
+	}
+	var refs []string
+	for _, ref := range imported.References {
+		if !utils.Contains(existing.References, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) > 0 {
+		existing.References = append(append([]string{}, existing.References...), refs...)
+		changed = true
+	}
+	return existing, changed
+}
@@ -113,3 +113,303 @@ nb:
 		})
 	}
 }
+
+func TestImportHandlerGettext(t *testing.T) {
+	internal.NewMockTimeNow()
+	l := logger.GetLoggerWithLevel("test", "fatal")
+	bb := bboltStorage.NewMockDB(t)
+	err := bb.StandardSeed()
+	testza.AssertNoError(t, err)
+	base := types.Project{}
+	base.CreatedBy = "jim"
+	base.OrganizationID = "org-123"
+	base.ID = "proj-123"
+	base.ShortName = "proj"
+	base.Title = "proj"
+	project, err := bb.CreateProject(base)
+	testza.AssertNoError(t, err)
+
+	po := `
+msgid ""
+msgstr ""
+"Language: en-GB\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+# Shown on the checkout-button
+#: src/Checkout.tsx:12
+msgid "General.Forms.GoToCheckout"
+msgstr "Go to checkout"
+
+msgctxt "formal"
+msgid "General.Forms.GoToCheckout"
+msgstr "Please proceed to checkout"
+
+msgid "Items"
+msgid_plural "Items_other"
+msgstr[0] "{{count}} item"
+msgstr[1] "{{count}} items"
+`
+	r, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(po))
+	r.Header.Set("Content-Type", importexport.ContentTypeGettextPO)
+
+	// A dry-run should not create anything, but show the diff
+	dryRun, Err := ImportIntoProject(l, bb, "po", base.CreatedBy, project, "", []byte(po), r)
+	testza.AssertNil(t, Err)
+	testza.AssertNotNil(t, dryRun)
+	testza.AssertLen(t, dryRun.Diff.Creations, 1)
+	translations, err := bb.GetTranslations()
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, translations, 0)
+
+	impo, Err := ImportIntoProject(l, bb, "po", base.CreatedBy, project, "", []byte(po), r, ImportIntoProjectOptions{NoDryRun: true})
+	testza.AssertNil(t, Err)
+	testza.AssertNotNil(t, impo)
+
+	// The project needs to be refreshed, since its categories have changed
+	p, err := bb.GetProject(project.ID)
+	testza.AssertNoError(t, err)
+	ep, err := p.Extend(bb)
+	testza.AssertNoError(t, err)
+	export, err := importexport.ExportI18N(ep, importexport.ExportI18NOptions{})
+	testza.AssertNoError(t, err)
+	want := map[string]interface{}{
+		"en": map[string]interface{}{
+			"General": map[string]interface{}{
+				"Forms": map[string]interface{}{
+					"GoToCheckout":        "Go to checkout",
+					"GoToCheckout_formal": "Please proceed to checkout",
+				},
+			},
+			"Items_one":   "{{count}} item",
+			"Items_other": "{{count}} items",
+		},
+	}
+	if err := internal.Compare("Export of result of import should match import-input", export.ToMap(), want); err != nil {
+		t.Error(err)
+	}
+	found := false
+	translations, err = bb.GetTranslations()
+	testza.AssertNoError(t, err)
+	for _, tr := range translations {
+		if tr.Key != "GoToCheckout" {
+			continue
+		}
+		found = true
+		testza.AssertEqual(t, "Shown on the checkout-button", tr.Description)
+		testza.AssertEqual(t, []string{"src/Checkout.tsx:12"}, tr.References)
+	}
+	testza.AssertTrue(t, found, "Expected the translation to be created")
+
+	// A dry-run with a changed value should show the update
+	changed := strings.Replace(po, `msgstr "Go to checkout"`, `msgstr "Checkout"`, 1)
+	r, _ = http.NewRequest(http.MethodPost, "", strings.NewReader(changed))
+	dryRun, Err = ImportIntoProject(l, bb, "po", base.CreatedBy, *p, "en-GB", []byte(changed), r)
+	testza.AssertNil(t, Err)
+	testza.AssertLen(t, dryRun.Diff.Creations, 0)
+	testza.AssertLen(t, dryRun.Diff.Updates, 1)
+	testza.AssertEqual(t, "Checkout", dryRun.Diff.Updates["en-GB.General.Forms.GoToCheckout"].To)
+}
+
+func TestImportHandlerXliff(t *testing.T) {
+	internal.NewMockTimeNow()
+	l := logger.GetLoggerWithLevel("test", "fatal")
+	bb := bboltStorage.NewMockDB(t)
+	err := bb.StandardSeed()
+	testza.AssertNoError(t, err)
+	base := types.Project{}
+	base.CreatedBy = "jim"
+	base.OrganizationID = "org-123"
+	base.ID = "proj-123"
+	base.ShortName = "proj"
+	base.Title = "proj"
+	project, err := bb.CreateProject(base)
+	testza.AssertNoError(t, err)
+
+	xlf := `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:1.2" version="1.2">
+  <file original="proj" source-language="en-GB" target-language="nb-NO" datatype="plaintext">
+    <body>
+      <trans-unit id="General.Forms.GoToCheckout">
+        <source>Go to checkout</source>
+        <target state="translated">Gå til kassen</target>
+      </trans-unit>
+      <trans-unit id="General.Forms.Untranslated">
+        <source>Not yet translated</source>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>`
+	r, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(xlf))
+	r.Header.Set("Content-Type", importexport.ContentTypeXliff)
+
+	impo, Err := ImportIntoProject(l, bb, "xliff", base.CreatedBy, project, "", []byte(xlf), r, ImportIntoProjectOptions{NoDryRun: true})
+	testza.AssertNil(t, Err)
+	testza.AssertNotNil(t, impo)
+
+	p, err := bb.GetProject(project.ID)
+	testza.AssertNoError(t, err)
+	ep, err := p.Extend(bb)
+	testza.AssertNoError(t, err)
+	export, err := importexport.ExportI18N(ep, importexport.ExportI18NOptions{})
+	testza.AssertNoError(t, err)
+	want := map[string]interface{}{
+		"nb": map[string]interface{}{
+			"General": map[string]interface{}{
+				"Forms": map[string]interface{}{
+					"GoToCheckout": "Gå til kassen",
+				},
+			},
+		},
+	}
+	if err := internal.Compare("Export of result of import should match the targets", export.ToMap(), want); err != nil {
+		t.Error(err)
+	}
+	tvs, err := bb.GetTranslationValues()
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, tvs, 1)
+	for _, tv := range tvs {
+		testza.AssertEqual(t, types.CreatorSourceTranslationAgency, tv.Source)
+	}
+}
+
+func TestImportHandlerMobile(t *testing.T) {
+	internal.NewMockTimeNow()
+	l := logger.GetLoggerWithLevel("test", "fatal")
+	bb := bboltStorage.NewMockDB(t)
+	err := bb.StandardSeed()
+	testza.AssertNoError(t, err)
+	base := types.Project{}
+	base.CreatedBy = "jim"
+	base.OrganizationID = "org-123"
+	base.ID = "proj-123"
+	base.ShortName = "proj"
+	base.Title = "proj"
+	project, err := bb.CreateProject(base)
+	testza.AssertNoError(t, err)
+
+	android := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <!-- Shown on the checkout-button -->
+    <string name="General.Forms.GoToCheckout">Gå til kassen</string>
+    <plurals name="Items">
+        <item quantity="one">{{count}} ting</item>
+        <item quantity="other">{{count}} ting</item>
+    </plurals>
+</resources>`
+	r, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(android))
+
+	// These formats does not include the locale
+	_, Err := ImportIntoProject(l, bb, "android", base.CreatedBy, project, "", []byte(android), r)
+	testza.AssertNotNil(t, Err)
+
+	impo, Err := ImportIntoProject(l, bb, "android", base.CreatedBy, project, "nb-NO", []byte(android), r, ImportIntoProjectOptions{NoDryRun: true})
+	testza.AssertNil(t, Err)
+	testza.AssertNotNil(t, impo)
+
+	p, err := bb.GetProject(project.ID)
+	testza.AssertNoError(t, err)
+	iosStrings := `"General.Forms.GoToCheckout" = "Go to checkout";`
+	r, _ = http.NewRequest(http.MethodPost, "", strings.NewReader(iosStrings))
+	impo, Err = ImportIntoProject(l, bb, "strings", base.CreatedBy, *p, "en-GB", []byte(iosStrings), r, ImportIntoProjectOptions{NoDryRun: true})
+	testza.AssertNil(t, Err)
+	testza.AssertNotNil(t, impo)
+
+	p, err = bb.GetProject(project.ID)
+	testza.AssertNoError(t, err)
+	ep, err := p.Extend(bb)
+	testza.AssertNoError(t, err)
+	export, err := importexport.ExportI18N(ep, importexport.ExportI18NOptions{LocaleKey: importexport.LocaleKeyIETF})
+	testza.AssertNoError(t, err)
+	want := map[string]interface{}{
+		"en-GB": map[string]interface{}{
+			"General": map[string]interface{}{
+				"Forms": map[string]interface{}{
+					"GoToCheckout": "Go to checkout",
+				},
+			},
+		},
+		"nb-NO": map[string]interface{}{
+			"General": map[string]interface{}{
+				"Forms": map[string]interface{}{
+					"GoToCheckout": "Gå til kassen",
+				},
+			},
+			"Items_one":   "{{count}} ting",
+			"Items_other": "{{count}} ting",
+		},
+	}
+	if err := internal.Compare("Export of result of import should match import-input", export.ToMap(), want); err != nil {
+		t.Error(err)
+	}
+	translations, err := bb.GetTranslations()
+	testza.AssertNoError(t, err)
+	for _, tr := range translations {
+		if tr.Key == "GoToCheckout" {
+			testza.AssertEqual(t, "Shown on the checkout-button", tr.Description)
+		}
+	}
+}
+
+func TestImportHandlerReviewState(t *testing.T) {
+	internal.NewMockTimeNow()
+	l := logger.GetLoggerWithLevel("test", "fatal")
+	bb := bboltStorage.NewMockDB(t)
+	err := bb.StandardSeed()
+	testza.AssertNoError(t, err)
+	org, err := bb.CreateOrganization(types.Organization{Title: "acme", CreatedBy: "test"})
+	testza.AssertNoError(t, err)
+	u := types.User{UserName: "admin", Active: true, Store: types.UserStoreLocal, PW: []byte("pw"), CanCreateTranslations: true, Roles: []types.RoleAssignment{{Role: types.RoleAdmin}}}
+	u.CreatedBy = "test"
+	u.OrganizationID = org.ID
+	user, err := bb.CreateUser(u)
+	testza.AssertNoError(t, err)
+	base := types.Project{Title: "proj", ShortName: "proj"}
+	base.CreatedBy = user.ID
+	base.OrganizationID = org.ID
+	project, err := bb.CreateProject(base)
+	testza.AssertNoError(t, err)
+
+	// Even though the user may review, the api-key may only import
+	k := types.APIKey{Name: "ci", Scopes: []types.APIKeyScope{types.APIKeyScopeImport}, Hash: hashAPIKeySecret("s3cret")}
+	k.CreatedBy = user.ID
+	k.OrganizationID = org.ID
+	key, err := bb.CreateAPIKey(k)
+	testza.AssertNoError(t, err)
+	keySession, err := NewAPIKeySessions(mockSessionManager{}, bb).GetSession(apiKeyPrefix + key.ID + ".s3cret")
+	testza.AssertNoError(t, err)
+	reviewerSession := types.Session{User: user, Organization: org}
+
+	importAs := func(session types.Session, input string) {
+		r, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(input))
+		r.Header.Set("Content-Type", "text/vnd.yaml")
+		p, err := bb.GetProject(project.ID)
+		testza.AssertNoError(t, err)
+		_, Err := ImportIntoProject(l, bb, "i18n", session.User.ID, *p, "", []byte(input), r, ImportIntoProjectOptions{NoDryRun: true, Session: &session})
+		testza.AssertNil(t, Err)
+	}
+	importAs(keySession, "en:\n  Welcome: Welcome\n")
+	importAs(reviewerSession, "nb:\n  Welcome: Velkommen\n")
+
+	p, err := bb.GetProject(project.ID)
+	testza.AssertNoError(t, err)
+	ep, err := p.Extend(bb)
+	testza.AssertNoError(t, err)
+	export, err := importexport.ExportI18N(importexport.OnlyApproved(ep), importexport.ExportI18NOptions{})
+	testza.AssertNoError(t, err)
+	want := map[string]interface{}{
+		"nb": map[string]interface{}{"Welcome": "Velkommen"},
+	}
+	if err := internal.Compare("Values imported with the api-key need review, and are not exported as approved", export.ToMap(), want); err != nil {
+		t.Error(err)
+	}
+
+	// Changes imported with the api-key need review too, and the previously approved value is exported instead
+	importAs(keySession, "nb:\n  Welcome: Velkommen tilbake\n")
+	ep, err = p.Extend(bb)
+	testza.AssertNoError(t, err)
+	export, err = importexport.ExportI18N(importexport.OnlyApproved(ep), importexport.ExportI18NOptions{})
+	testza.AssertNoError(t, err)
+	if err := internal.Compare("The last approved value is exported", export.ToMap(), want); err != nil {
+		t.Error(err)
+	}
+}
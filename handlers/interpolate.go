@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/runar-rkmedia/skiver/importexport"
+	"github.com/runar-rkmedia/skiver/interpolator"
+	"github.com/runar-rkmedia/skiver/models"
+	"github.com/runar-rkmedia/skiver/plurals"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+// PostInterpolate renders a translation server-side, like i18next's `t(key, variables)` would in the client.
+// Like the export, only published locales are available.
+func PostInterpolate() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		params := GetParams(r)
+		orgKey := params.ByName("org")
+		projectKey := params.ByName("project")
+		localeKey := params.ByName("locale")
+		key := params.ByName("key")
+		if projectKey == "" {
+			return nil, ErrApiMissingArgument("project")
+		}
+		if localeKey == "" {
+			return nil, ErrApiMissingArgument("locale")
+		}
+		if key == "" {
+			return nil, ErrApiMissingArgument("key")
+		}
+		if orgKey == "me" {
+			session, err := GetRequestSession(r)
+			if err != nil {
+				return nil, err
+			}
+			orgKey = session.Organization.ID
+		}
+		var input models.InterpolateInput
+		if r.ContentLength != 0 {
+			if err := rc.ValidateBody(&input, false); err != nil {
+				return nil, err
+			}
+		}
+
+		db := rc.Context.DB
+		ps, err := db.GetProjectByIDOrShortName(projectKey)
+		if err != nil {
+			return nil, ErrApiDatabase("Project", err)
+		}
+		if ps == nil {
+			return nil, ErrApiNotFound("Project", projectKey)
+		}
+		if ps.OrganizationID != orgKey {
+			org, err := db.FindOrganizationByIdOrTitle(orgKey)
+			if err != nil {
+				return nil, ErrApiDatabase("Organization", err)
+			}
+			if org == nil || org.ID != ps.OrganizationID {
+				return nil, ErrApiNotFound("Project", projectKey)
+			}
+		}
+		ep, err := ps.Extend(db, types.ExtendOptions{
+			ByID: true,
+			LocaleFilterFunc: func(locale types.Locale) bool {
+				return ps.LocaleIDs[locale.ID].Publish
+			},
+		})
+		if err != nil {
+			return nil, NewApiErr(err, http.StatusInternalServerError, string(requestContext.CodeErrProject))
+		}
+		return InterpolateInProject(ep, localeKey, key, input.Variables)
+	}
+}
+
+// InterpolateInProject renders the translation for the key within the project.
+// The value is looked up in the locale, followed by its fallback-locales.
+func InterpolateInProject(ep types.ExtendedProject, localeKey, key string, variables map[string]interface{}) (*models.InterpolateResponse, error) {
+	locales := interpolationLocales(ep.Locales, localeKey)
+	if len(locales) == 0 {
+		return nil, ErrApiNotFound("Locale", localeKey)
+	}
+	values := map[string]map[string]string{}
+	for _, ft := range importexport.FlattenExtendedProject(ep) {
+		for localeID, tv := range ft.Values {
+			if values[localeID] == nil {
+				values[localeID] = map[string]string{}
+			}
+			values[localeID][ft.FullKey] = tv.Value
+			for ctx, v := range tv.Context {
+				values[localeID][ft.FullKey+"_"+ctx] = v
+			}
+		}
+	}
+	lookups := make([]interpolator.Lookup, len(locales))
+	for i, locale := range locales {
+		localeValues := values[locale.ID]
+		lookups[i] = interpolator.Lookup{
+			Locale: locale.ID,
+			Value: func(key string) (string, bool) {
+				v, ok := localeValues[key]
+				return v, ok
+			},
+		}
+		if rules, ok := plurals.ForLocale(locale); ok {
+			lookups[i].PluralCategory = rules.Category
+		}
+	}
+	ip := interpolator.NewInterpolator()
+	result, err := ip.Interpolate(key, variables, lookups...)
+	if err != nil {
+		if errors.Is(err, interpolator.ErrKeyNotFound) {
+			return nil, ErrApiNotFound("Translation", key)
+		}
+		return nil, NewApiErr(err, http.StatusUnprocessableEntity, string(requestContext.CodeErrTranslation))
+	}
+	return &models.InterpolateResponse{
+		Value:            &result.Value,
+		Key:              &result.Key,
+		LocaleID:         &result.Locale,
+		MissingVariables: result.MissingVariables,
+		MissingKeys:      result.MissingKeys,
+	}, nil
+}
+
+// Returns the locales matching the key, followed by the fallback-locales of the first match.
+// Since a key like `en` may match multiple locales, the locales are sorted for a stable order.
+func interpolationLocales(locales map[string]types.Locale, localeKey string) []types.Locale {
+	sorted := make([]types.Locale, 0, len(locales))
+	for _, l := range locales {
+		sorted = append(sorted, l)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].IETF < sorted[j].IETF
+	})
+	var chain []types.Locale
+	seen := map[string]bool{}
+	add := func(key string) {
+		for _, l := range sorted {
+			if !seen[l.ID] && l.Matches(key) {
+				seen[l.ID] = true
+				chain = append(chain, l)
+			}
+		}
+	}
+	add(localeKey)
+	if len(chain) == 0 {
+		return nil
+	}
+	for _, fallback := range chain[0].Fallbacks {
+		add(fallback)
+	}
+	return chain
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/go-common/logger"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/internal"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestInterpolateInProject(t *testing.T) {
+	internal.NewMockTimeNow()
+	l := logger.GetLoggerWithLevel("test", "fatal")
+	bb := bboltStorage.NewMockDB(t)
+	err := bb.StandardSeed()
+	testza.AssertNoError(t, err)
+	base := types.Project{}
+	base.CreatedBy = "jim"
+	base.OrganizationID = "org-123"
+	base.ID = "proj-123"
+	base.ShortName = "proj"
+	base.Title = "proj"
+	project, err := bb.CreateProject(base)
+	testza.AssertNoError(t, err)
+
+	input := `
+en-GB:
+  General:
+    Welcome: 'Welcome, {{name}}. $t(General.Items, {"count": {{count}} })'
+    Items_one: You have {{count}} item
+    Items_other: You have {{count}} items
+    Items_male_one: He has {{count}} item
+    Items_male_other: He has {{count}} items
+    OnlyInEnglish: English only
+nb-NO:
+  General:
+    Welcome: 'Velkommen, {{name}}. $t(General.Items, {"count": {{count}} })'
+    Items_one: Du har {{count}} ting
+    Items_other: Du har {{count}} ting
+`
+	r, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(input))
+	r.Header.Set("Content-Type", "text/vnd.yaml")
+	impo, Err := ImportIntoProject(l, bb, "i18n", base.CreatedBy, project, "", []byte(input), r, ImportIntoProjectOptions{NoDryRun: true})
+	testza.AssertNil(t, Err)
+	testza.AssertNotNil(t, impo)
+	p, err := bb.GetProject(project.ID)
+	testza.AssertNoError(t, err)
+	ep, err := p.Extend(bb)
+	testza.AssertNoError(t, err)
+
+	localeID := func(ietf string) string {
+		for _, l := range ep.Locales {
+			if l.IETF == ietf {
+				return l.ID
+			}
+		}
+		t.Fatalf("locale %s not found", ietf)
+		return ""
+	}
+
+	tests := []struct {
+		name      string
+		locale    string
+		key       string
+		variables map[string]interface{}
+		want      string
+		wantKey   string
+		wantIETF  string
+		wantCode  int
+	}{
+		{"Nesting with plural", "nb-NO", "General.Welcome", map[string]interface{}{"name": "Roll", "count": 2}, "Velkommen, Roll. Du har 2 ting", "General.Welcome", "nb-NO", 0},
+		{"Context with plural", "en-GB", "General.Items", map[string]interface{}{"context": "male", "count": 1}, "He has 1 item", "General.Items_male_one", "en-GB", 0},
+		{"Fallback-locale", "nb-NO", "General.OnlyInEnglish", nil, "English only", "General.OnlyInEnglish", "en-GB", 0},
+		{"Missing translation", "nb-NO", "General.Nope", nil, "", "", "", http.StatusNotFound},
+		{"Missing locale", "xx-XX", "General.Welcome", nil, "", "", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InterpolateInProject(ep, tt.locale, tt.key, tt.variables)
+			if tt.wantCode != 0 {
+				testza.AssertNotNil(t, err)
+				if apiErr, ok := err.(requestContext.APIError); ok {
+					testza.AssertEqual(t, tt.wantCode, apiErr.ErrHttpStatus())
+				} else {
+					t.Errorf("expected an APIError, got %T", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			testza.AssertEqual(t, tt.want, *got.Value)
+			testza.AssertEqual(t, tt.wantKey, *got.Key)
+			testza.AssertEqual(t, localeID(tt.wantIETF), *got.LocaleID)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/models"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+const defaultInviteDuration = 7 * 24 * time.Hour
+
+// DefaultUserPermissions are the permissions that invited users get unless others are set, which are the same as for users that join through the join-link.
+var DefaultUserPermissions = types.UserPermissions{
+	CanCreateProjects:     true,
+	CanCreateTranslations: true,
+	CanUpdateProjects:     true,
+	CanUpdateTranslations: true,
+	CanManageSnapshots:    true,
+	CanReviewTranslations: true,
+}
+
+// CreatedInvite is the invited user, with the token of the invite-link.
+//
+// swagger:model CreatedInvite
+type CreatedInvite struct {
+	User types.User `json:"user"`
+	// The token of the invite-link, which is only shown once.
+	// It can only be used for this user, and only once.
+	Token   string    `json:"token"`
+	Expires time.Time `json:"expires"`
+}
+
+// InviteInfo is what the invited user sees before accepting the invite
+//
+// swagger:model InviteInfo
+type InviteInfo struct {
+	UserName          string    `json:"username"`
+	OrganizationTitle string    `json:"organization_title"`
+	Expires           time.Time `json:"expires"`
+}
+
+// Creates a new invite for the user. Any previous invite-link for the user stops working.
+func newInvite(d time.Duration) (*types.UserInvite, string, error) {
+	secret, err := utils.GenerateSecret(32)
+	if err != nil {
+		return nil, "", ErrApiInternalError("Failed to create the invite", "Invite", err)
+	}
+	h := sha256.Sum256([]byte(secret))
+	return &types.UserInvite{Expires: time.Now().Add(d), Hash: h[:]}, secret, nil
+}
+
+// Returns the invited user for the token, if the invite is valid
+func userForInvite(db UserStorage, token string) (*types.User, error) {
+	id, secret, ok := strings.Cut(token, ".")
+	notFound := ErrApiNotFound("Invite", "token")
+	if !ok || id == "" || secret == "" {
+		return nil, notFound
+	}
+	u, err := db.GetUser(id)
+	if err != nil {
+		return nil, ErrApiDatabase("User", err)
+	}
+	if u == nil || u.Invite == nil || time.Now().After(u.Invite.Expires) {
+		return nil, notFound
+	}
+	h := sha256.Sum256([]byte(secret))
+	if subtle.ConstantTimeCompare(h[:], u.Invite.Hash) != 1 {
+		return nil, notFound
+	}
+	return u, nil
+}
+
+// InviteUser creates an inactive user within the organization, along with an invite-link that lets only that user choose a password.
+// The link is not sent anywhere, but is shared with the user by the one that invited it.
+func InviteUser(db types.Storage) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		if err := Authorize(rc.Context.DB, session, types.ActionCreateUsers, types.AccessScope{}); err != nil {
+			return nil, err
+		}
+		var j models.InviteUserInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		// Logins find users by their username alone, so it must be unique across organizations
+		existing, err := db.FindUserByUserName("", *j.Username)
+		if err != nil {
+			return nil, ErrApiDatabase("User", err)
+		}
+		if existing != nil {
+			return nil, NewApiError("The username is taken", http.StatusConflict, string(requestContext.CodeErrUser))
+		}
+		permissions := DefaultUserPermissions
+		if err := applyPermissions(db, session, &permissions, j.Permissions); err != nil {
+			return nil, err
+		}
+		d := defaultInviteDuration
+		if j.ExpiresInHours > 0 {
+			d = time.Duration(j.ExpiresInHours) * time.Hour
+		}
+		u := types.User{
+			UserName: *j.Username,
+			Active:   false,
+			Store:    types.UserStoreLocal,
+		}
+		u.SetPermissions(permissions)
+		u.CreatedBy = session.User.ID
+		u.OrganizationID = session.Organization.ID
+		invite, secret, err := newInvite(d)
+		if err != nil {
+			return nil, err
+		}
+		u.Invite = invite
+		user, err := db.CreateUser(u)
+		if err != nil {
+			return nil, ErrApiDatabase("User", err)
+		}
+		return CreatedInvite{user, user.ID + "." + secret, invite.Expires}, nil
+	}
+}
+
+// RenewInvite creates a new invite-link for a user that has not accepted the invite yet,
+// for instance if the previous link expired, or was lost.
+func RenewInvite(db UserStorage) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		u, err := otherUserInOrganization(db, session, GetParams(r).ByName("id"))
+		if err != nil {
+			return nil, err
+		}
+		if u.Invite == nil {
+			return nil, ErrApiInputValidation("The user has already accepted the invite", "Invite")
+		}
+		invite, secret, err := newInvite(defaultInviteDuration)
+		if err != nil {
+			return nil, err
+		}
+		payload := types.UpdateUserPayload{Invite: invite}
+		payload.UpdatedBy = session.User.ID
+		user, err := db.UpdateUser(u.ID, payload)
+		if err != nil {
+			return nil, ErrApiDatabase("User", err)
+		}
+		return CreatedInvite{user, user.ID + "." + secret, invite.Expires}, nil
+	}
+}
+
+// GetInvite lets the invited user see the invite before accepting it.
+func GetInvite(db types.Storage) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		u, err := userForInvite(db, GetParams(r).ByName("token"))
+		if err != nil {
+			return nil, err
+		}
+		org, err := db.GetOrganization(u.OrganizationID)
+		if err != nil {
+			return nil, ErrApiDatabase("Organization", err)
+		}
+		if org == nil {
+			return nil, ErrApiNotFound("Organization", u.OrganizationID)
+		}
+		return InviteInfo{u.UserName, org.Title, u.Invite.Expires}, nil
+	}
+}
+
+// AcceptInvite lets the invited user choose a password, which activates the user. The invite-link can then not be used again.
+func AcceptInvite(db types.Storage, pw PasswordKeeper) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		var j models.AcceptInviteInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		u, err := userForInvite(db, GetParams(r).ByName("token"))
+		if err != nil {
+			return nil, err
+		}
+		org, err := db.GetOrganization(u.OrganizationID)
+		if err != nil {
+			return nil, ErrApiDatabase("Organization", err)
+		}
+		if org == nil {
+			return nil, ErrApiNotFound("Organization", u.OrganizationID)
+		}
+		hashed, err := pw.Hash(*j.Password)
+		if err != nil {
+			return nil, ErrApiInternalError("Failure in password-creator", "Password", err)
+		}
+		active := true
+		payload := types.UpdateUserPayload{PW: &hashed, Active: &active, Invite: &types.UserInvite{}}
+		payload.UpdatedBy = u.ID
+		user, err := db.UpdateUser(u.ID, payload)
+		if err != nil {
+			return nil, ErrApiDatabase("User", err)
+		}
+		return types.LoginResponse{
+			User:         user,
+			Organization: *org,
+			Ok:           true,
+		}, nil
+	}
+}
@@ -86,6 +86,7 @@ func JoinOrgFromJoinID(db types.Storage, pw PasswordKeeper) AppHandler {
 			CanUpdateTranslations: true,
 			CanUpdateLocales:      false,
 			CanManageSnapshots:    true,
+			CanReviewTranslations: true,
 		}
 		existingUsers := false
 		{
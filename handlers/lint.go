@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/runar-rkmedia/skiver/glossary"
+	"github.com/runar-rkmedia/skiver/lint"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+// GetLint lints all the translations within the project, with the rule-settings of the project.
+func GetLint() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		projectKey := GetParams(r).ByName("id")
+		if projectKey == "" {
+			return nil, ErrApiMissingArgument("id")
+		}
+		db := rc.Context.DB
+		p, err := db.GetProjectByIDOrShortName(projectKey)
+		if err != nil {
+			return nil, ErrApiDatabase("Project", err)
+		}
+		if p == nil || p.OrganizationID != session.Organization.ID {
+			return nil, ErrApiNotFound("Project", projectKey)
+		}
+		ep, err := p.Extend(db)
+		if err != nil {
+			return nil, NewApiErr(err, http.StatusInternalServerError, string(requestContext.CodeErrProject))
+		}
+		return lint.LintProject(ep), nil
+	}
+}
+
+// Returns an error if any of the rule-settings are for an unknown rule, or have an invalid severity.
+func validateLintRules(settings map[string]types.LintRuleSetting) error {
+	for id, s := range settings {
+		if !lint.IsRule(id) {
+			return ErrApiInputValidation(fmt.Sprintf("Unknown lint-rule '%s'. Valid rules are: %v", id, lint.RuleIDs()), "Project")
+		}
+		if s.Severity != "" && !lint.IsSeverity(s.Severity) {
+			return ErrApiInputValidation(fmt.Sprintf("Invalid severity '%s' for the lint-rule '%s'", s.Severity, id), "Project")
+		}
+	}
+	return nil
+}
+
+// A translation, with any issues found when linting it after a change
+type lintedTranslation struct {
+	types.Translation
+	LintIssues []lint.Issue `json:"lint_issues,omitempty"`
+}
+
+// A translation-value, with any issues found when linting its translation after a change.
+// Only the issues for the value itself are included.
+type lintedTranslationValue struct {
+	types.TranslationValue
+	LintIssues []lint.Issue `json:"lint_issues,omitempty"`
+	// Glossary-terms used in the other values of the translation, that are not translated as approved
+	GlossaryViolations []glossary.Violation `json:"glossary_violations,omitempty"`
+}
+
+// Lints the translation, with all its values, within its project.
+func lintTranslation(db types.Storage, project types.Project, t types.Translation) ([]lint.Issue, error) {
+	fullKey := t.Key
+	if t.CategoryID != "" {
+		c, err := db.GetCategory(t.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil && !c.IsRoot() {
+			fullKey = c.Key + "." + t.Key
+		}
+	}
+	locales, err := db.GetLocales()
+	if err != nil {
+		return nil, err
+	}
+	et, err := t.Extend(db)
+	if err != nil {
+		return nil, err
+	}
+	return lint.New(project, locales).LintTranslation(fullKey, et), nil
+}
+
+// Lints the translation of the value, and returns the issues for the value.
+func lintTranslationValue(db types.Storage, project types.Project, tv types.TranslationValue) ([]lint.Issue, error) {
+	// The translation is retrieved again, since it should now include the value
+	t, err := db.GetTranslation(tv.TranslationID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("translation %s not found", tv.TranslationID)
+	}
+	issues, err := lintTranslation(db, project, *t)
+	if err != nil {
+		return nil, err
+	}
+	var filtered []lint.Issue
+	for _, issue := range issues {
+		if issue.TranslationValueID == tv.ID {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/go-common/logger"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/internal"
+	"github.com/runar-rkmedia/skiver/lint"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestLintTranslation(t *testing.T) {
+	internal.NewMockTimeNow()
+	l := logger.GetLoggerWithLevel("test", "fatal")
+	bb := bboltStorage.NewMockDB(t)
+	err := bb.StandardSeed()
+	testza.AssertNoError(t, err)
+	base := types.Project{}
+	base.CreatedBy = "jim"
+	base.OrganizationID = "org-123"
+	base.ShortName = "proj"
+	base.Title = "proj"
+	project, err := bb.CreateProject(base)
+	testza.AssertNoError(t, err)
+
+	input := `
+en-GB:
+  General:
+    Welcome: 'Welcome, {{name}} '
+nb-NO:
+  General:
+    Welcome: Velkommen
+`
+	r, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(input))
+	r.Header.Set("Content-Type", "text/vnd.yaml")
+	_, Err := ImportIntoProject(l, bb, "i18n", base.CreatedBy, project, "", []byte(input), r, ImportIntoProjectOptions{NoDryRun: true})
+	testza.AssertNil(t, Err)
+	p, err := bb.GetProject(project.ID)
+	testza.AssertNoError(t, err)
+
+	translations, err := bb.GetTranslations()
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, translations, 1)
+	for _, tr := range translations {
+		issues, err := lintTranslation(bb, *p, tr)
+		testza.AssertNoError(t, err)
+		rules := map[lint.RuleID]string{}
+		for _, issue := range issues {
+			testza.AssertEqual(t, "General.Welcome", issue.Key)
+			rules[issue.Rule] = issue.Message
+		}
+		testza.AssertEqual(t, map[lint.RuleID]string{
+			lint.RuleMissingVariables: "The variable 'name' is missing, but is used in en-GB",
+			lint.RuleWhitespace:       "The value has leading or trailing whitespace",
+		}, rules)
+	}
+}
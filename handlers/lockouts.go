@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/localuser"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+// Returns an error with the status 429 if logins are throttled, and sets the Retry-After-header
+func loginThrottledError(rw http.ResponseWriter, err error) error {
+	var throttled localuser.ErrLoginThrottled
+	if !errors.As(err, &throttled) {
+		return ErrApiDatabase("LoginAttempts", err)
+	}
+	retryAfter := throttled.RetryAfter(time.Now())
+	rw.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+	return NewApiError(throttled.Error()+". Please try again later", http.StatusTooManyRequests, string(requestContext.CodeErrLoginThrottled),
+		map[string]interface{}{"retry_after": retryAfter.String(), "locked": throttled.Locked})
+}
+
+// Returns true if the login-attempts are visible to the user of the session.
+// Attempts for ips are not tied to an organization, and are only visible to those that can create organizations.
+func canSeeLoginAttempts(db ProjectGetter, session types.Session, a types.LoginAttempts) bool {
+	if Authorize(db, session, types.ActionCreateOrganization, types.AccessScope{}) == nil {
+		return true
+	}
+	return a.Kind == types.LoginAttemptsUser && a.OrganizationID != "" && a.OrganizationID == session.Organization.ID
+}
+
+// GetLockouts lists the usernames and ips with failed logins, newest first, including those that are locked.
+func GetLockouts(throttle *localuser.LoginThrottle) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		all, err := throttle.Find()
+		if err != nil {
+			return nil, ErrApiDatabase("LoginAttempts", err)
+		}
+		attempts := []types.LoginAttempts{}
+		for _, a := range all {
+			if canSeeLoginAttempts(rc.Context.DB, session, a) {
+				attempts = append(attempts, a)
+			}
+		}
+		sort.Slice(attempts, func(i, j int) bool {
+			return attempts[i].LastFailure.After(attempts[j].LastFailure)
+		})
+		return attempts, nil
+	}
+}
+
+// DeleteLockout unlocks logins for a username or ip, and forgets its failed logins.
+func DeleteLockout(throttle *localuser.LoginThrottle) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		key := GetParams(r).ByName("key")
+		if key == "" {
+			return nil, ErrApiMissingArgument("key")
+		}
+		err = throttle.Unlock(key, func(a types.LoginAttempts) error {
+			if !canSeeLoginAttempts(rc.Context.DB, session, a) {
+				return localuser.ErrNotFound
+			}
+			return nil
+		})
+		if err != nil {
+			if errors.Is(err, localuser.ErrNotFound) {
+				return nil, ErrApiNotFound("LoginAttempts", key)
+			}
+			return nil, ErrApiDatabase("LoginAttempts", err)
+		}
+		rc.L.Info().Str("key", key).Str("userID", session.User.ID).Msg("Login-attempts were unlocked")
+		return Ok, nil
+	}
+}
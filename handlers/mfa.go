@@ -0,0 +1,365 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/runar-rkmedia/skiver/localuser"
+	"github.com/runar-rkmedia/skiver/models"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+type MFASessions interface {
+	SessionCreator
+	SessionReplacer
+}
+
+// MFA handles multi-factor authentication with TOTP for local users:
+// enrollment, recovery-codes and the second step of the login.
+type MFA struct {
+	db       types.Storage
+	sessions MFASessions
+	// Codes are throttled like passwords, so that they cannot be guessed by logging in repeatedly
+	throttle *localuser.LoginThrottle
+	// Logins where the password was correct, and that wait for a code, by their token
+	challenges *cache.Cache
+}
+
+const (
+	// The name that authenticator-apps show for the account
+	mfaIssuer = "Skiver"
+	// The user must enter the code within this time after the password
+	mfaChallengeTimeout = 5 * time.Minute
+	// Challenges are discarded after this many wrong codes, and the login must be restarted
+	mfaChallengeAttempts = 5
+	mfaRecoveryCodeCount = 10
+)
+
+var (
+	ErrApiMFAInvalidCode = NewApiError("The code is invalid", http.StatusUnauthorized, string(requestContext.CodeErrAuthoriziationFailed))
+	errMFAInvalidCode    = errors.New("invalid code")
+)
+
+func NewMFA(db types.Storage, sessions MFASessions, throttle *localuser.LoginThrottle) *MFA {
+	return &MFA{db, sessions, throttle, cache.New(mfaChallengeTimeout, time.Minute)}
+}
+
+type mfaChallenge struct {
+	UserID    string
+	UserName  string
+	UserAgent string
+	// The login where the password was correct. It counts as failed until a code is verified,
+	// and remains so if the challenge expires.
+	login *localuser.PendingLogin
+	// Set if the user enrolls during the login
+	Secret string
+	sync.Mutex
+	attempts int
+}
+
+// Challenge is called once the password of the user has been verified.
+// It returns nil if the user can be logged in directly, and the login should be resolved as successful.
+// Otherwise, the login is resolved once a code is verified.
+func (m *MFA) Challenge(user types.User, userAgent string, login *localuser.PendingLogin) (*types.MFAChallenge, error) {
+	c := mfaChallenge{UserID: user.ID, UserName: user.UserName, UserAgent: userAgent, login: login}
+	challenge := &types.MFAChallenge{Expires: time.Now().Add(mfaChallengeTimeout)}
+	if !user.MFA.Enabled() {
+		org, err := m.db.GetOrganization(user.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		if org == nil || !org.RequireMFA {
+			return nil, nil
+		}
+		enrollment, err := newMFAEnrollment(user)
+		if err != nil {
+			return nil, err
+		}
+		c.Secret = enrollment.Secret
+		challenge.Enroll = &enrollment
+	}
+	token, err := utils.GenerateSecret(32)
+	if err != nil {
+		return nil, err
+	}
+	challenge.Token = token
+	m.challenges.SetDefault(token, &c)
+	return challenge, nil
+}
+
+func newMFAEnrollment(user types.User) (types.MFAEnrollment, error) {
+	secret, err := localuser.GenerateTOTPSecret()
+	if err != nil {
+		return types.MFAEnrollment{}, err
+	}
+	return types.MFAEnrollment{Secret: secret, URI: localuser.TOTPURI(mfaIssuer, user.UserName, secret)}, nil
+}
+
+// Enables MFA for the user with the secret, if the code is valid, and returns new recovery-codes.
+func (m *MFA) enable(user types.User, secret, code string) ([]string, types.User, error) {
+	step, ok := localuser.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return nil, user, ErrApiMFAInvalidCode
+	}
+	codes, hashes, err := localuser.GenerateRecoveryCodes(mfaRecoveryCodeCount)
+	if err != nil {
+		return nil, user, ErrApiInternalError("Failed to create recovery-codes", "MFA", err)
+	}
+	now := time.Now()
+	payload := types.UpdateUserPayload{MFA: &types.UserMFA{EnabledAt: &now, Secret: secret, RecoveryCodes: hashes, LastStep: step}}
+	payload.UpdatedBy = user.ID
+	updated, err := m.db.UpdateUser(user.ID, payload)
+	if err != nil {
+		return nil, user, ErrApiDatabase("User", err)
+	}
+	m.sessions.UpdateAllSessionsForUser(updated.ID, updated)
+	return codes, updated, nil
+}
+
+// Verifies the code, or the recovery-code, and marks it as used, so that it cannot be used again.
+func (m *MFA) verify(userID, code, recoveryCode string) error {
+	err := m.db.UpdateUserMFA(userID, func(mfa *types.UserMFA) error {
+		if !mfa.Enabled() {
+			return errMFAInvalidCode
+		}
+		if recoveryCode != "" {
+			h := localuser.HashRecoveryCode(recoveryCode)
+			for i, c := range mfa.RecoveryCodes {
+				if subtle.ConstantTimeCompare(c, h) == 1 {
+					mfa.RecoveryCodes = append(mfa.RecoveryCodes[:i], mfa.RecoveryCodes[i+1:]...)
+					return nil
+				}
+			}
+			return errMFAInvalidCode
+		}
+		step, ok := localuser.ValidateTOTP(mfa.Secret, code, time.Now())
+		if !ok || step <= mfa.LastStep {
+			return errMFAInvalidCode
+		}
+		mfa.LastStep = step
+		return nil
+	})
+	if err != nil {
+		return ErrApiMFAInvalidCode
+	}
+	return nil
+}
+
+// Login completes a login that was challenged for a code.
+func (m *MFA) Login() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		var j models.MfaLoginInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		v, ok := m.challenges.Get(*j.Token)
+		if !ok {
+			return nil, NewApiError("The login has expired. Please log in again", http.StatusUnauthorized, string(requestContext.CodeErrAuthenticationRequired))
+		}
+		c := v.(*mfaChallenge)
+		c.Lock()
+		c.attempts++
+		attempts := c.attempts
+		c.Unlock()
+		if attempts > mfaChallengeAttempts {
+			m.challenges.Delete(*j.Token)
+			return nil, NewApiError("Too many attempts. Please log in again", http.StatusUnauthorized, string(requestContext.CodeErrAuthenticationRequired))
+		}
+		user, err := m.db.GetUser(c.UserID)
+		if err != nil {
+			return nil, ErrApiDatabase("User", err)
+		}
+		if user == nil || !user.Active {
+			m.challenges.Delete(*j.Token)
+			return nil, NewApiError("The user is not active", http.StatusForbidden, string(requestContext.CodeErrAuthoriziation))
+		}
+		// Each code counts as a failed login for the username and ip until it is verified,
+		// so the limits of the challenge cannot be escaped by logging in again.
+		attempt, err := m.throttle.Attempt(c.UserName, m.throttle.ClientIP(r), user)
+		if err != nil {
+			return nil, loginThrottledError(rw, err)
+		}
+		var recoveryCodes []string
+		if c.Secret != "" {
+			recoveryCodes, *user, err = m.enable(*user, c.Secret, j.Code)
+		} else {
+			err = m.verify(user.ID, j.Code, j.RecoveryCode)
+		}
+		if err != nil {
+			attempt.Fail(localuser.LoginFailureIncorrectCode)
+			return nil, err
+		}
+		for _, login := range []*localuser.PendingLogin{attempt, c.login} {
+			if login == nil {
+				continue
+			}
+			if err := login.Succeed(); err != nil {
+				rc.L.Error().Err(err).Msg("Failed to reset the login-attempts")
+			}
+		}
+		m.challenges.Delete(*j.Token)
+		org, err := m.db.GetOrganization(user.OrganizationID)
+		if err != nil {
+			return nil, ErrApiDatabase("Organization", err)
+		}
+		if org == nil || org.ID == "" {
+			return nil, ErrApiNotFound("Organization", user.OrganizationID)
+		}
+		session := m.sessions.NewSession(*user, *org, c.UserAgent)
+		if session.Token == "" {
+			return nil, ErrApiInternalError("Failed to create a session", "Session", nil)
+		}
+		expiresD := setSessionCookie(rw, r, session)
+		return types.LoginResponse{
+			User:          session.User,
+			Organization:  session.Organization,
+			Ok:            true,
+			Expires:       session.Expires,
+			ExpiresIn:     expiresD.String(),
+			RecoveryCodes: recoveryCodes,
+		}, nil
+	}
+}
+
+// Returns the current version of the logged in user, which must be a local user
+func (m *MFA) localUser(r *http.Request) (*types.User, error) {
+	session, err := GetRequestSession(r)
+	if err != nil {
+		return nil, err
+	}
+	user, err := m.db.GetUser(session.User.ID)
+	if err != nil {
+		return nil, ErrApiDatabase("User", err)
+	}
+	if user == nil {
+		return nil, ErrApiNotFound("User", session.User.ID)
+	}
+	if user.Store != types.UserStoreLocal {
+		return nil, ErrApiInputValidation("Multi-factor authentication is handled by the identity-provider of the user", "User")
+	}
+	return user, nil
+}
+
+// Enroll starts the enrollment of the user. The secret is pending until it is verified with a code.
+func (m *MFA) Enroll() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		user, err := m.localUser(r)
+		if err != nil {
+			return nil, err
+		}
+		if user.MFA.Enabled() {
+			return nil, NewApiError("Multi-factor authentication is already enabled", http.StatusConflict, string(requestContext.CodeErrUser))
+		}
+		enrollment, err := newMFAEnrollment(*user)
+		if err != nil {
+			return nil, ErrApiInternalError("Failed to create the secret", "MFA", err)
+		}
+		payload := types.UpdateUserPayload{MFA: &types.UserMFA{Secret: enrollment.Secret}}
+		payload.UpdatedBy = user.ID
+		if _, err := m.db.UpdateUser(user.ID, payload); err != nil {
+			return nil, ErrApiDatabase("User", err)
+		}
+		return enrollment, nil
+	}
+}
+
+// VerifyEnrollment enables MFA for the user, once the user has entered a code for the pending secret.
+func (m *MFA) VerifyEnrollment() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		var j models.MfaCodeInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		user, err := m.localUser(r)
+		if err != nil {
+			return nil, err
+		}
+		if user.MFA == nil || user.MFA.Secret == "" {
+			return nil, NewApiError("There is no pending enrollment", http.StatusBadRequest, string(requestContext.CodeErrUser))
+		}
+		if user.MFA.Enabled() {
+			return nil, NewApiError("Multi-factor authentication is already enabled", http.StatusConflict, string(requestContext.CodeErrUser))
+		}
+		codes, updated, err := m.enable(*user, user.MFA.Secret, j.Code)
+		if err != nil {
+			return nil, err
+		}
+		return types.LoginResponse{User: updated, Ok: true, RecoveryCodes: codes}, nil
+	}
+}
+
+// Disable turns off MFA for the user, unless the organization requires it.
+func (m *MFA) Disable() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		var j models.MfaCodeInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		user, err := m.localUser(r)
+		if err != nil {
+			return nil, err
+		}
+		if user.MFA == nil {
+			return nil, NewApiError("Multi-factor authentication is not enabled", http.StatusBadRequest, string(requestContext.CodeErrUser))
+		}
+		org, err := m.db.GetOrganization(user.OrganizationID)
+		if err != nil {
+			return nil, ErrApiDatabase("Organization", err)
+		}
+		if org != nil && org.RequireMFA && user.MFA.Enabled() {
+			return nil, NewApiError("The organization requires multi-factor authentication", http.StatusForbidden, string(requestContext.CodeErrAuthoriziation))
+		}
+		// A pending enrollment can be cancelled without a code
+		if user.MFA.Enabled() {
+			if err := m.verify(user.ID, j.Code, j.RecoveryCode); err != nil {
+				return nil, err
+			}
+		}
+		payload := types.UpdateUserPayload{MFA: &types.UserMFA{}}
+		payload.UpdatedBy = user.ID
+		updated, err := m.db.UpdateUser(user.ID, payload)
+		if err != nil {
+			return nil, ErrApiDatabase("User", err)
+		}
+		m.sessions.UpdateAllSessionsForUser(updated.ID, updated)
+		return updated, nil
+	}
+}
+
+// RegenerateRecoveryCodes replaces the recovery-codes of the user, for instance if they have been used up.
+func (m *MFA) RegenerateRecoveryCodes() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		var j models.MfaCodeInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		user, err := m.localUser(r)
+		if err != nil {
+			return nil, err
+		}
+		if !user.MFA.Enabled() {
+			return nil, NewApiError("Multi-factor authentication is not enabled", http.StatusBadRequest, string(requestContext.CodeErrUser))
+		}
+		if err := m.verify(user.ID, j.Code, ""); err != nil {
+			return nil, err
+		}
+		codes, hashes, err := localuser.GenerateRecoveryCodes(mfaRecoveryCodeCount)
+		if err != nil {
+			return nil, ErrApiInternalError("Failed to create recovery-codes", "MFA", err)
+		}
+		err = m.db.UpdateUserMFA(user.ID, func(mfa *types.UserMFA) error {
+			mfa.RecoveryCodes = hashes
+			return nil
+		})
+		if err != nil {
+			return nil, ErrApiDatabase("User", err)
+		}
+		return types.LoginResponse{User: *user, Ok: true, RecoveryCodes: codes}, nil
+	}
+}
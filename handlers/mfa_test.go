@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/go-common/logger"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/localuser"
+	"github.com/runar-rkmedia/skiver/models"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func (m *mockSessionCreator) UpdateAllSessionsForUser(userId string, user types.User) error {
+	return nil
+}
+
+func TestMFA(t *testing.T) {
+	ctx := &requestContext.Context{
+		L: logger.GetLoggerWithLevel("test", "fatal"),
+		StructValidater: func(m interface{}) error {
+			return models.Validate(m.(models.Validator))
+		},
+	}
+	bb := bboltStorage.NewMockDB(t)
+	org, err := bb.CreateOrganization(types.Organization{Title: "acme", CreatedBy: "test"})
+	testza.AssertNoError(t, err)
+	u := types.User{UserName: "jane", Active: true, Store: types.UserStoreLocal, PW: []byte("pw")}
+	u.CreatedBy = "test"
+	u.OrganizationID = org.ID
+	user, err := bb.CreateUser(u)
+	testza.AssertNoError(t, err)
+	sessions := &mockSessionCreator{}
+	// The throttling of codes is tested separately
+	throttle := localuser.NewLoginThrottle(localuser.LoginThrottleOptions{Disabled: true}, bb.Storage.(localuser.LoginThrottleStore))
+	mfa := NewMFA(bb, sessions, throttle)
+
+	serve := func(handler AppHandler, body interface{}, session *types.Session) (*httptest.ResponseRecorder, interface{}) {
+		b, _ := json.Marshal(body)
+		rw := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(b)))
+		r.Header.Set("Content-Type", "application/json")
+		if session != nil {
+			r = setValue(r, ContextKeySession, *session)
+		}
+		rc := ctx.NewReqContext(rw, r)
+		out, err := handler(rc, rw, r)
+		if err != nil {
+			rc.WriteErr(err, "")
+		}
+		return rw, out
+	}
+	codeAt := func(secret string, offset int64) string {
+		code, err := localuser.TOTPCode(secret, localuser.TOTPStep(time.Now())+offset)
+		testza.AssertNoError(t, err)
+		return code
+	}
+	getUser := func() types.User {
+		u, err := bb.GetUser(user.ID)
+		testza.AssertNoError(t, err)
+		return *u
+	}
+
+	challenge, err := mfa.Challenge(user, "laptop", nil)
+	testza.AssertNoError(t, err)
+	testza.AssertNil(t, challenge, "Users without MFA log in directly, unless the organization requires it")
+
+	// The organization requires MFA, so the user must enroll during the login
+	requireMFA := true
+	_, err = bb.UpdateOrganization(org.ID, types.UpdateOrganizationPayload{UpdatedBy: "test", RequireMFA: &requireMFA})
+	testza.AssertNoError(t, err)
+	challenge, err = mfa.Challenge(user, "laptop", nil)
+	testza.AssertNoError(t, err)
+	testza.AssertNotNil(t, challenge)
+	testza.AssertNotNil(t, challenge.Enroll)
+	testza.AssertContains(t, challenge.Enroll.URI, "otpauth://totp/Skiver:jane?")
+	secret := challenge.Enroll.Secret
+
+	rw, _ := serve(mfa.Login(), map[string]string{"token": challenge.Token, "code": "000000"}, nil)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code)
+	testza.AssertFalse(t, getUser().MFA.Enabled())
+
+	rw, out := serve(mfa.Login(), map[string]string{"token": challenge.Token, "code": codeAt(secret, 0)}, nil)
+	testza.AssertEqual(t, http.StatusOK, rw.Code)
+	testza.AssertContains(t, rw.Header().Get("Set-Cookie"), "token=")
+	login := out.(types.LoginResponse)
+	testza.AssertTrue(t, login.Ok)
+	testza.AssertLen(t, login.RecoveryCodes, mfaRecoveryCodeCount)
+	testza.AssertTrue(t, getUser().MFA.Enabled())
+	rw, _ = serve(mfa.Login(), map[string]string{"token": challenge.Token, "code": codeAt(secret, 0)}, nil)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code, "Challenges can only be completed once")
+
+	// Later logins require a code
+	user = getUser()
+	challenge, err = mfa.Challenge(user, "laptop", nil)
+	testza.AssertNoError(t, err)
+	testza.AssertNil(t, challenge.Enroll)
+	rw, _ = serve(mfa.Login(), map[string]string{"token": challenge.Token, "code": codeAt(secret, 0)}, nil)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code, "Codes cannot be reused")
+	rw, _ = serve(mfa.Login(), map[string]string{"token": challenge.Token, "code": codeAt(secret, 1)}, nil)
+	testza.AssertEqual(t, http.StatusOK, rw.Code)
+
+	challenge, err = mfa.Challenge(user, "laptop", nil)
+	testza.AssertNoError(t, err)
+	rw, _ = serve(mfa.Login(), map[string]string{"token": challenge.Token, "recovery_code": login.RecoveryCodes[0]}, nil)
+	testza.AssertEqual(t, http.StatusOK, rw.Code, "Recovery-codes can be used instead of codes")
+	challenge, err = mfa.Challenge(user, "laptop", nil)
+	testza.AssertNoError(t, err)
+	rw, _ = serve(mfa.Login(), map[string]string{"token": challenge.Token, "recovery_code": login.RecoveryCodes[0]}, nil)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code, "Recovery-codes can only be used once")
+	testza.AssertLen(t, getUser().MFA.RecoveryCodes, mfaRecoveryCodeCount-1)
+
+	for i := 0; i < mfaChallengeAttempts-1; i++ {
+		serve(mfa.Login(), map[string]string{"token": challenge.Token, "code": "000000"}, nil)
+	}
+	rw, _ = serve(mfa.Login(), map[string]string{"token": challenge.Token, "recovery_code": login.RecoveryCodes[1]}, nil)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code, "The challenge should be discarded after too many attempts")
+	testza.AssertContains(t, rw.Body.String(), "Too many attempts")
+
+	session := &types.Session{User: getUser(), Organization: org}
+	rw, _ = serve(mfa.Disable(), map[string]string{"recovery_code": login.RecoveryCodes[1]}, session)
+	testza.AssertEqual(t, http.StatusForbidden, rw.Code, "MFA cannot be disabled when the organization requires it")
+
+	requireMFA = false
+	_, err = bb.UpdateOrganization(org.ID, types.UpdateOrganizationPayload{UpdatedBy: "test", RequireMFA: &requireMFA})
+	testza.AssertNoError(t, err)
+	rw, _ = serve(mfa.Disable(), map[string]string{"code": "000000"}, session)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code, "A code is required to disable MFA")
+	rw, _ = serve(mfa.Disable(), map[string]string{"recovery_code": login.RecoveryCodes[1]}, session)
+	testza.AssertEqual(t, http.StatusOK, rw.Code)
+	testza.AssertNil(t, getUser().MFA)
+	challenge, err = mfa.Challenge(getUser(), "laptop", nil)
+	testza.AssertNoError(t, err)
+	testza.AssertNil(t, challenge)
+
+	// Enrolling from the settings of the user
+	rw, out = serve(mfa.Enroll(), nil, session)
+	testza.AssertEqual(t, http.StatusOK, rw.Code)
+	enrollment := out.(types.MFAEnrollment)
+	testza.AssertFalse(t, getUser().MFA.Enabled(), "The secret should be pending until it is verified")
+	rw, out = serve(mfa.VerifyEnrollment(), map[string]string{"code": codeAt(enrollment.Secret, 0)}, session)
+	testza.AssertEqual(t, http.StatusOK, rw.Code)
+	testza.AssertLen(t, out.(types.LoginResponse).RecoveryCodes, mfaRecoveryCodeCount)
+	testza.AssertTrue(t, getUser().MFA.Enabled())
+	rw, _ = serve(mfa.Enroll(), nil, session)
+	testza.AssertEqual(t, http.StatusConflict, rw.Code)
+}
+
+func TestMFAIsThrottled(t *testing.T) {
+	ctx := &requestContext.Context{
+		L: logger.GetLoggerWithLevel("test", "fatal"),
+		StructValidater: func(m interface{}) error {
+			return models.Validate(m.(models.Validator))
+		},
+	}
+	bb := bboltStorage.NewMockDB(t)
+	org, err := bb.CreateOrganization(types.Organization{Title: "acme", CreatedBy: "test"})
+	testza.AssertNoError(t, err)
+	secret, err := localuser.GenerateTOTPSecret()
+	testza.AssertNoError(t, err)
+	now := time.Now()
+	u := types.User{UserName: "jane", Active: true, Store: types.UserStoreLocal, PW: []byte("pw"), MFA: &types.UserMFA{EnabledAt: &now, Secret: secret}}
+	u.CreatedBy = "test"
+	u.OrganizationID = org.ID
+	user, err := bb.CreateUser(u)
+	testza.AssertNoError(t, err)
+	throttle := localuser.NewLoginThrottle(localuser.LoginThrottleOptions{
+		User:            localuser.LoginThrottleLimits{FreeAttempts: 3, LockoutThreshold: 6},
+		IP:              localuser.LoginThrottleLimits{FreeAttempts: 100, LockoutThreshold: 200},
+		LockoutDuration: time.Hour,
+	}, bb.Storage.(localuser.LoginThrottleStore))
+	mfa := NewMFA(bb, &mockSessionCreator{}, throttle)
+
+	submit := func(token, code string) int {
+		b, _ := json.Marshal(map[string]string{"token": token, "code": code})
+		rw := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(b)))
+		r.Header.Set("Content-Type", "application/json")
+		rc := ctx.NewReqContext(rw, r)
+		if _, err := mfa.Login()(rc, rw, r); err != nil {
+			rc.WriteErr(err, "")
+		}
+		return rw.Code
+	}
+	// Logs in with the correct password, as the login-handler does
+	login := func() (*types.MFAChallenge, error) {
+		attempt, err := throttle.Attempt(user.UserName, "192.0.2.1", &user)
+		if err != nil {
+			return nil, err
+		}
+		return mfa.Challenge(user, "laptop", attempt)
+	}
+	userAttempts := func() types.LoginAttempts {
+		all, err := throttle.Find()
+		testza.AssertNoError(t, err)
+		for _, a := range all {
+			if a.Kind == types.LoginAttemptsUser {
+				return a
+			}
+		}
+		return types.LoginAttempts{}
+	}
+
+	challenge, err := login()
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, 1, userAttempts().Failures, "The login counts as failed until the code is verified")
+	testza.AssertEqual(t, http.StatusUnauthorized, submit(challenge.Token, "000000"))
+	testza.AssertEqual(t, 2, userAttempts().Failures, "Incorrect codes count as failed logins")
+
+	// Restarting the login for a fresh challenge does not reset the failures
+	challenge, err = login()
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, 3, userAttempts().Failures)
+	testza.AssertEqual(t, http.StatusUnauthorized, submit(challenge.Token, "000000"))
+	testza.AssertEqual(t, http.StatusTooManyRequests, submit(challenge.Token, "000000"), "Codes are throttled like passwords")
+	var throttled localuser.ErrLoginThrottled
+	_, err = login()
+	testza.AssertTrue(t, errors.As(err, &throttled), "The password is throttled by the failed codes")
+
+	testza.AssertNoError(t, throttle.Unlock(localuser.UserAttemptsKey("jane"), nil))
+	challenge, err = login()
+	testza.AssertNoError(t, err)
+	code, err := localuser.TOTPCode(secret, localuser.TOTPStep(time.Now()))
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, http.StatusOK, submit(challenge.Token, code))
+	testza.AssertEqual(t, 0, userAttempts().Failures, "The failures are forgotten once the code is verified")
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/oidc"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+// OIDCOptions maps the claims of the identity-provider to users within skiver
+type OIDCOptions struct {
+	// Defaults to preferred_username, and falls back to email
+	UserNameClaim string
+	// The claim that maps users to organizations, through Organizations
+	OrganizationClaim string
+	// Maps values of the OrganizationClaim to organization-ids
+	Organizations map[string]string
+	// Used for users that are not mapped to an organization
+	DefaultOrganization string
+	// Defaults to groups
+	GroupsClaim string
+	// Maps groups to roles
+	Groups map[string][]OIDCGroupRole
+}
+
+// OIDCGroupRole is a role given to members of a group at the identity-provider
+type OIDCGroupRole struct {
+	Role types.Role
+	// The project, by id or short-name
+	Project string
+	// The locales, by id or short-name
+	Locales []string
+}
+
+// OIDCHandler logs in users through an OpenID Connect-provider, with the authorization-code flow and PKCE.
+// Users are created on their first login, and their roles are updated from their groups on every login.
+type OIDCHandler struct {
+	provider *oidc.Provider
+	sessions SessionCreator
+	// Used to apply changed roles to the other sessions of the user
+	sessionReplace SessionReplacer
+	options        OIDCOptions
+	// Auth-requests that are waiting for the callback, by state
+	pending *cache.Cache
+}
+
+// The user must complete the login at the identity-provider within this time
+const oidcLoginTimeout = 10 * time.Minute
+
+// The login is bound to the browser that started it with this cookie, which holds a hash of the state.
+// Without it, anyone could send a user to the callback with the state of their own login,
+// and have the user logged in as them.
+const oidcStateCookie = "oidc_state"
+
+func oidcStateHash(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
+
+func setOIDCStateCookie(rw http.ResponseWriter, r *http.Request, value string, maxAge int) {
+	http.SetCookie(rw, &http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     "/",
+		Value:    value,
+		MaxAge:   maxAge,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		HttpOnly: true,
+		// The identity-provider redirects back with a top-level navigation, which Lax allows
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func NewOIDCHandler(provider *oidc.Provider, sessions SessionCreator, sessionReplace SessionReplacer, options OIDCOptions) OIDCHandler {
+	if options.UserNameClaim == "" {
+		options.UserNameClaim = "preferred_username"
+	}
+	if options.GroupsClaim == "" {
+		options.GroupsClaim = "groups"
+	}
+	// Group-names are matched case-insensitively, since some configuration-sources lowercase keys
+	groups := map[string][]OIDCGroupRole{}
+	for k, v := range options.Groups {
+		k = strings.ToLower(k)
+		groups[k] = append(groups[k], v...)
+	}
+	options.Groups = groups
+	return OIDCHandler{provider, sessions, sessionReplace, options, cache.New(oidcLoginTimeout, oidcLoginTimeout)}
+}
+
+type pendingOIDCLogin struct {
+	oidc.AuthRequest
+	// Where the user is sent after the login
+	Redirect string
+}
+
+// Login redirects the user to the identity-provider
+func (h OIDCHandler) Login() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		ar, err := h.provider.NewAuthRequest()
+		if err != nil {
+			return nil, ErrApiInternalError("Failed to create the login-request", "OIDC", err)
+		}
+		h.pending.SetDefault(ar.State, pendingOIDCLogin{ar, safeRedirect(r.URL.Query().Get("redirect"))})
+		setOIDCStateCookie(rw, r, oidcStateHash(ar.State), int(oidcLoginTimeout.Seconds()))
+		http.Redirect(rw, r, ar.URL, http.StatusFound)
+		return nil, nil
+	}
+}
+
+// Callback is where the identity-provider sends the user back to after the login.
+func (h OIDCHandler) Callback(db types.Storage) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		q := r.URL.Query()
+		if e := q.Get("error"); e != "" {
+			return nil, NewApiError(fmt.Sprintf("The identity-provider refused the login: %s %s", e, q.Get("error_description")), http.StatusUnauthorized, string(requestContext.CodeErrAuthoriziationFailed))
+		}
+		state := q.Get("state")
+		cookie, err := r.Cookie(oidcStateCookie)
+		if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(oidcStateHash(state))) != 1 {
+			return nil, NewApiError("The login was not started in this browser. Please try again", http.StatusBadRequest, string(requestContext.CodeErrAuthoriziationFailed))
+		}
+		p, ok := h.pending.Get(state)
+		if !ok || state == "" {
+			return nil, NewApiError("The login has expired, or was not started here. Please try again", http.StatusBadRequest, string(requestContext.CodeErrAuthoriziationFailed))
+		}
+		// The state can only be used once
+		h.pending.Delete(state)
+		setOIDCStateCookie(rw, r, "", -1)
+		pending := p.(pendingOIDCLogin)
+		claims, err := h.provider.Exchange(r.Context(), q.Get("code"), pending.AuthRequest)
+		if err != nil {
+			rc.L.Warn().Err(err).Msg("Failed to complete the login through OpenID Connect")
+			return nil, NewApiError("The login could not be verified", http.StatusUnauthorized, string(requestContext.CodeErrAuthoriziationFailed))
+		}
+		user, org, err := h.provision(db, claims)
+		if err != nil {
+			return nil, err
+		}
+		session := h.sessions.NewSession(*user, *org, r.UserAgent()+";"+rc.RemoteIP)
+		setSessionCookie(rw, r, session)
+		http.Redirect(rw, r, pending.Redirect, http.StatusFound)
+		return nil, nil
+	}
+}
+
+// Only paths within skiver are allowed as redirects, so that the login cannot be used to send users elsewhere.
+func safeRedirect(redirect string) string {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.Contains(redirect, `\`) {
+		return "/"
+	}
+	return redirect
+}
+
+// Returns the user for the claims, creating it if needed, with the roles of its groups.
+func (h OIDCHandler) provision(db types.Storage, claims oidc.Claims) (*types.User, *types.Organization, error) {
+	existing, err := db.FindUsers(1, types.User{Store: types.UserStoreOIDC, ExternalID: claims.Subject()})
+	if err != nil {
+		return nil, nil, ErrApiDatabase("User", err)
+	}
+	var user types.User
+	for _, u := range existing {
+		user = u
+	}
+	if user.ID == "" {
+		user, err = h.createUser(db, claims)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		if !user.Active {
+			return nil, nil, NewApiError("The user is deactivated", http.StatusForbidden, string(requestContext.CodeErrAuthoriziation))
+		}
+		roles := h.roles(db, claims, user.OrganizationID)
+		payload := types.UpdateUserPayload{Roles: &roles}
+		payload.UpdatedBy = user.ID
+		updated, err := db.UpdateUser(user.ID, payload)
+		switch {
+		case err == nil:
+			user = updated
+			// The roles should apply immediately, and not just for the new session
+			h.sessionReplace.UpdateAllSessionsForUser(user.ID, user)
+		case !errors.Is(err, bboltStorage.ErrNoFieldsChanged):
+			return nil, nil, ErrApiDatabase("User", err)
+		}
+	}
+	org, err := db.GetOrganization(user.OrganizationID)
+	if err != nil {
+		return nil, nil, ErrApiDatabase("Organization", err)
+	}
+	if org == nil || org.ID == "" {
+		return nil, nil, ErrApiNotFound("Organization", user.OrganizationID)
+	}
+	return &user, org, nil
+}
+
+func (h OIDCHandler) createUser(db types.Storage, claims oidc.Claims) (types.User, error) {
+	orgID := h.options.DefaultOrganization
+	if h.options.OrganizationClaim != "" {
+		for _, v := range claims.Strings(h.options.OrganizationClaim) {
+			if id, ok := h.options.Organizations[v]; ok {
+				orgID = id
+				break
+			}
+		}
+	}
+	if orgID == "" {
+		return types.User{}, NewApiError("The user is not mapped to any organization. Please contact your administrator", http.StatusForbidden, string(requestContext.CodeErrOrganization))
+	}
+	if org, err := db.GetOrganization(orgID); err != nil || org == nil || org.ID == "" {
+		return types.User{}, NewApiError("The organization of the user was not found. Please contact your administrator", http.StatusForbidden, string(requestContext.CodeErrOrganization))
+	}
+	userName := claims.String(h.options.UserNameClaim)
+	if userName == "" {
+		userName = claims.String("email")
+	}
+	if userName == "" {
+		return types.User{}, ErrApiInputValidation("The identity-provider did not provide a username", "User")
+	}
+	// Usernames are used to log in, so they must be unique across organizations
+	if taken, err := db.FindUserByUserName("", userName); err != nil {
+		return types.User{}, ErrApiDatabase("User", err)
+	} else if taken != nil {
+		return types.User{}, NewApiError("The username is already taken by another user", http.StatusConflict, string(requestContext.CodeErrAuthoriziationFailed))
+	}
+	u := types.User{
+		UserName:   userName,
+		Active:     true,
+		Store:      types.UserStoreOIDC,
+		ExternalID: claims.Subject(),
+		Roles:      h.roles(db, claims, orgID),
+	}
+	u.CreatedBy = "oidc"
+	u.OrganizationID = orgID
+	user, err := db.CreateUser(u)
+	if err != nil {
+		return user, ErrApiDatabase("User", err)
+	}
+	return user, nil
+}
+
+// Returns the roles for the groups of the user.
+// Roles with projects or locales that cannot be found within the organization are skipped,
+// since they would otherwise apply more broadly than intended.
+func (h OIDCHandler) roles(db types.Storage, claims oidc.Claims, organizationID string) []types.RoleAssignment {
+	roles := []types.RoleAssignment{}
+	for _, group := range claims.Strings(h.options.GroupsClaim) {
+		for _, gr := range h.options.Groups[strings.ToLower(group)] {
+			if !gr.Role.Valid() {
+				continue
+			}
+			ra := types.RoleAssignment{Role: gr.Role}
+			if gr.Project != "" {
+				p, err := db.GetProjectByIDOrShortName(gr.Project)
+				if err != nil || p == nil || p.OrganizationID != organizationID {
+					continue
+				}
+				ra.ProjectID = p.ID
+			}
+			if h.resolveLocales(db, &ra, gr.Locales) {
+				roles = append(roles, ra)
+			}
+		}
+	}
+	return roles
+}
+
+// Sets the locales of the assignment, and returns false if any of them cannot be found
+func (h OIDCHandler) resolveLocales(db types.Storage, ra *types.RoleAssignment, locales []string) bool {
+	for _, l := range locales {
+		locale, err := db.GetLocaleByIDOrShortName(l)
+		if err != nil || locale == nil {
+			return false
+		}
+		ra.LocaleIDs = append(ra.LocaleIDs, locale.ID)
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/go-common/logger"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/oidc"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+type mockSessionCreator struct {
+	users []types.User
+}
+
+func (m *mockSessionCreator) NewSession(user types.User, organization types.Organization, userAgent string, opts ...types.UserSessionOptions) types.Session {
+	m.users = append(m.users, user)
+	return types.Session{Token: "token-" + user.ID, User: user, Organization: organization}
+}
+
+func TestOIDCLogin(t *testing.T) {
+	ctx := &requestContext.Context{L: logger.GetLoggerWithLevel("test", "fatal")}
+	bb := bboltStorage.NewMockDB(t)
+	org, err := bb.CreateOrganization(types.Organization{Title: "acme", CreatedBy: "test"})
+	testza.AssertNoError(t, err)
+	other, err := bb.CreateOrganization(types.Organization{Title: "other", CreatedBy: "test"})
+	testza.AssertNoError(t, err)
+	p := types.Project{Title: "web", ShortName: "web"}
+	p.CreatedBy = "test"
+	p.OrganizationID = org.ID
+	project, err := bb.CreateProject(p)
+	testza.AssertNoError(t, err)
+	l := types.Locale{Title: "Norwegian", Iso639_1: "nb", Iso639_2: "nob", Iso639_3: "nob", IETF: "nb-NO"}
+	l.CreatedBy = "test"
+	l.OrganizationID = org.ID
+	locale, err := bb.CreateLocale(l)
+	testza.AssertNoError(t, err)
+
+	idp := oidc.NewMockIdP(t, "skiver")
+	provider, err := oidc.NewProvider(context.Background(), oidc.Config{
+		Issuer:      idp.URL,
+		ClientID:    "skiver",
+		RedirectURL: "https://skiver.example/api/auth/oidc/callback",
+	}, nil)
+	testza.AssertNoError(t, err)
+	sessions := &mockSessionCreator{}
+	replaced := &mockUserSessions{}
+	h := NewOIDCHandler(provider, sessions, replaced, OIDCOptions{
+		OrganizationClaim: "tenant",
+		Organizations:     map[string]string{"acme": org.ID, "other": other.ID},
+		Groups: map[string][]OIDCGroupRole{
+			"Translators-NB": {{Role: types.RoleTranslator, Project: "web", Locales: []string{"nb-NO"}}},
+			"Developers":     {{Role: types.RoleDeveloper, Project: "web"}},
+			"Misconfigured":  {{Role: types.RoleAdmin, Project: "web", Locales: []string{"xx-XX"}}, {Role: types.RoleReviewer, Project: "web"}},
+		},
+	})
+
+	serve := func(handler AppHandler, target string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		rc := ctx.NewReqContext(rw, r)
+		if _, err := handler(rc, rw, r); err != nil {
+			rc.WriteErr(err, "")
+		}
+		return rw
+	}
+	login := func(claims oidc.Claims) *httptest.ResponseRecorder {
+		rw := serve(h.Login(), "/api/auth/oidc/login?redirect=/projects")
+		testza.AssertEqual(t, http.StatusFound, rw.Code)
+		callback, err := idp.Login(rw.Header().Get("Location"), claims)
+		testza.AssertNoError(t, err)
+		return serve(h.Callback(bb), callback.RequestURI(), rw.Result().Cookies()...)
+	}
+
+	rw := login(oidc.Claims{"sub": "u-1", "preferred_username": "kari", "tenant": "acme", "groups": []string{"translators-nb", "misconfigured"}})
+	testza.AssertEqual(t, http.StatusFound, rw.Code)
+	testza.AssertEqual(t, "/projects", rw.Header().Get("Location"))
+	var names []string
+	for _, c := range rw.Result().Cookies() {
+		names = append(names, c.Name)
+	}
+	testza.AssertEqual(t, []string{oidcStateCookie, "token"}, names, "The state-cookie should be removed, and the session-cookie set")
+	testza.AssertLen(t, sessions.users, 1)
+	user := sessions.users[0]
+	testza.AssertEqual(t, "kari", user.UserName)
+	testza.AssertEqual(t, org.ID, user.OrganizationID, "The user should be provisioned into the mapped organization")
+	testza.AssertEqual(t, types.UserStoreOIDC, user.Store)
+	testza.AssertEqual(t, []types.RoleAssignment{
+		{Role: types.RoleTranslator, ProjectID: project.ID, LocaleIDs: []string{locale.ID}},
+		{Role: types.RoleReviewer, ProjectID: project.ID},
+	}, user.Roles, "Roles with unknown locales should be skipped, instead of applying to all locales, but not the other roles of the group")
+	testza.AssertTrue(t, user.Can(types.ActionEditTranslationValues, types.AccessScope{ProjectID: project.ID, LocaleID: locale.ID}))
+	testza.AssertFalse(t, user.Can(types.ActionCreateTranslations, types.AccessScope{ProjectID: project.ID}))
+
+	rw = login(oidc.Claims{"sub": "u-1", "preferred_username": "kari", "tenant": "acme", "groups": "developers"})
+	testza.AssertEqual(t, http.StatusFound, rw.Code)
+	testza.AssertLen(t, sessions.users, 2)
+	testza.AssertEqual(t, user.ID, sessions.users[1].ID, "The same user should be used on later logins")
+	testza.AssertEqual(t, []types.RoleAssignment{{Role: types.RoleDeveloper, ProjectID: project.ID}}, sessions.users[1].Roles,
+		"The roles should be replaced by the current groups")
+	testza.AssertEqual(t, []string{user.ID}, replaced.updated, "The changed roles should apply to the other sessions of the user")
+
+	rw = login(oidc.Claims{"sub": "u-2", "preferred_username": "ola", "tenant": "other", "groups": "developers"})
+	testza.AssertEqual(t, http.StatusFound, rw.Code)
+	testza.AssertEqual(t, other.ID, sessions.users[2].OrganizationID)
+	testza.AssertEqual(t, []types.RoleAssignment{}, sessions.users[2].Roles, "Projects of other organizations should not be granted")
+
+	rw = login(oidc.Claims{"sub": "u-3", "preferred_username": "kari", "tenant": "acme"})
+	testza.AssertEqual(t, http.StatusConflict, rw.Code, "Usernames must be unique")
+	rw = login(oidc.Claims{"sub": "u-4", "preferred_username": "nobody", "tenant": "unknown"})
+	testza.AssertEqual(t, http.StatusForbidden, rw.Code, "Users that are not mapped to an organization cannot log in")
+
+	rw = serve(h.Login(), "/api/auth/oidc/login")
+	cookies := rw.Result().Cookies()
+	testza.AssertLen(t, cookies, 1)
+	testza.AssertEqual(t, oidcStateCookie, cookies[0].Name)
+	testza.AssertTrue(t, cookies[0].HttpOnly)
+	testza.AssertEqual(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	callback, err := idp.Login(rw.Header().Get("Location"), oidc.Claims{"sub": "u-1", "tenant": "acme"})
+	testza.AssertNoError(t, err)
+	otherBrowser := serve(h.Login(), "/api/auth/oidc/login").Result().Cookies()
+	testza.AssertEqual(t, http.StatusBadRequest, serve(h.Callback(bb), callback.RequestURI()).Code, "The login must be completed in the browser that started it")
+	testza.AssertEqual(t, http.StatusBadRequest, serve(h.Callback(bb), callback.RequestURI(), otherBrowser...).Code, "The login must be completed in the browser that started it")
+	testza.AssertEqual(t, http.StatusFound, serve(h.Callback(bb), callback.RequestURI(), cookies...).Code)
+	testza.AssertEqual(t, http.StatusBadRequest, serve(h.Callback(bb), callback.RequestURI(), cookies...).Code, "The state can only be used once")
+	testza.AssertEqual(t, http.StatusBadRequest, serve(h.Callback(bb), "/api/auth/oidc/callback?code=abc&state=forged", cookies...).Code)
+
+	for redirect, expected := range map[string]string{
+		"/projects":             "/projects",
+		"":                      "/",
+		"https://evil.example":  "/",
+		"//evil.example":        "/",
+		`/\evil.example`:        "/",
+		"javascript:alert('x')": "/",
+	} {
+		testza.AssertEqual(t, expected, safeRedirect(redirect), redirect)
+	}
+}
@@ -17,7 +17,7 @@ func GetOrganization(db types.OrgStorage) AppHandler {
 		if err != nil {
 			return nil, err
 		}
-		if session.User.CanCreateOrganization {
+		if Authorize(rc.Context.DB, session, types.ActionCreateOrganization, types.AccessScope{}) == nil {
 			orgs, err := db.GetOrganizations()
 			if err != nil {
 				return orgs, ErrApiDatabase("Project", err)
@@ -40,8 +40,8 @@ func CreateOrganization(db types.OrgStorage) AppHandler {
 		if err != nil {
 			return nil, err
 		}
-		if !session.User.CanCreateOrganization {
-			return nil, ErrApiNotAuthorized("Organization", "create")
+		if err := Authorize(rc.Context.DB, session, types.ActionCreateOrganization, types.AccessScope{}); err != nil {
+			return nil, err
 		}
 		var j models.OrganizationInput
 		if err := rc.ValidateBody(&j, false); err != nil {
@@ -72,8 +72,8 @@ func UpdateOrganization(db types.OrgStorage) AppHandler {
 			return nil, err
 		}
 		session.User.CanUpdateOrganization = true
-		if !session.User.CanUpdateOrganization {
-			return nil, ErrApiNotAuthorized("Organization", "update")
+		if err := Authorize(rc.Context.DB, session, types.ActionUpdateOrganization, types.AccessScope{}); err != nil {
+			return nil, err
 		}
 		var j models.UpdateOrganizationInput
 		if err := rc.ValidateBody(&j, false); err != nil {
@@ -82,16 +82,20 @@ func UpdateOrganization(db types.OrgStorage) AppHandler {
 		if *j.ID != session.User.OrganizationID {
 			return nil, ErrApiNotAuthorized("Organization other than own organization", "update")
 		}
+		if j.JoinIDExpires == nil && j.RequireMfa == nil {
+			return nil, NewApiError("No changes required", http.StatusAlreadyReported, "NoChanges")
+		}
+		org, err := db.GetOrganization(session.User.OrganizationID)
+		if err != nil {
+			return nil, ErrApiDatabase("Organization", err)
+		}
+		payload := types.UpdateOrganizationPayload{
+			UpdatedBy:  session.User.ID,
+			RequireMFA: j.RequireMfa,
+		}
 		if j.JoinIDExpires != nil {
-			org, err := db.GetOrganization(session.User.OrganizationID)
-			if err != nil {
-				return nil, ErrApiDatabase("Organization", err)
-			}
-			payload := types.UpdateOrganizationPayload{
-				JoinID:        &j.JoinID,
-				JoinIDExpires: (*time.Time)(j.JoinIDExpires),
-				UpdatedBy:     session.User.ID,
-			}
+			payload.JoinID = &j.JoinID
+			payload.JoinIDExpires = (*time.Time)(j.JoinIDExpires)
 
 			if payload.JoinID == nil || *payload.JoinID == "" {
 				id, err := utils.GetRandomName()
@@ -102,12 +106,11 @@ func UpdateOrganization(db types.OrgStorage) AppHandler {
 			} else if org.JoinID != j.JoinID {
 				return nil, ErrApiNotFound("JoinId", j.JoinID)
 			}
-			updated, err := db.UpdateOrganization(org.ID, payload)
-			if err != nil {
-				return nil, ErrApiDatabase("Organization", err)
-			}
-			return updated, err
 		}
-		return nil, NewApiError("No changes required", http.StatusAlreadyReported, "NoChanges")
+		updated, err := db.UpdateOrganization(org.ID, payload)
+		if err != nil {
+			return nil, ErrApiDatabase("Organization", err)
+		}
+		return updated, err
 	}
 }
@@ -29,10 +29,6 @@ func UpdateProject(db types.Storage) AppHandler {
 		if err != nil {
 			return nil, ErrApiInternalErrorMissingSession
 		}
-
-		if !session.User.CanUpdateProjects {
-			return nil, ErrApiNotAuthorized("Project", "update")
-		}
 		var j models.UpdateProjectInput
 		if err := rc.ValidateBody(&j, false); err != nil {
 			return nil, err
@@ -45,6 +41,9 @@ func UpdateProject(db types.Storage) AppHandler {
 		if p == nil || session.User.OrganizationID != p.OrganizationID {
 			return nil, ErrApiNotFound("Project", *j.ID)
 		}
+		if err := Authorize(rc.Context.DB, session, types.ActionUpdateProjects, types.AccessScope{ProjectID: p.ID}); err != nil {
+			return nil, err
+		}
 		payload := types.Project{
 			Title:       j.Title,
 			Description: j.Description,
@@ -62,6 +61,23 @@ func UpdateProject(db types.Storage) AppHandler {
 
 			}
 		}
+		if len(j.LintRules) > 0 {
+			payload.LintRules = map[string]types.LintRuleSetting{}
+			for id, s := range j.LintRules {
+				payload.LintRules[id] = types.LintRuleSetting{
+					Disabled: s.Disabled,
+					Severity: s.Severity,
+				}
+			}
+			if err := validateLintRules(payload.LintRules); err != nil {
+				return nil, err
+			}
+		}
+		if j.ReleaseGate != nil {
+			payload.ReleaseGate = &types.ReleaseGateSetting{
+				Enabled: j.ReleaseGate.Enabled,
+			}
+		}
 		project, err := db.UpdateProject(*j.ID, payload)
 		if err != nil {
 			return nil, ErrApiDatabase("Project", err)
@@ -77,8 +93,8 @@ func CreateProject(db types.Storage) AppHandler {
 			return nil, ErrApiInternalErrorMissingSession
 		}
 
-		if !session.User.CanCreateProjects {
-			return nil, ErrApiNotAuthorized("Project", "create")
+		if err := Authorize(rc.Context.DB, session, types.ActionCreateProjects, types.AccessScope{}); err != nil {
+			return nil, err
 		}
 		var j models.ProjectInput
 		if err := rc.ValidateBody(&j, false); err != nil {
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/models"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+// SetTranslationValueState changes the workflow-state of a translation-value.
+// Users that can edit the translation-value may move it between draft and needs-review,
+// while only reviewers of its project and locale may approve or reject it.
+func SetTranslationValueState() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		id := GetParams(r).ByName("id")
+		if id == "" {
+			return nil, ErrApiMissingArgument("id")
+		}
+		var j models.TranslationValueStateInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		state := types.TranslationValueState(*j.State)
+		db := rc.Context.DB
+		tv, err := organizationTranslationValue(db, id, session.Organization.ID)
+		if err != nil {
+			return nil, err
+		}
+		scope, err := translationValueScope(db, *tv)
+		if err != nil {
+			return nil, err
+		}
+		action := types.ActionEditTranslationValues
+		if state.RequiresReviewer() {
+			action = types.ActionReviewTranslationValues
+		}
+		if err := Authorize(rc.Context.DB, session, action, scope); err != nil {
+			return nil, err
+		}
+		updated, err := db.SetTranslationValueState(id, types.TranslationValueReview{
+			State:   state,
+			By:      session.User.ID,
+			Comment: strings.TrimSpace(j.Comment),
+		})
+		if err != nil {
+			return nil, NewApiErr(err, http.StatusInternalServerError, string(requestContext.CodeErrUpdateTranslationValue))
+		}
+		return updated, nil
+	}
+}
+
+// Returns the translation-value, if it belongs to the organization
+func organizationTranslationValue(db types.Storage, id, organizationID string) (*types.TranslationValue, error) {
+	tv, err := db.GetTranslationValue(id)
+	if err != nil && !errors.Is(err, bboltStorage.ErrNotFound) {
+		return nil, ErrApiDatabase("TranslationValue", err)
+	}
+	if tv == nil || tv.ID == "" || tv.OrganizationID != organizationID {
+		return nil, ErrApiNotFound("TranslationValue", id)
+	}
+	return tv, nil
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/runar-rkmedia/skiver/localuser"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+type SessionRevoker interface {
+	SessionsForUser(userId string) (s []types.Session)
+	RevokeSession(userId, id string) error
+}
+
+// Returns the sessions of the user, newest first, without their tokens.
+func sessionInfos(sessions SessionRevoker, current types.Session) []types.SessionInfo {
+	list := sessions.SessionsForUser(current.User.ID)
+	infos := make([]types.SessionInfo, len(list))
+	for i, s := range list {
+		infos[i] = types.SessionInfo{
+			ID:        s.ID,
+			UserAgent: s.UserAgent,
+			Issued:    s.Issued,
+			Expires:   s.Expires,
+			Current:   s.ID == current.ID,
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Issued.After(infos[j].Issued)
+	})
+	return infos
+}
+
+// GetSessions lists the active sessions of the user, like logins on other devices, and tokens.
+func GetSessions(sessions SessionRevoker) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		return sessionInfos(sessions, session), nil
+	}
+}
+
+// RevokeSession revokes one of the sessions of the user.
+func RevokeSession(sessions SessionRevoker) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		id := GetParams(r).ByName("id")
+		if id == "" {
+			return nil, ErrApiMissingArgument("id")
+		}
+		if err := sessions.RevokeSession(session.User.ID, id); err != nil {
+			if errors.Is(err, localuser.ErrNotFound) {
+				return nil, ErrApiNotFound("Session", id)
+			}
+			return nil, ErrApiDatabase("Session", err)
+		}
+		if id == session.ID {
+			writeLogoutCookie(rw)
+		}
+		return sessionInfos(sessions, session), nil
+	}
+}
+
+// RevokeOtherSessions revokes all the sessions of the user, except the one that made the request.
+func RevokeOtherSessions(sessions SessionRevoker) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range sessions.SessionsForUser(session.User.ID) {
+			if s.ID == session.ID {
+				continue
+			}
+			if err := sessions.RevokeSession(session.User.ID, s.ID); err != nil && !errors.Is(err, localuser.ErrNotFound) {
+				return nil, ErrApiDatabase("Session", err)
+			}
+		}
+		return sessionInfos(sessions, session), nil
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/Masterminds/semver/v3"
 	"github.com/runar-rkmedia/go-common/logger"
 	"github.com/runar-rkmedia/skiver/importexport"
+	"github.com/runar-rkmedia/skiver/lint"
 	"github.com/runar-rkmedia/skiver/models"
 	"github.com/runar-rkmedia/skiver/requestContext"
 	"github.com/runar-rkmedia/skiver/types"
@@ -153,7 +154,8 @@ func uploadSnapshot(l logger.AppLogger, uploaders []uploader.FileUploader, tag s
 	return m, nil
 }
 
-func uploadSnapshotForProjectAndUpdateIt(db types.Storage, l logger.AppLogger, uploaders []uploader.FileUploader, tag string, snap types.ProjectSnapshot) {
+// UploadSnapshotForProjectAndUpdateIt uploads the snapshot to all the uploaders, and updates the project with references to the uploads.
+func UploadSnapshotForProjectAndUpdateIt(db types.Storage, l logger.AppLogger, uploaders []uploader.FileUploader, tag string, snap types.ProjectSnapshot) {
 	var uploadMetas []types.UploadMeta
 	// Upload the snapshots, if there are any uploaders configured
 	if len(uploaders) > 0 {
@@ -198,6 +200,7 @@ func uploadSnapshotForProjectAndUpdateIt(db types.Storage, l logger.AppLogger, u
 		}
 	}
 	p.Snapshots[tag] = pSnap
+	p.UpdatedBy = snap.CreatedBy
 	_, err = db.UpdateProject(p.ID, *p)
 	if err != nil {
 		l.Error().Err(err).Msg("Failed to update database with new uploads for project")
@@ -206,6 +209,119 @@ func uploadSnapshotForProjectAndUpdateIt(db types.Storage, l logger.AppLogger, u
 	l.Info().Interface("UploadMeta", uploadMetas).Msg("Snapshot was updated with referances to externally uploaded snapshots")
 }
 
+// ReleaseGateDetails are the details of the error returned when the release-gate refuses a snapshot
+type ReleaseGateDetails struct {
+	// The full keys of the translations with issues
+	Keys   []string     `json:"keys"`
+	Issues []lint.Issue `json:"issues"`
+}
+
+// Returns an error if the project has the release-gate enabled, and it has issues that would break the release.
+// Users that can manage snapshots may force the release.
+func checkReleaseGate(db ProjectGetter, ep types.ExtendedProject, force bool, session types.Session) error {
+	if ep.ReleaseGate == nil || !ep.ReleaseGate.Enabled {
+		return nil
+	}
+	if force {
+		return Authorize(db, session, types.ActionManageSnapshots, types.AccessScope{ProjectID: ep.ID})
+	}
+	return ReleaseGate(ep)
+}
+
+// ReleaseGate returns an error if the project has the release-gate enabled, and it has issues that would break the release.
+// The error has ReleaseGateDetails.
+func ReleaseGate(ep types.ExtendedProject) error {
+	if ep.ReleaseGate == nil || !ep.ReleaseGate.Enabled {
+		return nil
+	}
+	issues := lint.ReleaseBlockers(ep)
+	if len(issues) == 0 {
+		return nil
+	}
+	keys := map[string]bool{}
+	for _, issue := range issues {
+		keys[issue.Key] = true
+	}
+	return NewApiError(
+		fmt.Sprintf("The release-gate refused the snapshot, since %d translations have issues. These must be fixed, or the snapshot must be forced.", len(keys)),
+		http.StatusUnprocessableEntity,
+		string(requestContext.CodeErrReleaseGate),
+		ReleaseGateDetails{Keys: utils.SortedMapKeys(keys), Issues: issues},
+	)
+}
+
+// CreateProjectSnapshot creates a snapshot of the published locales of the project, and tags it.
+// If there already exists a snapshot with the same hash, that snapshot is tagged instead.
+// The gate is called with the extended project before the snapshot is created, and can refuse it by returning an error.
+func CreateProjectSnapshot(db types.Storage, project types.Project, tag, description, createdBy string, gate func(ep types.ExtendedProject) error) (types.Project, types.ProjectSnapshot, error) {
+	if s, ok := project.Snapshots[tag]; ok {
+		return project, types.ProjectSnapshot{}, NewApiError("The tag already exists", http.StatusNotFound, "TaxExists", s)
+	}
+
+	projectLocaleMap := map[string]bool{}
+	for k, v := range project.LocaleIDs {
+		if !v.Publish {
+			continue
+		}
+		projectLocaleMap[k] = true
+
+	}
+	if len(projectLocaleMap) == 0 {
+		return project, types.ProjectSnapshot{}, NewApiError("There are no published locales for this project", http.StatusBadRequest, "No published project-locales")
+	}
+
+	ep, err := project.Extend(db, types.ExtendOptions{
+		ByID:      true,
+		ByKeyLike: false,
+		LocaleFilterFunc: func(l types.Locale) bool {
+			return projectLocaleMap[l.ID]
+		},
+		ErrOnNoLocales: true,
+	})
+	if err != nil {
+		return project, types.ProjectSnapshot{}, NewApiErr(err, http.StatusInternalServerError, string(requestContext.CodeErrProject))
+	}
+	if gate != nil {
+		if err := gate(ep); err != nil {
+			return project, types.ProjectSnapshot{}, err
+		}
+	}
+	s, err := ep.CreateSnapshot(createdBy)
+	if err != nil {
+		return project, s, ErrApiDatabase("Project", err)
+	}
+
+	var projectSnapshot types.ProjectSnapshot
+	existing, err := db.FindOneSnapshot(s)
+	if err != nil {
+		return project, s, err
+	}
+	if existing != nil {
+		projectSnapshot = *existing
+	} else {
+		ss, err := db.CreateSnapshot(s)
+		if err != nil {
+			return project, s, NewApiErr(err, http.StatusInternalServerError, string(requestContext.CodeErrProject))
+		}
+		projectSnapshot = ss
+	}
+
+	if project.Snapshots == nil {
+		project.Snapshots = map[string]types.ProjectSnapshotMeta{}
+	}
+	project.Snapshots[tag] = types.ProjectSnapshotMeta{
+		Description: description,
+		CreatedBy:   createdBy,
+		SnapshotID:  projectSnapshot.ID,
+		CreatedAt:   time.Now(),
+		Hash:        s.ProjectHash,
+	}
+
+	project.UpdatedBy = createdBy
+	updatedProject, err := db.UpdateProject(project.ID, project)
+	return updatedProject, s, err
+}
+
 // PostSnapshot creates a snapshot if there does not exist one already with the same hash.
 func PostSnapshot(uploaders []uploader.FileUploader) AppHandler {
 	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (output interface{}, err error) {
@@ -230,74 +346,17 @@ func PostSnapshot(uploaders []uploader.FileUploader) AppHandler {
 			err = NewApiError("Project not found", http.StatusNotFound, string(requestContext.CodeErrNotFoundProject))
 			return
 		}
-		if s, ok := project.Snapshots[*j.Tag]; ok {
-			err = NewApiError("The tag already exists", http.StatusNotFound, "TaxExists", s)
-			return
-		}
-
-		projectLocaleMap := map[string]bool{}
-		for k, v := range project.LocaleIDs {
-			if !v.Publish {
-				continue
-			}
-			projectLocaleMap[k] = true
-
-		}
-		if len(projectLocaleMap) == 0 {
-			err = NewApiError("There are no published locales for this project", http.StatusBadRequest, "No published project-locales")
+		if err = Authorize(rc.Context.DB, session, types.ActionCreateSnapshots, types.AccessScope{ProjectID: project.ID}); err != nil {
 			return
 		}
-
-		ep, err := project.Extend(rc.Context.DB, types.ExtendOptions{
-			ByID:      true,
-			ByKeyLike: false,
-			LocaleFilterFunc: func(l types.Locale) bool {
-				return projectLocaleMap[l.ID]
-			},
-			ErrOnNoLocales: true,
+		updatedProject, s, err := CreateProjectSnapshot(rc.Context.DB, *project, *j.Tag, j.Description, session.User.ID, func(ep types.ExtendedProject) error {
+			return checkReleaseGate(rc.Context.DB, ep, j.Force, session)
 		})
-		if err != nil {
-			err = NewApiErr(err, http.StatusInternalServerError, string(requestContext.CodeErrProject))
-			return
-		}
-		s, err := ep.CreateSnapshot(session.User.ID)
-		if err != nil {
-			return nil, ErrApiDatabase("Project", err)
-		}
-
-		var projectSnapshot types.ProjectSnapshot
-		existing, err := rc.Context.DB.FindOneSnapshot(s)
-		if err != nil {
-			return
-		}
-		if existing != nil {
-			projectSnapshot = *existing
-		} else {
-			ss, err := rc.Context.DB.CreateSnapshot(s)
-			if err != nil {
-				err = NewApiErr(err, http.StatusInternalServerError, string(requestContext.CodeErrProject))
-				return nil, err
-			}
-			projectSnapshot = ss
-		}
-
-		if project.Snapshots == nil {
-			project.Snapshots = map[string]types.ProjectSnapshotMeta{}
-		}
-		project.Snapshots[*j.Tag] = types.ProjectSnapshotMeta{
-			Description: j.Description,
-			CreatedBy:   session.User.ID,
-			SnapshotID:  projectSnapshot.ID,
-			CreatedAt:   time.Now(),
-			Hash:        s.ProjectHash,
-		}
-
-		updatedProject, err := rc.Context.DB.UpdateProject(project.ID, *project)
 		if err != nil {
 			return
 		}
 		if len(uploaders) > 0 {
-			go uploadSnapshotForProjectAndUpdateIt(rc.Context.DB, rc.L, uploaders, *j.Tag, s)
+			go UploadSnapshotForProjectAndUpdateIt(rc.Context.DB, rc.L, uploaders, *j.Tag, s)
 		}
 
 		return updatedProject, err
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestCheckReleaseGate(t *testing.T) {
+	ep := types.ExtendedProject{
+		Locales: map[string]types.Locale{
+			"loc-en": {Entity: types.Entity{ID: "loc-en"}, IETF: "en-GB"},
+			"loc-nb": {Entity: types.Entity{ID: "loc-nb"}, IETF: "nb-NO"},
+		},
+		Categories: map[string]types.ExtendedCategory{
+			"cat": {
+				Category: types.Category{Key: "General"},
+				Translations: map[string]types.ExtendedTranslation{
+					"t": {
+						Translation: types.Translation{Key: "Welcome"},
+						Values: map[string]types.TranslationValue{
+							"tv": {LocaleID: "loc-en", Value: "Welcome"},
+						},
+					},
+				},
+			},
+		},
+	}
+	user := types.Session{User: types.User{}}
+	manager := types.Session{User: types.User{CanManageSnapshots: true}}
+
+	testza.AssertNoError(t, checkReleaseGate(nil, ep, false, user), "The gate is not enabled")
+
+	ep.ReleaseGate = &types.ReleaseGateSetting{Enabled: true}
+	err := checkReleaseGate(nil, ep, false, manager)
+	if apiErr, ok := err.(requestContext.APIError); ok {
+		testza.AssertEqual(t, http.StatusUnprocessableEntity, apiErr.ErrHttpStatus())
+		details, ok := apiErr.Details.(ReleaseGateDetails)
+		testza.AssertTrue(t, ok)
+		testza.AssertEqual(t, []string{"General.Welcome"}, details.Keys)
+		testza.AssertLen(t, details.Issues, 1)
+	} else {
+		t.Fatalf("expected an APIError, got %T", err)
+	}
+
+	testza.AssertNotNil(t, checkReleaseGate(nil, ep, true, user), "Only users that can manage snapshots can force")
+	testza.AssertNoError(t, checkReleaseGate(nil, ep, true, manager))
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/translationmemory"
+)
+
+// GetSuggestions returns suggestions for the value of a translation in a locale,
+// from other translations within the organization with a similar source-value.
+func GetSuggestions(tm *translationmemory.Memory) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		id := GetParams(r).ByName("id")
+		if id == "" {
+			return nil, ErrApiMissingArgument("id")
+		}
+		localeKey := r.URL.Query().Get("locale")
+		if localeKey == "" {
+			return nil, ErrApiMissingArgument("locale")
+		}
+		db := rc.Context.DB
+		t, err := db.GetTranslation(id)
+		if err != nil {
+			return nil, ErrApiDatabase("Translation", err)
+		}
+		if t == nil || t.Deleted != nil || t.OrganizationID != session.Organization.ID {
+			return nil, ErrApiNotFound("Translation", id)
+		}
+		locales, err := db.GetLocales()
+		if err != nil {
+			return nil, ErrApiDatabase("Locale", err)
+		}
+		localeID := ""
+		for _, l := range locales {
+			if l.Matches(localeKey) {
+				localeID = l.ID
+				break
+			}
+		}
+		if localeID == "" {
+			return nil, ErrApiNotFound("Locale", localeKey)
+		}
+		suggestions, err := tm.Suggest(session.Organization.ID, t.ID, localeID)
+		if err != nil {
+			if errors.Is(err, translationmemory.ErrNoSource) {
+				return nil, NewApiErr(err, http.StatusUnprocessableEntity, string(requestContext.CodeErrTranslation))
+			}
+			return nil, NewApiErr(err, http.StatusInternalServerError, string(requestContext.CodeErrTranslation))
+		}
+		return suggestions, nil
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/go-common/logger"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/internal"
+	"github.com/runar-rkmedia/skiver/translationmemory"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestTranslationMemory(t *testing.T) {
+	internal.NewMockTimeNow()
+	l := logger.GetLoggerWithLevel("test", "fatal")
+	bb := bboltStorage.NewMockDB(t)
+	err := bb.StandardSeed()
+	testza.AssertNoError(t, err)
+	base := types.Project{}
+	base.CreatedBy = "jim"
+	base.OrganizationID = "org-123"
+	base.ShortName = "proj"
+	base.Title = "My project"
+	project, err := bb.CreateProject(base)
+	testza.AssertNoError(t, err)
+
+	input := `
+en-GB:
+  General:
+    Welcome: Welcome to the application
+    WelcomeBack: Welcome back to the application
+    Goodbye: Goodbye
+nb-NO:
+  General:
+    WelcomeBack: Velkommen tilbake til applikasjonen
+    Goodbye: Ha det
+`
+	r, _ := http.NewRequest(http.MethodPost, "", strings.NewReader(input))
+	r.Header.Set("Content-Type", "text/vnd.yaml")
+	_, Err := ImportIntoProject(l, bb, "i18n", base.CreatedBy, project, "", []byte(input), r, ImportIntoProjectOptions{NoDryRun: true})
+	testza.AssertNil(t, Err)
+
+	tm := translationmemory.NewMemory(bb)
+	testza.AssertNoError(t, tm.Build())
+	testza.AssertEqual(t, 5, tm.Size())
+
+	translations, err := bb.GetTranslations()
+	testza.AssertNoError(t, err)
+	var welcome types.Translation
+	for _, tr := range translations {
+		if tr.Key == "Welcome" {
+			welcome = tr
+		}
+	}
+	locales, err := bb.GetLocales()
+	testza.AssertNoError(t, err)
+	var nb, en types.Locale
+	for _, l := range locales {
+		switch l.IETF {
+		case "nb-NO":
+			nb = l
+		case "en-GB":
+			en = l
+		}
+	}
+
+	s, err := tm.Suggest(base.OrganizationID, welcome.ID, nb.ID)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Welcome to the application", s.Source)
+	testza.AssertEqual(t, en.ID, s.SourceLocaleID)
+	testza.AssertLen(t, s.Suggestions, 1)
+	testza.AssertEqual(t, "Velkommen tilbake til applikasjonen", s.Suggestions[0].Value)
+	testza.AssertEqual(t, "General.WelcomeBack", s.Suggestions[0].Key)
+	testza.AssertEqual(t, project.ID, s.Suggestions[0].ProjectID)
+	testza.AssertEqual(t, "My project", s.Suggestions[0].ProjectTitle)
+	testza.AssertEqual(t, 84, s.Suggestions[0].Match)
+
+	// Other organizations should not get suggestions from this one
+	_, err = tm.Suggest("org-other", welcome.ID, nb.ID)
+	testza.AssertErrorIs(t, err, translationmemory.ErrNoSource)
+
+	// Changes are picked up from the published events
+	tv, err := bb.GetTranslationValue(s.Suggestions[0].TranslationValueID)
+	testza.AssertNoError(t, err)
+	tv.Value = "Velkommen tilbake"
+	tm.Publish(string(types.PubTypeTranslationValue), string(types.PubVerbUpdate), *tv)
+	s, err = tm.Suggest(base.OrganizationID, welcome.ID, nb.ID)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Velkommen tilbake", s.Suggestions[0].Value)
+
+	tv.Deleted = &tv.CreatedAt
+	tm.Publish(string(types.PubTypeTranslationValue), string(types.PubVerbSoftDelete), *tv)
+	s, err = tm.Suggest(base.OrganizationID, welcome.ID, nb.ID)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, s.Suggestions, 0)
+	testza.AssertEqual(t, 4, tm.Size())
+}
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
 	"github.com/runar-rkmedia/skiver/models"
 	"github.com/runar-rkmedia/skiver/requestContext"
 	"github.com/runar-rkmedia/skiver/types"
@@ -42,6 +44,20 @@ func DeleteTranslation() AppHandler {
 				}
 			}
 		}
+		existing, err := rc.Context.DB.GetTranslation(tid)
+		if err != nil {
+			return nil, ErrApiDatabase("Translation", err)
+		}
+		if existing == nil || existing.OrganizationID != session.Organization.ID {
+			return nil, ErrApiNotFound("Translation", tid)
+		}
+		scope, err := translationScope(rc.Context.DB, *existing)
+		if err != nil {
+			return nil, err
+		}
+		if err := Authorize(rc.Context.DB, session, types.ActionUpdateTranslations, scope); err != nil {
+			return nil, err
+		}
 		return rc.Context.DB.SoftDeleteTranslation(tid, session.User.ID, deleteTime)
 	}
 }
@@ -52,9 +68,6 @@ func UpdateTranslation() AppHandler {
 		if err != nil {
 			return nil, err
 		}
-		if !session.User.CanUpdateTranslations {
-			return nil, ErrApiNotAuthorized("Translation", "update")
-		}
 		params := httprouter.ParamsFromContext(r.Context())
 		tid := params.ByName("id")
 		var j models.UpdateTranslationInput
@@ -75,10 +88,24 @@ func UpdateTranslation() AppHandler {
 		if existing == nil || existing.OrganizationID != session.User.OrganizationID {
 			return nil, ErrApiNotFound("Translation", tid)
 		}
+		scope, err := translationScope(rc.Context.DB, *existing)
+		if err != nil {
+			return nil, err
+		}
+		if err := Authorize(rc.Context.DB, session, types.ActionUpdateTranslations, scope); err != nil {
+			return nil, err
+		}
+
+		version, err := expectedVersion(r, j.Version)
+		if err != nil {
+			return nil, err
+		}
 
 		t := types.Translation{
-			Key: existing.Key,
+			Key:     existing.Key,
+			Version: version,
 		}
+		t.UpdatedBy = session.User.ID
 		if j.Description != nil {
 			t.Description = *j.Description
 		}
@@ -99,9 +126,22 @@ func UpdateTranslation() AppHandler {
 
 		updated, err := rc.Context.DB.UpdateTranslation(tid, t)
 		if err != nil {
-
+			if errors.Is(err, bboltStorage.ErrVersionConflict) {
+				current, _ := rc.Context.DB.GetTranslation(tid)
+				return nil, ErrApiVersionConflict("Translation", current)
+			}
+			return nil, ErrApiDatabase("Translation", err)
 		}
-		return updated, ErrApiDatabase("Translation", err)
+		setETag(rw, updated.Version)
+		out := lintedTranslation{Translation: updated}
+		project, err := updated.GetProject(rc.Context.DB)
+		if err == nil {
+			out.LintIssues, err = lintTranslation(rc.Context.DB, project, updated)
+		}
+		if err != nil {
+			rc.L.Error().Err(err).Str("translationID", tid).Msg("Failed to lint the translation")
+		}
+		return out, nil
 
 	}
 }
@@ -111,14 +151,20 @@ func CreateTranslation() AppHandler {
 		if err != nil {
 			return nil, err
 		}
-
-		if !session.User.CanCreateTranslations {
-			return nil, ErrApiNotAuthorized("Translation", "create")
-		}
 		var j models.TranslationInput
 		if err := rc.ValidateBody(&j, false); err != nil {
 			return nil, err
 		}
+		category, err := rc.Context.DB.GetCategory(*j.CategoryID)
+		if err != nil {
+			return nil, ErrApiDatabase("Category", err)
+		}
+		if category == nil || category.OrganizationID != session.Organization.ID {
+			return nil, ErrApiNotFound("Category", *j.CategoryID)
+		}
+		if err := Authorize(rc.Context.DB, session, types.ActionCreateTranslations, types.AccessScope{ProjectID: category.ProjectID}); err != nil {
+			return nil, err
+		}
 
 		t := types.Translation{
 			// TranslationInput: j,
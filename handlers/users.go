@@ -1,16 +1,21 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/go-openapi/strfmt"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
 	"github.com/runar-rkmedia/skiver/models"
 	"github.com/runar-rkmedia/skiver/requestContext"
 	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
 )
 
 type UserStorage interface {
+	ProjectGetter
 	FindUsers(max int, filter ...types.User) (map[string]types.User, error)
 	UpdateUser(id string, payload types.UpdateUserPayload) (types.User, error)
 	GetUser(userId string) (*types.User, error)
@@ -36,8 +41,14 @@ func ListUsers(db UserStorage, simpleusers bool) AppHandler {
 			return nil, err
 		}
 		if !simpleusers {
+			for id, u := range users {
+				if u.Deleted != nil {
+					delete(users, id)
+				}
+			}
 			return users, nil
 		}
+		// Removed users are kept here, so that the changes they made can still be attributed to them
 
 		u := map[string]models.SimpleUser{}
 		for _, v := range users {
@@ -149,3 +160,283 @@ func CreateToken(sessionCreator SessionCreator) AppHandler {
 }
 
 var Ok = models.OkResponse{Ok: boolPointer(true)}
+
+// SetUserRoles replaces the roles of a user within the organization.
+// Users can only give roles for what they are authorized for themselves, within the same project and locales.
+func SetUserRoles(db UserStorage, sessionReplace SessionReplacer) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		if err := Authorize(db, session, types.ActionUpdateUsers, types.AccessScope{}); err != nil {
+			return nil, err
+		}
+		var j models.UserRolesInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		u, err := otherUserInOrganization(db, session, GetParams(r).ByName("id"))
+		if err != nil {
+			return nil, err
+		}
+		roles := []types.RoleAssignment{}
+		for _, ra := range j.Roles {
+			if ra == nil {
+				continue
+			}
+			role := types.Role(*ra.Role)
+			if !role.Valid() {
+				return nil, ErrApiInputValidation(fmt.Sprintf("Invalid role '%s', allowed values: %v", role, types.Roles), "Role")
+			}
+			assignment := types.RoleAssignment{Role: role}
+			if ra.ProjectID != "" {
+				p, err := rc.Context.DB.GetProjectByIDOrShortName(ra.ProjectID)
+				if err != nil || p == nil || p.OrganizationID != session.Organization.ID {
+					return nil, ErrApiNotFound("Project", ra.ProjectID)
+				}
+				assignment.ProjectID = p.ID
+			}
+			for _, localeID := range ra.LocaleIds {
+				l, err := rc.Context.DB.GetLocaleByIDOrShortName(localeID)
+				if err != nil || l == nil || l.ID == "" {
+					return nil, ErrApiNotFound("Locale", localeID)
+				}
+				assignment.LocaleIDs = append(assignment.LocaleIDs, l.ID)
+			}
+			for _, action := range assignment.Actions() {
+				if err := AuthorizeGrant(db, session, action, assignment); err != nil {
+					return nil, err
+				}
+			}
+			roles = append(roles, assignment)
+		}
+		payload := types.UpdateUserPayload{Roles: &roles}
+		payload.UpdatedBy = session.User.ID
+		user, err := db.UpdateUser(u.ID, payload)
+		if err != nil {
+			if errors.Is(err, bboltStorage.ErrNoFieldsChanged) {
+				return u, nil
+			}
+			return nil, ErrApiDatabase("User", err)
+		}
+		// The roles should apply immediately, and not just for new sessions
+		sessionReplace.UpdateAllSessionsForUser(user.ID, user)
+		return user, nil
+	}
+}
+
+type UserSessionClearer interface {
+	SessionReplacer
+	ClearAllSessionsForUser(userId string) error
+}
+
+// Returns the user within the organization of the session, which cannot be the user of the session.
+// Users change their own settings elsewhere, and should not be able to lock themselves out.
+// Users can only manage users that they could have given all their access, so that they cannot
+// deactivate, remove or strip the roles of users with more access than they have themselves.
+func otherUserInOrganization(db UserStorage, session types.Session, id string) (*types.User, error) {
+	if id == "" {
+		return nil, ErrApiMissingArgument("id")
+	}
+	u, err := db.GetUser(id)
+	if err != nil {
+		return nil, ErrApiDatabase("User", err)
+	}
+	if u == nil || u.Deleted != nil || u.OrganizationID != session.Organization.ID {
+		return nil, ErrApiNotFound("User", id)
+	}
+	if u.ID == session.User.ID {
+		return nil, NewApiError("You cannot manage your own user", http.StatusForbidden, string(requestContext.CodeErrAuthoriziation))
+	}
+	for _, action := range u.PermissionActions() {
+		if err := AuthorizeGrant(db, session, action, types.RoleAssignment{}); err != nil {
+			return nil, err
+		}
+	}
+	for _, ra := range u.Roles {
+		for _, action := range ra.Actions() {
+			if err := AuthorizeGrant(db, session, action, ra); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return u, nil
+}
+
+// Applies the permissions that are set in the input.
+// Permissions apply to the whole organization, and can only be granted by users that are authorized for them
+// within the whole organization, so that users cannot give others more permissions than they have themselves.
+// Every permission of the result is checked, including those that were not set in the input.
+func applyPermissions(db ProjectGetter, session types.Session, p *types.UserPermissions, j *models.UserPermissionsInput) error {
+	if j == nil {
+		j = &models.UserPermissionsInput{}
+	}
+	for _, f := range []struct {
+		input  *bool
+		target *bool
+		action types.Action
+	}{
+		{j.CanCreateOrganization, &p.CanCreateOrganization, types.ActionCreateOrganization},
+		{j.CanCreateUsers, &p.CanCreateUsers, types.ActionCreateUsers},
+		{j.CanCreateProjects, &p.CanCreateProjects, types.ActionCreateProjects},
+		{j.CanCreateTranslations, &p.CanCreateTranslations, types.ActionCreateTranslations},
+		{j.CanCreateLocales, &p.CanCreateLocales, types.ActionCreateLocales},
+		{j.CanUpdateOrganization, &p.CanUpdateOrganization, types.ActionUpdateOrganization},
+		{j.CanUpdateUsers, &p.CanUpdateUsers, types.ActionUpdateUsers},
+		{j.CanUpdateProjects, &p.CanUpdateProjects, types.ActionUpdateProjects},
+		{j.CanManageSnapshots, &p.CanManageSnapshots, types.ActionManageSnapshots},
+		{j.CanUpdateTranslations, &p.CanUpdateTranslations, types.ActionUpdateTranslations},
+		{j.CanUpdateLocales, &p.CanUpdateLocales, types.ActionCreateLocales},
+		{j.CanReviewTranslations, &p.CanReviewTranslations, types.ActionReviewTranslationValues},
+	} {
+		if f.input != nil {
+			*f.target = *f.input
+		}
+		if *f.target {
+			if err := AuthorizeGrant(db, session, f.action, types.RoleAssignment{}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// UpdateUser changes the permissions of a user within the organization, and deactivates or reactivates it.
+// The sessions of deactivated users are revoked.
+func UpdateUser(db UserStorage, sessions UserSessionClearer) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		var j models.UpdateUserInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		u, err := otherUserInOrganization(db, session, GetParams(r).ByName("id"))
+		if err != nil {
+			return nil, err
+		}
+		permissions := u.Permissions()
+		if err := applyPermissions(db, session, &permissions, j.Permissions); err != nil {
+			return nil, err
+		}
+		payload := types.UpdateUserPayload{Active: j.Active, Permissions: &permissions}
+		payload.UpdatedBy = session.User.ID
+		user, err := db.UpdateUser(u.ID, payload)
+		if err != nil {
+			if errors.Is(err, bboltStorage.ErrNoFieldsChanged) {
+				return u, nil
+			}
+			return nil, ErrApiDatabase("User", err)
+		}
+		if !user.Active {
+			err = sessions.ClearAllSessionsForUser(user.ID)
+		} else {
+			err = sessions.UpdateAllSessionsForUser(user.ID, user)
+		}
+		if err != nil {
+			return nil, ErrApiDatabase("Session", err)
+		}
+		return user, nil
+	}
+}
+
+// PasswordResetResponse is the user, with the temporary password that replaced its password.
+//
+// swagger:model PasswordResetResponse
+type PasswordResetResponse struct {
+	User types.User `json:"user"`
+	// The user must change it on the next login. It is only shown once
+	TemporaryPassword string `json:"temporary_password"`
+}
+
+// ResetUserPassword replaces the password of a user with a temporary password, which the user must change on the next login.
+// The sessions of the user are revoked.
+func ResetUserPassword(db UserStorage, pwKeeper PasswordKeeper, sessions UserSessionClearer) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		u, err := otherUserInOrganization(db, session, GetParams(r).ByName("id"))
+		if err != nil {
+			return nil, err
+		}
+		if u.Store != types.UserStoreLocal {
+			return nil, ErrApiInputValidation("The password of the user is handled by its identity-provider", "User")
+		}
+		if u.Invite != nil {
+			return nil, ErrApiInputValidation("The user has not accepted the invite yet. Create a new invite instead", "User")
+		}
+		password, err := utils.GenerateSecret(9)
+		if err != nil {
+			return nil, ErrApiInternalError("Failed to create a password", "Password", err)
+		}
+		hashed, err := pwKeeper.Hash(password)
+		if err != nil {
+			return nil, ErrApiInternalError("Failure in password-creator", "Password", err)
+		}
+		temporary := true
+		payload := types.UpdateUserPayload{PW: &hashed, TemporaryPassword: &temporary}
+		payload.UpdatedBy = session.User.ID
+		user, err := db.UpdateUser(u.ID, payload)
+		if err != nil {
+			return nil, ErrApiDatabase("User", err)
+		}
+		if err := sessions.ClearAllSessionsForUser(user.ID); err != nil {
+			return nil, ErrApiDatabase("Session", err)
+		}
+		rc.L.Info().Str("userID", user.ID).Str("byUserID", session.User.ID).Msg("Password was reset for user")
+		return PasswordResetResponse{user, password}, nil
+	}
+}
+
+type UserRemover interface {
+	UserStorage
+	SoftDeleteUser(id string, byUser string, deleteDate *time.Time) (types.User, error)
+	FindAPIKeys(max int, filter ...types.APIKey) (map[string]types.APIKey, error)
+	SoftDeleteAPIKey(id string, byUser string, deleteDate *time.Time) (types.APIKey, error)
+}
+
+// RemoveUser removes a user from the organization. The user is deactivated first, so that it cannot log in,
+// and then its sessions and api-keys are revoked. The removal appears in the audit-log.
+func RemoveUser(db UserRemover, sessions UserSessionClearer) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		u, err := otherUserInOrganization(db, session, GetParams(r).ByName("id"))
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		user, err := db.SoftDeleteUser(u.ID, session.User.ID, &now)
+		if err != nil {
+			return nil, ErrApiDatabase("User", err)
+		}
+		if err := sessions.ClearAllSessionsForUser(user.ID); err != nil {
+			return nil, ErrApiDatabase("Session", err)
+		}
+		f := types.APIKey{}
+		f.CreatedBy = user.ID
+		keys, err := db.FindAPIKeys(0, f)
+		if err != nil {
+			return nil, ErrApiDatabase("APIKey", err)
+		}
+		revoked := 0
+		for _, k := range keys {
+			if k.Deleted != nil {
+				continue
+			}
+			if _, err := db.SoftDeleteAPIKey(k.ID, session.User.ID, &now); err != nil {
+				return nil, ErrApiDatabase("APIKey", err)
+			}
+			revoked++
+		}
+		rc.L.Info().Str("userID", user.ID).Str("byUserID", session.User.ID).Int("revokedAPIKeys", revoked).Msg("User was removed from the organization")
+		return user, nil
+	}
+}
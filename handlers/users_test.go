@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/julienschmidt/httprouter"
+	"github.com/runar-rkmedia/go-common/logger"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/localuser"
+	"github.com/runar-rkmedia/skiver/models"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+type mockUserSessions struct {
+	cleared []string
+	updated []string
+}
+
+func (m *mockUserSessions) ClearAllSessionsForUser(userId string) error {
+	m.cleared = append(m.cleared, userId)
+	return nil
+}
+func (m *mockUserSessions) UpdateAllSessionsForUser(userId string, user types.User) error {
+	m.updated = append(m.updated, userId)
+	return nil
+}
+
+func TestUserManagement(t *testing.T) {
+	ctx := &requestContext.Context{
+		L: logger.GetLoggerWithLevel("test", "fatal"),
+		StructValidater: func(m interface{}) error {
+			return models.Validate(m.(models.Validator))
+		},
+	}
+	bb := bboltStorage.NewMockDB(t)
+	ctx.DB = bb
+	pw := localuser.NewPwHasher([]byte("salt"))
+	org, err := bb.CreateOrganization(types.Organization{Title: "acme", CreatedBy: "test"})
+	testza.AssertNoError(t, err)
+	create := func(u types.User) types.User {
+		u.Active = true
+		u.Store = types.UserStoreLocal
+		u.PW, _ = pw.Hash(u.UserName)
+		u.CreatedBy = "test"
+		u.OrganizationID = org.ID
+		user, err := bb.CreateUser(u)
+		testza.AssertNoError(t, err)
+		return user
+	}
+	admin := create(types.User{UserName: "admin", CanUpdateUsers: true, CanCreateUsers: true})
+	jane := create(types.User{UserName: "jane"})
+	adminSession := &types.Session{User: admin, Organization: org}
+	sessions := &mockUserSessions{}
+
+	serve := func(handler AppHandler, params httprouter.Params, body interface{}, session *types.Session) (*httptest.ResponseRecorder, interface{}) {
+		b, _ := json.Marshal(body)
+		rw := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(b)))
+		r.Header.Set("Content-Type", "application/json")
+		r = r.WithContext(context.WithValue(r.Context(), httprouter.ParamsKey, params))
+		if session != nil {
+			r = setValue(r, ContextKeySession, *session)
+		}
+		rc := ctx.NewReqContext(rw, r)
+		out, err := handler(rc, rw, r)
+		if err != nil {
+			rc.WriteErr(err, "")
+		}
+		return rw, out
+	}
+	id := func(id string) httprouter.Params {
+		return httprouter.Params{{Key: "id", Value: id}}
+	}
+	getUser := func(id string) types.User {
+		u, err := bb.GetUser(id)
+		testza.AssertNoError(t, err)
+		return *u
+	}
+
+	// Permissions
+	rw, _ := serve(UpdateUser(bb, sessions), id(jane.ID), map[string]interface{}{"permissions": map[string]bool{"can_create_organization": true}}, adminSession)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code, "Users cannot grant permissions that they do not have")
+	superAdmin := create(types.User{UserName: "root", CanCreateOrganization: true})
+	rw, _ = serve(UpdateUser(bb, sessions), id(superAdmin.ID), map[string]interface{}{"active": false}, adminSession)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code, "Users that can create organizations can only be managed by users that can too")
+	rw, _ = serve(UpdateUser(bb, sessions), id(admin.ID), map[string]interface{}{"active": false}, adminSession)
+	testza.AssertEqual(t, http.StatusForbidden, rw.Code, "Users cannot manage themselves")
+	rw, _ = serve(UpdateUser(bb, sessions), id(jane.ID), map[string]interface{}{"permissions": map[string]bool{"can_update_users": true}}, adminSession)
+	testza.AssertEqual(t, http.StatusOK, rw.Code)
+	testza.AssertTrue(t, getUser(jane.ID).CanUpdateUsers)
+	testza.AssertEqual(t, []string{jane.ID}, sessions.updated, "The permissions should apply to existing sessions")
+
+	// Deactivation
+	rw, _ = serve(UpdateUser(bb, sessions), id(jane.ID), map[string]interface{}{"active": false}, adminSession)
+	testza.AssertEqual(t, http.StatusOK, rw.Code)
+	testza.AssertFalse(t, getUser(jane.ID).Active)
+	testza.AssertEqual(t, []string{jane.ID}, sessions.cleared, "The sessions of deactivated users are revoked")
+	rw, _ = serve(UpdateUser(bb, sessions), id(jane.ID), map[string]interface{}{"active": true}, adminSession)
+	testza.AssertEqual(t, http.StatusOK, rw.Code)
+	testza.AssertTrue(t, getUser(jane.ID).Active)
+
+	// Password-reset
+	rw, out := serve(ResetUserPassword(bb, &pw, sessions), id(jane.ID), nil, adminSession)
+	testza.AssertEqual(t, http.StatusOK, rw.Code)
+	reset := out.(PasswordResetResponse)
+	testza.AssertTrue(t, getUser(jane.ID).TemporaryPassword)
+	ok, err := pw.Verify(getUser(jane.ID).PW, reset.TemporaryPassword)
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, ok)
+	ok, _ = pw.Verify(getUser(jane.ID).PW, "jane")
+	testza.AssertFalse(t, ok, "The old password should no longer work")
+
+	// Roles
+	project := func(organizationID, shortName string) types.Project {
+		p := types.Project{Title: shortName, ShortName: shortName}
+		p.CreatedBy = "test"
+		p.OrganizationID = organizationID
+		project, err := bb.CreateProject(p)
+		testza.AssertNoError(t, err)
+		return project
+	}
+	web := project(org.ID, "web")
+	app := project(org.ID, "app")
+	otherOrg := project("org-other", "theirs")
+	roles := func(ras ...map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"roles": ras}
+	}
+	orgAdmin := map[string]interface{}{"role": "admin"}
+	janeSession := &types.Session{User: getUser(jane.ID), Organization: org}
+	bob := create(types.User{UserName: "bob"})
+	rw, _ = serve(SetUserRoles(bb, sessions), id(jane.ID), roles(orgAdmin), janeSession)
+	testza.AssertEqual(t, http.StatusForbidden, rw.Code, "Users cannot change their own roles")
+	rw, _ = serve(SetUserRoles(bb, sessions), id(bob.ID), roles(orgAdmin), janeSession)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code, "Users that can manage users cannot grant the admin-role")
+	testza.AssertLen(t, getUser(bob.ID).Roles, 0)
+
+	webAdmin := create(types.User{UserName: "web-admin", CanUpdateUsers: true, Roles: []types.RoleAssignment{{Role: types.RoleAdmin, ProjectID: web.ID}}})
+	webAdminSession := &types.Session{User: webAdmin, Organization: org}
+	rw, _ = serve(SetUserRoles(bb, sessions), id(bob.ID), roles(map[string]interface{}{"role": "developer", "project_id": web.ID}), webAdminSession)
+	testza.AssertEqual(t, http.StatusOK, rw.Code, "Project-admins can grant roles within their project")
+	testza.AssertEqual(t, []types.RoleAssignment{{Role: types.RoleDeveloper, ProjectID: web.ID}}, getUser(bob.ID).Roles)
+	rw, _ = serve(SetUserRoles(bb, sessions), id(bob.ID), roles(map[string]interface{}{"role": "developer", "project_id": app.ID}), webAdminSession)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code, "Project-admins cannot grant roles in other projects")
+	rw, _ = serve(SetUserRoles(bb, sessions), id(bob.ID), roles(map[string]interface{}{"role": "developer"}), webAdminSession)
+	testza.AssertEqual(t, http.StatusForbidden, rw.Code, "Project-admins cannot grant roles for the whole organization")
+	rw, _ = serve(SetUserRoles(bb, sessions), id(bob.ID), roles(orgAdmin), webAdminSession)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code)
+	rw, _ = serve(UpdateUser(bb, sessions), id(bob.ID), map[string]interface{}{"permissions": map[string]bool{"can_update_projects": true}}, webAdminSession)
+	testza.AssertEqual(t, http.StatusForbidden, rw.Code, "Project-admins cannot grant permissions, which apply to the whole organization")
+	testza.AssertFalse(t, getUser(bob.ID).CanUpdateProjects)
+	rw, _ = serve(SetUserRoles(bb, sessions), id(bob.ID), roles(map[string]interface{}{"role": "developer", "project_id": otherOrg.ID}), webAdminSession)
+	testza.AssertEqual(t, http.StatusNotFound, rw.Code, "Projects in other organizations cannot be used")
+
+	owner := create(types.User{UserName: "owner", Roles: []types.RoleAssignment{{Role: types.RoleAdmin}}})
+	ownerSession := &types.Session{User: owner, Organization: org}
+	rw, _ = serve(SetUserRoles(bb, sessions), id(bob.ID), roles(orgAdmin), ownerSession)
+	testza.AssertEqual(t, http.StatusOK, rw.Code, "Organization-admins can grant the admin-role")
+	testza.AssertEqual(t, []types.RoleAssignment{{Role: types.RoleAdmin}}, getUser(bob.ID).Roles)
+	rw, _ = serve(SetUserRoles(bb, sessions), id(bob.ID), roles(map[string]interface{}{"role": "developer", "project_id": web.ID}), webAdminSession)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code, "Users cannot strip the roles of users with more access than they have")
+	testza.AssertEqual(t, []types.RoleAssignment{{Role: types.RoleAdmin}}, getUser(bob.ID).Roles)
+	rw, _ = serve(UpdateUser(bb, sessions), id(bob.ID), map[string]interface{}{"active": false}, adminSession)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code, "Users cannot deactivate users with more access than they have")
+	testza.AssertTrue(t, getUser(bob.ID).Active)
+	rw, _ = serve(ResetUserPassword(bb, &pw, sessions), id(bob.ID), nil, adminSession)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code, "Users cannot reset the password of users with more access than they have")
+	rw, _ = serve(RemoveUser(bb, sessions), id(bob.ID), nil, adminSession)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code, "Users cannot remove users with more access than they have")
+	testza.AssertNil(t, getUser(bob.ID).Deleted)
+
+	// Invites
+	rw, _ = serve(InviteUser(bb), nil, map[string]interface{}{"username": "jane"}, adminSession)
+	testza.AssertEqual(t, http.StatusConflict, rw.Code)
+	rw, _ = serve(InviteUser(bb), nil, map[string]interface{}{"username": "joe"}, adminSession)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code, "Users cannot invite others with default permissions that they do not have")
+	rw, out = serve(InviteUser(bb), nil, map[string]interface{}{"username": "joe", "permissions": map[string]bool{"can_review_translations": false}}, ownerSession)
+	testza.AssertEqual(t, http.StatusOK, rw.Code)
+	invite := out.(CreatedInvite)
+	testza.AssertFalse(t, invite.User.Active, "Invited users are inactive until they accept")
+	testza.AssertFalse(t, invite.User.CanReviewTranslations)
+	testza.AssertTrue(t, invite.User.CanCreateTranslations)
+
+	token := func(token string) httprouter.Params {
+		return httprouter.Params{{Key: "token", Value: token}}
+	}
+	rw, out = serve(GetInvite(bb), token(invite.Token), nil, nil)
+	testza.AssertEqual(t, http.StatusOK, rw.Code)
+	testza.AssertEqual(t, "joe", out.(InviteInfo).UserName)
+	rw, _ = serve(GetInvite(bb), token(invite.User.ID+".forged"), nil, nil)
+	testza.AssertEqual(t, http.StatusNotFound, rw.Code)
+
+	rw, out = serve(RenewInvite(bb), id(invite.User.ID), nil, ownerSession)
+	testza.AssertEqual(t, http.StatusOK, rw.Code)
+	renewed := out.(CreatedInvite)
+	rw, _ = serve(AcceptInvite(bb, &pw), token(invite.Token), map[string]string{"password": "secret"}, nil)
+	testza.AssertEqual(t, http.StatusNotFound, rw.Code, "Renewing the invite should invalidate the previous link")
+	rw, _ = serve(AcceptInvite(bb, &pw), token(renewed.Token), map[string]string{"password": "secret"}, nil)
+	testza.AssertEqual(t, http.StatusOK, rw.Code)
+	joe := getUser(invite.User.ID)
+	testza.AssertTrue(t, joe.Active)
+	testza.AssertNil(t, joe.Invite)
+	ok, _ = pw.Verify(joe.PW, "secret")
+	testza.AssertTrue(t, ok)
+	rw, _ = serve(AcceptInvite(bb, &pw), token(renewed.Token), map[string]string{"password": "other"}, nil)
+	testza.AssertEqual(t, http.StatusNotFound, rw.Code, "Invites can only be accepted once")
+	rw, _ = serve(RenewInvite(bb), id(joe.ID), nil, ownerSession)
+	testza.AssertEqual(t, http.StatusBadRequest, rw.Code)
+
+	// Removal
+	k := types.APIKey{Name: "ci", Hash: []byte("hash"), Scopes: []types.APIKeyScope{types.APIKeyScopeExport}}
+	k.CreatedBy = joe.ID
+	k.OrganizationID = org.ID
+	key, err := bb.CreateAPIKey(k)
+	testza.AssertNoError(t, err)
+	rw, _ = serve(RemoveUser(bb, sessions), id(admin.ID), nil, adminSession)
+	testza.AssertEqual(t, http.StatusForbidden, rw.Code, "Users cannot remove themselves")
+	rw, _ = serve(RemoveUser(bb, sessions), id(superAdmin.ID), nil, adminSession)
+	testza.AssertEqual(t, http.StatusUnauthorized, rw.Code)
+	sessions.cleared = nil
+	rw, _ = serve(RemoveUser(bb, sessions), id(joe.ID), nil, ownerSession)
+	testza.AssertEqual(t, http.StatusOK, rw.Code)
+	joe = getUser(joe.ID)
+	testza.AssertNotNil(t, joe.Deleted)
+	testza.AssertFalse(t, joe.Active, "Removed users cannot log in")
+	testza.AssertEqual(t, []string{joe.ID}, sessions.cleared, "The sessions of removed users are revoked")
+	revoked, err := bb.GetAPIKey(key.ID)
+	testza.AssertNoError(t, err)
+	testza.AssertNotNil(t, revoked.Deleted, "The api-keys of removed users are revoked")
+	rw, _ = serve(RemoveUser(bb, sessions), id(joe.ID), nil, ownerSession)
+	testza.AssertEqual(t, http.StatusNotFound, rw.Code)
+	rw, _ = serve(UpdateUser(bb, sessions), id(joe.ID), map[string]interface{}{"active": true}, adminSession)
+	testza.AssertEqual(t, http.StatusNotFound, rw.Code, "Removed users cannot be reactivated")
+	rw, out = serve(ListUsers(bb, false), nil, nil, adminSession)
+	testza.AssertEqual(t, http.StatusOK, rw.Code)
+	_, listed := out.(map[string]types.User)[joe.ID]
+	testza.AssertFalse(t, listed, "Removed users are not listed")
+	var audited bool
+	err = bb.IterateAuditEntries(types.AuditFilter{Entity: joe.ID}, func(entry types.AuditEntry) bool {
+		for _, c := range entry.Changes {
+			audited = audited || (entry.UserID == owner.ID && strings.Join(c.Path, ".") == "entity.deleted")
+		}
+		return true
+	})
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, audited, "The removal appears in the audit-log")
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/models"
+	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
+	"github.com/runar-rkmedia/skiver/webhooks"
+)
+
+// CreatedWebhook is the webhook, with the secret used for signing.
+// The secret is only returned when the webhook is created.
+// swagger:model CreatedWebhook
+type CreatedWebhook struct {
+	types.Webhook
+	// Used to verify the signature of the deliveries
+	Secret string `json:"secret"`
+}
+
+type WebhookReplayer interface {
+	Replay(deliveryID string) (types.WebhookDelivery, error)
+}
+
+// GetWebhooks returns all the webhooks within the organization
+func GetWebhooks() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		filter := types.Webhook{}
+		filter.OrganizationID = session.Organization.ID
+		hooks, err := rc.Context.DB.FindWebhooks(0, filter)
+		if err != nil {
+			return nil, ErrApiDatabase("Webhook", err)
+		}
+		return hooks, nil
+	}
+}
+
+func CreateWebhook() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		var j models.WebhookInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		w, err := webhookFromInput(j)
+		if err != nil {
+			return nil, err
+		}
+		if w.Secret == "" {
+			w.Secret, err = utils.GenerateSecret(32)
+			if err != nil {
+				return nil, NewApiErr(err, http.StatusInternalServerError, string(requestContext.CodeErrWebhook))
+			}
+		}
+		w.CreatedBy = session.User.ID
+		w.OrganizationID = session.Organization.ID
+		w, err = rc.Context.DB.CreateWebhook(w)
+		if err != nil {
+			return nil, ErrApiDatabase("Webhook", err)
+		}
+		return CreatedWebhook{w, w.Secret}, nil
+	}
+}
+
+func UpdateWebhook() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		id := GetParams(r).ByName("id")
+		if id == "" {
+			return nil, ErrApiMissingArgument("id")
+		}
+		var j models.WebhookInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		db := rc.Context.DB
+		if _, err := organizationWebhook(db, id, session.Organization.ID); err != nil {
+			return nil, err
+		}
+		w, err := webhookFromInput(j)
+		if err != nil {
+			return nil, err
+		}
+		w.UpdatedBy = session.User.ID
+		updated, err := db.UpdateWebhook(id, w)
+		if err != nil {
+			return nil, ErrApiDatabase("Webhook", err)
+		}
+		return updated, nil
+	}
+}
+
+func DeleteWebhook() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		id := GetParams(r).ByName("id")
+		if id == "" {
+			return nil, ErrApiMissingArgument("id")
+		}
+		var j models.DeleteInput
+		if err := rc.ValidateBody(&j, false); err != nil {
+			return nil, err
+		}
+		db := rc.Context.DB
+		if _, err := organizationWebhook(db, id, session.Organization.ID); err != nil {
+			return nil, err
+		}
+		var deleteTime *time.Time
+		if !j.Undelete {
+			t := time.Now()
+			deleteTime = &t
+		}
+		w, err := db.SoftDeleteWebhook(id, session.User.ID, deleteTime)
+		if err != nil {
+			return nil, NewApiErr(err, http.StatusBadRequest, string(requestContext.CodeErrWebhook))
+		}
+		return w, nil
+	}
+}
+
+// GetWebhookDeliveries returns the delivery-log of the webhook, newest first
+func GetWebhookDeliveries() AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		id := GetParams(r).ByName("id")
+		if id == "" {
+			return nil, ErrApiMissingArgument("id")
+		}
+		limit := 50
+		if s := r.URL.Query().Get("limit"); s != "" {
+			limit, err = strconv.Atoi(s)
+			if err != nil || limit < 0 {
+				return nil, ErrApiInputValidation("The limit must be a positive number", "WebhookDelivery")
+			}
+		}
+		db := rc.Context.DB
+		if _, err := organizationWebhook(db, id, session.Organization.ID); err != nil {
+			return nil, err
+		}
+		deliveries, err := db.FindWebhookDeliveries(id, limit)
+		if err != nil {
+			return nil, ErrApiDatabase("WebhookDelivery", err)
+		}
+		return deliveries, nil
+	}
+}
+
+// ReplayWebhookDelivery sends the payload of an earlier delivery again, as a new delivery
+func ReplayWebhookDelivery(replayer WebhookReplayer) AppHandler {
+	return func(rc requestContext.ReqContext, rw http.ResponseWriter, r *http.Request) (interface{}, error) {
+		session, err := GetRequestSession(r)
+		if err != nil {
+			return nil, err
+		}
+		params := GetParams(r)
+		id := params.ByName("id")
+		deliveryID := params.ByName("delivery")
+		if id == "" || deliveryID == "" {
+			return nil, ErrApiMissingArgument("id")
+		}
+		db := rc.Context.DB
+		if _, err := organizationWebhook(db, id, session.Organization.ID); err != nil {
+			return nil, err
+		}
+		existing, err := db.GetWebhookDelivery(deliveryID)
+		if err != nil {
+			return nil, ErrApiDatabase("WebhookDelivery", err)
+		}
+		if existing == nil || existing.WebhookID != id {
+			return nil, ErrApiNotFound("WebhookDelivery", deliveryID)
+		}
+		delivery, err := replayer.Replay(deliveryID)
+		if err != nil {
+			if errors.Is(err, webhooks.ErrNotFound) {
+				return nil, ErrApiNotFound("WebhookDelivery", deliveryID)
+			}
+			return nil, NewApiErr(err, http.StatusInternalServerError, string(requestContext.CodeErrWebhook))
+		}
+		return delivery, nil
+	}
+}
+
+func webhookFromInput(j models.WebhookInput) (types.Webhook, error) {
+	w := types.Webhook{
+		URL:      strings.TrimSpace(*j.URL),
+		Secret:   j.Secret,
+		Disabled: j.Disabled,
+	}
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return w, ErrApiInputValidation("The url must be an absolute http- or https-url", "Webhook")
+	}
+	for _, e := range j.Events {
+		e = strings.TrimSpace(e)
+		if !webhooks.ValidEvent(e) {
+			return w, ErrApiInputValidation("Unknown event: "+e, "Webhook")
+		}
+		w.Events = append(w.Events, e)
+	}
+	return w, nil
+}
+
+// Returns the webhook, if it belongs to the organization
+func organizationWebhook(db types.Storage, id, organizationID string) (*types.Webhook, error) {
+	w, err := db.GetWebhook(id)
+	if err != nil {
+		return nil, ErrApiDatabase("Webhook", err)
+	}
+	if w == nil || w.OrganizationID != organizationID {
+		return nil, ErrApiNotFound("Webhook", id)
+	}
+	return w, nil
+}
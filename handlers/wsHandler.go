@@ -3,17 +3,22 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"reflect"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/runar-rkmedia/go-common/logger"
 	"github.com/runar-rkmedia/go-common/utils"
+	"github.com/runar-rkmedia/skiver/types"
 )
 
 type WsOptions struct {
 	PingInterval,
 	WriteTimeout time.Duration
+	// Used to find the project of changes to categories, translations and translation-values,
+	// so that clients can subscribe to projects.
+	DB types.Storage
 }
 
 type Msg struct {
@@ -22,11 +27,78 @@ type Msg struct {
 	Contents interface{} `json:"contents,omitempty"`
 }
 
+// WsSubscription limits the changes that are sent to a websocket-client.
+// Empty lists match everything within the organization.
+// swagger:model WsSubscription
+type WsSubscription struct {
+	// Only send changes within these projects, by id or short-name.
+	// Changes that do not belong to a project, like locales, are not sent.
+	Projects []string `json:"projects,omitempty"`
+	// Only send changes to these kinds, like translation or snapshot
+	Kinds []string `json:"kinds,omitempty"`
+}
+
+// WsClientMsg is a message sent from the websocket-client
+// swagger:model WsClientMsg
+type WsClientMsg struct {
+	// Enum: [subscribe presence]
+	Type string `json:"type"`
+	WsSubscription
+	// For presence-messages, the translation that the user is editing
+	TranslationID string `json:"translation_id,omitempty"`
+	// For presence-messages, the locale of the value that the user is editing, if any
+	LocaleID string `json:"locale_id,omitempty"`
+	// For presence-messages, set to false when the user stops editing
+	Editing bool `json:"editing,omitempty"`
+}
+
+// WsPresence tells other users within the organization that a user is editing a translation,
+// so that they can be warned before making conflicting changes.
+// It is sent with the kind presence, and the variant editing or stopped.
+// swagger:model WsPresence
+type WsPresence struct {
+	UserID        string    `json:"user_id"`
+	UserName      string    `json:"username"`
+	TranslationID string    `json:"translation_id"`
+	Key           string    `json:"key,omitempty"`
+	LocaleID      string    `json:"locale_id,omitempty"`
+	ProjectID     string    `json:"project_id,omitempty"`
+	Editing       bool      `json:"editing"`
+	At            time.Time `json:"at"`
+}
+
+// Matches returns true if the change should be sent to the client
+func (s WsSubscription) Matches(kind, projectID string) bool {
+	if len(s.Kinds) > 0 && !contains(s.Kinds, kind) {
+		return false
+	}
+	if len(s.Projects) > 0 && (projectID == "" || !contains(s.Projects, projectID)) {
+		return false
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Used to authenticate the websocket-clients
+type SessionGetter interface {
+	GetSession(token string) (s types.Session, err error)
+}
+
 func NewPubSubChannel() PubSub {
 	return PubSub{make(chan Msg)}
 }
 
-func NewWsHandler(l logger.AppLogger, sendChannel chan Msg, options WsOptions) http.HandlerFunc {
+// NewWsHandler sends the changes from the channel to the connected websocket-clients.
+// Clients must authenticate with a session-token, and only receive changes within their own organization.
+func NewWsHandler(l logger.AppLogger, sendChannel chan Msg, sessions SessionGetter, options WsOptions) http.HandlerFunc {
 	if options.WriteTimeout == 0 {
 		options.WriteTimeout = 30 * time.Second
 	}
@@ -42,6 +114,7 @@ func NewWsHandler(l logger.AppLogger, sendChannel chan Msg, options WsOptions) h
 		map[string]*Client{},
 		&options,
 		l,
+		sessions,
 		sync.RWMutex{},
 	}
 	go clients.Writer(sendChannel)
@@ -49,8 +122,22 @@ func NewWsHandler(l logger.AppLogger, sendChannel chan Msg, options WsOptions) h
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		debug := l.HasDebug()
+		token, err := tokenFromRequest(r)
+		if err != nil || token == "" {
+			http.Error(w, "A session-token is required", http.StatusUnauthorized)
+			return
+		}
+		session, err := sessions.GetSession(token)
+		if err != nil {
+			http.Error(w, "The session is not valid", http.StatusUnauthorized)
+			return
+		}
+		if session.APIKey != nil {
+			http.Error(w, "Api-keys cannot be used with the websocket", http.StatusForbidden)
+			return
+		}
 		if debug {
-			l.Debug().Msg("Client upgrading")
+			l.Debug().Str("user", session.User.ID).Msg("Client upgrading")
 		}
 		ws, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
@@ -61,19 +148,28 @@ func NewWsHandler(l logger.AppLogger, sendChannel chan Msg, options WsOptions) h
 			l.Debug().Msg("Client subscribing")
 		}
 
-		clients.Subscribe(&Client{
+		c := &Client{
 			conn:       ws,
 			options:    &options,
 			l:          l,
 			clientLIst: &clients,
-		})
+			token:      token,
+			session:    session,
+		}
+		clients.Subscribe(c)
+		// Let the new client know who is currently editing
+		for _, p := range clients.presences(session.Organization.ID, c.id) {
+			c.writeJSON(presenceMsg(p))
+		}
+		go c.reader()
 	}
 }
 
 type ClientList struct {
-	clients map[string]*Client
-	options *WsOptions
-	l       logger.AppLogger
+	clients  map[string]*Client
+	options  *WsOptions
+	l        logger.AppLogger
+	sessions SessionGetter
 	sync.RWMutex
 }
 
@@ -98,8 +194,162 @@ func (cl *ClientList) Unsubscribe(id string) {
 		cl.l.Debug().Str("ID", id).Msg("Client unsubscribed")
 	}
 	cl.Lock()
+	c, ok := cl.clients[id]
 	delete(cl.clients, id)
 	cl.Unlock()
+	if !ok {
+		return
+	}
+	// Clients that disconnect while editing have stopped editing
+	c.Lock()
+	p := c.presence
+	c.presence = nil
+	c.Unlock()
+	if p != nil {
+		p.Editing = false
+		p.At = time.Now()
+		go cl.send(presenceMsg(*p), msgScope{organizationID: c.session.Organization.ID, projectID: p.ProjectID}, id)
+	}
+}
+
+// Returns the presences of the clients within the organization, except for the client with the given id
+func (cl *ClientList) presences(organizationID, except string) []WsPresence {
+	cl.RLock()
+	defer cl.RUnlock()
+	var presences []WsPresence
+	for id, c := range cl.clients {
+		if id == except || c.session.Organization.ID != organizationID {
+			continue
+		}
+		c.Lock()
+		if c.presence != nil {
+			presences = append(presences, *c.presence)
+		}
+		c.Unlock()
+	}
+	return presences
+}
+
+func presenceMsg(p WsPresence) Msg {
+	variant := "editing"
+	if !p.Editing {
+		variant = "stopped"
+	}
+	return Msg{Kind: "presence", Variant: variant, Contents: p}
+}
+
+// The organization and project that a message belongs to
+type msgScope struct {
+	// Messages without contents, like notifications of changes to the frontend during development,
+	// are sent to all clients.
+	global         bool
+	organizationID string
+	projectID      string
+	// Set for changes to entities that are not within a project, and are only available to some users,
+	// like users and webhooks. The change is only sent to clients that can perform the action
+	// for the whole organization, and to the owner, if any.
+	action  types.Action
+	ownerID string
+}
+
+// Returns the scope of the message, or false if the message should not be sent to any client.
+func (cl *ClientList) scopeOf(m Msg) (msgScope, bool) {
+	if m.Contents == nil {
+		return msgScope{global: true}, true
+	}
+	contents := dereference(m.Contents)
+	e, ok := contents.(types.EntityGetter)
+	if !ok {
+		return msgScope{}, false
+	}
+	entity := e.GetEntity()
+	scope := msgScope{organizationID: entity.OrganizationID}
+	if m.Kind == string(types.PubTypeOrganization) {
+		scope.organizationID = entity.ID
+	}
+	if scope.organizationID == "" {
+		return scope, false
+	}
+	switch types.PubType(m.Kind) {
+	case types.PubTypeUser:
+		scope.action = types.ActionUpdateUsers
+		scope.ownerID = entity.ID
+	case types.PubTypeAPIKey:
+		scope.action = types.ActionUpdateOrganization
+		scope.ownerID = entity.CreatedBy
+	case types.PubTypeWebhook:
+		scope.action = types.ActionUpdateOrganization
+	}
+	scope.projectID = projectIDOf(cl.options.DB, contents)
+	if scope.projectID == "" && projectScoped(types.PubType(m.Kind)) {
+		// Without the project, it cannot be checked whether the clients may see the change
+		return scope, false
+	}
+	return scope, true
+}
+
+// Returns true if the entities of the kind belong to a project
+func projectScoped(kind types.PubType) bool {
+	switch kind {
+	case types.PubTypeProject,
+		types.PubTypeSnapshot,
+		types.PubTypeMissingTranslation,
+		types.PubTypeCategory,
+		types.PubTypeTranslation,
+		types.PubTypeTranslationValue:
+		return true
+	}
+	return false
+}
+
+// Returns the value that the contents points to, since changes may be published as pointers.
+// Nil-pointers are returned as nil.
+func dereference(contents interface{}) interface{} {
+	v := reflect.ValueOf(contents)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// Returns the id of the project that the entity belongs to, if any
+func projectIDOf(db types.Storage, contents interface{}) string {
+	categoryID := ""
+	switch t := dereference(contents).(type) {
+	case types.Project:
+		return t.ID
+	case types.ProjectSnapshot:
+		return t.Project.ID
+	case types.MissingTranslation:
+		return t.ProjectID
+	case types.Category:
+		return t.ProjectID
+	case types.Translation:
+		categoryID = t.CategoryID
+	case types.TranslationValue:
+		if db == nil {
+			return ""
+		}
+		translation, err := db.GetTranslation(t.TranslationID)
+		if err != nil || translation == nil {
+			return ""
+		}
+		categoryID = translation.CategoryID
+	}
+	if categoryID == "" || db == nil {
+		return ""
+	}
+	category, err := db.GetCategory(categoryID)
+	if err != nil || category == nil {
+		return ""
+	}
+	return category.ProjectID
 }
 
 func (cl *ClientList) Writer(ch chan Msg) {
@@ -108,44 +358,27 @@ func (cl *ClientList) Writer(ch chan Msg) {
 	for {
 		select {
 		case j := <-ch:
-			cl.RLock()
-			length := len(cl.clients)
-			if length == 0 {
+			scope, ok := cl.scopeOf(j)
+			if !ok {
 				if cl.l.HasTrace() {
-					cl.l.Trace().Msg("No clients are listening at the moment")
+					cl.l.Trace().Str("kind", j.Kind).Str("variant", j.Variant).Msg("Message does not belong to any organization")
 				}
-				cl.RUnlock()
-				continue
-			}
-			json, err := json.Marshal(j)
-			if err != nil {
-				cl.l.Error().Err(err).Msg("Failed to json-marshal message")
-				cl.RUnlock()
 				continue
 			}
-			if debug {
-				cl.l.Debug().Int("count", length).Str("kind", j.Kind).Str("variant", j.Variant).Msg("Sending message to clients")
-			}
-			wg := sync.WaitGroup{}
-			wg.Add(length)
+			cl.send(j, scope, "")
 
+		case <-ticker.C:
+			cl.RLock()
+			clients := make([]*Client, 0, len(cl.clients))
 			for _, client := range cl.clients {
-				go func(c *Client) {
-					c.write(websocket.TextMessage, json)
-					wg.Done()
-				}(client)
+				clients = append(clients, client)
 			}
 			cl.RUnlock()
-			wg.Wait()
-
-		case <-ticker.C:
-			cl.RLock()
-			length := len(cl.clients)
+			length := len(clients)
 			if length == 0 {
 				if cl.l.HasTrace() {
 					cl.l.Trace().Msg("No clients are listening at the moment")
 				}
-				cl.RUnlock()
 				continue
 			}
 			if debug {
@@ -153,42 +386,229 @@ func (cl *ClientList) Writer(ch chan Msg) {
 			}
 			wg := sync.WaitGroup{}
 			wg.Add(length)
-			for _, client := range cl.clients {
+			for _, client := range clients {
 				go func(c *Client) {
-
+					defer wg.Done()
+					// Clients whose sessions have expired, or been revoked, are disconnected.
+					if _, err := cl.sessions.GetSession(c.token); err != nil {
+						c.close()
+						return
+					}
 					c.write(websocket.PingMessage, []byte("ping"))
-					wg.Done()
 				}(client)
 			}
-			cl.RUnlock()
 			wg.Wait()
 
 		}
 	}
 }
 
+// Sends the message to all clients that accept it, except for the client with the given id
+func (cl *ClientList) send(j Msg, scope msgScope, except string) {
+	cl.RLock()
+	var targets []*Client
+	for id, client := range cl.clients {
+		if id != except && client.accepts(j.Kind, scope) {
+			targets = append(targets, client)
+		}
+	}
+	cl.RUnlock()
+	length := len(targets)
+	if length == 0 {
+		if cl.l.HasTrace() {
+			cl.l.Trace().Msg("No clients are listening at the moment")
+		}
+		return
+	}
+	json, err := json.Marshal(j)
+	if err != nil {
+		cl.l.Error().Err(err).Msg("Failed to json-marshal message")
+		return
+	}
+	if cl.l.HasDebug() {
+		cl.l.Debug().Int("count", length).Str("kind", j.Kind).Str("variant", j.Variant).Msg("Sending message to clients")
+	}
+	wg := sync.WaitGroup{}
+	wg.Add(length)
+
+	for _, client := range targets {
+		go func(c *Client) {
+			c.write(websocket.TextMessage, json)
+			wg.Done()
+		}(client)
+	}
+	wg.Wait()
+}
+
 type Client struct {
-	id         string
-	conn       *websocket.Conn
-	options    *WsOptions
-	l          logger.AppLogger
-	clientLIst *ClientList
+	id           string
+	conn         *websocket.Conn
+	options      *WsOptions
+	l            logger.AppLogger
+	clientLIst   *ClientList
+	token        string
+	session      types.Session
+	subscription WsSubscription
+	// Set while the user is editing a translation
+	presence *WsPresence
+	// Guards the subscription, the presence, and writes to the connection
+	sync.Mutex
 }
 
-func (c *Client) write(kind int, j []byte) error {
-	if err := c.conn.SetWriteDeadline(time.Now().Add(c.options.WriteTimeout)); err != nil {
-		c.l.Error().Err(err).Msg("Failed to set write deadline")
-		c.clientLIst.Unsubscribe(c.id)
-		c.conn.Close()
+func (c *Client) accepts(kind string, scope msgScope) bool {
+	if scope.global {
+		return true
+	}
+	if scope.organizationID != c.session.Organization.ID {
+		return false
+	}
+	if !c.allowed(scope) {
+		return false
+	}
+	c.Lock()
+	defer c.Unlock()
+	return c.subscription.Matches(kind, scope.projectID)
+}
+
+// Returns true if the session may see changes within the scope.
+// Changes within a project are only sent to users with a role for the project, or the whole organization,
+// and sessions from api-keys are limited to the projects of the key.
+func (c *Client) allowed(scope msgScope) bool {
+	s := c.session
+	if scope.projectID != "" {
+		if s.APIKey != nil && !s.APIKey.AllowsProject(scope.projectID) {
+			return false
+		}
+		return s.User.CanView(scope.projectID)
+	}
+	if scope.action == "" {
+		return true
+	}
+	if scope.ownerID != "" && scope.ownerID == s.User.ID {
+		return true
+	}
+	if s.APIKey != nil && !s.APIKey.AllowsAction(scope.action) {
+		return false
+	}
+	return s.User.Can(scope.action, types.AccessScope{})
+}
+
+// Reads the messages from the client, until the connection is closed
+func (c *Client) reader() {
+	defer c.close()
+	for {
+		_, b, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				c.l.Debug().Err(err).Msg("Failed to read message from the client")
+			}
+			return
+		}
+		var m WsClientMsg
+		if err := json.Unmarshal(b, &m); err != nil {
+			c.writeJSON(Msg{Kind: "error", Contents: "The message must be json"})
+			continue
+		}
+		switch m.Type {
+		case "subscribe":
+			sub, err := c.resolveSubscription(m.WsSubscription)
+			if err != nil {
+				c.writeJSON(Msg{Kind: "error", Contents: err.Error()})
+				continue
+			}
+			c.Lock()
+			c.subscription = sub
+			c.Unlock()
+			c.writeJSON(Msg{Kind: "subscribed", Contents: sub})
+		case "presence":
+			p, err := c.resolvePresence(m)
+			if err != nil {
+				c.writeJSON(Msg{Kind: "error", Contents: err.Error()})
+				continue
+			}
+			c.Lock()
+			if p.Editing {
+				c.presence = &p
+			} else {
+				c.presence = nil
+			}
+			c.Unlock()
+			c.clientLIst.send(presenceMsg(p), msgScope{organizationID: c.session.Organization.ID, projectID: p.ProjectID}, c.id)
+		default:
+			c.writeJSON(Msg{Kind: "error", Contents: "Unknown message-type: " + m.Type})
+		}
+	}
+}
+
+// Resolves the projects of the subscription to ids, and checks that they belong to the client's organization.
+func (c *Client) resolveSubscription(sub WsSubscription) (WsSubscription, error) {
+	db := c.options.DB
+	if db == nil || len(sub.Projects) == 0 {
+		return sub, nil
+	}
+	ids := make([]string, len(sub.Projects))
+	for i, key := range sub.Projects {
+		p, err := db.GetProjectByIDOrShortName(key)
+		if err != nil || p == nil || p.OrganizationID != c.session.Organization.ID {
+			return sub, ErrApiNotFound("Project", key)
+		}
+		ids[i] = p.ID
+	}
+	sub.Projects = ids
+	return sub, nil
+}
+
+// Creates the presence of the user, and checks that the translation belongs to the client's organization.
+func (c *Client) resolvePresence(m WsClientMsg) (WsPresence, error) {
+	if m.TranslationID == "" {
+		return WsPresence{}, ErrApiMissingArgument("translation_id")
+	}
+	p := WsPresence{
+		UserID:        c.session.User.ID,
+		UserName:      c.session.User.UserName,
+		TranslationID: m.TranslationID,
+		LocaleID:      m.LocaleID,
+		Editing:       m.Editing,
+		At:            time.Now(),
+	}
+	db := c.options.DB
+	if db == nil {
+		return p, nil
+	}
+	t, err := db.GetTranslation(m.TranslationID)
+	if err != nil || t == nil || t.OrganizationID != c.session.Organization.ID {
+		return p, ErrApiNotFound("Translation", m.TranslationID)
+	}
+	p.Key = t.Key
+	p.ProjectID = projectIDOf(db, *t)
+	return p, nil
+}
+
+func (c *Client) writeJSON(m Msg) error {
+	b, err := json.Marshal(m)
+	if err != nil {
 		return err
 	}
-	if err := c.conn.WriteMessage(kind, j); err != nil {
+	return c.write(websocket.TextMessage, b)
+}
+
+func (c *Client) write(kind int, j []byte) error {
+	c.Lock()
+	err := c.conn.SetWriteDeadline(time.Now().Add(c.options.WriteTimeout))
+	if err == nil {
+		err = c.conn.WriteMessage(kind, j)
+	}
+	c.Unlock()
+	if err != nil {
 		c.l.Error().Err(err).Msg("Failed to write message to the client")
-		c.clientLIst.Unsubscribe(c.id)
-		c.conn.Close()
-		return err
+		c.close()
 	}
-	return nil
+	return err
+}
+
+func (c *Client) close() {
+	c.clientLIst.Unsubscribe(c.id)
+	c.conn.Close()
 }
 
 type PubSub struct {
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/gorilla/websocket"
+	"github.com/runar-rkmedia/go-common/logger"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+type mockSessions map[string]types.Session
+
+func (m mockSessions) GetSession(token string) (types.Session, error) {
+	s, ok := m[token]
+	if !ok {
+		return s, errors.New("Session not found")
+	}
+	return s, nil
+}
+
+// A session for a user that can view the whole organization
+func session(organizationID string) types.Session {
+	s := types.Session{}
+	s.User.ID = "user-" + organizationID
+	s.User.Roles = []types.RoleAssignment{{Role: types.RoleViewer}}
+	s.Organization.ID = organizationID
+	return s
+}
+
+func project(id, organizationID string) types.Project {
+	p := types.Project{}
+	p.ID = id
+	p.OrganizationID = organizationID
+	return p
+}
+
+type wsTestServer struct {
+	t   *testing.T
+	ch  chan Msg
+	url string
+}
+
+func newWsTestServer(t *testing.T) wsTestServer {
+	ch := make(chan Msg)
+	admin := session("org-a")
+	admin.User.ID = "admin"
+	admin.User.Roles = []types.RoleAssignment{{Role: types.RoleAdmin}}
+	translator := session("org-a")
+	translator.User.ID = "translator"
+	translator.User.Roles = []types.RoleAssignment{{Role: types.RoleTranslator, ProjectID: "p2"}}
+	sessions := mockSessions{
+		"token-a":          session("org-a"),
+		"token-a2":         session("org-a"),
+		"token-b":          session("org-b"),
+		"token-admin":      admin,
+		"token-translator": translator,
+	}
+	srv := httptest.NewServer(NewWsHandler(logger.GetLoggerWithLevel("test", "fatal"), ch, sessions, WsOptions{PingInterval: time.Hour}))
+	t.Cleanup(srv.Close)
+	return wsTestServer{t, ch, "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws/"}
+}
+
+func (ts wsTestServer) dial(token string) (*websocket.Conn, *http.Response, error) {
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", token)
+	}
+	return websocket.DefaultDialer.Dial(ts.url, header)
+}
+
+func (ts wsTestServer) read(conn *websocket.Conn) Msg {
+	ts.t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var m Msg
+	testza.AssertNoError(ts.t, conn.ReadJSON(&m))
+	return m
+}
+
+func (ts wsTestServer) connect(token string, sub WsSubscription) *websocket.Conn {
+	ts.t.Helper()
+	conn, _, err := ts.dial(token)
+	testza.AssertNoError(ts.t, err)
+	ts.t.Cleanup(func() { conn.Close() })
+	// Waiting for the reply ensures that the client is registered before messages are sent
+	testza.AssertNoError(ts.t, conn.WriteJSON(WsClientMsg{Type: "subscribe", WsSubscription: sub}))
+	testza.AssertEqual(ts.t, "subscribed", ts.read(conn).Kind)
+	return conn
+}
+
+func TestWsHandler(t *testing.T) {
+	ts := newWsTestServer(t)
+	ch, dial, read, connect := ts.ch, ts.dial, ts.read, ts.connect
+
+	for _, token := range []string{"", "token-unknown"} {
+		_, res, err := dial(token)
+		testza.AssertNotNil(t, err)
+		testza.AssertEqual(t, http.StatusUnauthorized, res.StatusCode)
+	}
+
+	a := connect("token-a", WsSubscription{})
+	aProjects := connect("token-a", WsSubscription{Kinds: []string{"project"}, Projects: []string{"p2"}})
+	b := connect("token-b", WsSubscription{})
+
+	locale := types.Locale{}
+	locale.OrganizationID = "org-a"
+	ch <- Msg{Kind: "locale", Variant: "create", Contents: locale}
+	ch <- Msg{Kind: "project", Variant: "create", Contents: project("p1", "org-b")}
+	ch <- Msg{Kind: "project", Variant: "create", Contents: project("p2", "org-a")}
+	ch <- Msg{Kind: "project", Variant: "create", Contents: types.Project{}}
+	ch <- Msg{Kind: "dist", Variant: "change"}
+
+	testza.AssertEqual(t, "locale", read(a).Kind)
+	m := read(a)
+	testza.AssertEqual(t, "project", m.Kind)
+	testza.AssertEqual(t, "p2", m.Contents.(map[string]interface{})["id"])
+	testza.AssertEqual(t, "dist", read(a).Kind)
+
+	m = read(aProjects)
+	testza.AssertEqual(t, "p2", m.Contents.(map[string]interface{})["id"], "Only the subscribed kinds and projects should be sent")
+	testza.AssertEqual(t, "dist", read(aProjects).Kind)
+
+	m = read(b)
+	testza.AssertEqual(t, "p1", m.Contents.(map[string]interface{})["id"], "Changes in other organizations should not be sent")
+	testza.AssertEqual(t, "dist", read(b).Kind)
+
+	testza.AssertNoError(t, b.WriteJSON(WsClientMsg{Type: "unsubscribe-everything"}))
+	testza.AssertEqual(t, "error", read(b).Kind)
+}
+
+func TestWsPointerPayloads(t *testing.T) {
+	ts := newWsTestServer(t)
+	conn := ts.connect("token-a", WsSubscription{Projects: []string{"p2"}})
+
+	other := project("p3", "org-a")
+	p2 := project("p2", "org-a")
+	category := types.Category{ProjectID: "p2"}
+	category.ID = "c1"
+	category.OrganizationID = "org-a"
+	var nilProject *types.Project
+	ts.ch <- Msg{Kind: "project", Variant: "update", Contents: &other}
+	ts.ch <- Msg{Kind: "project", Variant: "update", Contents: nilProject}
+	ts.ch <- Msg{Kind: "project", Variant: "update", Contents: &p2}
+	ts.ch <- Msg{Kind: "category", Variant: "create", Contents: &category}
+
+	m := ts.read(conn)
+	testza.AssertEqual(t, "project", m.Kind)
+	testza.AssertEqual(t, "p2", m.Contents.(map[string]interface{})["id"], "Pointer-payloads should be sent to the subscribers of the project")
+	m = ts.read(conn)
+	testza.AssertEqual(t, "category", m.Kind)
+	testza.AssertEqual(t, "c1", m.Contents.(map[string]interface{})["id"])
+}
+
+func TestWsPresence(t *testing.T) {
+	ts := newWsTestServer(t)
+	a := ts.connect("token-a", WsSubscription{})
+	a2 := ts.connect("token-a2", WsSubscription{})
+	b := ts.connect("token-b", WsSubscription{})
+
+	testza.AssertNoError(t, a.WriteJSON(WsClientMsg{Type: "presence", TranslationID: "tr-1", LocaleID: "en", Editing: true}))
+	m := ts.read(a2)
+	testza.AssertEqual(t, "presence", m.Kind)
+	testza.AssertEqual(t, "editing", m.Variant)
+	p := m.Contents.(map[string]interface{})
+	testza.AssertEqual(t, "user-org-a", p["user_id"])
+	testza.AssertEqual(t, "tr-1", p["translation_id"])
+
+	// Clients that connect later are told who is editing
+	a3, _, err := ts.dial("token-a2")
+	testza.AssertNoError(t, err)
+	t.Cleanup(func() { a3.Close() })
+	m = ts.read(a3)
+	testza.AssertEqual(t, "editing", m.Variant)
+
+	// Disconnecting stops the editing
+	a.Close()
+	m = ts.read(a2)
+	testza.AssertEqual(t, "presence", m.Kind)
+	testza.AssertEqual(t, "stopped", m.Variant)
+
+	ts.ch <- Msg{Kind: "dist", Variant: "change"}
+	testza.AssertEqual(t, "dist", ts.read(b).Kind, "Presence in other organizations should not be sent")
+
+	testza.AssertNoError(t, b.WriteJSON(WsClientMsg{Type: "presence"}))
+	testza.AssertEqual(t, "error", ts.read(b).Kind)
+}
+
+func TestWsPermissions(t *testing.T) {
+	ts := newWsTestServer(t)
+	viewer := ts.connect("token-a", WsSubscription{})
+	admin := ts.connect("token-admin", WsSubscription{})
+	translator := ts.connect("token-translator", WsSubscription{})
+
+	user := types.User{}
+	user.ID = "user-org-a"
+	user.OrganizationID = "org-a"
+	webhook := types.Webhook{}
+	webhook.ID = "hook"
+	webhook.OrganizationID = "org-a"
+	apiKey := types.APIKey{}
+	apiKey.ID = "key"
+	apiKey.OrganizationID = "org-a"
+	apiKey.CreatedBy = "translator"
+	ts.ch <- Msg{Kind: "project", Variant: "update", Contents: project("p1", "org-a")}
+	ts.ch <- Msg{Kind: "project", Variant: "update", Contents: project("p2", "org-a")}
+	ts.ch <- Msg{Kind: "user", Variant: "update", Contents: user}
+	ts.ch <- Msg{Kind: "webhook", Variant: "update", Contents: webhook}
+	ts.ch <- Msg{Kind: "apiKey", Variant: "update", Contents: apiKey}
+	ts.ch <- Msg{Kind: "dist", Variant: "change"}
+
+	received := func(conn *websocket.Conn) []string {
+		var got []string
+		for {
+			m := ts.read(conn)
+			if m.Kind == "dist" {
+				return got
+			}
+			if id, _ := m.Contents.(map[string]interface{})["id"].(string); id != "" {
+				m.Kind += ":" + id
+			}
+			got = append(got, m.Kind)
+		}
+	}
+	testza.AssertEqual(t, []string{"project:p1", "project:p2", "user", "webhook:hook", "apiKey:key"}, received(admin))
+	testza.AssertEqual(t, []string{"project:p1", "project:p2", "user"}, received(viewer), "Viewers should only get their own user")
+	testza.AssertEqual(t, []string{"project:p2", "apiKey:key"}, received(translator), "Users with project-roles should only get changes within those projects, and to their own entities")
+}
@@ -0,0 +1,348 @@
+package importexport
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+// Android-support (strings.xml)
+//
+// The full key of the translation is used as the resource-name, like `General.Forms.Submit`.
+// Android allows dots in resource-names, and replaces them with underscores in the generated R-class.
+//
+// - Contexts are written as separate strings, with the i18next-suffix, like `General.Forms.Submit_formal`
+// - Plurals are written as <plurals>, with one <item> per plural-category
+// - Translation.Description is written as a comment before the resource
+//
+// Values are written as-is, so any interpolation like `{{count}}` is kept.
+// When importing, an underscore in a resource-name separates the key from the context, as with i18next.
+
+const ContentTypeAndroid = "application/xml"
+
+// AndroidResourceQualifier returns the qualifier used for the values-folder of the locale,
+// so that the file can be placed in `values-<qualifier>/strings.xml`
+//
+// E.g.:
+// en => en
+// nb-NO => nb-rNO
+// sr-Latn-RS => b+sr+Latn+RS
+func AndroidResourceQualifier(locale types.Locale) string {
+	tag := locale.IETF
+	if tag == "" {
+		tag = locale.Iso639_1
+	}
+	parts := strings.Split(tag, "-")
+	switch {
+	case len(parts) == 1:
+		return parts[0]
+	case len(parts) == 2 && len(parts[1]) == 2:
+		return parts[0] + "-r" + strings.ToUpper(parts[1])
+	}
+	return "b+" + strings.Join(parts, "+")
+}
+
+// A group of values for a single resource-name
+type androidResource struct {
+	name    string
+	value   string
+	plurals map[string]string
+}
+
+// Returns the resources for a translation, sorted by name, with strings before plurals
+func androidResources(fullKey string, tv types.TranslationValue) []androidResource {
+	var stringResources []androidResource
+	plurals := map[string]map[string]string{}
+	values := i18nextValues(tv)
+	for _, suffix := range utils.SortedMapKeys(values) {
+		ctx, plural := SplitContextAndPlural(suffix)
+		name := fullKey
+		if ctx != "" {
+			name += "_" + ctx
+		}
+		if plural == "" {
+			stringResources = append(stringResources, androidResource{name: name, value: values[suffix]})
+			continue
+		}
+		if _, ok := plurals[name]; !ok {
+			plurals[name] = map[string]string{}
+		}
+		plurals[name][plural] = values[suffix]
+	}
+	for _, name := range utils.SortedMapKeys(plurals) {
+		stringResources = append(stringResources, androidResource{name: name, plurals: plurals[name]})
+	}
+	return stringResources
+}
+
+// ExportAndroid exports the values for a single locale as an Android strings.xml-file.
+func ExportAndroid(ep types.ExtendedProject, locale types.Locale) ([]byte, error) {
+	var w bytes.Buffer
+	w.WriteString(xml.Header)
+	fmt.Fprintf(&w, "<!-- %s: values-%s/strings.xml -->\n", androidComment(ep.Title), AndroidResourceQualifier(locale))
+	w.WriteString("<resources>\n")
+	for _, t := range FlattenExtendedProject(ep) {
+		resources := androidResources(t.FullKey, t.Values[locale.ID])
+		if len(resources) == 0 {
+			continue
+		}
+		if t.Description != "" {
+			fmt.Fprintf(&w, "    <!-- %s -->\n", androidComment(t.Description))
+		}
+		for _, r := range resources {
+			if r.plurals == nil {
+				fmt.Fprintf(&w, "    <string name=\"%s\">%s</string>\n", xmlEscape(r.name), androidEscape(r.value))
+				continue
+			}
+			fmt.Fprintf(&w, "    <plurals name=\"%s\">\n", xmlEscape(r.name))
+			categories := utils.SortedMapKeys(r.plurals)
+			SortPluralCategories(categories)
+			for _, c := range categories {
+				fmt.Fprintf(&w, "        <item quantity=\"%s\">%s</item>\n", c, androidEscape(r.plurals[c]))
+			}
+			w.WriteString("    </plurals>\n")
+		}
+	}
+	w.WriteString("</resources>\n")
+	return w.Bytes(), nil
+}
+
+var xmlEscaper = strings.NewReplacer(
+	`&`, `&amp;`,
+	`<`, `&lt;`,
+	`>`, `&gt;`,
+	`"`, `&quot;`,
+)
+
+// Escapes text for use in xml-content and attributes.
+// Unlike xml.EscapeText, apostrophes and newlines are kept as-is.
+func xmlEscape(s string) string {
+	return xmlEscaper.Replace(s)
+}
+
+// Comments cannot contain `--`
+func androidComment(s string) string {
+	return strings.ReplaceAll(s, "--", "- -")
+}
+
+var androidEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`'`, `\'`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\t", `\t`,
+	`&`, `&amp;`,
+	`<`, `&lt;`,
+	`>`, `&gt;`,
+)
+
+// Escapes a value for use as the content of a string-resource
+func androidEscape(s string) string {
+	escaped := androidEscaper.Replace(s)
+	if strings.HasPrefix(escaped, "@") || strings.HasPrefix(escaped, "?") {
+		escaped = `\` + escaped
+	}
+	// Android collapses whitespace, unless the value is within double quotes
+	if strings.TrimSpace(s) != s || strings.Contains(s, "  ") {
+		escaped = `"` + escaped + `"`
+	}
+	return escaped
+}
+
+// androidUnescape is the reverse of androidEscape, for the inner xml of a string-resource.
+// Any markup, like <b>, is kept.
+func androidUnescape(inner string) string {
+	s := []rune(html.UnescapeString(inner))
+	var out []rune
+	// Tracks which of the runes in out are whitespace that Android would trim
+	var soft []bool
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		r := s[i]
+		switch {
+		case r == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				r = '\n'
+			case 't':
+				r = '\t'
+			case 'u':
+				if i+4 < len(s) {
+					if n, err := strconv.ParseUint(string(s[i+1:i+5]), 16, 32); err == nil {
+						r = rune(n)
+						i += 4
+						break
+					}
+				}
+				r = s[i]
+			default:
+				r = s[i]
+			}
+			out = append(out, r)
+			soft = append(soft, false)
+		case r == '"':
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			if len(soft) > 0 && soft[len(soft)-1] {
+				continue
+			}
+			out = append(out, ' ')
+			soft = append(soft, true)
+		default:
+			out = append(out, r)
+			soft = append(soft, false)
+		}
+	}
+	start, end := 0, len(out)
+	for start < end && soft[start] {
+		start++
+	}
+	for end > start && soft[end-1] {
+		end--
+	}
+	return string(out[start:end])
+}
+
+type AndroidFile struct {
+	Resources []AndroidResource
+}
+
+// AndroidResource is a <string> or <plurals> from a strings.xml-file
+type AndroidResource struct {
+	Name    string
+	Comment string
+	Value   string
+	// Only set for <plurals>, keyed by the quantity
+	Plurals map[string]string
+}
+
+// ParseAndroid parses an Android strings.xml-file
+// Only <string> and <plurals> are read, other resources are skipped.
+func ParseAndroid(b []byte) (*AndroidFile, error) {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	f := AndroidFile{}
+	var comment string
+	foundRoot := false
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.Comment:
+			comment = strings.TrimSpace(string(t))
+		case xml.StartElement:
+			if !foundRoot {
+				if t.Name.Local != "resources" {
+					return nil, fmt.Errorf("expected the root-element to be resources, but got %s", t.Name.Local)
+				}
+				foundRoot = true
+				comment = ""
+				continue
+			}
+			switch t.Name.Local {
+			case "string":
+				var s struct {
+					Name  string `xml:"name,attr"`
+					Inner string `xml:",innerxml"`
+				}
+				if err := d.DecodeElement(&s, &t); err != nil {
+					return nil, err
+				}
+				f.Resources = append(f.Resources, AndroidResource{Name: s.Name, Comment: comment, Value: androidUnescape(s.Inner)})
+			case "plurals":
+				var p struct {
+					Name  string `xml:"name,attr"`
+					Items []struct {
+						Quantity string `xml:"quantity,attr"`
+						Inner    string `xml:",innerxml"`
+					} `xml:"item"`
+				}
+				if err := d.DecodeElement(&p, &t); err != nil {
+					return nil, err
+				}
+				r := AndroidResource{Name: p.Name, Comment: comment, Plurals: map[string]string{}}
+				for _, item := range p.Items {
+					r.Plurals[item.Quantity] = androidUnescape(item.Inner)
+				}
+				f.Resources = append(f.Resources, r)
+			default:
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+			}
+			comment = ""
+		}
+	}
+	if !foundRoot {
+		return nil, fmt.Errorf("expected the root-element to be resources")
+	}
+	return &f, nil
+}
+
+// ToI18Next converts the Android-resources into a i18next-compatible map, for use with ImportI18NTranslation.
+// The resource-names are used as the key, see SplitKey.
+//
+// Comments are returned as TranslationMeta.
+func (f AndroidFile) ToI18Next() (map[string]interface{}, map[string]TranslationMeta, []Warning, error) {
+	var warnings []Warning
+	out := map[string]interface{}{}
+	meta := map[string]TranslationMeta{}
+	for _, r := range f.Resources {
+		if r.Name == "" {
+			w := newWarning("Skipped resource without name", WarningKindImportFormat, WarningLevelMinor)
+			w.Details = r
+			warnings = append(warnings, w)
+			continue
+		}
+		path := SplitKey(r.Name)
+		if r.Comment != "" {
+			// The resource-name may include a context-suffix, but the comment belongs to the translation
+			fullKey := translationKeyOf(path)
+			if m := meta[fullKey]; m.Description == "" {
+				m.Description = r.Comment
+				meta[fullKey] = m
+			}
+		}
+		if r.Plurals == nil {
+			if r.Value == "" {
+				continue
+			}
+			if err := setInMap(out, path, r.Value); err != nil {
+				return out, meta, warnings, fmt.Errorf("failed to set value for '%s': %w", r.Name, err)
+			}
+			continue
+		}
+		for _, quantity := range utils.SortedMapKeys(r.Plurals) {
+			if !IsPluralCategory(quantity) {
+				w := newWarning(fmt.Sprintf("Skipped unknown quantity '%s' for '%s'", quantity, r.Name), WarningKindImportFormat, WarningLevelMajor)
+				w.Details = r
+				warnings = append(warnings, w)
+				continue
+			}
+			value := r.Plurals[quantity]
+			if value == "" {
+				continue
+			}
+			p := make([]string, len(path))
+			copy(p, path)
+			p[len(p)-1] += "_" + quantity
+			if err := setInMap(out, p, value); err != nil {
+				return out, meta, warnings, fmt.Errorf("failed to set value for '%s': %w", r.Name, err)
+			}
+		}
+	}
+	return out, meta, warnings, nil
+}
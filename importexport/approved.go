@@ -0,0 +1,26 @@
+package importexport
+
+import "github.com/runar-rkmedia/skiver/types"
+
+// OnlyApproved returns a copy of the project with only the approved values.
+// Values that are not approved are replaced with their last approved revision, or left out if they have never been approved.
+func OnlyApproved(ep types.ExtendedProject) types.ExtendedProject {
+	categories := make(map[string]types.ExtendedCategory, len(ep.Categories))
+	for cid, ec := range ep.Categories {
+		translations := make(map[string]types.ExtendedTranslation, len(ec.Translations))
+		for tid, et := range ec.Translations {
+			values := make(map[string]types.TranslationValue, len(et.Values))
+			for k, tv := range et.Values {
+				if approved, ok := tv.Approved(); ok {
+					values[k] = approved
+				}
+			}
+			et.Values = values
+			translations[tid] = et
+		}
+		ec.Translations = translations
+		categories[cid] = ec
+	}
+	ep.Categories = categories
+	return ep
+}
@@ -194,6 +194,50 @@ func Test_Export(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Should document plural-forms",
+			options: ExportI18NOptions{
+				LocaleKey:    "Iso639_1",
+				LocaleFilter: []string{},
+			},
+			project: types.ExtendedProject{
+				Locales: LocaleListToDict([]types.Locale{
+					{IETF: "en-GB", Iso639_1: "en", Iso639_2: "eng", Iso639_3: "eng", Title: "British"},
+					{IETF: "pl-PL", Iso639_1: "pl", Iso639_2: "pol", Iso639_3: "pol", Title: "Polish"},
+				}),
+				Project: types.Project{
+					Title: "Project Foo",
+				},
+				Categories: map[string]types.ExtendedCategory{
+					"cat-a": {
+						Category: types.Category{
+							Title: "General Category",
+							Key:   "General",
+						},
+						Translations: map[string]types.ExtendedTranslation{
+							"t-a": {
+								Translation: types.Translation{
+									Key:   "Items",
+									Title: "The number of items",
+								},
+								Values: map[string]types.TranslationValue{
+									"en-GB": {
+										LocaleID: "en-GB",
+										Value:    "Items",
+										Context:  map[string]string{"one": "{{count}} item", "other": "{{count}} items"},
+									},
+									"pl-PL": {
+										LocaleID: "pl-PL",
+										Value:    "Przedmioty",
+										Context:  map[string]string{"one": "{{count}} przedmiot", "other": "{{count}} przedmiotu"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -17,6 +17,8 @@ type ExportI18NOptions struct {
 	// Must be a key of locale
 	LocaleKey    LocaleKey
 	LocaleFilter []string
+	// Joins the plurals and contexts of each translation into a single ICU MessageFormat-message
+	ICU bool
 }
 
 type LocaleKey string
@@ -101,7 +103,7 @@ type LocaleMatch struct {
 	KeyType LocaleKeyEnum
 }
 
-func addi18nnode(c types.ExtendedCategory, localeID string) (I18N, error) {
+func addi18nnode(c types.ExtendedCategory, localeID string, icu bool) (I18N, error) {
 	node := I18N{
 		Nodes: make(map[string]I18N),
 	}
@@ -119,6 +121,10 @@ func addi18nnode(c types.ExtendedCategory, localeID string) (I18N, error) {
 				if tv.LocaleID != localeID {
 					continue inner
 				}
+				if icu {
+					node.Nodes[t.Key] = I18N{Value: TranslationValueToICU(tv)}
+					continue inner
+				}
 				in := I18N{Value: tv.Value}
 				node.Nodes[t.Key] = in
 				if len(tv.Context) > 0 {
@@ -176,7 +182,7 @@ func ExportI18N(ex types.ExtendedProject, options ExportI18NOptions) (node I18N,
 			// if !cat.HasTranslationForLocaleDeep(l.ID) {
 			// 	continue
 			// }
-			n, err := addi18nnode(cat, l.ID)
+			n, err := addi18nnode(cat, l.ID, options.ICU)
 
 			if err != nil {
 				return node, err
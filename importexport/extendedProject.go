@@ -14,6 +14,20 @@ type ExportOptions struct {
 	Locales                []string
 	LocaleKey, Format, Tag string
 	NoFlatten              bool
+	// Used by formats that have both a source and a target, like xliff
+	SourceLocale string
+	// Joins plurals and contexts into ICU MessageFormat-messages
+	ICU bool
+	// Only approved values are exported. Values that are not approved use their last approved revision.
+	ApprovedOnly bool
+}
+
+// ExportFormatOptions are options that only apply to some of the formats
+type ExportFormatOptions struct {
+	// Used by formats that have both a source and a target, like xliff
+	SourceLocale string
+	// Used by the i18n and typescript-formats, see ExportI18NOptions
+	ICU bool
 }
 
 type Format struct {
@@ -30,6 +44,16 @@ var (
 	FormatI18n       = Format{"i18n"}
 	FormatRaw        = Format{"raw"}
 	FormatTypescript = Format{"typescript"}
+	FormatGettextPO  = Format{"po"}
+	FormatGettextPOT = Format{"pot"}
+	FormatXliff      = Format{"xliff"}
+	FormatXliff2     = Format{"xliff2"}
+	// Android strings.xml
+	FormatAndroid = Format{"android"}
+	// iOS Localizable.strings
+	FormatIOSStrings = Format{"strings"}
+	// iOS Localizable.stringsdict, for plurals
+	FormatIOSStringsDict = Format{"stringsdict"}
 )
 
 // ExportExtendedProject exports a project into a fileformat.
@@ -37,7 +61,7 @@ var (
 // On the other hand, some formats are not marshallable, and therefore are already ready to be returned to the user directly
 //
 // for instance, typescript-format would return simply a []byte, with the contentType set to 'application/typescript'
-func ExportExtendedProject(l logger.AppLogger, ep types.ExtendedProject, locales []string, localeKey LocaleKeyEnum, format Format, localeFilter []string) (out interface{}, contentType string, err error) {
+func ExportExtendedProject(l logger.AppLogger, ep types.ExtendedProject, locales []string, localeKey LocaleKeyEnum, format Format, localeFilter []string, options ExportFormatOptions) (out interface{}, contentType string, err error) {
 
 	if format.Is(FormatRaw) {
 		out = ep
@@ -47,9 +71,81 @@ func ExportExtendedProject(l logger.AppLogger, ep types.ExtendedProject, locales
 		err = fmt.Errorf("No locales were published")
 		return
 	}
+	if format.Is(FormatGettextPOT) {
+		out, err = ExportGettext(ep, nil, localeKey)
+		contentType = ContentTypeGettextPOT
+		return
+	}
+	if format.Is(FormatGettextPO) {
+		locale, lErr := singleLocale(ep, locales)
+		if lErr != nil {
+			err = lErr
+			return
+		}
+		out, err = ExportGettext(ep, &locale, localeKey)
+		contentType = ContentTypeGettextPO
+		return
+	}
+	if format.Is(FormatAndroid) || format.Is(FormatIOSStrings) || format.Is(FormatIOSStringsDict) {
+		locale, lErr := singleLocale(ep, locales)
+		if lErr != nil {
+			err = lErr
+			return
+		}
+		switch format {
+		case FormatAndroid:
+			out, err = ExportAndroid(ep, locale)
+			contentType = ContentTypeAndroid
+		case FormatIOSStrings:
+			out, err = ExportIOSStrings(ep, locale)
+			contentType = ContentTypeIOSStrings
+		case FormatIOSStringsDict:
+			out, err = ExportIOSStringsDict(ep, locale)
+			contentType = ContentTypeIOSStringsDict
+		}
+		return
+	}
+	if format.Is(FormatXliff) || format.Is(FormatXliff2) {
+		if options.SourceLocale == "" {
+			err = fmt.Errorf("The source-locale must be set for the %s-format", format)
+			return
+		}
+		source, lErr := singleLocale(ep, []string{options.SourceLocale})
+		if lErr != nil {
+			err = lErr
+			return
+		}
+		var targetFilter []string
+		for _, l := range locales {
+			if !source.Matches(l) {
+				targetFilter = append(targetFilter, l)
+			}
+		}
+		if len(targetFilter) == 0 {
+			for _, l := range ep.Locales {
+				if l.ID != source.ID {
+					targetFilter = append(targetFilter, l.ID)
+				}
+			}
+		}
+		target, lErr := singleLocale(ep, targetFilter)
+		if lErr != nil {
+			err = lErr
+			return
+		}
+		version := "1.2"
+		if format.Is(FormatXliff2) {
+			version = "2.0"
+		}
+		out, err = ExportXliff(ep, version, source, target, localeKey)
+		contentType = ContentTypeXliff
+		return
+	}
 	i18nodes, err := ExportI18N(ep, ExportI18NOptions{
 		LocaleFilter: localeFilter,
-		LocaleKey:    LocaleKey(localeKey.Name)})
+		LocaleKey:    LocaleKey(localeKey.Name),
+		ICU:          options.ICU,
+	})
 	if err != nil {
 		return
 	}
@@ -79,6 +175,30 @@ func ExportExtendedProject(l logger.AppLogger, ep types.ExtendedProject, locales
 	}
 	return
 }
+
+// Some formats only supports a single locale per file.
+// Returns the locale matching the locale-filter, or the only locale in the project.
+func singleLocale(ep types.ExtendedProject, locales []string) (types.Locale, error) {
+	switch len(locales) {
+	case 0:
+		if len(ep.Locales) == 1 {
+			for _, l := range ep.Locales {
+				return l, nil
+			}
+		}
+	case 1:
+		if l, ok := ep.Locales[locales[0]]; ok {
+			return l, nil
+		}
+		matches := InferLocales(locales[0], ep.Locales)
+		if len(matches) == 0 {
+			return types.Locale{}, fmt.Errorf("The locale '%s' was not found", locales[0])
+		}
+		return matches[0].Locale, nil
+	}
+	return types.Locale{}, fmt.Errorf("This format requires exactly one locale to be selected")
+}
+
 func ExportExtendedProjectToI18Next(l logger.AppLogger, ep types.ExtendedProject, locales []string, localeKey LocaleKeyEnum) (out map[string]interface{}, err error) {
 	if len(ep.Locales) == 0 {
 		return nil, fmt.Errorf("No locales were published")
@@ -0,0 +1,125 @@
+package importexport
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/runar-rkmedia/skiver/plurals"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+// FlatTranslation is a translation with its full, dotted key.
+// Used by the formats that are key-value-based, and does not nest categories, like gettext.
+type FlatTranslation struct {
+	types.Translation
+	// The full key, including the categories, like `General.Forms.Submit`
+	FullKey string
+	// The values, keyed by their LocaleID
+	Values map[string]types.TranslationValue
+}
+
+// FlattenExtendedProject returns all translations within the project, sorted by their full key.
+// Deleted translations and values are ignored.
+func FlattenExtendedProject(ep types.ExtendedProject) []FlatTranslation {
+	var list []FlatTranslation
+	for _, c := range ep.Categories {
+		if c.Deleted != nil {
+			continue
+		}
+		for _, t := range c.Translations {
+			if t.Deleted != nil {
+				continue
+			}
+			ft := FlatTranslation{
+				Translation: t.Translation,
+				FullKey:     t.Key,
+				Values:      map[string]types.TranslationValue{},
+			}
+			if !c.IsRoot() {
+				ft.FullKey = c.Key + "." + t.Key
+			}
+			for _, tv := range t.Values {
+				if tv.Deleted != nil {
+					continue
+				}
+				ft.Values[tv.LocaleID] = tv
+			}
+			list = append(list, ft)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].FullKey < list[j].FullKey
+	})
+	return list
+}
+
+// The plural-suffixes used by i18next, in the order of the CLDR plural-categories
+var PluralCategories = plurals.Categories
+
+func IsPluralCategory(s string) bool {
+	for _, p := range PluralCategories {
+		if p == s {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitContextAndPlural splits a key from TranslationValue.Context into its context and plural-category.
+//
+// E.g.:
+// "one" => "", "one"
+// "male_one" => "male", "one"
+// "male" => "male", ""
+func SplitContextAndPlural(contextKey string) (context string, plural string) {
+	if IsPluralCategory(contextKey) {
+		return "", contextKey
+	}
+	i := strings.LastIndex(contextKey, "_")
+	if i > 0 && IsPluralCategory(contextKey[i+1:]) {
+		return contextKey[:i], contextKey[i+1:]
+	}
+	return contextKey, ""
+}
+
+// JoinContextAndPlural is the reverse of SplitContextAndPlural
+func JoinContextAndPlural(context, plural string) string {
+	switch {
+	case context == "":
+		return plural
+	case plural == "":
+		return context
+	}
+	return context + "_" + plural
+}
+
+// SortPluralCategories sorts the plural-categories in the CLDR-order.
+func SortPluralCategories(categories []string) {
+	sort.Slice(categories, func(i, j int) bool {
+		return pluralIndex(categories[i]) < pluralIndex(categories[j])
+	})
+}
+
+func pluralIndex(s string) int {
+	for i, p := range PluralCategories {
+		if p == s {
+			return i
+		}
+	}
+	return len(PluralCategories)
+}
+
+// SplitKey splits a full key into the path of categories and the translation-key, like importFromCategoryNode does.
+// If the key contains whitespace, it is assumed to be a source-string, and is not split into categories.
+func SplitKey(fullKey string) []string {
+	if strings.ContainsAny(fullKey, " \t\n") {
+		return []string{fullKey}
+	}
+	return strings.Split(fullKey, ".")
+}
+
+// Returns the full key of the translation for a path from SplitKey, without any context- or plural-suffix.
+func translationKeyOf(path []string) string {
+	key, _ := SplitTranslationAndContext(path[len(path)-1], "_")
+	return strings.Join(append(path[:len(path)-1:len(path)-1], key), ".")
+}
@@ -0,0 +1,349 @@
+package importexport
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/runar-rkmedia/skiver/plurals"
+	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+// Gettext-support (.po and .pot)
+//
+// Skiver uses keys as identifiers, so the msgid is the full key of the translation, like `General.Forms.Submit`.
+// This is how gettext is commonly used for "monolingual" files.
+//
+// - Translation.Description is written as translator-comments (`# `)
+// - Translation.References are written as reference-comments (`#: `)
+// - Contexts are written as msgctxt
+// - Plurals (i18next-style suffixes like `_one` and `_other`) are written as msgid_plural with msgstr[N]
+//
+// Since the plural-forms are defined by an expression in the Plural-Forms-header,
+// which Skiver does not know about for all locales, the plural-categories used are also
+// written to the header `X-Skiver-Plural-Categories`. This is used when importing.
+
+const (
+	ContentTypeGettextPO  = "text/x-gettext-translation"
+	ContentTypeGettextPOT = "text/x-gettext-translation-template"
+
+	gettextHeaderPluralCategories = "X-Skiver-Plural-Categories"
+)
+
+type GettextEntry struct {
+	TranslatorComments []string
+	ExtractedComments  []string
+	References         []string
+	Flags              []string
+	Context            string
+	ID                 string
+	IDPlural           string
+	Str                string
+	StrPlural          []string
+}
+
+// A group of values for a single context within a translation
+type gettextGroup struct {
+	hasValue bool
+	value    string
+	plurals  map[string]string
+}
+
+// ExportGettext exports the project as a gettext-file for a single locale.
+// If locale is nil, a template (.pot) is created, with all msgstr empty.
+func ExportGettext(ep types.ExtendedProject, locale *types.Locale, localeKey LocaleKeyEnum) ([]byte, error) {
+	translations := FlattenExtendedProject(ep)
+	localeID := ""
+	if locale != nil {
+		localeID = locale.ID
+	}
+	pluralCategories := gettextPluralCategories(translations, localeID)
+	if len(pluralCategories) == 0 && localeID != "" {
+		pluralCategories = gettextPluralCategories(translations, "")
+	}
+	if len(pluralCategories) > 0 && locale != nil {
+		// The translators need all the plural-forms that the locale uses, including the ones that are not yet translated
+		if rules, ok := plurals.ForLocale(*locale); ok {
+			pluralCategories = append(pluralCategories, rules.Missing(pluralCategories)...)
+			SortPluralCategories(pluralCategories)
+		}
+	}
+
+	var entries []GettextEntry
+	for _, t := range translations {
+		groups := map[string]*gettextGroup{}
+		for _, tv := range t.Values {
+			isLocale := localeID != "" && tv.LocaleID == localeID
+			g := gettextGetGroup(groups, "")
+			if isLocale {
+				g.value = tv.Value
+			}
+			g.hasValue = g.hasValue || tv.Value != ""
+			for k, v := range tv.Context {
+				ctx, plural := SplitContextAndPlural(k)
+				g := gettextGetGroup(groups, ctx)
+				if plural == "" {
+					g.hasValue = true
+					if isLocale {
+						g.value = v
+					}
+					continue
+				}
+				if _, ok := g.plurals[plural]; !ok || isLocale {
+					g.plurals[plural] = ""
+					if isLocale {
+						g.plurals[plural] = v
+					}
+				}
+			}
+		}
+		if len(groups) == 0 {
+			// No values for any locale, but we still want the key to be available for translators
+			groups[""] = &gettextGroup{hasValue: true}
+		}
+		for _, ctx := range utils.SortedMapKeys(groups) {
+			g := groups[ctx]
+			entry := GettextEntry{
+				References: t.References,
+				Context:    ctx,
+				ID:         t.FullKey,
+			}
+			if t.Description != "" {
+				entry.TranslatorComments = strings.Split(t.Description, "\n")
+			}
+			if len(g.plurals) > 0 {
+				// In i18next, the key without the plural-suffix is only used when count is not set.
+				// Gettext cannot represent both, so the plural-form is preferred.
+				entry.IDPlural = t.FullKey + "_" + "other"
+				if localeID == "" {
+					entry.StrPlural = make([]string, 2)
+				} else {
+					entry.StrPlural = make([]string, len(pluralCategories))
+					for i, p := range pluralCategories {
+						entry.StrPlural[i] = g.plurals[p]
+					}
+				}
+			} else if !g.hasValue && ctx == "" {
+				continue
+			} else {
+				entry.Str = g.value
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	var w bytes.Buffer
+	header := []string{
+		"Project-Id-Version: " + ep.Title,
+	}
+	if locale != nil {
+		header = append(header, "Language: "+getLocaleKey(LocaleKey(localeKey.Name), *locale))
+	}
+	header = append(header,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding: 8bit",
+	)
+	if locale == nil {
+		header = append(header, "Plural-Forms: nplurals=INTEGER; plural=EXPRESSION;")
+	} else if expr := gettextPluralExpression(*locale, pluralCategories); expr != "" {
+		header = append(header, fmt.Sprintf("Plural-Forms: nplurals=%d; plural=%s;", len(pluralCategories), expr))
+	}
+	if len(pluralCategories) > 0 {
+		header = append(header, gettextHeaderPluralCategories+": "+strings.Join(pluralCategories, ", "))
+	}
+	writeGettextEntry(&w, GettextEntry{Str: strings.Join(header, "\n") + "\n"})
+	for _, e := range entries {
+		w.WriteString("\n")
+		writeGettextEntry(&w, e)
+	}
+
+	return w.Bytes(), nil
+}
+
+func gettextGetGroup(groups map[string]*gettextGroup, ctx string) *gettextGroup {
+	g, ok := groups[ctx]
+	if !ok {
+		g = &gettextGroup{plurals: map[string]string{}}
+		groups[ctx] = g
+	}
+	return g
+}
+
+// Returns the plural-categories in use for the locale, sorted.
+// If localeID is empty, all locales are used.
+func gettextPluralCategories(translations []FlatTranslation, localeID string) []string {
+	seen := map[string]bool{}
+	for _, t := range translations {
+		for _, tv := range t.Values {
+			if localeID != "" && tv.LocaleID != localeID {
+				continue
+			}
+			for k := range tv.Context {
+				if _, plural := SplitContextAndPlural(k); plural != "" {
+					seen[plural] = true
+				}
+			}
+		}
+	}
+	list := utils.SortedMapKeys(seen)
+	SortPluralCategories(list)
+	return list
+}
+
+// A Plural-Forms-expression of gettext, which selects the index of the plural-category within the categories.
+// index is the same expression in Go, so that it can be verified against the CLDR-rules of a locale.
+type gettextPluralForm struct {
+	categories string
+	expression string
+	index      func(n int) int
+}
+
+func gettextIf(b bool, then, otherwise int) int {
+	if b {
+		return then
+	}
+	return otherwise
+}
+
+// The usual gettext-expressions for the plural-categories of CLDR, in the CLDR-order, with the most common first.
+// Gettext only counts integers, so the categories that only apply to decimals, like `other` for Polish, are never selected.
+var gettextPluralForms = []gettextPluralForm{
+	{"other", "0", func(n int) int { return 0 }},
+	{"one,other", "(n != 1)", func(n int) int { return gettextIf(n != 1, 1, 0) }},
+	{"one,other", "(n > 1)", func(n int) int { return gettextIf(n > 1, 1, 0) }},
+	{"one,other", "(n%10 != 1 || n%100 == 11)", func(n int) int { return gettextIf(n%10 != 1 || n%100 == 11, 1, 0) }},
+	{"one,other", "(n%10 != 1)", func(n int) int { return gettextIf(n%10 != 1, 1, 0) }},
+	{"one,other", "(n != 1 && n != 2 && n != 3 && (n%10 == 4 || n%10 == 6 || n%10 == 9))", func(n int) int {
+		return gettextIf(n != 1 && n != 2 && n != 3 && (n%10 == 4 || n%10 == 6 || n%10 == 9), 1, 0)
+	}},
+	{"one,few,many,other", "(n%10 == 1 && n%100 != 11 ? 0 : n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14) ? 1 : 2)", func(n int) int {
+		return gettextIf(n%10 == 1 && n%100 != 11, 0, gettextIf(n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14), 1, 2))
+	}},
+	{"one,few,many,other", "(n == 1 ? 0 : n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14) ? 1 : 2)", func(n int) int {
+		return gettextIf(n == 1, 0, gettextIf(n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14), 1, 2))
+	}},
+	{"one,few,many,other", "(n == 1 ? 0 : n >= 2 && n <= 4 ? 1 : 3)", func(n int) int {
+		return gettextIf(n == 1, 0, gettextIf(n >= 2 && n <= 4, 1, 3))
+	}},
+	{"one,few,many,other", "(n%10 == 1 && (n%100 < 11 || n%100 > 19) ? 0 : n%10 >= 2 && (n%100 < 11 || n%100 > 19) ? 1 : 3)", func(n int) int {
+		return gettextIf(n%10 == 1 && (n%100 < 11 || n%100 > 19), 0, gettextIf(n%10 >= 2 && (n%100 < 11 || n%100 > 19), 1, 3))
+	}},
+	{"one,few,many,other", "(n == 1 ? 0 : n == 0 || (n%100 >= 2 && n%100 <= 10) ? 1 : n%100 >= 11 && n%100 <= 19 ? 2 : 3)", func(n int) int {
+		return gettextIf(n == 1, 0, gettextIf(n == 0 || (n%100 >= 2 && n%100 <= 10), 1, gettextIf(n%100 >= 11 && n%100 <= 19, 2, 3)))
+	}},
+	{"one,few,other", "(n%10 == 1 && n%100 != 11 ? 0 : n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14) ? 1 : 2)", func(n int) int {
+		return gettextIf(n%10 == 1 && n%100 != 11, 0, gettextIf(n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14), 1, 2))
+	}},
+	{"one,few,other", "(n == 1 ? 0 : n == 0 || (n%100 >= 1 && n%100 <= 19) ? 1 : 2)", func(n int) int {
+		return gettextIf(n == 1, 0, gettextIf(n == 0 || (n%100 >= 1 && n%100 <= 19), 1, 2))
+	}},
+	{"zero,one,other", "(n%10 == 0 || (n%100 >= 11 && n%100 <= 19) ? 0 : n%10 == 1 && n%100 != 11 ? 1 : 2)", func(n int) int {
+		return gettextIf(n%10 == 0 || (n%100 >= 11 && n%100 <= 19), 0, gettextIf(n%10 == 1 && n%100 != 11, 1, 2))
+	}},
+	{"one,two,many,other", "(n == 1 ? 0 : n == 2 ? 1 : n > 10 && n%10 == 0 ? 2 : 3)", func(n int) int {
+		return gettextIf(n == 1, 0, gettextIf(n == 2, 1, gettextIf(n > 10 && n%10 == 0, 2, 3)))
+	}},
+	{"one,two,few,other", "(n%100 == 1 ? 0 : n%100 == 2 ? 1 : n%100 == 3 || n%100 == 4 ? 2 : 3)", func(n int) int {
+		return gettextIf(n%100 == 1, 0, gettextIf(n%100 == 2, 1, gettextIf(n%100 == 3 || n%100 == 4, 2, 3)))
+	}},
+	{"one,two,few,other", "(n == 1 || n == 11 ? 0 : n == 2 || n == 12 ? 1 : n >= 3 && n <= 19 ? 2 : 3)", func(n int) int {
+		return gettextIf(n == 1 || n == 11, 0, gettextIf(n == 2 || n == 12, 1, gettextIf(n >= 3 && n <= 19, 2, 3)))
+	}},
+	{"one,two,few,many,other", "(n == 1 ? 0 : n == 2 ? 1 : n >= 3 && n <= 6 ? 2 : n >= 7 && n <= 10 ? 3 : 4)", func(n int) int {
+		return gettextIf(n == 1, 0, gettextIf(n == 2, 1, gettextIf(n >= 3 && n <= 6, 2, gettextIf(n >= 7 && n <= 10, 3, 4))))
+	}},
+	{"zero,one,two,few,many,other", "(n == 0 ? 0 : n == 1 ? 1 : n == 2 ? 2 : n%100 >= 3 && n%100 <= 10 ? 3 : n%100 >= 11 ? 4 : 5)", func(n int) int {
+		return gettextIf(n == 0, 0, gettextIf(n == 1, 1, gettextIf(n == 2, 2, gettextIf(n%100 >= 3 && n%100 <= 10, 3, gettextIf(n%100 >= 11, 4, 5)))))
+	}},
+	{"zero,one,two,few,many,other", "(n == 0 ? 0 : n == 1 ? 1 : n == 2 ? 2 : n == 3 ? 3 : n == 6 ? 4 : 5)", func(n int) int {
+		return gettextIf(n == 0, 0, gettextIf(n == 1, 1, gettextIf(n == 2, 2, gettextIf(n == 3, 3, gettextIf(n == 6, 4, 5)))))
+	}},
+}
+
+// Returns true if the expression selects the same category as the CLDR-rules, for the counts that are checked.
+func (f gettextPluralForm) matches(rules plurals.Rules, categories []string) bool {
+	for n := 0; n <= 1100; n++ {
+		if categories[f.index(n)] != rules.Category(float64(n)) {
+			return false
+		}
+	}
+	for _, n := range []int{1000000, 2000000, 1000001} {
+		if categories[f.index(n)] != rules.Category(float64(n)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the Plural-Forms-expression for the plural-categories of the locale.
+// The expression is the one that matches the CLDR-rules of the locale.
+// For locales without known rules, the most common expression is used for `other` and `one,other`.
+// For other combinations, like when the values use categories that the locale does not, an empty string is returned.
+func gettextPluralExpression(locale types.Locale, categories []string) string {
+	rules, ok := plurals.ForLocale(locale)
+	if !ok && len(categories) > 2 {
+		return ""
+	}
+	joined := strings.Join(categories, ",")
+	for _, f := range gettextPluralForms {
+		if f.categories != joined {
+			continue
+		}
+		if !ok || f.matches(rules, categories) {
+			return f.expression
+		}
+	}
+	return ""
+}
+
+func writeGettextEntry(w io.Writer, e GettextEntry) {
+	for _, c := range e.TranslatorComments {
+		fmt.Fprintf(w, "# %s\n", c)
+	}
+	for _, r := range e.References {
+		fmt.Fprintf(w, "#: %s\n", r)
+	}
+	if e.Context != "" {
+		writeGettextString(w, "msgctxt", e.Context)
+	}
+	writeGettextString(w, "msgid", e.ID)
+	if e.IDPlural == "" {
+		writeGettextString(w, "msgstr", e.Str)
+		return
+	}
+	writeGettextString(w, "msgid_plural", e.IDPlural)
+	for i, s := range e.StrPlural {
+		writeGettextString(w, fmt.Sprintf("msgstr[%d]", i), s)
+	}
+}
+
+// Writes a keyword with its string, splitting multiline-strings into one line per newline.
+func writeGettextString(w io.Writer, keyword, s string) {
+	if !strings.Contains(strings.TrimSuffix(s, "\n"), "\n") {
+		fmt.Fprintf(w, "%s \"%s\"\n", keyword, gettextEscape(s))
+		return
+	}
+	fmt.Fprintf(w, "%s \"\"\n", keyword)
+	lines := strings.SplitAfter(s, "\n")
+	for _, l := range lines {
+		if l == "" {
+			continue
+		}
+		fmt.Fprintf(w, "\"%s\"\n", gettextEscape(l))
+	}
+}
+
+var gettextEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\t", `\t`,
+	"\r", `\r`,
+)
+
+func gettextEscape(s string) string {
+	return gettextEscaper.Replace(s)
+}
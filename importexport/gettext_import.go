@@ -0,0 +1,317 @@
+package importexport
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+type GettextFile struct {
+	Header  map[string]string
+	Entries []GettextEntry
+}
+
+// ParseGettext parses a gettext-file (.po or .pot).
+// Obsolete entries (`#~`) are ignored.
+func ParseGettext(b []byte) (*GettextFile, error) {
+	f := GettextFile{Header: map[string]string{}}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(b)+1)
+
+	var entry GettextEntry
+	// Tracks whether anything has been added to the current entry
+	dirty := false
+	hasID := false
+	// The last keyword seen, used for continuation-strings
+	var keyword string
+	flush := func() {
+		if !dirty {
+			return
+		}
+		if hasID {
+			f.Entries = append(f.Entries, entry)
+		}
+		entry = GettextEntry{}
+		dirty = false
+		hasID = false
+		keyword = ""
+	}
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		switch {
+		case line == "":
+			flush()
+			continue
+		case strings.HasPrefix(line, "#~"):
+			continue
+		case strings.HasPrefix(line, "#|"):
+			continue
+		case strings.HasPrefix(line, "#"):
+			// A comment after the strings starts a new entry
+			if keyword != "" && strings.HasPrefix(keyword, "msgstr") {
+				flush()
+			}
+			dirty = true
+			switch {
+			case strings.HasPrefix(line, "#:"):
+				entry.References = append(entry.References, strings.Fields(line[2:])...)
+			case strings.HasPrefix(line, "#."):
+				entry.ExtractedComments = append(entry.ExtractedComments, strings.TrimSpace(line[2:]))
+			case strings.HasPrefix(line, "#,"):
+				for _, flag := range strings.Split(line[2:], ",") {
+					if flag = strings.TrimSpace(flag); flag != "" {
+						entry.Flags = append(entry.Flags, flag)
+					}
+				}
+			default:
+				comment := strings.TrimPrefix(line, "#")
+				comment = strings.TrimPrefix(comment, " ")
+				entry.TranslatorComments = append(entry.TranslatorComments, comment)
+			}
+			continue
+		case strings.HasPrefix(line, `"`):
+			if keyword == "" {
+				return nil, fmt.Errorf("line %d: unexpected string without a preceding keyword", lineNo)
+			}
+			s, err := gettextUnquote(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			if err := entry.appendTo(keyword, s); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			continue
+		}
+
+		kw, rest, found := strings.Cut(line, " ")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected a keyword followed by a string, but got '%s'", lineNo, line)
+		}
+		// A new msgctxt or msgid after msgstr starts a new entry, even without a blank line
+		if (kw == "msgctxt" || kw == "msgid") && strings.HasPrefix(keyword, "msgstr") {
+			flush()
+		}
+		s, err := gettextUnquote(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if err := entry.appendTo(kw, s); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if kw == "msgid" {
+			hasID = true
+		}
+		dirty = true
+		keyword = kw
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	// The header is the entry with an empty msgid
+	for i, e := range f.Entries {
+		if e.ID != "" || e.Context != "" {
+			continue
+		}
+		for _, line := range strings.Split(e.Str, "\n") {
+			k, v, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			f.Header[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+		f.Entries = append(f.Entries[:i], f.Entries[i+1:]...)
+		break
+	}
+
+	return &f, nil
+}
+
+var gettextMsgstrIndexRegex = regexp.MustCompile(`^msgstr\[(\d+)\]$`)
+
+func (e *GettextEntry) appendTo(keyword string, s string) error {
+	switch keyword {
+	case "msgctxt":
+		e.Context += s
+	case "msgid":
+		e.ID += s
+	case "msgid_plural":
+		e.IDPlural += s
+	case "msgstr":
+		e.Str += s
+	default:
+		m := gettextMsgstrIndexRegex.FindStringSubmatch(keyword)
+		if len(m) != 2 {
+			return fmt.Errorf("unknown keyword '%s'", keyword)
+		}
+		i, err := strconv.Atoi(m[1])
+		if err != nil {
+			return err
+		}
+		for len(e.StrPlural) <= i {
+			e.StrPlural = append(e.StrPlural, "")
+		}
+		e.StrPlural[i] += s
+	}
+	return nil
+}
+
+func gettextUnquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("expected a quoted string, but got '%s'", s)
+	}
+	// The escape-sequences in gettext are the same as in C, which are close enough to Go's.
+	unquoted, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted string %s: %w", s, err)
+	}
+	return unquoted, nil
+}
+
+func (f GettextFile) Language() string {
+	return f.Header["Language"]
+}
+
+// PluralCategories returns the plural-categories for the msgstr[N]-indexes.
+// Files exported from Skiver includes these in a header. For other files, they are inferred from the nplurals in the Plural-Forms-header.
+func (f GettextFile) PluralCategories() ([]string, error) {
+	if s := f.Header[gettextHeaderPluralCategories]; s != "" {
+		var categories []string
+		for _, c := range strings.Split(s, ",") {
+			c = strings.TrimSpace(c)
+			if !IsPluralCategory(c) {
+				return nil, fmt.Errorf("invalid plural-category '%s' in header %s", c, gettextHeaderPluralCategories)
+			}
+			categories = append(categories, c)
+		}
+		return categories, nil
+	}
+	pluralForms := f.Header["Plural-Forms"]
+	if pluralForms == "" {
+		return nil, nil
+	}
+	for _, part := range strings.Split(pluralForms, ";") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if strings.TrimSpace(k) != "nplurals" {
+			continue
+		}
+		switch strings.TrimSpace(v) {
+		case "1":
+			return []string{"other"}, nil
+		case "2":
+			return []string{"one", "other"}, nil
+		}
+		return nil, fmt.Errorf("unable to infer plural-categories from the Plural-Forms-header '%s'. Set the header %s to the plural-categories used, like: 'one, few, other'", pluralForms, gettextHeaderPluralCategories)
+	}
+	return nil, nil
+}
+
+// ToI18Next converts the gettext-file into a i18next-compatible map, for use with ImportI18NTranslation.
+// The msgid is used as the key, see SplitKey.
+//
+// Translator-comments and references are returned as TranslationMeta.
+// Untranslated and fuzzy entries are skipped.
+func (f GettextFile) ToI18Next() (map[string]interface{}, map[string]TranslationMeta, []Warning, error) {
+	var warnings []Warning
+	out := map[string]interface{}{}
+	meta := map[string]TranslationMeta{}
+	var pluralCategories []string
+	pluralCategoriesResolved := false
+
+	for _, e := range f.Entries {
+		if e.ID == "" {
+			continue
+		}
+		path := SplitKey(e.ID)
+		fullKey := strings.Join(path, ".")
+		if len(e.TranslatorComments) > 0 || len(e.References) > 0 {
+			m := meta[fullKey]
+			if len(e.TranslatorComments) > 0 && m.Description == "" {
+				m.Description = strings.Join(e.TranslatorComments, "\n")
+			}
+			for _, ref := range e.References {
+				if !utils.Contains(m.References, ref) {
+					m.References = append(m.References, ref)
+				}
+			}
+			meta[fullKey] = m
+		}
+		if utils.Contains(e.Flags, "fuzzy") {
+			w := newWarning(fmt.Sprintf("Skipped fuzzy entry '%s'", e.ID), WarningKindImportFormat, WarningLevelMinor)
+			w.Details = e
+			warnings = append(warnings, w)
+			continue
+		}
+		values := map[string]string{}
+		if e.IDPlural == "" {
+			values[e.Context] = e.Str
+		} else {
+			if !pluralCategoriesResolved {
+				c, err := f.PluralCategories()
+				if err != nil {
+					return out, meta, warnings, err
+				}
+				pluralCategories = c
+				pluralCategoriesResolved = true
+			}
+			if len(pluralCategories) == 0 {
+				return out, meta, warnings, fmt.Errorf("the entry '%s' has plurals, but the file does not have a Plural-Forms-header", e.ID)
+			}
+			for i, s := range e.StrPlural {
+				if i >= len(pluralCategories) {
+					w := newWarning(fmt.Sprintf("The entry '%s' has more plural-forms than expected from the header", e.ID), WarningKindImportFormat, WarningLevelMajor)
+					w.Details = e
+					warnings = append(warnings, w)
+					break
+				}
+				values[JoinContextAndPlural(e.Context, pluralCategories[i])] = s
+			}
+		}
+		for suffix, value := range values {
+			if value == "" {
+				continue
+			}
+			p := make([]string, len(path))
+			copy(p, path)
+			if suffix != "" {
+				p[len(p)-1] += "_" + suffix
+			}
+			if err := setInMap(out, p, value); err != nil {
+				return out, meta, warnings, fmt.Errorf("failed to set value for '%s': %w", e.ID, err)
+			}
+		}
+	}
+	return out, meta, warnings, nil
+}
+
+func setInMap(m map[string]interface{}, path []string, value string) error {
+	if len(path) == 1 {
+		if _, ok := m[path[0]].(map[string]interface{}); ok {
+			return fmt.Errorf("the key '%s' is already used as a category", path[0])
+		}
+		m[path[0]] = value
+		return nil
+	}
+	next, ok := m[path[0]]
+	if !ok {
+		n := map[string]interface{}{}
+		m[path[0]] = n
+		return setInMap(n, path[1:], value)
+	}
+	n, ok := next.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("the key '%s' is already used as a translation", path[0])
+	}
+	return setInMap(n, path[1:], value)
+}
@@ -0,0 +1,154 @@
+package importexport
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/internal"
+)
+
+func TestParseGettext(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantI18n map[string]interface{}
+		wantMeta map[string]TranslationMeta
+		wantErr  bool
+	}{
+		{
+			name: "Simple file with header, comments, context and plurals",
+			input: `
+# Some comment about the file
+msgid ""
+msgstr ""
+"Language: en\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+# The title of the page
+#: src/App.tsx:12
+#: src/Other.tsx:1 src/Third.tsx:99
+msgid "General.Title"
+msgstr "My app"
+
+msgctxt "formal"
+msgid "General.Title"
+msgstr "My "
+"application"
+
+#, fuzzy
+msgid "General.Fuzzy"
+msgstr "Not quite right"
+
+msgid "General.Empty"
+msgstr ""
+
+msgid "Items"
+msgid_plural "Items_other"
+msgstr[0] "{{count}} item"
+msgstr[1] "{{count}} items"
+msgctxt "male"
+msgid "Items"
+msgid_plural "Items_other"
+msgstr[0] "He has {{count}} item"
+msgstr[1] "He has {{count}} items"
+
+#~ msgid "Obsolete"
+#~ msgstr "Gone"
+`,
+			wantI18n: map[string]interface{}{
+				"General": map[string]interface{}{
+					"Title":        "My app",
+					"Title_formal": "My application",
+				},
+				"Items_one":        "{{count}} item",
+				"Items_other":      "{{count}} items",
+				"Items_male_one":   "He has {{count}} item",
+				"Items_male_other": "He has {{count}} items",
+			},
+			wantMeta: map[string]TranslationMeta{
+				"General.Title": {
+					Description: "The title of the page",
+					References:  []string{"src/App.tsx:12", "src/Other.tsx:1", "src/Third.tsx:99"},
+				},
+			},
+		},
+		{
+			name: "Source-strings as msgid are not split into categories",
+			input: `
+msgid "Hello. Welcome to the app"
+msgstr "Hei. Velkommen til appen"
+`,
+			wantI18n: map[string]interface{}{
+				"Hello. Welcome to the app": "Hei. Velkommen til appen",
+			},
+			wantMeta: map[string]TranslationMeta{},
+		},
+		{
+			name: "Plurals without header",
+			input: `
+msgid "Items"
+msgid_plural "Items_other"
+msgstr[0] "{{count}} item"
+msgstr[1] "{{count}} items"
+`,
+			wantErr: true,
+		},
+		{
+			name: "Invalid string",
+			input: `
+msgid "Items
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			po, err := ParseGettext([]byte(tt.input))
+			if err != nil {
+				if !tt.wantErr {
+					t.Fatal(err)
+				}
+				return
+			}
+			i18n, meta, _, err := po.ToI18Next()
+			if tt.wantErr {
+				testza.AssertNotNil(t, err)
+				return
+			}
+			testza.AssertNoError(t, err)
+			if err := internal.Compare("i18n", i18n, tt.wantI18n); err != nil {
+				t.Error(err)
+			}
+			testza.AssertEqual(t, tt.wantMeta, meta)
+		})
+	}
+}
+
+// Exporting a project and importing it again should result in the same values
+func TestGettextRoundTrip(t *testing.T) {
+	ep := gettextTestProject()
+	locale := ep.Locales["loc-en"]
+	b, err := ExportGettext(ep, &locale, LocaleKeyEnumISO1)
+	testza.AssertNoError(t, err)
+
+	po, err := ParseGettext(b)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "en", po.Language())
+	i18n, meta, warnings, err := po.ToI18Next()
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, warnings, 0)
+
+	i18nodes, err := ExportI18N(ep, ExportI18NOptions{LocaleKey: LocaleKeyISO1})
+	testza.AssertNoError(t, err)
+	exported, err := I18NNodeToI18Next(i18nodes)
+	testza.AssertNoError(t, err)
+	en := exported["en"].(map[string]interface{})
+	// The gettext-format cannot represent both the key without plural-suffix and the plural-forms at the same time.
+	delete(en["General"].(map[string]interface{})["Forms"].(map[string]interface{}), "Items")
+
+	if err := internal.Compare("round-trip", i18n, en); err != nil {
+		t.Error(err)
+	}
+	testza.AssertEqual(t, "Number of items in the cart.\nShown in the header", meta["General.Forms.Items"].Description)
+	testza.AssertEqual(t, []string{"src/Header.tsx:42", "src/Cart.tsx:12"}, meta["General.Forms.Items"].References)
+}
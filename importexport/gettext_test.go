@@ -0,0 +1,146 @@
+package importexport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/internal"
+	"github.com/runar-rkmedia/skiver/plurals"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func gettextTestProject() types.ExtendedProject {
+	return types.ExtendedProject{
+		Locales: map[string]types.Locale{
+			"loc-en": {Entity: types.Entity{ID: "loc-en"}, Iso639_1: "en", Iso639_2: "en", Iso639_3: "eng", IETF: "en-US"},
+			"loc-no": {Entity: types.Entity{ID: "loc-no"}, Iso639_1: "no", Iso639_2: "no", Iso639_3: "nor", IETF: "nb-NO"},
+		},
+		Project: types.Project{
+			Title: "Project Foo",
+		},
+		Categories: map[string]types.ExtendedCategory{
+			"cat-root": {
+				Category: types.Category{Key: ""},
+				Translations: map[string]types.ExtendedTranslation{
+					"t-404": {
+						Translation: types.Translation{Key: "404Page"},
+						Values: map[string]types.TranslationValue{
+							"tv-a": {LocaleID: "loc-en", Value: "This page is missing"},
+						},
+					},
+				},
+			},
+			"cat-a": {
+				Category: types.Category{Key: "General.Forms"},
+				Translations: map[string]types.ExtendedTranslation{
+					"t-a": {
+						Translation: types.Translation{
+							Key:         "Items",
+							Description: "Number of items in the cart.\nShown in the header",
+							References:  []string{"src/Header.tsx:42", "src/Cart.tsx:12"},
+						},
+						Values: map[string]types.TranslationValue{
+							"tv-a": {LocaleID: "loc-en", Value: "Items", Context: map[string]string{
+								"one":        "{{count}} item",
+								"other":      "{{count}} items",
+								"male_one":   "He has {{count}} item",
+								"male_other": "He has {{count}} items",
+							}},
+							"tv-b": {LocaleID: "loc-no", Value: "Ting", Context: map[string]string{
+								"one":   "{{count}} ting",
+								"other": "{{count}} ting",
+							}},
+						},
+					},
+					"t-b": {
+						Translation: types.Translation{Key: "Submit"},
+						Values: map[string]types.TranslationValue{
+							"tv-a": {LocaleID: "loc-en", Value: "Submit \"now\"", Context: map[string]string{"formal": "Please submit"}},
+							"tv-b": {LocaleID: "loc-no", Value: "Send inn\npå flere linjer"},
+						},
+					},
+					"t-deleted": {
+						Translation: types.Translation{Key: "Deleted", Entity: types.Entity{Deleted: &time.Time{}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestExportGettext(t *testing.T) {
+	ep := gettextTestProject()
+	tests := []struct {
+		name   string
+		locale *types.Locale
+	}{
+		{"English", &types.Locale{Entity: types.Entity{ID: "loc-en"}, IETF: "en-US", Iso639_1: "en"}},
+		{"Norwegian", &types.Locale{Entity: types.Entity{ID: "loc-no"}, IETF: "nb-NO", Iso639_1: "no"}},
+		{"Polish", &types.Locale{Entity: types.Entity{ID: "loc-pl"}, IETF: "pl-PL", Iso639_1: "pl"}},
+		{"Template", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ExportGettext(ep, tt.locale, LocaleKeyEnumIETF)
+			testza.AssertNoError(t, err)
+			internal.MatchSnapshot(t, "po", b)
+		})
+	}
+}
+
+func TestGettextPluralExpression(t *testing.T) {
+	tests := []struct {
+		ietf string
+		want string
+	}{
+		{"en-US", "(n != 1)"},
+		{"fr-FR", "(n > 1)"},
+		{"is-IS", "(n%10 != 1 || n%100 == 11)"},
+		{"mk-MK", "(n%10 != 1)"},
+		{"ja-JP", "0"},
+		{"pl-PL", "(n == 1 ? 0 : n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14) ? 1 : 2)"},
+		{"ru-RU", "(n%10 == 1 && n%100 != 11 ? 0 : n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14) ? 1 : 2)"},
+		{"uk-UA", "(n%10 == 1 && n%100 != 11 ? 0 : n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14) ? 1 : 2)"},
+		{"cs-CZ", "(n == 1 ? 0 : n >= 2 && n <= 4 ? 1 : 3)"},
+		{"lt-LT", "(n%10 == 1 && (n%100 < 11 || n%100 > 19) ? 0 : n%10 >= 2 && (n%100 < 11 || n%100 > 19) ? 1 : 3)"},
+		{"hr-HR", "(n%10 == 1 && n%100 != 11 ? 0 : n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14) ? 1 : 2)"},
+		{"ro-RO", "(n == 1 ? 0 : n == 0 || (n%100 >= 1 && n%100 <= 19) ? 1 : 2)"},
+		{"lv-LV", "(n%10 == 0 || (n%100 >= 11 && n%100 <= 19) ? 0 : n%10 == 1 && n%100 != 11 ? 1 : 2)"},
+		{"sl-SI", "(n%100 == 1 ? 0 : n%100 == 2 ? 1 : n%100 == 3 || n%100 == 4 ? 2 : 3)"},
+		{"ar-EG", "(n == 0 ? 0 : n == 1 ? 1 : n == 2 ? 2 : n%100 >= 3 && n%100 <= 10 ? 3 : n%100 >= 11 ? 4 : 5)"},
+		{"cy-GB", "(n == 0 ? 0 : n == 1 ? 1 : n == 2 ? 2 : n == 3 ? 3 : n == 6 ? 4 : 5)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ietf, func(t *testing.T) {
+			locale := types.Locale{IETF: tt.ietf}
+			rules, ok := plurals.ForLocale(locale)
+			testza.AssertTrue(t, ok)
+			testza.AssertEqual(t, tt.want, gettextPluralExpression(locale, rules.Categories))
+		})
+	}
+	// Values may use categories that the locale does not, which no expression can select
+	testza.AssertEqual(t, "", gettextPluralExpression(types.Locale{IETF: "en-US"}, []string{"zero", "one", "other"}))
+}
+
+func TestSplitContextAndPlural(t *testing.T) {
+	tests := []struct {
+		in, context, plural string
+	}{
+		{"one", "", "one"},
+		{"male_one", "male", "one"},
+		{"male", "male", ""},
+		{"male_female", "male_female", ""},
+		{"_other", "_other", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			ctx, plural := SplitContextAndPlural(tt.in)
+			testza.AssertEqual(t, tt.context, ctx)
+			testza.AssertEqual(t, tt.plural, plural)
+			if plural != "" {
+				testza.AssertEqual(t, tt.in, JoinContextAndPlural(ctx, plural))
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package importexport
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/interpolator"
+	"github.com/runar-rkmedia/skiver/interpolator/parser"
+	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+// ICU MessageFormat-support
+//
+// i18next uses key-suffixes for plurals and contexts, while ICU uses a single message with plural- and select-arguments.
+// When exporting with the ICU-option, all the values of a translation are joined into a single message:
+//
+//   Items_one: "{{count}} item", Items_other: "{{count}} items"
+//   => Items: "{count, plural, one {# item} other {# items}}"
+//
+// Contexts are selected with the variable `context`, which is the same option that i18next uses.
+
+const (
+	icuPluralVariable  = "count"
+	icuContextVariable = "context"
+)
+
+// Returns an example-value for the variable, based on its type
+func icuVariableExample(v parser.ICUVariable, interpolationMaps []map[string]interface{}) interface{} {
+	example := getValueForVariableKey(v.Name, interpolationMaps)
+	switch v.Type {
+	case "number", "plural", "selectordinal", "spellout", "ordinal", "duration":
+		switch example.(type) {
+		case int, int64, float64:
+			return example
+		}
+		return 42
+	case "date", "time":
+		if _, ok := example.(time.Time); ok {
+			return example
+		}
+		return interpolator.DefaultInterpolationExamples["date"]
+	case "select":
+		for _, o := range v.Options {
+			if o != "other" {
+				return o
+			}
+		}
+		return "other"
+	}
+	return example
+}
+
+// A message for a single context, with its plurals
+type icuGroup struct {
+	value   string
+	plurals map[string]string
+}
+
+// TranslationValueToICU joins the value and all the contexts of a TranslationValue into a single ICU-message.
+// i18next-interpolations like `{{name}}` are converted to `{name}`.
+func TranslationValueToICU(tv types.TranslationValue) string {
+	groups := map[string]*icuGroup{"": {value: tv.Value, plurals: map[string]string{}}}
+	for k, v := range tv.Context {
+		ctx, plural := SplitContextAndPlural(k)
+		g, ok := groups[ctx]
+		if !ok {
+			g = &icuGroup{plurals: map[string]string{}}
+			groups[ctx] = g
+		}
+		if plural == "" {
+			g.value = v
+		} else {
+			g.plurals[plural] = v
+		}
+	}
+	if len(groups) == 1 {
+		return groups[""].message()
+	}
+	var b strings.Builder
+	b.WriteString("{" + icuContextVariable + ", select,")
+	for _, ctx := range utils.SortedMapKeys(groups) {
+		if ctx == "" {
+			continue
+		}
+		b.WriteString(" " + ctx + " {" + groups[ctx].message() + "}")
+	}
+	b.WriteString(" other {" + groups[""].message() + "}}")
+	return b.String()
+}
+
+func (g icuGroup) message() string {
+	if len(g.plurals) == 0 {
+		return i18NextToICU(g.value, false)
+	}
+	if _, ok := g.plurals["other"]; !ok {
+		// ICU requires the other-option
+		g.plurals["other"] = g.value
+	}
+	categories := utils.SortedMapKeys(g.plurals)
+	SortPluralCategories(categories)
+	var b strings.Builder
+	b.WriteString("{" + icuPluralVariable + ", plural,")
+	for _, c := range categories {
+		b.WriteString(" " + c + " {" + i18NextToICU(g.plurals[c], true) + "}")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+var (
+	i18NextInterpolationRegex = regexp.MustCompile(`{{\s*([^\s,}]+)\s*(?:,\s*([^}]*?))?\s*}}`)
+	icuEscaper                = strings.NewReplacer(`'`, `''`, `{`, `'{'`, `}`, `'}'`)
+	icuPluralEscaper          = strings.NewReplacer(`'`, `''`, `{`, `'{'`, `}`, `'}'`, `#`, `'#'`)
+)
+
+// Converts a i18next-value to ICU, by escaping any syntax-characters and converting the interpolations.
+// Within plurals, `{{count}}` is converted to `#`.
+func i18NextToICU(s string, inPlural bool) string {
+	escaper := icuEscaper
+	if inPlural {
+		escaper = icuPluralEscaper
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range i18NextInterpolationRegex.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(escaper.Replace(s[last:m[0]]))
+		last = m[1]
+		name := s[m[2]:m[3]]
+		format := ""
+		if m[4] >= 0 {
+			format = strings.TrimSpace(s[m[4]:m[5]])
+		}
+		switch {
+		case inPlural && name == icuPluralVariable && format == "":
+			b.WriteString("#")
+		case format == "":
+			b.WriteString("{" + name + "}")
+		case format == "datetime":
+			b.WriteString("{" + name + ", date}")
+		default:
+			b.WriteString("{" + name + ", " + format + "}")
+		}
+	}
+	b.WriteString(escaper.Replace(s[last:]))
+	return b.String()
+}
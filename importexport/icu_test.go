@@ -0,0 +1,92 @@
+package importexport
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/internal"
+	"github.com/runar-rkmedia/skiver/interpolator"
+	"github.com/runar-rkmedia/skiver/interpolator/parser"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestTranslationValueToICU(t *testing.T) {
+	tests := []struct {
+		name string
+		tv   types.TranslationValue
+		want string
+	}{
+		{
+			"Simple value with interpolation and escaping",
+			types.TranslationValue{Value: "Hi {{name}}, it's {{when, datetime}} {curly}"},
+			"Hi {name}, it''s {when, date} '{'curly'}'",
+		},
+		{
+			"Plurals",
+			types.TranslationValue{Value: "Items", Context: map[string]string{
+				"one":   "{{count}} item",
+				"other": "{{count}} items #1",
+			}},
+			"{count, plural, one {# item} other {# items '#'1}}",
+		},
+		{
+			"Contexts with plurals",
+			types.TranslationValue{Value: "Submit", Context: map[string]string{
+				"formal":     "Please submit",
+				"male_one":   "He has {{count}} item",
+				"male_other": "He has {{count}} items",
+			}},
+			"{context, select, formal {Please submit} male {{count, plural, one {He has # item} other {He has # items}}} other {Submit}}",
+		},
+	}
+	p := parser.NewICUParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TranslationValueToICU(tt.tv)
+			testza.AssertEqual(t, tt.want, got)
+			_, err := p.Parse(got)
+			testza.AssertNoError(t, err, "The output should be valid ICU")
+		})
+	}
+}
+
+func TestInferVariablesICU(t *testing.T) {
+	w, variables, _ := InferVariables(
+		"{name} bought {count, plural, one {# item} other {# items}} on {when, date, short} for {gender, select, male {him} other {them}}",
+		"cat", "key", []map[string]interface{}{interpolator.DefaultInterpolationExamples})
+	testza.AssertLen(t, w, 0)
+	want := map[string]interface{}{
+		"name":   interpolator.DefaultInterpolationExamples["name"],
+		"count":  interpolator.DefaultInterpolationExamples["count"],
+		"when":   interpolator.DefaultInterpolationExamples["date"],
+		"gender": "male",
+	}
+	if err := internal.Compare("variables", variables, want); err != nil {
+		t.Error(err)
+	}
+
+	// i18next-values are not parsed as ICU
+	w, variables, _ = InferVariables("{{count}} items", "cat", "key", []map[string]interface{}{interpolator.DefaultInterpolationExamples})
+	testza.AssertLen(t, w, 0)
+	testza.AssertEqual(t, map[string]interface{}{"count": 42}, variables)
+}
+
+func TestExportI18NWithICU(t *testing.T) {
+	ep := gettextTestProject()
+	i18nodes, err := ExportI18N(ep, ExportI18NOptions{LocaleKey: LocaleKeyISO1, ICU: true})
+	testza.AssertNoError(t, err)
+	exported, err := I18NNodeToI18Next(i18nodes)
+	testza.AssertNoError(t, err)
+	want := map[string]interface{}{
+		"General": map[string]interface{}{
+			"Forms": map[string]interface{}{
+				"Items":  "{context, select, male {{count, plural, one {He has # item} other {He has # items}}} other {{count, plural, one {# item} other {# items}}}}",
+				"Submit": `{context, select, formal {Please submit} other {Submit "now"}}`,
+			},
+		},
+		"404Page": "This page is missing",
+	}
+	if err := internal.Compare("icu", exported["en"], want); err != nil {
+		t.Error(err)
+	}
+}
@@ -21,6 +21,43 @@ type Import struct {
 	Categories map[string]types.ExtendedCategory
 }
 
+// TranslationMeta is information about a translation that some import-formats carry
+// alongside the values, like gettext-comments.
+type TranslationMeta struct {
+	Description string
+	References  []string
+}
+
+// AddTranslationMeta sets the meta-information on the translations within the import.
+// The keys of the map should be the full key of the translation, like `General.Forms.Submit`.
+func (imp *Import) AddTranslationMeta(meta map[string]TranslationMeta) {
+	if len(meta) == 0 {
+		return
+	}
+	for ck, c := range imp.Categories {
+		for tk, t := range c.Translations {
+			fullKey := t.Key
+			if !c.IsRoot() {
+				fullKey = c.Key + "." + t.Key
+			}
+			m, ok := meta[fullKey]
+			if !ok {
+				continue
+			}
+			if m.Description != "" {
+				t.Description = m.Description
+			}
+			for _, ref := range m.References {
+				if !utils.Contains(t.References, ref) {
+					t.References = append(t.References, ref)
+				}
+			}
+			c.Translations[tk] = t
+		}
+		imp.Categories[ck] = c
+	}
+}
+
 type WarningLevel string
 type WarningKind string
 
@@ -29,6 +66,8 @@ const (
 	WarningLevelMajor               WarningLevel = "major"
 	WarningKindTranslationVariables WarningKind  = "translation-variable"
 	WarningKindTranslationReference WarningKind  = "translation-reference"
+	WarningKindImportFormat         WarningKind  = "import-format"
+	WarningKindTranslationPlurals   WarningKind  = "translation-plurals"
 )
 
 type Warning struct {
@@ -491,6 +530,19 @@ func InferVariables(translationValue, category, translation string, interpolatio
 			variables[key] = getValueForVariableKey(key, interpolationMaps)
 		}
 	}
+	// ICU MessageFormat uses single braces, while `{{` is not valid in ICU, so these values are assumed to be i18next.
+	if strings.Contains(translationValue, "{") && !strings.Contains(translationValue, "{{") {
+		ast, err := parser.NewICUParser().Parse(translationValue)
+		if err != nil {
+			warn := newWarning("There was a problem parsing the translation-value as ICU MessageFormat.", WarningKindTranslationVariables, WarningLevelMinor)
+			warn.Error = err
+			w = append(w, warn)
+		} else {
+			for name, v := range ast.Variables() {
+				variables[name] = icuVariableExample(v, interpolationMaps)
+			}
+		}
+	}
 	sort.Strings(refs)
 	return w, variables, refs
 }
@@ -645,6 +697,14 @@ func ImportI18NTranslation(
 		rootNode.OrganizationID = base.OrganizationID
 		imp.Categories[types.RootCategory] = rootNode
 	}
+	localesByID := make(map[string]types.Locale, len(locales)+1)
+	for _, l := range locales {
+		localesByID[l.ID] = l
+	}
+	if locale.ID != "" {
+		localesByID[locale.ID] = locale
+	}
+	w = append(w, imp.validatePlurals(localesByID)...)
 	return &imp, w, nil
 }
 func getValueForVariableKey(key string, interpolationMaps []map[string]interface{}) interface{} {
@@ -0,0 +1,524 @@
+package importexport
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+// iOS-support (Localizable.strings and Localizable.stringsdict)
+//
+// The full key of the translation is used as the key, like `General.Forms.Submit`.
+//
+// - Contexts are written as separate keys, with the i18next-suffix, like `General.Forms.Submit_formal`
+// - Plurals are written to the .stringsdict-file, as a NSStringPluralRuleType for the variable `count`.
+//   All other values are written to the .strings-file.
+// - Translation.Description is written as a comment before the key in the .strings-file
+//
+// Values are written as-is, so any interpolation like `{{count}}` is kept.
+
+const (
+	ContentTypeIOSStrings     = "text/plain; charset=utf-8"
+	ContentTypeIOSStringsDict = "application/x-plist"
+
+	stringsDictPluralVariable = "count"
+)
+
+// ExportIOSStrings exports the non-plural values for a single locale as a Localizable.strings-file.
+func ExportIOSStrings(ep types.ExtendedProject, locale types.Locale) ([]byte, error) {
+	var w bytes.Buffer
+	fmt.Fprintf(&w, "/* %s: %s.lproj/Localizable.strings */\n", iosComment(ep.Title), locale.IETF)
+	for _, t := range FlattenExtendedProject(ep) {
+		values := i18nextValues(t.Values[locale.ID])
+		first := true
+		for _, suffix := range utils.SortedMapKeys(values) {
+			if _, plural := SplitContextAndPlural(suffix); plural != "" {
+				continue
+			}
+			if first {
+				w.WriteString("\n")
+				if t.Description != "" {
+					fmt.Fprintf(&w, "/* %s */\n", iosComment(t.Description))
+				}
+				first = false
+			}
+			key := t.FullKey
+			if suffix != "" {
+				key += "_" + suffix
+			}
+			fmt.Fprintf(&w, "\"%s\" = \"%s\";\n", iosEscape(key), iosEscape(values[suffix]))
+		}
+	}
+	return w.Bytes(), nil
+}
+
+// ExportIOSStringsDict exports the plural values for a single locale as a Localizable.stringsdict-file.
+func ExportIOSStringsDict(ep types.ExtendedProject, locale types.Locale) ([]byte, error) {
+	var w bytes.Buffer
+	w.WriteString(xml.Header)
+	w.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	w.WriteString("<plist version=\"1.0\">\n<dict>\n")
+	for _, t := range FlattenExtendedProject(ep) {
+		for _, r := range androidResources(t.FullKey, t.Values[locale.ID]) {
+			if r.plurals == nil {
+				continue
+			}
+			fmt.Fprintf(&w, "\t<key>%s</key>\n\t<dict>\n", xmlEscape(r.name))
+			fmt.Fprintf(&w, "\t\t<key>NSStringLocalizedFormatKey</key>\n\t\t<string>%%#@%s@</string>\n", stringsDictPluralVariable)
+			fmt.Fprintf(&w, "\t\t<key>%s</key>\n\t\t<dict>\n", stringsDictPluralVariable)
+			w.WriteString("\t\t\t<key>NSStringFormatSpecTypeKey</key>\n\t\t\t<string>NSStringPluralRuleType</string>\n")
+			w.WriteString("\t\t\t<key>NSStringFormatValueTypeKey</key>\n\t\t\t<string>d</string>\n")
+			categories := utils.SortedMapKeys(r.plurals)
+			SortPluralCategories(categories)
+			for _, c := range categories {
+				fmt.Fprintf(&w, "\t\t\t<key>%s</key>\n\t\t\t<string>%s</string>\n", c, xmlEscape(r.plurals[c]))
+			}
+			w.WriteString("\t\t</dict>\n\t</dict>\n")
+		}
+	}
+	w.WriteString("</dict>\n</plist>\n")
+	return w.Bytes(), nil
+}
+
+// Comments cannot contain `*/`
+func iosComment(s string) string {
+	return strings.ReplaceAll(s, "*/", "* /")
+}
+
+var iosEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\t", `\t`,
+	"\r", `\r`,
+)
+
+func iosEscape(s string) string {
+	return iosEscaper.Replace(s)
+}
+
+// IOSStringsEntry is a key-value-pair from a .strings-file
+type IOSStringsEntry struct {
+	Key     string
+	Value   string
+	Comment string
+}
+
+type IOSStringsFile struct {
+	Entries []IOSStringsEntry
+}
+
+// ParseIOSStrings parses a .strings-file, in UTF-8 or UTF-16 (with a byte-order-mark)
+func ParseIOSStrings(b []byte) (*IOSStringsFile, error) {
+	s, err := decodeIOSStrings(b)
+	if err != nil {
+		return nil, err
+	}
+	p := iosStringsParser{s: []rune(s), line: 1}
+	f := IOSStringsFile{}
+	var comment string
+	for {
+		c, err := p.skipWhitespaceAndComments()
+		if err != nil {
+			return nil, err
+		}
+		if c != "" {
+			comment = c
+		}
+		if p.eof() {
+			break
+		}
+		key, err := p.readString()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.skipWhitespaceAndComments(); err != nil {
+			return nil, err
+		}
+		if err := p.expect('='); err != nil {
+			return nil, err
+		}
+		if _, err := p.skipWhitespaceAndComments(); err != nil {
+			return nil, err
+		}
+		value, err := p.readString()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.skipWhitespaceAndComments(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(';'); err != nil {
+			return nil, err
+		}
+		f.Entries = append(f.Entries, IOSStringsEntry{Key: key, Value: value, Comment: comment})
+		comment = ""
+	}
+	return &f, nil
+}
+
+func decodeIOSStrings(b []byte) (string, error) {
+	var order func([]byte) uint16
+	switch {
+	case bytes.HasPrefix(b, []byte{0xff, 0xfe}):
+		order = func(b []byte) uint16 { return uint16(b[0]) | uint16(b[1])<<8 }
+	case bytes.HasPrefix(b, []byte{0xfe, 0xff}):
+		order = func(b []byte) uint16 { return uint16(b[1]) | uint16(b[0])<<8 }
+	default:
+		b = bytes.TrimPrefix(b, []byte("\ufeff"))
+		if !utf8.Valid(b) {
+			return "", fmt.Errorf("the file is not valid UTF-8, nor UTF-16 with a byte-order-mark")
+		}
+		return string(b), nil
+	}
+	b = b[2:]
+	if len(b)%2 != 0 {
+		return "", fmt.Errorf("the file has an odd number of bytes for UTF-16")
+	}
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = order(b[i*2:])
+	}
+	return string(utf16.Decode(u)), nil
+}
+
+type iosStringsParser struct {
+	s    []rune
+	pos  int
+	line int
+}
+
+func (p *iosStringsParser) eof() bool { return p.pos >= len(p.s) }
+
+func (p *iosStringsParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *iosStringsParser) next() rune {
+	r := p.s[p.pos]
+	p.pos++
+	if r == '\n' {
+		p.line++
+	}
+	return r
+}
+
+func (p *iosStringsParser) expect(r rune) error {
+	if p.eof() {
+		return p.errorf("expected '%c', but got end of file", r)
+	}
+	if got := p.next(); got != r {
+		return p.errorf("expected '%c', but got '%c'", r, got)
+	}
+	return nil
+}
+
+// Skips whitespace and comments, and returns the last comment found.
+// Comments followed by a blank line, like a file-header, are not returned.
+func (p *iosStringsParser) skipWhitespaceAndComments() (string, error) {
+	var comment string
+	newlines := 0
+	for !p.eof() {
+		r := p.s[p.pos]
+		switch {
+		case unicode.IsSpace(r):
+			if p.next() == '\n' {
+				newlines++
+				if newlines > 1 {
+					comment = ""
+				}
+			}
+		case r == '/' && p.pos+1 < len(p.s) && p.s[p.pos+1] == '*':
+			p.pos += 2
+			start := p.pos
+			for {
+				if p.pos+1 >= len(p.s) {
+					return "", p.errorf("unterminated comment")
+				}
+				if p.s[p.pos] == '*' && p.s[p.pos+1] == '/' {
+					break
+				}
+				p.next()
+			}
+			comment = strings.TrimSpace(string(p.s[start:p.pos]))
+			p.pos += 2
+			newlines = 0
+		case r == '/' && p.pos+1 < len(p.s) && p.s[p.pos+1] == '/':
+			start := p.pos + 2
+			for !p.eof() && p.s[p.pos] != '\n' {
+				p.next()
+			}
+			comment = strings.TrimSpace(string(p.s[start:p.pos]))
+			newlines = 0
+		default:
+			return comment, nil
+		}
+	}
+	return comment, nil
+}
+
+// Reads a quoted string, or an unquoted string of alphanumeric characters
+func (p *iosStringsParser) readString() (string, error) {
+	if p.eof() {
+		return "", p.errorf("expected a string, but got end of file")
+	}
+	if p.s[p.pos] != '"' {
+		start := p.pos
+		for !p.eof() {
+			r := p.s[p.pos]
+			if !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.$:/-", r)) {
+				break
+			}
+			p.next()
+		}
+		if start == p.pos {
+			return "", p.errorf("expected a string, but got '%c'", p.s[p.pos])
+		}
+		return string(p.s[start:p.pos]), nil
+	}
+	p.next()
+	var out strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		r := p.next()
+		switch r {
+		case '"':
+			return out.String(), nil
+		case '\\':
+			if p.eof() {
+				return "", p.errorf("unterminated string")
+			}
+			e := p.next()
+			switch e {
+			case 'n':
+				out.WriteRune('\n')
+			case 't':
+				out.WriteRune('\t')
+			case 'r':
+				out.WriteRune('\r')
+			case '0':
+				out.WriteRune(0)
+			case 'U', 'u':
+				if p.pos+4 > len(p.s) {
+					return "", p.errorf("invalid unicode-escape")
+				}
+				n, err := strconv.ParseUint(string(p.s[p.pos:p.pos+4]), 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode-escape: %s", err)
+				}
+				p.pos += 4
+				out.WriteRune(rune(n))
+			default:
+				out.WriteRune(e)
+			}
+		default:
+			out.WriteRune(r)
+		}
+	}
+}
+
+// ToI18Next converts the .strings-file into a i18next-compatible map, for use with ImportI18NTranslation.
+// The keys are split into categories, see SplitKey.
+//
+// Comments are returned as TranslationMeta.
+func (f IOSStringsFile) ToI18Next() (map[string]interface{}, map[string]TranslationMeta, []Warning, error) {
+	out := map[string]interface{}{}
+	meta := map[string]TranslationMeta{}
+	for _, e := range f.Entries {
+		path := SplitKey(e.Key)
+		if e.Comment != "" {
+			fullKey := translationKeyOf(path)
+			if m := meta[fullKey]; m.Description == "" {
+				m.Description = e.Comment
+				meta[fullKey] = m
+			}
+		}
+		if e.Value == "" {
+			continue
+		}
+		if err := setInMap(out, path, e.Value); err != nil {
+			return out, meta, nil, fmt.Errorf("failed to set value for '%s': %w", e.Key, err)
+		}
+	}
+	return out, meta, nil, nil
+}
+
+// IOSStringsDictFile is the parsed content of a .stringsdict-file.
+// Only the values of plural-rules are kept.
+type IOSStringsDictFile struct {
+	// The plural-values, keyed by the key of the entry and then the plural-category
+	Plurals map[string]map[string]string
+	// Entries that could not be represented, like entries with multiple variables
+	skipped []string
+}
+
+var stringsDictVariableRegex = regexp.MustCompile(`%#@([^@]+)@`)
+
+// ParseIOSStringsDict parses a .stringsdict-file (an xml-plist)
+func ParseIOSStringsDict(b []byte) (*IOSStringsDictFile, error) {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	var root interface{}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("expected a plist-element")
+			}
+			return nil, err
+		}
+		if t, ok := tok.(xml.StartElement); ok {
+			if t.Name.Local != "plist" {
+				return nil, fmt.Errorf("expected the root-element to be plist, but got %s", t.Name.Local)
+			}
+			root, err = decodePlistValue(d, nil)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	entries, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected the plist to contain a dict")
+	}
+	f := IOSStringsDictFile{Plurals: map[string]map[string]string{}}
+	for _, key := range utils.SortedMapKeys(entries) {
+		entry, ok := entries[key].(map[string]interface{})
+		if !ok {
+			f.skipped = append(f.skipped, key)
+			continue
+		}
+		format, _ := entry["NSStringLocalizedFormatKey"].(string)
+		variables := stringsDictVariableRegex.FindAllStringSubmatch(format, -1)
+		// Formats with multiple variables, or with text around the variable cannot be represented as i18next-plurals
+		if len(variables) != 1 || variables[0][0] != format {
+			f.skipped = append(f.skipped, key)
+			continue
+		}
+		rule, ok := entry[variables[0][1]].(map[string]interface{})
+		if !ok || rule["NSStringFormatSpecTypeKey"] != "NSStringPluralRuleType" {
+			f.skipped = append(f.skipped, key)
+			continue
+		}
+		plurals := map[string]string{}
+		for k, v := range rule {
+			if s, ok := v.(string); ok && IsPluralCategory(k) {
+				plurals[k] = s
+			}
+		}
+		f.Plurals[key] = plurals
+	}
+	return &f, nil
+}
+
+// Decodes the next plist-value from the decoder, where start is the element already read, if any.
+// Dicts are returned as map[string]interface{}, arrays as []interface{}, and all other values as strings.
+func decodePlistValue(d *xml.Decoder, start *xml.StartElement) (interface{}, error) {
+	for start == nil {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			start = &t
+		case xml.EndElement:
+			return nil, nil
+		}
+	}
+	switch start.Name.Local {
+	case "dict":
+		m := map[string]interface{}{}
+		var key *string
+		for {
+			tok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.EndElement:
+				return m, nil
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					var k string
+					if err := d.DecodeElement(&k, &t); err != nil {
+						return nil, err
+					}
+					key = &k
+					continue
+				}
+				if key == nil {
+					return nil, fmt.Errorf("expected a key before the %s-element in dict", t.Name.Local)
+				}
+				v, err := decodePlistValue(d, &t)
+				if err != nil {
+					return nil, err
+				}
+				m[*key] = v
+				key = nil
+			}
+		}
+	case "array":
+		var list []interface{}
+		for {
+			v, err := decodePlistValue(d, nil)
+			if err != nil {
+				return nil, err
+			}
+			if v == nil {
+				return list, nil
+			}
+			list = append(list, v)
+		}
+	case "true", "false":
+		if err := d.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local, nil
+	default:
+		var s string
+		if err := d.DecodeElement(&s, start); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+}
+
+// ToI18Next converts the .stringsdict-file into a i18next-compatible map, for use with ImportI18NTranslation.
+// The keys are split into categories, see SplitKey.
+func (f IOSStringsDictFile) ToI18Next() (map[string]interface{}, []Warning, error) {
+	var warnings []Warning
+	for _, key := range f.skipped {
+		w := newWarning(fmt.Sprintf("Skipped the entry '%s', since only entries with a single plural-variable are supported", key), WarningKindImportFormat, WarningLevelMajor)
+		warnings = append(warnings, w)
+	}
+	out := map[string]interface{}{}
+	for _, key := range utils.SortedMapKeys(f.Plurals) {
+		path := SplitKey(key)
+		for _, category := range utils.SortedMapKeys(f.Plurals[key]) {
+			value := f.Plurals[key][category]
+			if value == "" {
+				continue
+			}
+			p := make([]string, len(path))
+			copy(p, path)
+			p[len(p)-1] += "_" + category
+			if err := setInMap(out, p, value); err != nil {
+				return out, warnings, fmt.Errorf("failed to set value for '%s': %w", key, err)
+			}
+		}
+	}
+	return out, warnings, nil
+}
@@ -0,0 +1,269 @@
+package importexport
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/internal"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+// Returns the i18next-tree for a single locale from the project
+func exportedI18Next(t *testing.T, ep types.ExtendedProject, localeKey string) map[string]interface{} {
+	i18nodes, err := ExportI18N(ep, ExportI18NOptions{LocaleKey: LocaleKeyISO1})
+	testza.AssertNoError(t, err)
+	exported, err := I18NNodeToI18Next(i18nodes)
+	testza.AssertNoError(t, err)
+	return exported[localeKey].(map[string]interface{})
+}
+
+func TestAndroid(t *testing.T) {
+	ep := gettextTestProject()
+	for _, localeID := range []string{"loc-en", "loc-no"} {
+		locale := ep.Locales[localeID]
+		t.Run(locale.IETF, func(t *testing.T) {
+			b, err := ExportAndroid(ep, locale)
+			testza.AssertNoError(t, err)
+			internal.MatchSnapshot(t, "xml", b)
+
+			f, err := ParseAndroid(b)
+			testza.AssertNoError(t, err)
+			i18n, meta, warnings, err := f.ToI18Next()
+			testza.AssertNoError(t, err)
+			testza.AssertLen(t, warnings, 0)
+			if err := internal.Compare("round-trip", i18n, exportedI18Next(t, ep, locale.Iso639_1)); err != nil {
+				t.Error(err)
+			}
+			testza.AssertEqual(t, "Number of items in the cart.\nShown in the header", meta["General.Forms.Items"].Description)
+		})
+	}
+}
+
+func TestIOS(t *testing.T) {
+	ep := gettextTestProject()
+	for _, localeID := range []string{"loc-en", "loc-no"} {
+		locale := ep.Locales[localeID]
+		t.Run(locale.IETF, func(t *testing.T) {
+			strings, err := ExportIOSStrings(ep, locale)
+			testza.AssertNoError(t, err)
+			internal.MatchSnapshot(t, "strings", strings)
+			stringsDict, err := ExportIOSStringsDict(ep, locale)
+			testza.AssertNoError(t, err)
+			internal.MatchSnapshot(t, "stringsdict", stringsDict)
+
+			sf, err := ParseIOSStrings(strings)
+			testza.AssertNoError(t, err)
+			i18n, meta, _, err := sf.ToI18Next()
+			testza.AssertNoError(t, err)
+			df, err := ParseIOSStringsDict(stringsDict)
+			testza.AssertNoError(t, err)
+			plurals, warnings, err := df.ToI18Next()
+			testza.AssertNoError(t, err)
+			testza.AssertLen(t, warnings, 0)
+			if err := internal.Compare("round-trip", mergeI18Next(i18n, plurals), exportedI18Next(t, ep, locale.Iso639_1)); err != nil {
+				t.Error(err)
+			}
+			testza.AssertEqual(t, "Number of items in the cart.\nShown in the header", meta["General.Forms.Items"].Description)
+		})
+	}
+}
+
+func mergeI18Next(a, b map[string]interface{}) map[string]interface{} {
+	for k, v := range b {
+		am, aok := a[k].(map[string]interface{})
+		bm, bok := v.(map[string]interface{})
+		if aok && bok {
+			a[k] = mergeI18Next(am, bm)
+			continue
+		}
+		a[k] = v
+	}
+	return a
+}
+
+func TestParseAndroid(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantI18n map[string]interface{}
+		wantErr  bool
+	}{
+		{
+			name: "Escapes, whitespace and markup",
+			input: `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <!-- The title -->
+    <string name="General.Title">My   \'app\'
+      is \"great\"</string>
+    <string name="General.Spaced">"  keep   spaces  "</string>
+    <string name="General.Bold">Hello <b>you</b> &amp; me</string>
+    <string name="General.Unicode">æøå</string>
+    <string-array name="Planets">
+        <item>Mercury</item>
+    </string-array>
+    <plurals name="Items_male">
+        <item quantity="one">He has %d item</item>
+        <item quantity="other">He has %d items</item>
+    </plurals>
+</resources>`,
+			wantI18n: map[string]interface{}{
+				"General": map[string]interface{}{
+					"Title":   `My 'app' is "great"`,
+					"Spaced":  "  keep   spaces  ",
+					"Bold":    "Hello <b>you</b> & me",
+					"Unicode": "æøå",
+				},
+				"Items_male_one":   "He has %d item",
+				"Items_male_other": "He has %d items",
+			},
+		},
+		{
+			name:    "Not a resources-file",
+			input:   `<plist><dict></dict></plist>`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseAndroid([]byte(tt.input))
+			if tt.wantErr {
+				testza.AssertNotNil(t, err)
+				return
+			}
+			testza.AssertNoError(t, err)
+			i18n, meta, _, err := f.ToI18Next()
+			testza.AssertNoError(t, err)
+			if err := internal.Compare("i18n", i18n, tt.wantI18n); err != nil {
+				t.Error(err)
+			}
+			testza.AssertEqual(t, "The title", meta["General.Title"].Description)
+		})
+	}
+}
+
+func TestParseIOSStrings(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		wantI18n map[string]interface{}
+		wantErr  bool
+	}{
+		{
+			name: "Comments, escapes and unquoted keys",
+			input: []byte(`/* File header */
+
+/* The title */
+"General.Title" = "My \"app\"\nis great";
+// A line-comment
+General.Title_formal = "My application";
+"Hello. Welcome" = "Hei. Velkommen";
+"General.Unicode" = "\U00e6\U00f8\U00e5";
+`),
+			wantI18n: map[string]interface{}{
+				"General": map[string]interface{}{
+					"Title":        "My \"app\"\nis great",
+					"Title_formal": "My application",
+					"Unicode":      "æøå",
+				},
+				"Hello. Welcome": "Hei. Velkommen",
+			},
+		},
+		{
+			name: "UTF-16 with byte-order-mark",
+			// "a" = "b";
+			input: []byte{0xff, 0xfe, '"', 0, 'a', 0, '"', 0, '=', 0, '"', 0, 'b', 0, '"', 0, ';', 0},
+			wantI18n: map[string]interface{}{
+				"a": "b",
+			},
+		},
+		{
+			name:    "Missing semicolon",
+			input:   []byte(`"a" = "b"`),
+			wantErr: true,
+		},
+		{
+			name:    "Unterminated string",
+			input:   []byte(`"a" = "b;`),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseIOSStrings(tt.input)
+			if tt.wantErr {
+				testza.AssertNotNil(t, err)
+				return
+			}
+			testza.AssertNoError(t, err)
+			i18n, meta, _, err := f.ToI18Next()
+			testza.AssertNoError(t, err)
+			if err := internal.Compare("i18n", i18n, tt.wantI18n); err != nil {
+				t.Error(err)
+			}
+			if _, ok := meta["General.Title"]; ok {
+				testza.AssertEqual(t, "The title", meta["General.Title"].Description)
+			}
+		})
+	}
+}
+
+func TestParseIOSStringsDict(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Items</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%#@apples@</string>
+		<key>apples</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>NSStringFormatValueTypeKey</key>
+			<string>d</string>
+			<key>zero</key>
+			<string>No apples</string>
+			<key>one</key>
+			<string>%d apple</string>
+			<key>other</key>
+			<string>%d apples</string>
+		</dict>
+	</dict>
+	<key>Multiple</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%#@apples@ and %#@pears@</string>
+	</dict>
+	<key>Flags</key>
+	<array>
+		<true/>
+		<string>x</string>
+	</array>
+</dict>
+</plist>`
+	f, err := ParseIOSStringsDict([]byte(input))
+	testza.AssertNoError(t, err)
+	i18n, warnings, err := f.ToI18Next()
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, warnings, 2)
+	want := map[string]interface{}{
+		"Items_zero":  "No apples",
+		"Items_one":   "%d apple",
+		"Items_other": "%d apples",
+	}
+	if err := internal.Compare("i18n", i18n, want); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAndroidResourceQualifier(t *testing.T) {
+	tests := map[string]string{
+		"en":         "en",
+		"nb-NO":      "nb-rNO",
+		"sr-Latn-RS": "b+sr+Latn+RS",
+	}
+	for ietf, want := range tests {
+		testza.AssertEqual(t, want, AndroidResourceQualifier(types.Locale{IETF: ietf}))
+	}
+}
@@ -0,0 +1,84 @@
+package importexport
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runar-rkmedia/skiver/plurals"
+	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+// MissingPluralForms is the details of a warning for a translation-value that is missing some of the plural-forms
+// that the locale uses.
+type MissingPluralForms struct {
+	// The full key of the translation, like `General.Items`
+	Key      string   `json:"key"`
+	LocaleID string   `json:"locale_id"`
+	Context  string   `json:"context,omitempty"`
+	Missing  []string `json:"missing"`
+}
+
+// ValidatePlurals returns warnings if the value has plurals, but is missing some of the plural-forms required by the locale,
+// like `few` and `many` for Polish.
+// Each context is validated separately. Values without any plurals are not validated.
+func ValidatePlurals(fullKey string, tv types.TranslationValue, locale types.Locale) []Warning {
+	rules, ok := plurals.ForLocale(locale)
+	if !ok {
+		return nil
+	}
+	byContext := map[string][]string{}
+	for k := range tv.Context {
+		ctx, plural := SplitContextAndPlural(k)
+		if plural != "" {
+			byContext[ctx] = append(byContext[ctx], plural)
+		}
+	}
+	var w []Warning
+	for _, ctx := range utils.SortedMapKeys(byContext) {
+		missing := rules.Missing(byContext[ctx])
+		if len(missing) == 0 {
+			continue
+		}
+		key := fullKey
+		if ctx != "" {
+			key += "_" + ctx
+		}
+		warn := newWarning(
+			fmt.Sprintf("The translation '%s' is missing the plural-forms %s for the locale %s", key, strings.Join(missing, ", "), locale.IETF),
+			WarningKindTranslationPlurals,
+			WarningLevelMajor,
+		)
+		warn.Details = MissingPluralForms{
+			Key:      fullKey,
+			LocaleID: tv.LocaleID,
+			Context:  ctx,
+			Missing:  missing,
+		}
+		w = append(w, warn)
+	}
+	return w
+}
+
+// Validates the plurals of all the values within the import
+func (imp Import) validatePlurals(locales map[string]types.Locale) []Warning {
+	var w []Warning
+	for _, ck := range utils.SortedMapKeys(imp.Categories) {
+		c := imp.Categories[ck]
+		for _, tk := range utils.SortedMapKeys(c.Translations) {
+			t := c.Translations[tk]
+			fullKey := t.Key
+			if !c.IsRoot() {
+				fullKey = c.Key + "." + t.Key
+			}
+			for _, localeID := range utils.SortedMapKeys(t.Values) {
+				locale, ok := locales[localeID]
+				if !ok {
+					continue
+				}
+				w = append(w, ValidatePlurals(fullKey, t.Values[localeID], locale)...)
+			}
+		}
+	}
+	return w
+}
@@ -0,0 +1,46 @@
+package importexport
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestValidatePlurals(t *testing.T) {
+	polish := types.Locale{Entity: types.Entity{ID: "loc-pl"}, IETF: "pl-PL", Iso639_1: "pl"}
+	english := types.Locale{Entity: types.Entity{ID: "loc-en"}, IETF: "en-GB", Iso639_1: "en"}
+	tests := []struct {
+		name    string
+		locale  types.Locale
+		context map[string]string
+		want    []MissingPluralForms
+	}{
+		{"No plurals", polish, map[string]string{"male": "On"}, nil},
+		{"English is complete", english, map[string]string{"one": "item", "other": "items"}, nil},
+		{
+			"Polish is missing few and many",
+			polish,
+			map[string]string{"one": "przedmiot", "other": "przedmiotu"},
+			[]MissingPluralForms{{Key: "General.Items", LocaleID: "loc-pl", Missing: []string{"few", "many"}}},
+		},
+		{
+			"Each context is validated",
+			polish,
+			map[string]string{"one": "a", "few": "b", "many": "c", "other": "d", "male_one": "e", "male_other": "f"},
+			[]MissingPluralForms{{Key: "General.Items", LocaleID: "loc-pl", Context: "male", Missing: []string{"few", "many"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tv := types.TranslationValue{LocaleID: tt.locale.ID, Context: tt.context}
+			w := ValidatePlurals("General.Items", tv, tt.locale)
+			var got []MissingPluralForms
+			for _, warn := range w {
+				testza.AssertEqual(t, WarningKindTranslationPlurals, warn.Kind)
+				got = append(got, warn.Details.(MissingPluralForms))
+			}
+			testza.AssertEqual(t, tt.want, got)
+		})
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/Masterminds/sprig"
 	"github.com/jmespath/go-jmespath"
 	"github.com/pelletier/go-toml"
+	"github.com/runar-rkmedia/skiver/plurals"
 	"github.com/runar-rkmedia/skiver/types"
 	"github.com/runar-rkmedia/skiver/utils"
 	"gopkg.in/yaml.v2"
@@ -139,6 +140,36 @@ func PrepareTemplates() *template.Template {
 		}
 		return types.Locale{}, nil
 	}
+	// Returns the CLDR plural-forms used by the locale, like `one, few, many, other`
+	templateFuncs["pluralForms"] = func(locale types.Locale) string {
+		rules, ok := plurals.ForLocale(locale)
+		if !ok {
+			return ""
+		}
+		return strings.Join(rules.Categories, ", ")
+	}
+	// Returns the plural-forms of the translation-value, and any plural-forms that are missing for the locale.
+	// If the value has no plurals, an empty string is returned.
+	templateFuncs["valuePluralForms"] = func(tv types.TranslationValue, locale types.Locale) string {
+		seen := map[string]bool{}
+		for k := range tv.Context {
+			if _, plural := SplitContextAndPlural(k); plural != "" {
+				seen[plural] = true
+			}
+		}
+		if len(seen) == 0 {
+			return ""
+		}
+		forms := utils.SortedMapKeys(seen)
+		SortPluralCategories(forms)
+		s := strings.Join(forms, ", ")
+		if rules, ok := plurals.ForLocale(locale); ok {
+			if missing := rules.Missing(forms); len(missing) > 0 {
+				s += " (missing: " + strings.Join(missing, ", ") + ")"
+			}
+		}
+		return s
+	}
 	templateFuncs["structKeys"] = func(in interface{}) ([]string, error) {
 		val := reflect.Indirect(reflect.ValueOf(in))
 		kind := val.Kind()
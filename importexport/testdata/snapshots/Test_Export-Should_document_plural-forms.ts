@@ -0,0 +1,45 @@
+/**
+ * Generated translations for:
+ *
+ * Project: Project Foo ()
+ *
+ * Generated on: 1987-01-01
+ *
+ * Plural-forms per locale (CLDR):
+ *   - British (en-GB): one, other
+ *   - Polish (pl-PL): one, few, many, other
+ */
+const tKeys = {
+  /** General Category */
+  General: {
+    /**
+     * The number of items
+     * ### British
+     *
+     * Items
+     * 
+     * ####  context `one`:
+     * {{count}} item
+     * 
+     * ####  context `other`:
+     * {{count}} items
+     *
+     * Plural-forms: one, other
+     * ### Polish
+     *
+     * Przedmioty
+     * 
+     * ####  context `one`:
+     * {{count}} przedmiot
+     * 
+     * ####  context `other`:
+     * {{count}} przedmiotu
+     *
+     * Plural-forms: one, other (missing: few, many)
+     */
+    Items: 'General.Items',
+      
+  },
+}
+
+export default tKeys
@@ -4,6 +4,12 @@
  * Project: Project Foo ()
  *
  * Generated on: 1987-01-01
+ *
+ * Plural-forms per locale (CLDR):
+ *   - British (en-GB): one, other
+ *   - US English (en-US): one, other
+ *   - Norwegian bokmål (nb-NO): one, other
+ *   - Norwegian Nynorsk (nn-NO): one, other
  */
 const tKeys = {
     /**
@@ -0,0 +1,307 @@
+package importexport
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+// XLIFF-support (version 1.2 and 2.0)
+//
+// Each unit is a single i18next-key, including any context- or plural-suffix, like `General.Forms.Items_one`.
+// This way, the files are easy to import back into Skiver, while still being readable for translators.
+// Translation.Description is used as a note for each unit.
+
+const (
+	ContentTypeXliff = "application/x-xliff+xml"
+
+	xliffNamespace12 = "urn:oasis:names:tc:xliff:document:1.2"
+	xliffNamespace20 = "urn:oasis:names:tc:xliff:document:2.0"
+)
+
+type xliff12 struct {
+	XMLName xml.Name      `xml:"xliff"`
+	Xmlns   string        `xml:"xmlns,attr"`
+	Version string        `xml:"version,attr"`
+	Files   []xliff12File `xml:"file"`
+}
+type xliff12File struct {
+	Original       string         `xml:"original,attr"`
+	SourceLanguage string         `xml:"source-language,attr"`
+	TargetLanguage string         `xml:"target-language,attr,omitempty"`
+	Datatype       string         `xml:"datatype,attr"`
+	Units          []xliff12Unit  `xml:"body>trans-unit"`
+	Groups         []xliff12Group `xml:"body>group"`
+}
+type xliff12Group struct {
+	Units  []xliff12Unit  `xml:"trans-unit"`
+	Groups []xliff12Group `xml:"group"`
+}
+type xliff12Unit struct {
+	ID     string         `xml:"id,attr"`
+	Source string         `xml:"source"`
+	Target *xliff12Target `xml:"target"`
+	Notes  []string       `xml:"note"`
+}
+type xliff12Target struct {
+	State string `xml:"state,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type xliff20 struct {
+	XMLName xml.Name      `xml:"xliff"`
+	Xmlns   string        `xml:"xmlns,attr"`
+	Version string        `xml:"version,attr"`
+	SrcLang string        `xml:"srcLang,attr"`
+	TrgLang string        `xml:"trgLang,attr,omitempty"`
+	Files   []xliff20File `xml:"file"`
+}
+type xliff20File struct {
+	ID       string         `xml:"id,attr"`
+	Original string         `xml:"original,attr,omitempty"`
+	Units    []xliff20Unit  `xml:"unit"`
+	Groups   []xliff20Group `xml:"group"`
+}
+type xliff20Group struct {
+	ID     string         `xml:"id,attr"`
+	Units  []xliff20Unit  `xml:"unit"`
+	Groups []xliff20Group `xml:"group"`
+}
+type xliff20Unit struct {
+	ID       string           `xml:"id,attr"`
+	Notes    *xliff20Notes    `xml:"notes"`
+	Segments []xliff20Segment `xml:"segment"`
+}
+
+// A pointer is used, since encoding/xml writes empty parent-elements for `notes>note`
+type xliff20Notes struct {
+	Notes []string `xml:"note"`
+}
+type xliff20Segment struct {
+	State  string  `xml:"state,attr,omitempty"`
+	Source string  `xml:"source"`
+	Target *string `xml:"target"`
+}
+
+// A flat unit, independent of the xliff-version
+type xliffUnit struct {
+	ID, Source, Target string
+	HasTarget          bool
+	Note               string
+}
+
+// Returns a unit for each i18next-key in the project.
+func xliffUnits(ep types.ExtendedProject, source, target types.Locale) []xliffUnit {
+	var units []xliffUnit
+	for _, t := range FlattenExtendedProject(ep) {
+		sourceValues := i18nextValues(t.Values[source.ID])
+		targetValues := i18nextValues(t.Values[target.ID])
+		for _, suffix := range utils.SortedMapKeys(sourceValues) {
+			u := xliffUnit{
+				ID:     t.FullKey,
+				Source: sourceValues[suffix],
+				Note:   t.Description,
+			}
+			if suffix != "" {
+				u.ID += "_" + suffix
+			}
+			u.Target, u.HasTarget = targetValues[suffix]
+			units = append(units, u)
+		}
+	}
+	return units
+}
+
+// Returns the values for a TranslationValue, keyed by the i18next-suffix, where the empty string is the value itself
+func i18nextValues(tv types.TranslationValue) map[string]string {
+	m := map[string]string{}
+	if tv.Value != "" {
+		m[""] = tv.Value
+	}
+	for k, v := range tv.Context {
+		if v != "" {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// ExportXliff exports the values of a source-locale, along with the values of a target-locale
+// version must be either "1.2" or "2.0"
+func ExportXliff(ep types.ExtendedProject, version string, source, target types.Locale, localeKey LocaleKeyEnum) ([]byte, error) {
+	if source.ID == target.ID {
+		return nil, fmt.Errorf("The source-locale and target-locale must be different")
+	}
+	if localeKey.Name == "" {
+		// XLIFF uses BCP-47
+		localeKey = LocaleKeyEnumIETF
+	}
+	srcLang := localeKey.FromLocale(source)
+	trgLang := localeKey.FromLocale(target)
+	original := ep.ShortName
+	if original == "" {
+		original = ep.Title
+	}
+	units := xliffUnits(ep, source, target)
+
+	var x interface{}
+	switch version {
+	case "1.2":
+		f := xliff12File{
+			Original:       original,
+			SourceLanguage: srcLang,
+			TargetLanguage: trgLang,
+			Datatype:       "plaintext",
+		}
+		for _, u := range units {
+			unit := xliff12Unit{ID: u.ID, Source: u.Source}
+			if u.HasTarget {
+				unit.Target = &xliff12Target{Value: u.Target, State: "translated"}
+			}
+			if u.Note != "" {
+				unit.Notes = []string{u.Note}
+			}
+			f.Units = append(f.Units, unit)
+		}
+		x = xliff12{Xmlns: xliffNamespace12, Version: version, Files: []xliff12File{f}}
+	case "2.0":
+		f := xliff20File{
+			ID:       "f1",
+			Original: original,
+		}
+		for _, u := range units {
+			segment := xliff20Segment{Source: u.Source, State: "initial"}
+			if u.HasTarget {
+				target := u.Target
+				segment.Target = &target
+				segment.State = "translated"
+			}
+			unit := xliff20Unit{ID: u.ID, Segments: []xliff20Segment{segment}}
+			if u.Note != "" {
+				unit.Notes = &xliff20Notes{Notes: []string{u.Note}}
+			}
+			f.Units = append(f.Units, unit)
+		}
+		x = xliff20{Xmlns: xliffNamespace20, Version: version, SrcLang: srcLang, TrgLang: trgLang, Files: []xliff20File{f}}
+	default:
+		return nil, fmt.Errorf("Unsupported xliff-version: %s", version)
+	}
+
+	var w bytes.Buffer
+	w.WriteString(xml.Header)
+	enc := xml.NewEncoder(&w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(x); err != nil {
+		return nil, err
+	}
+	w.WriteString("\n")
+	return w.Bytes(), nil
+}
+
+type XliffFile struct {
+	Version        string
+	SourceLanguage string
+	TargetLanguage string
+	units          []xliffUnit
+}
+
+// ParseXliff parses a XLIFF-file of version 1.2 or 2.0
+func ParseXliff(b []byte) (*XliffFile, error) {
+	var probe struct {
+		XMLName xml.Name
+		Version string `xml:"version,attr"`
+	}
+	if err := xml.Unmarshal(b, &probe); err != nil {
+		return nil, err
+	}
+	if probe.XMLName.Local != "xliff" {
+		return nil, fmt.Errorf("expected the root-element to be xliff, but got %s", probe.XMLName.Local)
+	}
+	f := XliffFile{Version: probe.Version}
+	ns := probe.XMLName.Space
+	switch {
+	case ns == xliffNamespace20 || (ns == "" && strings.HasPrefix(probe.Version, "2")):
+		var x xliff20
+		if err := xml.Unmarshal(b, &x); err != nil {
+			return nil, err
+		}
+		f.SourceLanguage = x.SrcLang
+		f.TargetLanguage = x.TrgLang
+		for _, file := range x.Files {
+			groups := []xliff20Group{{Units: file.Units, Groups: file.Groups}}
+			for len(groups) > 0 {
+				g := groups[0]
+				groups = append(groups[1:], g.Groups...)
+				for _, u := range g.Units {
+					unit := xliffUnit{ID: u.ID}
+					if u.Notes != nil {
+						unit.Note = strings.Join(u.Notes.Notes, "\n")
+					}
+					for _, s := range u.Segments {
+						unit.Source += s.Source
+						if s.Target != nil {
+							unit.Target += *s.Target
+							unit.HasTarget = true
+						}
+					}
+					f.units = append(f.units, unit)
+				}
+			}
+		}
+	case ns == xliffNamespace12 || ns == "":
+		var x xliff12
+		if err := xml.Unmarshal(b, &x); err != nil {
+			return nil, err
+		}
+		for _, file := range x.Files {
+			if f.SourceLanguage == "" {
+				f.SourceLanguage = file.SourceLanguage
+			}
+			if f.TargetLanguage == "" {
+				f.TargetLanguage = file.TargetLanguage
+			}
+			groups := []xliff12Group{{Units: file.Units, Groups: file.Groups}}
+			for len(groups) > 0 {
+				g := groups[0]
+				groups = append(groups[1:], g.Groups...)
+				for _, u := range g.Units {
+					unit := xliffUnit{ID: u.ID, Source: u.Source, Note: strings.Join(u.Notes, "\n")}
+					if u.Target != nil {
+						unit.Target = u.Target.Value
+						unit.HasTarget = true
+					}
+					f.units = append(f.units, unit)
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported xliff-namespace: %s", probe.XMLName.Space)
+	}
+	return &f, nil
+}
+
+// ToI18Next converts the targets of the XLIFF-file into a i18next-compatible map, for use with ImportI18NTranslation.
+// Units without a target are skipped.
+func (f XliffFile) ToI18Next() (map[string]interface{}, []Warning, error) {
+	var warnings []Warning
+	out := map[string]interface{}{}
+	for _, u := range f.units {
+		if u.ID == "" {
+			w := newWarning("Skipped unit without id", WarningKindImportFormat, WarningLevelMinor)
+			w.Details = u
+			warnings = append(warnings, w)
+			continue
+		}
+		if !u.HasTarget || u.Target == "" {
+			continue
+		}
+		if err := setInMap(out, SplitKey(u.ID), u.Target); err != nil {
+			return out, warnings, fmt.Errorf("failed to set value for '%s': %w", u.ID, err)
+		}
+	}
+	return out, warnings, nil
+}
@@ -0,0 +1,127 @@
+package importexport
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/internal"
+)
+
+func TestXliff(t *testing.T) {
+	ep := gettextTestProject()
+	source := ep.Locales["loc-en"]
+	target := ep.Locales["loc-no"]
+	tests := []struct {
+		name    string
+		version string
+	}{
+		{"Version 1.2", "1.2"},
+		{"Version 2.0", "2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ExportXliff(ep, tt.version, source, target, LocaleKeyEnum{})
+			testza.AssertNoError(t, err)
+			internal.MatchSnapshot(t, "xlf", b)
+
+			x, err := ParseXliff(b)
+			testza.AssertNoError(t, err)
+			testza.AssertEqual(t, tt.version, x.Version)
+			testza.AssertEqual(t, "en-US", x.SourceLanguage)
+			testza.AssertEqual(t, "nb-NO", x.TargetLanguage)
+			i18n, warnings, err := x.ToI18Next()
+			testza.AssertNoError(t, err)
+			testza.AssertLen(t, warnings, 0)
+
+			// Only the values that has a source are exported
+			want := map[string]interface{}{
+				"General": map[string]interface{}{
+					"Forms": map[string]interface{}{
+						"Items":       "Ting",
+						"Items_one":   "{{count}} ting",
+						"Items_other": "{{count}} ting",
+						"Submit":      "Send inn\npå flere linjer",
+					},
+				},
+			}
+			if err := internal.Compare("xliff", i18n, want); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestParseXliff(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "1.2 without namespace and with groups",
+			input: `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2">
+  <file original="app" source-language="en" target-language="nb" datatype="plaintext">
+    <body>
+      <group>
+        <trans-unit id="General.Title">
+          <source>My app</source>
+          <target>Min app</target>
+        </trans-unit>
+        <group>
+          <trans-unit id="General.Untranslated">
+            <source>Not yet</source>
+          </trans-unit>
+        </group>
+      </group>
+      <trans-unit id="Welcome_formal">
+        <source>Welcome</source>
+        <target>Velkommen</target>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>`,
+			want: map[string]interface{}{
+				"General":        map[string]interface{}{"Title": "Min app"},
+				"Welcome_formal": "Velkommen",
+			},
+		},
+		{
+			name: "2.0 with multiple segments",
+			input: `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="nb">
+  <file id="f1">
+    <unit id="General.Title">
+      <segment><source>My </source><target>Min </target></segment>
+      <segment><source>app</source><target>app</target></segment>
+    </unit>
+  </file>
+</xliff>`,
+			want: map[string]interface{}{
+				"General": map[string]interface{}{"Title": "Min app"},
+			},
+		},
+		{
+			name:    "Not xliff",
+			input:   `<resources><string name="foo">bar</string></resources>`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, err := ParseXliff([]byte(tt.input))
+			if tt.wantErr {
+				testza.AssertNotNil(t, err)
+				return
+			}
+			testza.AssertNoError(t, err)
+			testza.AssertEqual(t, "nb", x.TargetLanguage)
+			got, _, err := x.ToI18Next()
+			testza.AssertNoError(t, err)
+			if err := internal.Compare("xliff", got, tt.want); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -65,14 +65,13 @@ type InterpolationOptions struct {
 	NestingOptionsSeperator string
 
 	// global variables to use in interpolation replacements: Default: null, but examples uses defaultInterpolationExamples
-	// Not yet supported
 	DefaultVariables map[string]interface{}
 	// After How many interpolation runs to break out before throwing a stack overflow: Default: 1000
-	// Not yet supported
 	MaxReplaces int
 
 	// Will skip to interpolate the variables, example:
-	// Not yet supported
+	// With SkipOnVariables, a missing variable is left as-is, like `Hello {{name}}`.
+	// Without it, it is replaced with an empty string, like `Hello `
 	SkipOnVariables bool
 }
 
@@ -86,7 +85,7 @@ func NewInterpolator() Interpolator {
 	ip.Options.Prefix = "{{"
 	ip.Options.Suffix = "}}"
 	ip.Options.NestingPrefix = "$t("
-	ip.Options.NestingSuffix = "$t("
+	ip.Options.NestingSuffix = ")"
 	ip.Options.NestingOptionsSeperator = ","
 	ip.Options.MaxReplaces = 1000
 	ip.Options.SkipOnVariables = true
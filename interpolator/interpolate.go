@@ -0,0 +1,279 @@
+package interpolator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/interpolator/lexer"
+	"github.com/runar-rkmedia/skiver/interpolator/parser"
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+var (
+	ErrKeyNotFound = errors.New("the key was not found in any of the locales")
+	ErrMaxReplaces = errors.New("reached the maximum number of replacements, there may be a loop in the nestings")
+)
+
+// Lookup resolves translation-values within a single locale.
+type Lookup struct {
+	// The locale of the values, used in the result
+	Locale string
+	// Returns the value for a full key, including any context- and plural-suffixes, like `General.Items_male_one`
+	Value func(key string) (string, bool)
+	// Returns the plural-category for the count, like `one` or `other`.
+	// If nil, the English rules are used.
+	PluralCategory func(count float64) string
+}
+
+type InterpolationResult struct {
+	// The rendered value
+	Value string
+	// The full key of the value that was used, including any context- and plural-suffixes
+	Key string
+	// The locale of the value that was used
+	Locale string
+	// Variables that were used within the value or its nestings, but were not set
+	MissingVariables []string
+	// Keys that were nested, but could not be found
+	MissingKeys []string
+}
+
+// Interpolate renders the value for the key, like i18next's `t(key, variables)`.
+//
+// The lookups are tried in order, so any fallback-locales should follow the preferred locale.
+// Within each lookup, the key is resolved with the same suffixes as i18next:
+//
+//	key_<context>_<plural>, key_<context>, key_<plural>, key
+//
+// where the context is the variable `context`, and the plural is the plural-category for the variable `count`.
+// Nestings like `$t(other.key, {"count": 2})` are resolved with the same lookups.
+func (ip *Interpolator) Interpolate(key string, variables map[string]interface{}, lookups ...Lookup) (InterpolationResult, error) {
+	in := interpolation{
+		ip:      ip,
+		lookups: lookups,
+		parser:  parser.NewParser(nil),
+		missing: map[string]bool{},
+	}
+	vars := map[string]interface{}{}
+	for k, v := range ip.Options.DefaultVariables {
+		vars[k] = v
+	}
+	for k, v := range variables {
+		vars[k] = v
+	}
+	value, fullKey, locale, ok := in.resolve(key, vars)
+	if !ok {
+		return InterpolationResult{}, fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	rendered, err := in.render(value, vars)
+	result := InterpolationResult{
+		Value:            rendered,
+		Key:              fullKey,
+		Locale:           locale,
+		MissingVariables: utils.SortedMapKeys(in.missing),
+		MissingKeys:      in.missingKeys,
+	}
+	return result, err
+}
+
+type interpolation struct {
+	ip          *Interpolator
+	lookups     []Lookup
+	parser      *parser.Parser
+	replaces    int
+	missing     map[string]bool
+	missingKeys []string
+}
+
+func (in *interpolation) resolve(key string, variables map[string]interface{}) (value, fullKey, locale string, ok bool) {
+	for _, l := range in.lookups {
+		for _, k := range CandidateKeys(key, variables, l.PluralCategory) {
+			if v, ok := l.Value(k); ok {
+				return v, k, l.Locale, true
+			}
+		}
+	}
+	return "", "", "", false
+}
+
+// CandidateKeys returns the keys to try for the variables, in order of preference.
+func CandidateKeys(key string, variables map[string]interface{}, pluralCategory func(count float64) string) []string {
+	if pluralCategory == nil {
+		pluralCategory = englishPluralCategory
+	}
+	var plurals []string
+	if count, ok := toFloat(variables["count"]); ok {
+		c := pluralCategory(count)
+		// Like i18next, the zero-suffix can be used for the count 0, even in languages without the zero-category
+		if count == 0 && c != "zero" {
+			plurals = append(plurals, "zero")
+		}
+		plurals = append(plurals, c)
+	}
+	var keys []string
+	if ctx, ok := variables["context"].(string); ok && ctx != "" {
+		for _, p := range plurals {
+			keys = append(keys, key+"_"+ctx+"_"+p)
+		}
+		keys = append(keys, key+"_"+ctx)
+	}
+	for _, p := range plurals {
+		keys = append(keys, key+"_"+p)
+	}
+	return append(keys, key)
+}
+
+func englishPluralCategory(count float64) string {
+	if count == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func (in *interpolation) render(value string, variables map[string]interface{}) (string, error) {
+	ast, err := in.parser.Parse(value)
+	if err != nil {
+		return value, fmt.Errorf("failed to parse the value '%s': %w", value, err)
+	}
+	var b strings.Builder
+	for _, n := range ast.Nodes {
+		switch n.Token.Kind {
+		case lexer.TokenLiteral:
+			b.WriteString(n.Token.Literal)
+		case lexer.TokenPrefix:
+			in.replaces++
+			if in.replaces > in.ip.Options.MaxReplaces {
+				return b.String(), ErrMaxReplaces
+			}
+			name := strings.TrimSpace(n.Left.Token.Literal)
+			v, ok := variableByPath(variables, name)
+			if !ok {
+				in.missing[name] = true
+				if in.ip.Options.SkipOnVariables {
+					b.WriteString(value[n.Token.Start:n.Token.End])
+				}
+				continue
+			}
+			format := ""
+			if n.Right != nil && n.Right.Left != nil {
+				format = strings.TrimSpace(n.Right.Left.Token.Literal)
+			}
+			b.WriteString(formatVariable(v, format))
+		case lexer.TokenNestingPrefix:
+			in.replaces++
+			if in.replaces > in.ip.Options.MaxReplaces {
+				return b.String(), ErrMaxReplaces
+			}
+			key := strings.TrimSpace(n.Left.Token.Literal)
+			nestedVariables := variables
+			if n.Right != nil {
+				// The options may themselves contain interpolations, like `{"count": {{count}}}`
+				options, err := in.render(strings.ReplaceAll(strings.TrimSpace(n.Right.Token.Literal), `\"`, `"`), variables)
+				if err != nil {
+					return b.String(), err
+				}
+				var nestedOptions map[string]interface{}
+				if err := json.Unmarshal([]byte(options), &nestedOptions); err != nil {
+					return b.String(), fmt.Errorf("failed to parse the options for the nesting of '%s' as json: %w", key, err)
+				}
+				nestedVariables = make(map[string]interface{}, len(variables)+len(nestedOptions))
+				for k, v := range variables {
+					nestedVariables[k] = v
+				}
+				for k, v := range nestedOptions {
+					nestedVariables[k] = v
+				}
+			}
+			nested, _, _, ok := in.resolve(key, nestedVariables)
+			if !ok {
+				// Like i18next, the key is used as the value
+				in.missingKeys = append(in.missingKeys, key)
+				b.WriteString(key)
+				continue
+			}
+			rendered, err := in.render(nested, nestedVariables)
+			if err != nil {
+				return b.String(), err
+			}
+			b.WriteString(rendered)
+		}
+	}
+	return b.String(), nil
+}
+
+// Returns the variable by its name, where dots are used for nested objects, like `user.name`
+func variableByPath(variables map[string]interface{}, name string) (interface{}, bool) {
+	if v, ok := variables[name]; ok {
+		return v, v != nil
+	}
+	path := strings.Split(name, ".")
+	var current interface{} = variables
+	for _, p := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[p]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, current != nil
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// Formats the variable for output.
+// Only a few formats are supported, and they are not locale-aware.
+func formatVariable(v interface{}, format string) string {
+	switch format {
+	case "uppercase":
+		return strings.ToUpper(formatVariable(v, ""))
+	case "lowercase":
+		return strings.ToLower(formatVariable(v, ""))
+	case "datetime":
+		if s, ok := v.(string); ok {
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				v = t
+			}
+		}
+		if t, ok := v.(time.Time); ok {
+			return t.Format("2006-01-02")
+		}
+	}
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case time.Time:
+		return t.Format(time.RFC3339)
+	case []interface{}:
+		s := make([]string, len(t))
+		for i, v := range t {
+			s[i] = formatVariable(v, format)
+		}
+		return strings.Join(s, ", ")
+	}
+	return fmt.Sprint(v)
+}
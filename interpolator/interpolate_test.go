@@ -0,0 +1,217 @@
+package interpolator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func mapLookup(locale string, values map[string]string) Lookup {
+	return Lookup{
+		Locale: locale,
+		Value: func(key string) (string, bool) {
+			v, ok := values[key]
+			return v, ok
+		},
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	en := mapLookup("en", map[string]string{
+		"Greeting":           "Hello, {{name}} ({{user.role}})",
+		"Items_zero":         "No items",
+		"Items_one":          "{{count}} item",
+		"Items_other":        "{{count}} items",
+		"Friend":             "A friend",
+		"Friend_male":        "A boyfriend",
+		"Friend_male_other":  "{{count}} boyfriends",
+		"Cart":               "You have $t(Items, {\"count\": {{count}} }) in your cart",
+		"Cart.Title":         "$t(Greeting). Date: {{when, datetime}}",
+		"Loop":               "$t(Loop)",
+		"MissingNested":      "See $t(Does.Not.Exist)",
+		"OnlyInEnglish":      "English",
+		"Formatted":          "{{name, uppercase}}",
+		"ParseError":         "{{}}",
+		"NestedContext":      "$t(Friend, {\"context\": \"male\"})",
+		"SyntaxInText":       "Items: 1, 2, (3)",
+		"MissingVariable":    "Hi {{name}}, {{missing}}",
+		"Interpolated.Count": "{{count}}",
+	})
+	nb := mapLookup("nb", map[string]string{
+		"Items_one":   "{{count}} ting",
+		"Items_other": "{{count}} ting",
+	})
+	tests := []struct {
+		name        string
+		key         string
+		variables   map[string]interface{}
+		lookups     []Lookup
+		want        InterpolationResult
+		wantErr     error
+		skipMissing bool
+	}{
+		{
+			name:      "Variables, including nested objects",
+			key:       "Greeting",
+			variables: map[string]interface{}{"name": "Roll", "user": map[string]interface{}{"role": "admin"}},
+			want:      InterpolationResult{Value: "Hello, Roll (admin)", Key: "Greeting", Locale: "en"},
+		},
+		{
+			name:      "Plural",
+			key:       "Items",
+			variables: map[string]interface{}{"count": 3},
+			want:      InterpolationResult{Value: "3 items", Key: "Items_other", Locale: "en"},
+		},
+		{
+			name:      "Plural from json-number",
+			key:       "Items",
+			variables: map[string]interface{}{"count": float64(1)},
+			want:      InterpolationResult{Value: "1 item", Key: "Items_one", Locale: "en"},
+		},
+		{
+			name:      "Plural with zero",
+			key:       "Items",
+			variables: map[string]interface{}{"count": 0},
+			want:      InterpolationResult{Value: "No items", Key: "Items_zero", Locale: "en"},
+		},
+		{
+			name:      "Context",
+			key:       "Friend",
+			variables: map[string]interface{}{"context": "male"},
+			want:      InterpolationResult{Value: "A boyfriend", Key: "Friend_male", Locale: "en"},
+		},
+		{
+			name:      "Context with plural",
+			key:       "Friend",
+			variables: map[string]interface{}{"context": "male", "count": 2},
+			want:      InterpolationResult{Value: "2 boyfriends", Key: "Friend_male_other", Locale: "en"},
+		},
+		{
+			name:      "Context with missing plural falls back to the context",
+			key:       "Friend",
+			variables: map[string]interface{}{"context": "male", "count": 1},
+			want:      InterpolationResult{Value: "A boyfriend", Key: "Friend_male", Locale: "en"},
+		},
+		{
+			name:      "Unknown context falls back to the key",
+			key:       "Friend",
+			variables: map[string]interface{}{"context": "female"},
+			want:      InterpolationResult{Value: "A friend", Key: "Friend", Locale: "en"},
+		},
+		{
+			name:      "Nesting with interpolated options",
+			key:       "Cart",
+			variables: map[string]interface{}{"count": 1},
+			want:      InterpolationResult{Value: "You have 1 item in your cart", Key: "Cart", Locale: "en"},
+		},
+		{
+			name:      "Nesting with context in options",
+			key:       "NestedContext",
+			variables: map[string]interface{}{},
+			want:      InterpolationResult{Value: "A boyfriend", Key: "NestedContext", Locale: "en"},
+		},
+		{
+			name:      "Nesting and format",
+			key:       "Cart.Title",
+			variables: map[string]interface{}{"name": "Roll", "user": map[string]interface{}{"role": "admin"}, "when": "1987-12-17T06:00:00Z"},
+			want:      InterpolationResult{Value: "Hello, Roll (admin). Date: 1987-12-17", Key: "Cart.Title", Locale: "en"},
+		},
+		{
+			name:      "Missing nested key uses the key",
+			key:       "MissingNested",
+			variables: map[string]interface{}{},
+			want:      InterpolationResult{Value: "See Does.Not.Exist", Key: "MissingNested", Locale: "en", MissingKeys: []string{"Does.Not.Exist"}},
+		},
+		{
+			name:      "Fallback to the next locale",
+			key:       "OnlyInEnglish",
+			variables: map[string]interface{}{},
+			lookups:   []Lookup{nb, en},
+			want:      InterpolationResult{Value: "English", Key: "OnlyInEnglish", Locale: "en"},
+		},
+		{
+			name:      "The preferred locale is used before the fallback, even without the zero-suffix",
+			key:       "Items",
+			variables: map[string]interface{}{"count": 0},
+			lookups:   []Lookup{nb, en},
+			want:      InterpolationResult{Value: "0 ting", Key: "Items_other", Locale: "nb"},
+		},
+		{
+			name:      "Format",
+			key:       "Formatted",
+			variables: map[string]interface{}{"name": "Roll"},
+			want:      InterpolationResult{Value: "ROLL", Key: "Formatted", Locale: "en"},
+		},
+		{
+			name:      "Syntax-characters in text",
+			key:       "SyntaxInText",
+			variables: map[string]interface{}{},
+			want:      InterpolationResult{Value: "Items: 1, 2, (3)", Key: "SyntaxInText", Locale: "en"},
+		},
+		{
+			name:      "Missing variables are kept",
+			key:       "MissingVariable",
+			variables: map[string]interface{}{"name": "Roll"},
+			want:      InterpolationResult{Value: "Hi Roll, {{missing}}", Key: "MissingVariable", Locale: "en", MissingVariables: []string{"missing"}},
+		},
+		{
+			name:        "Missing variables are removed without SkipOnVariables",
+			key:         "MissingVariable",
+			variables:   map[string]interface{}{"name": "Roll"},
+			skipMissing: true,
+			want:        InterpolationResult{Value: "Hi Roll, ", Key: "MissingVariable", Locale: "en", MissingVariables: []string{"missing"}},
+		},
+		{
+			name:      "Number as a string, in a dotted key",
+			key:       "Interpolated.Count",
+			variables: map[string]interface{}{"count": "7"},
+			want:      InterpolationResult{Value: "7", Key: "Interpolated.Count", Locale: "en"},
+		},
+		{
+			name:    "Loop is stopped by MaxReplaces",
+			key:     "Loop",
+			wantErr: ErrMaxReplaces,
+		},
+		{
+			name:    "Key not found",
+			key:     "Nope",
+			wantErr: ErrKeyNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := NewInterpolator()
+			if tt.skipMissing {
+				ip.Options.SkipOnVariables = false
+			}
+			lookups := tt.lookups
+			if lookups == nil {
+				lookups = []Lookup{en}
+			}
+			got, err := ip.Interpolate(tt.key, tt.variables, lookups...)
+			if tt.wantErr != nil {
+				testza.AssertTrue(t, errors.Is(err, tt.wantErr), "expected error", tt.wantErr, err)
+				return
+			}
+			testza.AssertNoError(t, err)
+			if tt.want.MissingVariables == nil {
+				tt.want.MissingVariables = []string{}
+			}
+			testza.AssertEqual(t, tt.want, got)
+		})
+	}
+
+	t.Run("Parse-errors are returned", func(t *testing.T) {
+		ip := NewInterpolator()
+		_, err := ip.Interpolate("ParseError", nil, en)
+		testza.AssertNotNil(t, err)
+	})
+}
+
+func TestCandidateKeys(t *testing.T) {
+	testza.AssertEqual(t,
+		[]string{"key_male_zero", "key_male_other", "key_male", "key_zero", "key_other", "key"},
+		CandidateKeys("key", map[string]interface{}{"count": 0, "context": "male"}, nil))
+	testza.AssertEqual(t, []string{"key"}, CandidateKeys("key", map[string]interface{}{"count": "many"}, nil))
+}
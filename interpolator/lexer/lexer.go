@@ -34,6 +34,15 @@ var (
 		"$t(": TokenNestingPrefix,
 		")":   TokenNestingSuffix,
 	}
+	// The map used for ICU MessageFormat, like `{count, plural, one {# item} other {# items}}`
+	// Since these tokens are also common in literal text, the parser decides from the context whether they are syntax or text.
+	DefaultICULexerMap = map[string]TokenKind{
+		"{": TokenICUOpen,
+		"}": TokenICUClose,
+		",": TokenICUSeparator,
+		"#": TokenICUPound,
+		"'": TokenICUQuote,
+	}
 )
 
 func (l *Lexer) NewInput(input string) {
@@ -85,6 +94,15 @@ const (
 	TokenNestingSeperator TokenKind = "Format/(Nesting) seperator"
 	TokenNestingPrefix    TokenKind = "NestingPrefix"
 	TokenNestingSuffix    TokenKind = "NestingSuffix"
+
+	// ICU MessageFormat
+	TokenICUOpen      TokenKind = "ICUOpen"
+	TokenICUClose     TokenKind = "ICUClose"
+	TokenICUSeparator TokenKind = "ICUSeparator"
+	// Within plurals, # is replaced with the number
+	TokenICUPound TokenKind = "ICUPound"
+	// Apostrophes are used for escaping syntax-characters
+	TokenICUQuote TokenKind = "ICUQuote"
 )
 
 func newToken(kind TokenKind, ch string, start, end int) Token {
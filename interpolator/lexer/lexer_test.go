@@ -99,3 +99,32 @@ func TestLexer(t *testing.T) {
 		})
 	}
 }
+
+func TestLexerICU(t *testing.T) {
+	input := "{count, plural, one {# item} other {'#'}}"
+	l := NewLexer(input, DefaultICULexerMap)
+	got := l.FindAllTokens()
+	expects := []Token{
+		{Kind: TokenICUOpen, Literal: "{", Start: 0, End: 1},
+		{Kind: TokenLiteral, Literal: "count", Start: 1, End: 6},
+		{Kind: TokenICUSeparator, Literal: ",", Start: 6, End: 7},
+		{Kind: TokenLiteral, Literal: " plural", Start: 7, End: 14},
+		{Kind: TokenICUSeparator, Literal: ",", Start: 14, End: 15},
+		{Kind: TokenLiteral, Literal: " one ", Start: 15, End: 20},
+		{Kind: TokenICUOpen, Literal: "{", Start: 20, End: 21},
+		{Kind: TokenICUPound, Literal: "#", Start: 21, End: 22},
+		{Kind: TokenLiteral, Literal: " item", Start: 22, End: 27},
+		{Kind: TokenICUClose, Literal: "}", Start: 27, End: 28},
+		{Kind: TokenLiteral, Literal: " other ", Start: 28, End: 35},
+		{Kind: TokenICUOpen, Literal: "{", Start: 35, End: 36},
+		{Kind: TokenICUQuote, Literal: "'", Start: 36, End: 37},
+		{Kind: TokenICUPound, Literal: "#", Start: 37, End: 38},
+		{Kind: TokenICUQuote, Literal: "'", Start: 38, End: 39},
+		{Kind: TokenICUClose, Literal: "}", Start: 39, End: 40},
+		{Kind: TokenICUClose, Literal: "}", Start: 40, End: 41},
+		{Kind: TokenEOF, Literal: "", Start: 41, End: 41},
+	}
+	if err := internal.Compare("result", got, expects); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,365 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/runar-rkmedia/skiver/interpolator/lexer"
+)
+
+// ICUParser parses ICU MessageFormat-messages, like:
+//
+//	Hello {name}, you have {count, plural, =0 {no messages} one {# message} other {# messages}}
+//
+// Unlike the i18next-parser, the AST is a tree, since arguments like plural and select contain nested messages.
+type ICUParser struct {
+	l      *lexer.Lexer
+	tokens []lexer.Token
+	pos    int
+}
+
+type ICUNodeKind string
+
+const (
+	ICUNodeLiteral ICUNodeKind = "literal"
+	// A simple argument like `{name}`, or a formatted argument like `{count, number}` or `{when, date, short}`
+	ICUNodeArgument      ICUNodeKind = "argument"
+	ICUNodePlural        ICUNodeKind = "plural"
+	ICUNodeSelectOrdinal ICUNodeKind = "selectordinal"
+	ICUNodeSelect        ICUNodeKind = "select"
+	// The `#` within a plural, which is replaced with the number
+	ICUNodePound ICUNodeKind = "pound"
+)
+
+type ICUNode struct {
+	Kind  ICUNodeKind
+	Start int
+	End   int
+	// The text of a literal, or the name of the argument
+	Value string `json:",omitempty"`
+	// The format of an argument, like number, date or time
+	Type string `json:",omitempty"`
+	// The style of a formatted argument, like `short` in `{when, date, short}`
+	Style string `json:",omitempty"`
+	// The offset of a plural, like `offset:1`
+	Offset int `json:",omitempty"`
+	// The options of plural, selectordinal and select
+	Options []ICUOption `json:",omitempty"`
+}
+
+type ICUOption struct {
+	// The selector, like `one`, `=0` or `male`
+	Selector string
+	Nodes    []ICUNode
+}
+
+type ICUAst struct {
+	Nodes []ICUNode
+}
+
+func NewICUParser() *ICUParser {
+	l := lexer.NewLexer("", lexer.DefaultICULexerMap)
+	return &ICUParser{l: l}
+}
+
+func (p *ICUParser) Parse(s string) (ICUAst, error) {
+	p.l.NewInput(s)
+	p.l.Tokens = []lexer.Token{}
+	p.tokens = p.l.FindAllTokens()
+	p.pos = 0
+	nodes, err := p.parseMessage(false)
+	if err != nil {
+		return ICUAst{nodes}, err
+	}
+	if tok := p.cur(); tok.Kind != lexer.TokenEOF {
+		return ICUAst{nodes}, Node{Token: tok}.err("unexpected '}' without a matching '{'")
+	}
+	return ICUAst{nodes}, nil
+}
+
+func (p *ICUParser) cur() lexer.Token {
+	if p.pos >= len(p.tokens) {
+		return lexer.Token{Kind: lexer.TokenEOF, Start: len(p.l.Input), End: len(p.l.Input)}
+	}
+	return p.tokens[p.pos]
+}
+
+// Parses a message until the end of the input, or an unmatched '}', which is not consumed.
+// Any syntax-tokens that are not part of an argument are treated as text.
+func (p *ICUParser) parseMessage(inPlural bool) ([]ICUNode, error) {
+	var nodes []ICUNode
+	var text strings.Builder
+	textStart, textEnd := -1, -1
+	addText := func(s string, tok lexer.Token) {
+		if textStart < 0 {
+			textStart = tok.Start
+		}
+		text.WriteString(s)
+		textEnd = tok.End
+	}
+	flush := func() {
+		if textStart < 0 {
+			return
+		}
+		nodes = append(nodes, ICUNode{Kind: ICUNodeLiteral, Value: text.String(), Start: textStart, End: textEnd})
+		text.Reset()
+		textStart, textEnd = -1, -1
+	}
+	for {
+		tok := p.cur()
+		switch tok.Kind {
+		case lexer.TokenEOF, lexer.TokenICUClose:
+			flush()
+			return nodes, nil
+		case lexer.TokenICUOpen:
+			flush()
+			node, err := p.parseArgument(inPlural)
+			if err != nil {
+				return nodes, err
+			}
+			nodes = append(nodes, node)
+		case lexer.TokenICUPound:
+			p.pos++
+			if !inPlural {
+				addText(tok.Literal, tok)
+				continue
+			}
+			flush()
+			nodes = append(nodes, ICUNode{Kind: ICUNodePound, Start: tok.Start, End: tok.End})
+		case lexer.TokenICUQuote:
+			p.pos++
+			next := p.cur()
+			switch {
+			case next.Kind == lexer.TokenICUQuote && next.Start == tok.End:
+				// '' is a literal apostrophe
+				addText("'", next)
+				p.pos++
+			case next.Start == tok.End && (next.Kind == lexer.TokenICUOpen || next.Kind == lexer.TokenICUClose || (inPlural && next.Kind == lexer.TokenICUPound)):
+				// Quoted text, until the next single apostrophe
+				for {
+					t := p.cur()
+					if t.Kind == lexer.TokenEOF {
+						return nodes, Node{Token: tok}.err("unterminated quoted text")
+					}
+					p.pos++
+					if t.Kind != lexer.TokenICUQuote {
+						addText(t.Literal, t)
+						continue
+					}
+					if n := p.cur(); n.Kind == lexer.TokenICUQuote && n.Start == t.End {
+						addText("'", n)
+						p.pos++
+						continue
+					}
+					break
+				}
+			default:
+				addText("'", tok)
+			}
+		default:
+			addText(tok.Literal, tok)
+			p.pos++
+		}
+	}
+}
+
+func (p *ICUParser) expect(kind lexer.TokenKind, message string) (lexer.Token, error) {
+	tok := p.cur()
+	if tok.Kind != kind {
+		return tok, Node{Token: tok}.err(message)
+	}
+	p.pos++
+	return tok, nil
+}
+
+func (p *ICUParser) parseArgument(inPlural bool) (ICUNode, error) {
+	open := p.cur()
+	p.pos++
+	node := ICUNode{Kind: ICUNodeArgument, Start: open.Start}
+	nameTok, err := p.expect(lexer.TokenLiteral, "expected an argument-name after '{'")
+	if err != nil {
+		return node, err
+	}
+	node.Value = strings.TrimSpace(nameTok.Literal)
+	if node.Value == "" || strings.ContainsAny(node.Value, " \t\n") {
+		return node, Node{Token: nameTok}.err(fmt.Sprintf("invalid argument-name '%s'", node.Value))
+	}
+	tok := p.cur()
+	p.pos++
+	switch tok.Kind {
+	case lexer.TokenICUClose:
+		node.End = tok.End
+		return node, nil
+	case lexer.TokenICUSeparator:
+	default:
+		return node, Node{Token: tok}.err("expected ',' or '}' after the argument-name")
+	}
+	typeTok, err := p.expect(lexer.TokenLiteral, "expected an argument-type after ','")
+	if err != nil {
+		return node, err
+	}
+	argType := strings.TrimSpace(typeTok.Literal)
+	switch ICUNodeKind(argType) {
+	case ICUNodePlural, ICUNodeSelectOrdinal, ICUNodeSelect:
+		node.Kind = ICUNodeKind(argType)
+		if _, err := p.expect(lexer.TokenICUSeparator, fmt.Sprintf("expected ',' after %s", argType)); err != nil {
+			return node, err
+		}
+		return p.parseOptions(node, inPlural)
+	}
+	node.Type = argType
+	tok = p.cur()
+	p.pos++
+	switch tok.Kind {
+	case lexer.TokenICUClose:
+		node.End = tok.End
+		return node, nil
+	case lexer.TokenICUSeparator:
+	default:
+		return node, Node{Token: tok}.err("expected ',' or '}' after the argument-type")
+	}
+	// The style is kept as-is, including any nested braces
+	var style strings.Builder
+	depth := 0
+	for {
+		tok := p.cur()
+		p.pos++
+		switch tok.Kind {
+		case lexer.TokenEOF:
+			return node, Node{Token: open}.err("expected '}' to close the argument")
+		case lexer.TokenICUOpen:
+			depth++
+		case lexer.TokenICUClose:
+			if depth == 0 {
+				node.Style = strings.TrimSpace(style.String())
+				node.End = tok.End
+				return node, nil
+			}
+			depth--
+		}
+		style.WriteString(tok.Literal)
+	}
+}
+
+func (p *ICUParser) parseOptions(node ICUNode, inPlural bool) (ICUNode, error) {
+	isPlural := node.Kind != ICUNodeSelect
+	selector := ""
+	for {
+		tok := p.cur()
+		switch tok.Kind {
+		case lexer.TokenICUClose:
+			if selector != "" {
+				return node, Node{Token: tok}.err(fmt.Sprintf("expected '{' after the selector '%s'", selector))
+			}
+			hasOther := false
+			for _, o := range node.Options {
+				if o.Selector == "other" {
+					hasOther = true
+				}
+			}
+			if !hasOther {
+				return node, Node{Token: tok}.err(fmt.Sprintf("the option 'other' is required for %s", node.Kind))
+			}
+			p.pos++
+			node.End = tok.End
+			return node, nil
+		case lexer.TokenLiteral:
+			p.pos++
+			for _, f := range strings.Fields(tok.Literal) {
+				if node.Kind == ICUNodePlural && len(node.Options) == 0 && selector == "" && strings.HasPrefix(f, "offset:") {
+					offset, err := strconv.Atoi(strings.TrimPrefix(f, "offset:"))
+					if err != nil {
+						return node, Node{Token: tok}.err(fmt.Sprintf("invalid offset '%s'", f))
+					}
+					node.Offset = offset
+					continue
+				}
+				if selector != "" {
+					return node, Node{Token: tok}.err(fmt.Sprintf("expected '{' after the selector '%s'", selector))
+				}
+				if isPlural && !isPluralSelector(f) {
+					return node, Node{Token: tok}.err(fmt.Sprintf("invalid selector '%s' for %s", f, node.Kind))
+				}
+				selector = f
+			}
+		case lexer.TokenICUOpen:
+			if selector == "" {
+				return node, Node{Token: tok}.err("expected a selector before '{'")
+			}
+			p.pos++
+			nodes, err := p.parseMessage(inPlural || isPlural)
+			if err != nil {
+				return node, err
+			}
+			if _, err := p.expect(lexer.TokenICUClose, fmt.Sprintf("expected '}' to close the option '%s'", selector)); err != nil {
+				return node, err
+			}
+			node.Options = append(node.Options, ICUOption{Selector: selector, Nodes: nodes})
+			selector = ""
+		default:
+			return node, Node{Token: tok}.err(fmt.Sprintf("unexpected token in the options of %s", node.Kind))
+		}
+	}
+}
+
+func isPluralSelector(s string) bool {
+	if strings.HasPrefix(s, "=") {
+		_, err := strconv.ParseFloat(s[1:], 64)
+		return err == nil
+	}
+	switch s {
+	case "zero", "one", "two", "few", "many", "other":
+		return true
+	}
+	return false
+}
+
+type ICUVariable struct {
+	Name string
+	// The type of the variable, like number, date, time, plural, selectordinal or select.
+	// Empty for simple arguments like `{name}`
+	Type string
+	// The selectors used for plural, selectordinal and select
+	Options []string `json:",omitempty"`
+}
+
+// Variables returns all the variables used within the message, keyed by name.
+// If a variable is used multiple times, the first typed usage decides the type.
+func (ast ICUAst) Variables() map[string]ICUVariable {
+	variables := map[string]ICUVariable{}
+	var walk func(nodes []ICUNode)
+	walk = func(nodes []ICUNode) {
+		for _, n := range nodes {
+			if n.Kind == ICUNodeLiteral || n.Kind == ICUNodePound {
+				continue
+			}
+			v := variables[n.Value]
+			v.Name = n.Value
+			if v.Type == "" {
+				v.Type = n.Type
+				if n.Kind != ICUNodeArgument {
+					v.Type = string(n.Kind)
+				}
+			}
+			for _, o := range n.Options {
+				found := false
+				for _, existing := range v.Options {
+					if existing == o.Selector {
+						found = true
+						break
+					}
+				}
+				if !found {
+					v.Options = append(v.Options, o.Selector)
+				}
+			}
+			variables[n.Value] = v
+			for _, o := range n.Options {
+				walk(o.Nodes)
+			}
+		}
+	}
+	walk(ast.Nodes)
+	return variables
+}
@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/internal"
+)
+
+func TestICUParser(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		expects ICUAst
+		wantErr bool
+	}{
+		{
+			"Literal with syntax-characters",
+			"Hello, #1 it's me",
+			ICUAst{Nodes: []ICUNode{
+				{Kind: ICUNodeLiteral, Value: "Hello, #1 it's me", Start: 0, End: 17},
+			}},
+			false,
+		},
+		{
+			"Simple and formatted arguments",
+			"Hi {name}, today is {today, date, short}",
+			ICUAst{Nodes: []ICUNode{
+				{Kind: ICUNodeLiteral, Value: "Hi ", Start: 0, End: 3},
+				{Kind: ICUNodeArgument, Value: "name", Start: 3, End: 9},
+				{Kind: ICUNodeLiteral, Value: ", today is ", Start: 9, End: 20},
+				{Kind: ICUNodeArgument, Value: "today", Type: "date", Style: "short", Start: 20, End: 40},
+			}},
+			false,
+		},
+		{
+			"Plural with offset and pound",
+			"{count, plural, offset:1 =0 {none} one {# item} other {# items}}",
+			ICUAst{Nodes: []ICUNode{
+				{Kind: ICUNodePlural, Value: "count", Offset: 1, Start: 0, End: 64, Options: []ICUOption{
+					{Selector: "=0", Nodes: []ICUNode{{Kind: ICUNodeLiteral, Value: "none", Start: 29, End: 33}}},
+					{Selector: "one", Nodes: []ICUNode{
+						{Kind: ICUNodePound, Start: 40, End: 41},
+						{Kind: ICUNodeLiteral, Value: " item", Start: 41, End: 46},
+					}},
+					{Selector: "other", Nodes: []ICUNode{
+						{Kind: ICUNodePound, Start: 55, End: 56},
+						{Kind: ICUNodeLiteral, Value: " items", Start: 56, End: 62},
+					}},
+				}},
+			}},
+			false,
+		},
+		{
+			"Quoted syntax-characters",
+			"'{'literal'}' and it''s #",
+			ICUAst{Nodes: []ICUNode{
+				{Kind: ICUNodeLiteral, Value: "{literal} and it's #", Start: 1, End: 25},
+			}},
+			false,
+		},
+		{
+			"Select with nested plural",
+			"{gender, select, male {{n, plural, one {He has #} other {He has # items}}} other {They}}",
+			ICUAst{Nodes: []ICUNode{
+				{Kind: ICUNodeSelect, Value: "gender", Start: 0, End: 88, Options: []ICUOption{
+					{Selector: "male", Nodes: []ICUNode{
+						{Kind: ICUNodePlural, Value: "n", Start: 23, End: 73, Options: []ICUOption{
+							{Selector: "one", Nodes: []ICUNode{
+								{Kind: ICUNodeLiteral, Value: "He has ", Start: 40, End: 47},
+								{Kind: ICUNodePound, Start: 47, End: 48},
+							}},
+							{Selector: "other", Nodes: []ICUNode{
+								{Kind: ICUNodeLiteral, Value: "He has ", Start: 57, End: 64},
+								{Kind: ICUNodePound, Start: 64, End: 65},
+								{Kind: ICUNodeLiteral, Value: " items", Start: 65, End: 71},
+							}},
+						}},
+					}},
+					{Selector: "other", Nodes: []ICUNode{
+						{Kind: ICUNodeLiteral, Value: "They", Start: 82, End: 86},
+					}},
+				}},
+			}},
+			false,
+		},
+		{"Missing other", "{count, plural, one {# item}}", ICUAst{}, true},
+		{"Invalid plural-selector", "{count, plural, some {x} other {y}}", ICUAst{}, true},
+		{"Unclosed argument", "Hello {name", ICUAst{}, true},
+		{"Unmatched close", "Hello }", ICUAst{}, true},
+		{"Unterminated quote", "'{ hello", ICUAst{}, true},
+	}
+	p := NewICUParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.Parse(tt.input)
+			if tt.wantErr {
+				testza.AssertNotNil(t, err)
+				return
+			}
+			testza.AssertNoError(t, err)
+			if err := internal.Compare("result", got, tt.expects); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestICUVariables(t *testing.T) {
+	ast, err := NewICUParser().Parse("{name} has {count, plural, one {# {gender, select, male {boy} female {girl} other {child}}} other {# children}} since {since, date} ({count, number})")
+	testza.AssertNoError(t, err)
+	want := map[string]ICUVariable{
+		"name":   {Name: "name"},
+		"count":  {Name: "count", Type: "plural", Options: []string{"one", "other"}},
+		"gender": {Name: "gender", Type: "select", Options: []string{"male", "female", "other"}},
+		"since":  {Name: "since", Type: "date"},
+	}
+	testza.AssertEqual(t, want, ast.Variables())
+}
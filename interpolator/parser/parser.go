@@ -101,13 +101,17 @@ func (p *Parser) parse() (ast Ast, err error) {
 
 	i := 0
 	for p.curToken.Kind != lexer.TokenEOF {
-		if i > 100 {
+		if i > len(p.l.Tokens) {
 			return ast, Node{Token: p.curToken}.err("Expected EOF, gave up")
 		}
 		node := Node{Token: p.curToken}
 		switch p.curToken.Kind {
 		case lexer.TokenLiteral:
-			ast.Nodes = append(ast.Nodes, node)
+			ast.appendLiteral(node)
+		case lexer.TokenFormatSeperator, lexer.TokenNestingSeperator, lexer.TokenNestingSuffix, lexer.TokenSuffix:
+			// Outside of interpolations and nestings, these are just regular text, like in "Hello, (world)"
+			node.Token.Kind = lexer.TokenLiteral
+			ast.appendLiteral(node)
 		case lexer.TokenPrefix:
 			node, err = p.parseInterpolation(node)
 			if err != nil {
@@ -145,6 +149,22 @@ func (p *Parser) parse() (ast Ast, err error) {
 
 }
 
+// Adds the literal-node, merging it with the previous node if that is an adjacent literal.
+func (ast *Ast) appendLiteral(node Node) {
+	if n := len(ast.Nodes); n > 0 {
+		last := &ast.Nodes[n-1]
+		if last.Token.Kind == lexer.TokenLiteral && last.Token.End == node.Token.Start {
+			last.Token.Literal += node.Token.Literal
+			last.Token.End = node.Token.End
+			return
+		}
+	}
+	ast.Nodes = append(ast.Nodes, node)
+}
+
+// Parses an interpolation like `{{name}}` or `{{date, datetime}}`.
+// The Left-node holds the name of the variable. If a format is used, the Right-node is the separator,
+// and its Left-node holds the format.
 func (p *Parser) parseInterpolation(node Node) (Node, error) {
 
 	node.Left = &Node{Token: p.peekToken}
@@ -161,6 +181,7 @@ func (p *Parser) parseInterpolation(node Node) (Node, error) {
 			nerr := NodeError{nil, node, fmt.Sprintf("Expected option to be a %s", p.printToken(lexer.TokenLiteral))}
 			return node, node.Left.parentErr(nerr, "expected TokenLiteral")
 		}
+		node.Right.Left = &Node{Token: p.peekToken}
 		p.nextToken()
 	}
 	switch p.peekToken.Kind {
@@ -125,6 +125,33 @@ func TestParser(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"Syntax-characters in text, and format",
+			"Hi, ({{when, datetime}})",
+			Ast{
+				Nodes: []Node{
+					{
+						Token: lexer.Token{Start: 0, End: 5, Kind: lexer.TokenLiteral, Literal: "Hi, ("},
+					},
+					{
+						Token: lexer.Token{Start: 5, End: 23, Kind: lexer.TokenPrefix, Literal: "{{"},
+						Left: &Node{
+							Token: lexer.Token{Start: 7, End: 11, Kind: lexer.TokenLiteral, Literal: "when"},
+						},
+						Right: &Node{
+							Token: lexer.Token{Start: 11, End: 12, Kind: lexer.TokenFormatSeperator, Literal: ","},
+							Left: &Node{
+								Token: lexer.Token{Start: 12, End: 21, Kind: lexer.TokenLiteral, Literal: " datetime"},
+							},
+						},
+					},
+					{
+						Token: lexer.Token{Start: 23, End: 24, Kind: lexer.TokenLiteral, Literal: ")"},
+					},
+				},
+			},
+			false,
+		},
 		// TODO: improve the parser with until the tests below give more meaningful result
 		// {
 		// 	"Multiple flat with semi-natural language",
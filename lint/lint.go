@@ -0,0 +1,226 @@
+// Package lint checks the translations of a project for common mistakes, like variables that are
+// missing in a locale, unbalanced nestings, or values that were never translated.
+//
+// Each project can disable rules, or override their severity, see types.Project.LintRules.
+package lint
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/runar-rkmedia/skiver/importexport"
+	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+type Severity string
+type RuleID string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Severities are all the valid severities, from the least to the most severe
+var Severities = []Severity{SeverityInfo, SeverityWarning, SeverityError}
+
+// An Issue is a single problem found by a rule
+// swagger:model LintIssue
+type Issue struct {
+	Rule     RuleID   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// The full key of the translation, like `General.Items`
+	Key string `json:"key"`
+	// The context-key of the value, if the issue is within a context, like `male_one`
+	Context            string `json:"context,omitempty"`
+	TranslationID      string `json:"translation_id,omitempty"`
+	TranslationValueID string `json:"translation_value_id,omitempty"`
+	LocaleID           string `json:"locale_id,omitempty"`
+}
+
+// RuleConfig is the effective configuration of a rule for a project
+// swagger:model LintRule
+type RuleConfig struct {
+	ID          RuleID   `json:"id"`
+	Description string   `json:"description"`
+	Severity    Severity `json:"severity"`
+	Enabled     bool     `json:"enabled"`
+}
+
+// Report is the result of linting a project
+// swagger:model LintReport
+type Report struct {
+	Rules  []RuleConfig `json:"rules"`
+	Issues []Issue      `json:"issues"`
+}
+
+// Linter runs the rules that are enabled for a project
+type Linter struct {
+	rules   []RuleConfig
+	locales map[string]types.Locale
+}
+
+// New returns a linter with the rule-settings of the project.
+// The locales are used to resolve the locale-ids of the translation-values.
+func New(project types.Project, locales map[string]types.Locale) Linter {
+	l := Linter{locales: locales}
+	for _, r := range rules {
+		rc := RuleConfig{
+			ID:          r.id,
+			Description: r.description,
+			Severity:    r.severity,
+			Enabled:     true,
+		}
+		if s, ok := project.LintRules[string(r.id)]; ok {
+			rc.Enabled = !s.Disabled
+			if s.Severity != "" {
+				rc.Severity = Severity(s.Severity)
+			}
+		}
+		l.rules = append(l.rules, rc)
+	}
+	return l
+}
+
+// Rules returns the effective configuration of all the rules
+func (l Linter) Rules() []RuleConfig {
+	return l.rules
+}
+
+// LintProject lints all the translations within the project
+func LintProject(ep types.ExtendedProject) Report {
+	l := New(ep.Project, ep.Locales)
+	report := Report{Rules: l.Rules(), Issues: []Issue{}}
+	for _, ft := range importexport.FlattenExtendedProject(ep) {
+		report.Issues = append(report.Issues, l.lint(ft.FullKey, ft.Translation, ft.Values)...)
+	}
+	return report
+}
+
+// LintTranslation lints a single translation, with its values.
+// The full key is used in the messages, and should include the category-key.
+func (l Linter) LintTranslation(fullKey string, et types.ExtendedTranslation) []Issue {
+	values := map[string]types.TranslationValue{}
+	for _, tv := range et.Values {
+		if tv.Deleted != nil {
+			continue
+		}
+		values[tv.LocaleID] = tv
+	}
+	return l.lint(fullKey, et.Translation, values)
+}
+
+func (l Linter) lint(fullKey string, t types.Translation, values map[string]types.TranslationValue) []Issue {
+	target := translation{
+		key:     fullKey,
+		values:  values,
+		locales: l.locales,
+	}
+	var issues []Issue
+	for i, r := range rules {
+		rc := l.rules[i]
+		if !rc.Enabled {
+			continue
+		}
+		for _, issue := range r.check(target) {
+			issue.Rule = rc.ID
+			issue.Severity = rc.Severity
+			issue.Key = fullKey
+			issue.TranslationID = t.ID
+			issues = append(issues, issue)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.LocaleID != b.LocaleID {
+			return a.LocaleID < b.LocaleID
+		}
+		return a.Context < b.Context
+	})
+	return issues
+}
+
+// IsRule returns true if the id is a known rule
+func IsRule(id string) bool {
+	for _, r := range rules {
+		if string(r.id) == id {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSeverity returns true if the severity is valid
+func IsSeverity(s string) bool {
+	for _, sev := range Severities {
+		if string(sev) == s {
+			return true
+		}
+	}
+	return false
+}
+
+// RuleIDs returns the ids of all the rules, in the order they are checked
+func RuleIDs() []string {
+	ids := make([]string, len(rules))
+	for i, r := range rules {
+		ids[i] = string(r.id)
+	}
+	return ids
+}
+
+// The translation being linted, with its values by locale-id
+type translation struct {
+	key     string
+	values  map[string]types.TranslationValue
+	locales map[string]types.Locale
+}
+
+// Calls f for each text of each value, that is, the value itself, and each of its contexts.
+// The values are visited in the order of their locale-ids.
+func (t translation) eachText(f func(tv types.TranslationValue, context, text string)) {
+	for _, localeID := range utils.SortedMapKeys(t.values) {
+		tv := t.values[localeID]
+		if tv.Value != "" {
+			f(tv, "", tv.Value)
+		}
+		for _, ctx := range utils.SortedMapKeys(tv.Context) {
+			f(tv, ctx, tv.Context[ctx])
+		}
+	}
+}
+
+// Returns the text for the context-key of the value, or the value itself for an empty context.
+func textOf(tv types.TranslationValue, context string) (string, bool) {
+	if context == "" {
+		return tv.Value, tv.Value != ""
+	}
+	s, ok := tv.Context[context]
+	return s, ok
+}
+
+func (t translation) localeName(localeID string) string {
+	if l, ok := t.locales[localeID]; ok && l.IETF != "" {
+		return l.IETF
+	}
+	return localeID
+}
+
+func (t translation) localeNames(localeIDs []string) string {
+	names := make([]string, len(localeIDs))
+	for i, id := range localeIDs {
+		names[i] = t.localeName(id)
+	}
+	return strings.Join(names, ", ")
+}
+
+func newIssue(tv types.TranslationValue, context, message string) Issue {
+	return Issue{
+		Message:            message,
+		Context:            context,
+		TranslationValueID: tv.ID,
+		LocaleID:           tv.LocaleID,
+	}
+}
@@ -0,0 +1,121 @@
+package lint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+var testLocales = map[string]types.Locale{
+	"loc-en": {Entity: types.Entity{ID: "loc-en"}, IETF: "en-GB", Iso639_1: "en"},
+	"loc-us": {Entity: types.Entity{ID: "loc-us"}, IETF: "en-US", Iso639_1: "en"},
+	"loc-nb": {Entity: types.Entity{ID: "loc-nb"}, IETF: "nb-NO", Iso639_1: "nb"},
+	"loc-pl": {Entity: types.Entity{ID: "loc-pl"}, IETF: "pl-PL", Iso639_1: "pl"},
+}
+
+func value(localeID, v string, context map[string]string) types.TranslationValue {
+	tv := types.TranslationValue{LocaleID: localeID, Value: v, Context: context}
+	tv.ID = "tv-" + localeID
+	return tv
+}
+
+func TestLintTranslation(t *testing.T) {
+	created := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	source := value("loc-en", "Welcome, {{name}}", nil)
+	source.CreatedAt = created
+	translated := func(localeID, v string) types.TranslationValue {
+		tv := value(localeID, v, nil)
+		tv.CreatedAt = created.Add(time.Hour)
+		return tv
+	}
+	tests := []struct {
+		name    string
+		values  []types.TranslationValue
+		project types.Project
+		want    []Issue
+	}{
+		{
+			"No issues",
+			[]types.TranslationValue{source, translated("loc-nb", "Velkommen, {{name}}")},
+			types.Project{},
+			nil,
+		},
+		{
+			"Missing variable",
+			[]types.TranslationValue{source, translated("loc-nb", "Velkommen")},
+			types.Project{},
+			[]Issue{{Rule: RuleMissingVariables, Severity: SeverityError, Message: "The variable 'name' is missing, but is used in en-GB", LocaleID: "loc-nb", TranslationValueID: "tv-loc-nb"}},
+		},
+		{
+			"Unclosed nesting and interpolation",
+			[]types.TranslationValue{value("loc-en", "$t(General.Foo and {{name", nil)},
+			types.Project{},
+			[]Issue{
+				{Rule: RuleSyntax, Severity: SeverityError, Message: "The nesting '$t(' at position 0 is not closed", LocaleID: "loc-en", TranslationValueID: "tv-loc-en"},
+				{Rule: RuleSyntax, Severity: SeverityError, Message: "The interpolation '{{' at position 19 is not closed", LocaleID: "loc-en", TranslationValueID: "tv-loc-en"},
+			},
+		},
+		{
+			"Unclosed interpolation",
+			[]types.TranslationValue{value("loc-en", "Hello {{name", nil)},
+			types.Project{},
+			[]Issue{
+				{Rule: RuleSyntax, Severity: SeverityError, Message: "The interpolation '{{' at position 6 is not closed", LocaleID: "loc-en", TranslationValueID: "tv-loc-en"},
+			},
+		},
+		{
+			"Whitespace, with overridden severity",
+			[]types.TranslationValue{value("loc-en", "Hello ", nil)},
+			types.Project{LintRules: map[string]types.LintRuleSetting{"whitespace": {Severity: "error"}}},
+			[]Issue{{Rule: RuleWhitespace, Severity: SeverityError, Message: "The value has leading or trailing whitespace", LocaleID: "loc-en", TranslationValueID: "tv-loc-en"}},
+		},
+		{
+			"Disabled rule",
+			[]types.TranslationValue{value("loc-en", "Hello ", nil)},
+			types.Project{LintRules: map[string]types.LintRuleSetting{"whitespace": {Disabled: true}}},
+			nil,
+		},
+		{
+			"Identical to source",
+			[]types.TranslationValue{source, translated("loc-nb", "Welcome, {{name}}"), translated("loc-us", "Welcome, {{name}}")},
+			types.Project{},
+			[]Issue{{Rule: RuleIdenticalToSource, Severity: SeverityInfo, Message: "The value is identical to the source-value in en-GB", LocaleID: "loc-nb", TranslationValueID: "tv-loc-nb"}},
+		},
+		{
+			"Plurals, where count is optional",
+			[]types.TranslationValue{
+				value("loc-en", "", map[string]string{"one": "One item", "other": "{{count}} items"}),
+				value("loc-pl", "", map[string]string{"one": "{{count}} przedmiot", "other": "{{count}} przedmiotu"}),
+			},
+			types.Project{},
+			[]Issue{{Rule: RulePlurals, Severity: SeverityWarning, Message: "The plural-forms few, many are missing", LocaleID: "loc-pl", TranslationValueID: "tv-loc-pl"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			et := types.ExtendedTranslation{Values: map[string]types.TranslationValue{}}
+			et.ID = "t-1"
+			for _, tv := range tt.values {
+				et.Values[tv.ID] = tv
+			}
+			for i := range tt.want {
+				tt.want[i].Key = "General.Welcome"
+				tt.want[i].TranslationID = "t-1"
+			}
+			got := New(tt.project, testLocales).LintTranslation("General.Welcome", et)
+			testza.AssertEqual(t, tt.want, got)
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	l := New(types.Project{LintRules: map[string]types.LintRuleSetting{"plurals": {Disabled: true}}}, nil)
+	for _, r := range l.Rules() {
+		testza.AssertTrue(t, IsRule(string(r.ID)))
+		testza.AssertTrue(t, IsSeverity(string(r.Severity)))
+		testza.AssertEqual(t, r.ID != RulePlurals, r.Enabled, r.ID)
+	}
+	testza.AssertFalse(t, IsRule("nope"))
+}
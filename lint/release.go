@@ -0,0 +1,69 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runar-rkmedia/skiver/importexport"
+	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+const (
+	RuleMissingValue RuleID = "missing-value"
+	RuleEmptyValue   RuleID = "empty-value"
+)
+
+// ReleaseBlockers returns the issues that would break a release of the project:
+// values that are missing or empty in any of the locales, and variables that differ between the locales.
+// Unlike the lint-rules, these cannot be configured, and are always errors.
+//
+// The project should be extended with only the locales that are published.
+func ReleaseBlockers(ep types.ExtendedProject) []Issue {
+	var issues []Issue
+	for _, ft := range importexport.FlattenExtendedProject(ep) {
+		values := map[string]types.TranslationValue{}
+		for localeID, tv := range ft.Values {
+			if _, ok := ep.Locales[localeID]; ok {
+				values[localeID] = tv
+			}
+		}
+		t := translation{key: ft.FullKey, values: values, locales: ep.Locales}
+		var found []Issue
+		for _, localeID := range utils.SortedMapKeys(ep.Locales) {
+			tv, ok := values[localeID]
+			if !ok {
+				found = append(found, Issue{
+					Rule:     RuleMissingValue,
+					Message:  fmt.Sprintf("The value for %s is missing", t.localeName(localeID)),
+					LocaleID: localeID,
+				})
+				continue
+			}
+			// Values with only contexts, like plurals, normally do not have a value of their own
+			if len(tv.Context) == 0 && strings.TrimSpace(tv.Value) == "" {
+				issue := newIssue(tv, "", "The value is empty")
+				issue.Rule = RuleEmptyValue
+				found = append(found, issue)
+			}
+			for _, ctx := range utils.SortedMapKeys(tv.Context) {
+				if strings.TrimSpace(tv.Context[ctx]) == "" {
+					issue := newIssue(tv, ctx, fmt.Sprintf("The value for the context '%s' is empty", ctx))
+					issue.Rule = RuleEmptyValue
+					found = append(found, issue)
+				}
+			}
+		}
+		for _, issue := range checkMissingVariables(t) {
+			issue.Rule = RuleMissingVariables
+			found = append(found, issue)
+		}
+		for _, issue := range found {
+			issue.Severity = SeverityError
+			issue.Key = ft.FullKey
+			issue.TranslationID = ft.ID
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
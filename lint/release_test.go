@@ -0,0 +1,57 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestReleaseBlockers(t *testing.T) {
+	locales := map[string]types.Locale{
+		"loc-en": testLocales["loc-en"],
+		"loc-nb": testLocales["loc-nb"],
+	}
+	ep := types.ExtendedProject{
+		Locales: locales,
+		Categories: map[string]types.ExtendedCategory{
+			"cat": {
+				Category: types.Category{Key: "General"},
+				Translations: map[string]types.ExtendedTranslation{
+					"t-ok": {
+						Translation: types.Translation{Key: "Ok"},
+						Values: map[string]types.TranslationValue{
+							"a": value("loc-en", "Hi, {{name}}", nil),
+							"b": value("loc-nb", "Hei, {{name}}", nil),
+							// Unpublished locales are ignored
+							"c": value("loc-pl", "", nil),
+						},
+					},
+					"t-broken": {
+						Translation: types.Translation{Key: "Broken"},
+						Values: map[string]types.TranslationValue{
+							"a": value("loc-en", "Hi, {{name}}", map[string]string{"male": " "}),
+						},
+					},
+					"t-mismatch": {
+						Translation: types.Translation{Key: "Mismatch"},
+						Values: map[string]types.TranslationValue{
+							"a": value("loc-en", "Hi, {{name}}", nil),
+							"b": value("loc-nb", "Hei", nil),
+						},
+					},
+				},
+			},
+		},
+	}
+	var got []string
+	for _, issue := range ReleaseBlockers(ep) {
+		testza.AssertEqual(t, SeverityError, issue.Severity)
+		got = append(got, issue.Key+" "+string(issue.Rule)+" "+issue.Message)
+	}
+	testza.AssertEqual(t, []string{
+		"General.Broken empty-value The value for the context 'male' is empty",
+		"General.Broken missing-value The value for nb-NO is missing",
+		"General.Mismatch missing-variables The variable 'name' is missing, but is used in en-GB",
+	}, got)
+}
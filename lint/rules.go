@@ -0,0 +1,198 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/runar-rkmedia/skiver/importexport"
+	"github.com/runar-rkmedia/skiver/interpolator/lexer"
+	"github.com/runar-rkmedia/skiver/interpolator/parser"
+	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+const (
+	RuleMissingVariables  RuleID = "missing-variables"
+	RuleSyntax            RuleID = "syntax"
+	RuleWhitespace        RuleID = "whitespace"
+	RuleIdenticalToSource RuleID = "identical-to-source"
+	RulePlurals           RuleID = "plurals"
+)
+
+type rule struct {
+	id          RuleID
+	description string
+	severity    Severity
+	check       func(t translation) []Issue
+}
+
+var rules = []rule{
+	{
+		id:          RuleSyntax,
+		description: "Interpolations like `{{name}}` and nestings like `$t(key)` must be closed",
+		severity:    SeverityError,
+		check:       checkSyntax,
+	},
+	{
+		id:          RuleMissingVariables,
+		description: "Variables used in one locale should be used in all locales",
+		severity:    SeverityError,
+		check:       checkMissingVariables,
+	},
+	{
+		id:          RulePlurals,
+		description: "Values with plurals should have all the plural-forms of the locale",
+		severity:    SeverityWarning,
+		check:       checkPlurals,
+	},
+	{
+		id:          RuleWhitespace,
+		description: "Values should not have leading or trailing whitespace",
+		severity:    SeverityWarning,
+		check:       checkWhitespace,
+	},
+	{
+		id:          RuleIdenticalToSource,
+		description: "Values should not be identical to the source-value in another language",
+		severity:    SeverityInfo,
+		check:       checkIdenticalToSource,
+	},
+}
+
+func checkSyntax(t translation) (issues []Issue) {
+	p := parser.NewParser(nil)
+	t.eachText(func(tv types.TranslationValue, context, text string) {
+		ast, err := p.Parse(text)
+		if err != nil {
+			msg := "The value could not be parsed"
+			var nodeErr parser.NodeError
+			if errors.As(err, &nodeErr) {
+				msg = fmt.Sprintf("The value could not be parsed, near position %d", nodeErr.Token.Start)
+			}
+			issues = append(issues, newIssue(tv, context, msg))
+			return
+		}
+		for _, n := range ast.Nodes {
+			// The parser only extends the end of the node to the suffix, if the suffix is found
+			if n.Token.End != n.Token.Start+len(n.Token.Literal) {
+				continue
+			}
+			switch n.Token.Kind {
+			case lexer.TokenPrefix:
+				issues = append(issues, newIssue(tv, context, fmt.Sprintf("The interpolation '%s' at position %d is not closed", n.Token.Literal, n.Token.Start)))
+			case lexer.TokenNestingPrefix:
+				issues = append(issues, newIssue(tv, context, fmt.Sprintf("The nesting '%s' at position %d is not closed", n.Token.Literal, n.Token.Start)))
+			}
+		}
+	})
+	return
+}
+
+// Variables are compared between the locales for each context.
+// For plural-forms, `count` is not required, since values like `One item` are common.
+func checkMissingVariables(t translation) (issues []Issue) {
+	type usage struct {
+		tv        types.TranslationValue
+		variables map[string]bool
+	}
+	byContext := map[string][]usage{}
+	t.eachText(func(tv types.TranslationValue, context, text string) {
+		_, variables, _ := importexport.InferVariables(text, "", "", nil)
+		u := usage{tv, map[string]bool{}}
+		for k := range variables {
+			u.variables[k] = true
+		}
+		byContext[context] = append(byContext[context], u)
+	})
+	for _, context := range utils.SortedMapKeys(byContext) {
+		usages := byContext[context]
+		_, plural := importexport.SplitContextAndPlural(context)
+		for _, u := range usages {
+			missing := map[string][]string{}
+			for _, other := range usages {
+				for v := range other.variables {
+					if u.variables[v] || (plural != "" && v == "count") {
+						continue
+					}
+					missing[v] = append(missing[v], other.tv.LocaleID)
+				}
+			}
+			for _, v := range utils.SortedMapKeys(missing) {
+				issues = append(issues, newIssue(u.tv, context, fmt.Sprintf("The variable '%s' is missing, but is used in %s", v, t.localeNames(missing[v]))))
+			}
+		}
+	}
+	return
+}
+
+func checkPlurals(t translation) (issues []Issue) {
+	for _, localeID := range utils.SortedMapKeys(t.values) {
+		locale, ok := t.locales[localeID]
+		if !ok {
+			continue
+		}
+		tv := t.values[localeID]
+		for _, w := range importexport.ValidatePlurals(t.key, tv, locale) {
+			d, _ := w.Details.(importexport.MissingPluralForms)
+			issues = append(issues, newIssue(tv, d.Context, fmt.Sprintf("The plural-forms %s are missing", strings.Join(d.Missing, ", "))))
+		}
+	}
+	return
+}
+
+func checkWhitespace(t translation) (issues []Issue) {
+	t.eachText(func(tv types.TranslationValue, context, text string) {
+		if strings.TrimSpace(text) != text {
+			issues = append(issues, newIssue(tv, context, "The value has leading or trailing whitespace"))
+		}
+	})
+	return
+}
+
+// The source-value is the value that was created first, which is normally the one that the others were translated from.
+// Values in other languages that are identical to the source were likely not translated.
+// Values without any letters, like `{{count}}` or `:)`, are not checked.
+func checkIdenticalToSource(t translation) (issues []Issue) {
+	if len(t.values) < 2 {
+		return
+	}
+	localeIDs := utils.SortedMapKeys(t.values)
+	sort.SliceStable(localeIDs, func(i, j int) bool {
+		return t.values[localeIDs[i]].CreatedAt.Before(t.values[localeIDs[j]].CreatedAt)
+	})
+	source := t.values[localeIDs[0]]
+	sourceLocale, ok := t.locales[source.LocaleID]
+	if !ok {
+		return
+	}
+	for _, localeID := range localeIDs[1:] {
+		locale, ok := t.locales[localeID]
+		if !ok || locale.Iso639_1 == sourceLocale.Iso639_1 {
+			continue
+		}
+		tv := t.values[localeID]
+		contexts := append([]string{""}, utils.SortedMapKeys(tv.Context)...)
+		for _, context := range contexts {
+			value, ok := textOf(tv, context)
+			if !ok || !hasLetters(value) {
+				continue
+			}
+			if sourceValue, ok := textOf(source, context); ok && sourceValue == value {
+				issues = append(issues, newIssue(tv, context, fmt.Sprintf("The value is identical to the source-value in %s", t.localeName(source.LocaleID))))
+			}
+		}
+	}
+	return
+}
+
+func hasLetters(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
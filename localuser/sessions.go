@@ -10,6 +10,23 @@ import (
 	"github.com/runar-rkmedia/skiver/types"
 )
 
+// Sessions is implemented by the session-backends
+type Sessions interface {
+	NewSession(user types.User, organization types.Organization, userAgent string, opts ...types.UserSessionOptions) (s types.Session)
+	GetSession(token string) (s types.Session, err error)
+	SessionsForUser(userId string) (s []types.Session)
+	ClearAllSessionsForUser(userId string) error
+	ClearSessionById(token string) error
+	// Revokes the session with the id, if it belongs to the user
+	RevokeSession(userId, id string) error
+	UpdateAllSessionsForUser(userId string, user types.User) error
+	// Removes expired sessions, and returns the number of sessions that were removed
+	Sweep() (int, error)
+	TTL() time.Duration
+}
+
+// UserSessionInMemory keeps sessions in memory, optionally persisted through a Persistor.
+// Sessions cannot be shared between replicas.
 type UserSessionInMemory struct {
 	c         *cache.Cache
 	t         func() string
@@ -40,6 +57,11 @@ func NewUserSessionInMemory(options types.UserSessionOptions, tokenCreator func(
 				if err != nil {
 					return u, fmt.Errorf("failed to evict prior sessions: %w", err)
 				}
+				continue
+			}
+			// Sessions that were persisted before sessions had ids
+			if v.ID == "" {
+				v.ID = tokenCreator()
 			}
 			items[k] = cache.Item{
 				Object:     v,
@@ -98,6 +120,7 @@ func (us UserSessionInMemory) newSession(token string, user types.User, organiza
 		ttl = us.UserSessionOptions.TTL
 	}
 	s = types.Session{
+		ID:           us.t(),
 		Token:        token,
 		User:         user,
 		Organization: organization,
@@ -126,6 +149,20 @@ func (us UserSessionInMemory) ClearAllSessionsForUser(userId string) error {
 	}
 	return nil
 }
+func (us UserSessionInMemory) RevokeSession(userId, id string) error {
+	for _, s := range us.SessionsForUser(userId) {
+		if s.ID == id {
+			us.c.Delete(s.Token)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+func (us UserSessionInMemory) Sweep() (int, error) {
+	before := us.c.ItemCount()
+	us.c.DeleteExpired()
+	return before - us.c.ItemCount(), nil
+}
 func (us UserSessionInMemory) UpdateAllSessionsForUser(userId string, user types.User) error {
 	s := us.SessionsForUser(userId)
 	for _, v := range s {
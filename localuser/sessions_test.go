@@ -0,0 +1,119 @@
+package localuser
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/google/uuid"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestSessions(t *testing.T) {
+	options := types.UserSessionOptions{TTL: time.Hour}
+	secret := []byte(strings.Repeat("s", MinSessionSecretLength))
+	backends := map[string]func(t *testing.T) Sessions{
+		"memory": func(t *testing.T) Sessions {
+			s, err := NewUserSessionInMemory(options, uuid.NewString, nil)
+			testza.AssertNoError(t, err)
+			return s
+		},
+		"bbolt": func(t *testing.T) Sessions {
+			s, err := NewSignedSessions(options, secret, uuid.NewString, mockStore(t))
+			testza.AssertNoError(t, err)
+			return s
+		},
+	}
+	for name, create := range backends {
+		t.Run(name, func(t *testing.T) {
+			sessions := create(t)
+			jane := types.User{Entity: types.Entity{ID: "jane"}, UserName: "jane"}
+			joe := types.User{Entity: types.Entity{ID: "joe"}, UserName: "joe"}
+			org := types.Organization{ID: "org"}
+
+			laptop := sessions.NewSession(jane, org, "laptop")
+			phone := sessions.NewSession(jane, org, "phone")
+			other := sessions.NewSession(joe, org, "laptop")
+			short := sessions.NewSession(jane, org, "ci", types.UserSessionOptions{TTL: time.Millisecond})
+			testza.AssertNotEqual(t, "", laptop.Token)
+			testza.AssertNotEqual(t, "", laptop.ID)
+			testza.AssertNotEqual(t, laptop.ID, laptop.Token, "The id is not a secret, and must differ from the token")
+
+			s, err := sessions.GetSession(laptop.Token)
+			testza.AssertNoError(t, err)
+			testza.AssertEqual(t, laptop.ID, s.ID)
+			testza.AssertEqual(t, "jane", s.User.UserName)
+			_, err = sessions.GetSession("forged")
+			testza.AssertErrorIs(t, err, ErrNotFound)
+
+			time.Sleep(5 * time.Millisecond)
+			_, err = sessions.GetSession(short.Token)
+			testza.AssertNotNil(t, err, "Expired sessions cannot be used")
+			_, err = sessions.Sweep()
+			testza.AssertNoError(t, err)
+			testza.AssertLen(t, sessions.SessionsForUser(jane.ID), 2)
+
+			for _, s := range sessions.SessionsForUser(jane.ID) {
+				if s.ID == phone.ID {
+					testza.AssertEqual(t, phone.Token, s.Token, "Listed sessions should be usable for logins on the same device")
+				}
+			}
+
+			testza.AssertErrorIs(t, sessions.RevokeSession(joe.ID, phone.ID), ErrNotFound, "Users can only revoke their own sessions")
+			testza.AssertNoError(t, sessions.RevokeSession(jane.ID, phone.ID))
+			_, err = sessions.GetSession(phone.Token)
+			testza.AssertNotNil(t, err, "Revoked sessions cannot be used")
+			testza.AssertLen(t, sessions.SessionsForUser(jane.ID), 1)
+
+			jane.UserName = "jane.doe"
+			testza.AssertNoError(t, sessions.UpdateAllSessionsForUser(jane.ID, jane))
+			s, err = sessions.GetSession(laptop.Token)
+			testza.AssertNoError(t, err)
+			testza.AssertEqual(t, "jane.doe", s.User.UserName)
+
+			testza.AssertNoError(t, sessions.ClearSessionById(laptop.Token))
+			_, err = sessions.GetSession(laptop.Token)
+			testza.AssertNotNil(t, err)
+			testza.AssertNoError(t, sessions.ClearAllSessionsForUser(joe.ID))
+			_, err = sessions.GetSession(other.Token)
+			testza.AssertNotNil(t, err)
+		})
+	}
+}
+
+func mockStore(t *testing.T) SessionStore {
+	bb := bboltStorage.NewMockDB(t)
+	return bb.Storage.(SessionStore)
+}
+
+func TestSignedSessionsRejectsTamperedTokens(t *testing.T) {
+	bb := mockStore(t)
+	secret := []byte(strings.Repeat("s", MinSessionSecretLength))
+	sessions, err := NewSignedSessions(types.UserSessionOptions{TTL: time.Hour}, secret, uuid.NewString, bb)
+	testza.AssertNoError(t, err)
+	_, err = NewSignedSessions(types.UserSessionOptions{TTL: time.Hour}, []byte("short"), uuid.NewString, bb)
+	testza.AssertErrorIs(t, err, ErrSessionSecretTooShort)
+
+	s := sessions.NewSession(types.User{Entity: types.Entity{ID: "jane"}}, types.Organization{ID: "org"}, "laptop")
+	parts := strings.Split(s.Token, ".")
+	testza.AssertLen(t, parts, 3)
+	extended := parts[0] + "." + "99999999999" + "." + parts[2]
+	_, err = sessions.GetSession(extended)
+	testza.AssertErrorIs(t, err, ErrNotFound, "The expiry cannot be changed without the secret")
+
+	otherSecret, err := NewSignedSessions(types.UserSessionOptions{TTL: time.Hour}, []byte(strings.Repeat("x", MinSessionSecretLength)), uuid.NewString, bb)
+	testza.AssertNoError(t, err)
+	_, err = otherSecret.GetSession(s.Token)
+	testza.AssertErrorIs(t, err, ErrNotFound, "Tokens signed with another secret are rejected")
+
+	replica, err := NewSignedSessions(types.UserSessionOptions{TTL: time.Hour}, secret, uuid.NewString, bb)
+	testza.AssertNoError(t, err)
+	_, err = replica.GetSession(s.Token)
+	testza.AssertNoError(t, err, "Replicas with the same store and secret share sessions")
+
+	stored, err := bb.GetSession(s.ID)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "", stored.Token, "Tokens should not be stored")
+}
@@ -0,0 +1,199 @@
+package localuser
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/runar-rkmedia/go-common/logger"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+// SessionStore persists sessions by their id.
+type SessionStore interface {
+	GetSession(id string) (*types.Session, error)
+	FindSessions(userID string) ([]types.Session, error)
+	CreateSession(id string, session types.Session) (types.Session, error)
+	EvictSession(id string) error
+	EvictExpiredSessions(now time.Time) (int, error)
+}
+
+// SignedSessions issues tokens that are signed with a secret, and keeps the sessions in a SessionStore.
+//
+// The signature lets forged and expired tokens be rejected without a lookup,
+// while the store makes it possible to list and revoke sessions.
+// Replicas that use the same store and secret share their sessions.
+// The tokens themselves are never stored.
+type SignedSessions struct {
+	store  SessionStore
+	secret []byte
+	newID  func() string
+	types.UserSessionOptions
+}
+
+// The secret should be at least this long
+const MinSessionSecretLength = 32
+
+var ErrSessionSecretTooShort = errors.New("The session-secret must be at least 32 bytes")
+
+func NewSignedSessions(options types.UserSessionOptions, secret []byte, idCreator func() string, store SessionStore) (SignedSessions, error) {
+	if len(secret) < MinSessionSecretLength {
+		return SignedSessions{}, ErrSessionSecretTooShort
+	}
+	return SignedSessions{store, secret, idCreator, options}, nil
+}
+
+// Tokens have the form id.expires.signature
+func (ss SignedSessions) token(id string, expires time.Time) string {
+	payload := id + "." + strconv.FormatInt(expires.Unix(), 10)
+	return payload + "." + ss.sign(payload)
+}
+
+func (ss SignedSessions) sign(payload string) string {
+	mac := hmac.New(sha256.New, ss.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Returns the id of the session, if the token is signed by us, and has not expired.
+func (ss SignedSessions) parse(token string) (string, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return "", ErrNotFound
+	}
+	payload, signature := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(ss.sign(payload)), []byte(signature)) {
+		return "", ErrNotFound
+	}
+	id, exp, ok := strings.Cut(payload, ".")
+	if !ok {
+		return "", ErrNotFound
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return "", ErrNotFound
+	}
+	if time.Now().After(time.Unix(unix, 0)) {
+		return id, ErrSessionExpired
+	}
+	return id, nil
+}
+
+func (ss SignedSessions) NewSession(user types.User, organization types.Organization, userAgent string, opts ...types.UserSessionOptions) (s types.Session) {
+	ttl := ss.UserSessionOptions.TTL
+	if len(opts) > 0 && opts[0].TTL > 0 {
+		ttl = opts[0].TTL
+	}
+	now := time.Now()
+	s = types.Session{
+		ID:           ss.newID(),
+		User:         user,
+		Organization: organization,
+		UserAgent:    userAgent,
+		Issued:       now,
+		// The token only has a precision of seconds
+		Expires: now.Add(ttl).Truncate(time.Second),
+	}
+	if _, err := ss.store.CreateSession(s.ID, s); err != nil {
+		l := logger.GetLogger("SignedSessions.NewSession")
+		l.Error().Err(err).Msg("failed to create session")
+		return types.Session{}
+	}
+	s.Token = ss.token(s.ID, s.Expires)
+	return s
+}
+
+func (ss SignedSessions) GetSession(token string) (s types.Session, err error) {
+	id, err := ss.parse(token)
+	if err != nil {
+		return s, err
+	}
+	session, err := ss.store.GetSession(id)
+	if err != nil {
+		return s, err
+	}
+	if session == nil {
+		return s, ErrNotFound
+	}
+	if time.Now().After(session.Expires) {
+		return s, ErrSessionExpired
+	}
+	session.Token = token
+	return *session, nil
+}
+
+func (ss SignedSessions) SessionsForUser(userId string) (s []types.Session) {
+	sessions, err := ss.store.FindSessions(userId)
+	if err != nil {
+		l := logger.GetLogger("SignedSessions.SessionsForUser")
+		l.Error().Err(err).Msg("failed to find sessions")
+		return nil
+	}
+	now := time.Now()
+	for _, session := range sessions {
+		if now.After(session.Expires) {
+			continue
+		}
+		session.Token = ss.token(session.ID, session.Expires)
+		s = append(s, session)
+	}
+	return
+}
+
+func (ss SignedSessions) ClearSessionById(token string) error {
+	id, err := ss.parse(token)
+	if err == ErrNotFound {
+		return nil
+	}
+	return ss.store.EvictSession(id)
+}
+
+func (ss SignedSessions) ClearAllSessionsForUser(userId string) error {
+	sessions, err := ss.store.FindSessions(userId)
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		if err := ss.store.EvictSession(s.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ss SignedSessions) RevokeSession(userId, id string) error {
+	s, err := ss.store.GetSession(id)
+	if err != nil {
+		return err
+	}
+	if s == nil || s.User.ID != userId {
+		return ErrNotFound
+	}
+	return ss.store.EvictSession(id)
+}
+
+func (ss SignedSessions) UpdateAllSessionsForUser(userId string, user types.User) error {
+	sessions, err := ss.store.FindSessions(userId)
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		s.User = user
+		if _, err := ss.store.CreateSession(s.ID, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ss SignedSessions) Sweep() (int, error) {
+	return ss.store.EvictExpiredSessions(time.Now())
+}
+
+func (ss SignedSessions) TTL() time.Duration {
+	return ss.UserSessionOptions.TTL
+}
@@ -0,0 +1,400 @@
+package localuser
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+// LoginThrottleStore persists login-attempts, so that they survive restarts.
+type LoginThrottleStore interface {
+	FindLoginAttempts() ([]types.LoginAttempts, error)
+	UpdateLoginAttempts(keys []string, f func(attempts []*types.LoginAttempts) error) error
+	EvictLoginAttempts(f func(a types.LoginAttempts) bool) (int, error)
+}
+
+type LoginThrottleLimits struct {
+	// Failed logins before each new attempt is delayed
+	FreeAttempts int
+	// Failed logins before logins are locked
+	LockoutThreshold int
+}
+
+type LoginThrottleOptions struct {
+	// If set, logins are never refused, but failures are still reported
+	Disabled bool
+	User     LoginThrottleLimits
+	// Ips are often shared, and should have higher limits than usernames
+	IP LoginThrottleLimits
+	// The delay after the first failure beyond the free attempts, which is doubled for every failure
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// The duration of the first lockout, which is doubled for every lockout
+	LockoutDuration    time.Duration
+	MaxLockoutDuration time.Duration
+	// Failures are forgotten after this long without failures
+	ResetAfter time.Duration
+	// The reverse-proxies whose forwarded-headers are trusted, for the ip of the client.
+	// Without any, the remote address of the connection is used, since the headers are set by the client.
+	TrustedProxies []*net.IPNet
+}
+
+// LoginThrottle slows down guessing of passwords, by delaying logins exponentially after a few failures,
+// and locking them temporarily after too many, both per username and per ip.
+//
+// Attempts are counted as failures before the password is verified, so that parallel attempts cannot slip past
+// the limits, and are undone if the login succeeds.
+type LoginThrottle struct {
+	store   LoginThrottleStore
+	options LoginThrottleOptions
+	now     func() time.Time
+	// Called for every failed or refused login, with the reason, for instance for metrics
+	OnFailure func(reason string)
+	// Called when logins for a username, or from an ip, are locked
+	OnLockout func(kind types.LoginAttemptsKind)
+}
+
+// Reasons that logins fail for
+const (
+	LoginFailureUnknownUser       = "unknown-user"
+	LoginFailureIncorrectPassword = "incorrect-password"
+	// An incorrect code for multi-factor authentication
+	LoginFailureIncorrectCode = "incorrect-code"
+	LoginFailureThrottled     = "throttled"
+	LoginFailureLocked        = "locked"
+)
+
+func NewLoginThrottle(options LoginThrottleOptions, store LoginThrottleStore) *LoginThrottle {
+	if options.User.FreeAttempts <= 0 {
+		options.User.FreeAttempts = 3
+	}
+	if options.User.LockoutThreshold <= 0 {
+		options.User.LockoutThreshold = 10
+	}
+	if options.IP.FreeAttempts <= 0 {
+		options.IP.FreeAttempts = 10
+	}
+	if options.IP.LockoutThreshold <= 0 {
+		options.IP.LockoutThreshold = 50
+	}
+	if options.BaseDelay <= 0 {
+		options.BaseDelay = time.Second
+	}
+	if options.MaxDelay <= 0 {
+		options.MaxDelay = 5 * time.Minute
+	}
+	if options.LockoutDuration <= 0 {
+		options.LockoutDuration = 15 * time.Minute
+	}
+	if options.MaxLockoutDuration <= 0 {
+		options.MaxLockoutDuration = 24 * time.Hour
+	}
+	if options.ResetAfter <= 0 {
+		options.ResetAfter = 24 * time.Hour
+	}
+	return &LoginThrottle{store: store, options: options, now: time.Now}
+}
+
+// ErrLoginThrottled is returned when logins are refused, along with when they are allowed again.
+type ErrLoginThrottled struct {
+	Kind   types.LoginAttemptsKind
+	Until  time.Time
+	Locked bool
+}
+
+func (e ErrLoginThrottled) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("Logins for this %s are locked because of too many failed logins", e.Kind)
+	}
+	return fmt.Sprintf("Too many failed logins for this %s", e.Kind)
+}
+
+// RetryAfter returns how long until logins are allowed again, in whole seconds
+func (e ErrLoginThrottled) RetryAfter(now time.Time) time.Duration {
+	d := e.Until.Sub(now)
+	if d < time.Second {
+		return time.Second
+	}
+	return d.Round(time.Second)
+}
+
+func UserAttemptsKey(username string) string {
+	return string(types.LoginAttemptsUser) + ":" + strings.ToLower(username)
+}
+func IPAttemptsKey(ip string) string {
+	return string(types.LoginAttemptsIP) + ":" + ip
+}
+
+// ParseTrustedProxies parses the ips and cidr-ranges of reverse-proxies, like 10.0.0.1 or 10.0.0.0/8
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, p := range proxies {
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip for trusted proxy: %s", p)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr-range for trusted proxy: %w", err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// ClientIP returns the ip of the client that made the request, which is the remote address of the connection,
+// unless that is a trusted proxy. The forwarded-headers are then read from the right, since each proxy appends
+// the address it received the request from, and only the entries appended by trusted proxies can be trusted.
+// Ports are removed, since they change for every connection.
+func (t *LoginThrottle) ClientIP(r *http.Request) string {
+	ip := withoutPort(r.RemoteAddr)
+	if !t.trustedProxy(ip) {
+		return ip
+	}
+	hops := forwardedFor(r.Header)
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip = hops[i]
+		if !t.trustedProxy(ip) {
+			return ip
+		}
+	}
+	return ip
+}
+
+func (t *LoginThrottle) trustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range t.options.TrustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the addresses from the Forwarded-header, or the X-Forwarded-For-header, in the order they were added.
+func forwardedFor(h http.Header) []string {
+	var hops []string
+	if forwarded := h.Values("Forwarded"); len(forwarded) > 0 {
+		for _, element := range strings.Split(strings.Join(forwarded, ","), ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+				if strings.EqualFold(key, "for") {
+					hops = append(hops, withoutPort(strings.Trim(value, `"`)))
+				}
+			}
+		}
+		return hops
+	}
+	for _, hop := range strings.Split(strings.Join(h.Values("X-Forwarded-For"), ","), ",") {
+		if hop = strings.TrimSpace(hop); hop != "" {
+			hops = append(hops, withoutPort(hop))
+		}
+	}
+	return hops
+}
+
+func withoutPort(address string) string {
+	address = strings.TrimSpace(address)
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	return strings.Trim(address, "[]")
+}
+
+// PendingLogin is a login that has been counted as failed, until it is resolved.
+type PendingLogin struct {
+	throttle *LoginThrottle
+	keys     []string
+	locked   []types.LoginAttemptsKind
+	done     bool
+}
+
+// Attempt registers an attempt to log in, which is counted as failed until Succeed is called.
+// It returns ErrLoginThrottled if logins for the username, or from the ip, are currently refused.
+// The user should be set if the username belongs to a user, so that admins of its organization can unlock it.
+func (t *LoginThrottle) Attempt(username, ip string, user *types.User) (*PendingLogin, error) {
+	p := &PendingLogin{throttle: t}
+	if t.options.Disabled {
+		return p, nil
+	}
+	p.keys = []string{UserAttemptsKey(username), IPAttemptsKey(ip)}
+	values := []string{strings.ToLower(username), ip}
+	kinds := []types.LoginAttemptsKind{types.LoginAttemptsUser, types.LoginAttemptsIP}
+	limits := []LoginThrottleLimits{t.options.User, t.options.IP}
+	now := t.now()
+	err := t.store.UpdateLoginAttempts(p.keys, func(attempts []*types.LoginAttempts) error {
+		for i, a := range attempts {
+			if a.LockedUntil != nil {
+				if a.Locked(now) {
+					return ErrLoginThrottled{Kind: kinds[i], Until: *a.LockedUntil, Locked: true}
+				}
+				a.LockedUntil = nil
+			} else if now.Sub(a.LastFailure) > t.options.ResetAfter {
+				*a = types.LoginAttempts{Key: a.Key}
+			}
+			if now.Before(a.NextAttempt) {
+				return ErrLoginThrottled{Kind: kinds[i], Until: a.NextAttempt}
+			}
+		}
+		p.locked = nil
+		for i, a := range attempts {
+			a.Kind = kinds[i]
+			a.Value = values[i]
+			if a.Kind == types.LoginAttemptsUser && user != nil {
+				a.UserID = user.ID
+				a.OrganizationID = user.OrganizationID
+			}
+			a.Failures++
+			a.LastFailure = now
+			if a.Failures >= limits[i].LockoutThreshold {
+				until := now.Add(t.lockoutDuration(a.Lockouts))
+				a.Lockouts++
+				a.Failures = 0
+				a.LockedUntil = &until
+				a.NextAttempt = until
+				p.locked = append(p.locked, a.Kind)
+			} else if a.Failures > limits[i].FreeAttempts {
+				a.NextAttempt = now.Add(t.delay(a.Failures - limits[i].FreeAttempts - 1))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		var throttled ErrLoginThrottled
+		if errors.As(err, &throttled) {
+			if throttled.Locked {
+				t.failure(LoginFailureLocked)
+			} else {
+				t.failure(LoginFailureThrottled)
+			}
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// Returns the delay after the failure, where 0 is the first failure beyond the free attempts
+func (t *LoginThrottle) delay(n int) time.Duration {
+	d := t.options.BaseDelay
+	for i := 0; i < n && d < t.options.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > t.options.MaxDelay {
+		return t.options.MaxDelay
+	}
+	return d
+}
+
+// Returns the duration of the lockout, when there have been n lockouts before it
+func (t *LoginThrottle) lockoutDuration(n int) time.Duration {
+	d := t.options.LockoutDuration
+	for i := 0; i < n && d < t.options.MaxLockoutDuration; i++ {
+		d *= 2
+	}
+	if d > t.options.MaxLockoutDuration {
+		return t.options.MaxLockoutDuration
+	}
+	return d
+}
+
+func (t *LoginThrottle) failure(reason string) {
+	if t.OnFailure != nil {
+		t.OnFailure(reason)
+	}
+}
+
+// Fail reports the login as failed. The attempt is already counted, so this only reports it.
+func (p *PendingLogin) Fail(reason string) {
+	if p.done {
+		return
+	}
+	p.done = true
+	p.throttle.failure(reason)
+	if p.throttle.OnLockout != nil {
+		for _, kind := range p.locked {
+			p.throttle.OnLockout(kind)
+		}
+	}
+}
+
+// Succeed undoes the attempt. The failures for the username are forgotten,
+// while the ip only has this attempt undone, since other users may have failed from the same ip.
+func (p *PendingLogin) Succeed() error {
+	if p.done || len(p.keys) == 0 {
+		return nil
+	}
+	p.done = true
+	t := p.throttle
+	return t.store.UpdateLoginAttempts(p.keys, func(attempts []*types.LoginAttempts) error {
+		*attempts[0] = types.LoginAttempts{Key: attempts[0].Key}
+		ip := attempts[1]
+		if ip.LockedUntil != nil && containsKind(p.locked, types.LoginAttemptsIP) {
+			// This attempt locked the ip
+			if ip.Lockouts > 0 {
+				ip.Lockouts--
+			}
+			ip.LockedUntil = nil
+			ip.Failures = t.options.IP.LockoutThreshold - 1
+		} else if ip.Failures > 0 {
+			ip.Failures--
+		}
+		ip.NextAttempt = time.Time{}
+		return nil
+	})
+}
+
+func containsKind(kinds []types.LoginAttemptsKind, kind types.LoginAttemptsKind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Find returns the login-attempts that have failures, or are locked
+func (t *LoginThrottle) Find() ([]types.LoginAttempts, error) {
+	return t.store.FindLoginAttempts()
+}
+
+// Unlock forgets the failed logins for the key, if the check allows it.
+// It returns ErrNotFound if there are no login-attempts for the key.
+func (t *LoginThrottle) Unlock(key string, check func(a types.LoginAttempts) error) error {
+	return t.store.UpdateLoginAttempts([]string{key}, func(attempts []*types.LoginAttempts) error {
+		if attempts[0].Empty() {
+			return ErrNotFound
+		}
+		if check != nil {
+			if err := check(*attempts[0]); err != nil {
+				return err
+			}
+		}
+		*attempts[0] = types.LoginAttempts{Key: key}
+		return nil
+	})
+}
+
+// Sweep removes the login-attempts that are no longer locked, and have been forgotten
+func (t *LoginThrottle) Sweep() (int, error) {
+	now := t.now()
+	return t.store.EvictLoginAttempts(func(a types.LoginAttempts) bool {
+		return !a.Locked(now) && now.Sub(a.LastFailure) > t.options.ResetAfter
+	})
+}
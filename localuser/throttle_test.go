@@ -0,0 +1,128 @@
+package localuser
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestLoginThrottle(t *testing.T) {
+	bb := bboltStorage.NewMockDB(t)
+	store := bb.Storage.(LoginThrottleStore)
+	options := LoginThrottleOptions{
+		User:            LoginThrottleLimits{FreeAttempts: 2, LockoutThreshold: 5},
+		IP:              LoginThrottleLimits{FreeAttempts: 20, LockoutThreshold: 100},
+		BaseDelay:       time.Second,
+		LockoutDuration: time.Minute,
+	}
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	throttle := NewLoginThrottle(options, store)
+	throttle.now = func() time.Time { return now }
+	var failures []string
+	var lockouts []types.LoginAttemptsKind
+	throttle.OnFailure = func(reason string) { failures = append(failures, reason) }
+	throttle.OnLockout = func(kind types.LoginAttemptsKind) { lockouts = append(lockouts, kind) }
+	user := &types.User{Entity: types.Entity{ID: "jane-id"}, UserName: "jane"}
+	user.OrganizationID = "org"
+
+	fail := func(username string) error {
+		attempt, err := throttle.Attempt(username, "10.0.0.1", user)
+		if err != nil {
+			return err
+		}
+		attempt.Fail(LoginFailureIncorrectPassword)
+		return nil
+	}
+	testza.AssertNoError(t, fail("jane"))
+	testza.AssertNoError(t, fail("Jane"), "Usernames are case-insensitive")
+	testza.AssertNoError(t, fail("jane"), "The first attempts are free")
+	err := fail("jane")
+	var throttled ErrLoginThrottled
+	testza.AssertTrue(t, errors.As(err, &throttled), "Attempts beyond the free attempts are delayed")
+	testza.AssertEqual(t, types.LoginAttemptsUser, throttled.Kind)
+	testza.AssertFalse(t, throttled.Locked)
+	testza.AssertEqual(t, time.Second, throttled.RetryAfter(now))
+
+	now = now.Add(time.Second)
+	testza.AssertNoError(t, fail("jane"))
+	now = now.Add(time.Second)
+	testza.AssertNotNil(t, fail("jane"), "The delay doubles for every failure")
+	now = now.Add(time.Second)
+	testza.AssertNoError(t, fail("jane"))
+	testza.AssertEqual(t, []types.LoginAttemptsKind{types.LoginAttemptsUser}, lockouts)
+
+	now = now.Add(30 * time.Second)
+	err = fail("jane")
+	testza.AssertTrue(t, errors.As(err, &throttled))
+	testza.AssertTrue(t, throttled.Locked)
+	testza.AssertEqual(t, 30*time.Second, throttled.RetryAfter(now))
+	testza.AssertNoError(t, fail("joe"), "Other usernames from the same ip are not affected")
+
+	attempts, err := throttle.Find()
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, attempts, 3)
+	for _, a := range attempts {
+		if a.Kind == types.LoginAttemptsUser && a.Value == "jane" {
+			testza.AssertEqual(t, "org", a.OrganizationID)
+			testza.AssertTrue(t, a.Locked(now))
+		}
+	}
+
+	// A successful login for another user only undoes its own attempt for the ip
+	attempt, err := throttle.Attempt("joe", "10.0.0.1", nil)
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, attempt.Succeed())
+	attempts, _ = throttle.Find()
+	testza.AssertLen(t, attempts, 2, "The failures for the username should be forgotten")
+	for _, a := range attempts {
+		if a.Kind == types.LoginAttemptsIP {
+			testza.AssertEqual(t, 6, a.Failures, "Refused attempts are not counted")
+		}
+	}
+
+	testza.AssertErrorIs(t, throttle.Unlock("user:nobody", nil), ErrNotFound)
+	testza.AssertNoError(t, throttle.Unlock(UserAttemptsKey("jane"), nil))
+	testza.AssertNoError(t, fail("jane"), "Unlocked usernames can log in again")
+
+	now = now.Add(25 * time.Hour)
+	count, err := throttle.Sweep()
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, 2, count, "Old login-attempts should be forgotten")
+	testza.AssertContains(t, failures, LoginFailureLocked)
+	testza.AssertContains(t, failures, LoginFailureThrottled)
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.1", "172.16.0.0/12", "::1"})
+	testza.AssertNoError(t, err)
+	throttle := NewLoginThrottle(LoginThrottleOptions{TrustedProxies: proxies}, nil)
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     http.Header
+		want       string
+	}{
+		{"Remote address", "127.0.0.1:54321", nil, "127.0.0.1"},
+		{"Headers from clients are ignored", "203.0.113.7:54321", http.Header{"X-Forwarded-For": {"192.0.2.1"}, "Forwarded": {"for=192.0.2.1"}}, "203.0.113.7"},
+		{"Trusted proxy", "10.0.0.1:80", http.Header{"X-Forwarded-For": {"203.0.113.7"}}, "203.0.113.7"},
+		{"Spoofed entries before the proxy are ignored", "10.0.0.1:80", http.Header{"X-Forwarded-For": {"192.0.2.1, 203.0.113.7"}}, "203.0.113.7"},
+		{"Chain of trusted proxies", "10.0.0.1:80", http.Header{"X-Forwarded-For": {"192.0.2.1, 203.0.113.7", "172.16.4.2"}}, "203.0.113.7"},
+		{"Forwarded is preferred", "[::1]:80", http.Header{"X-Forwarded-For": {"192.0.2.1"}, "Forwarded": {`for=192.0.2.60;proto=http, for="[2001:db8::1]:4711";proto=https`}}, "2001:db8::1"},
+		{"Without headers", "[::1]:54321", nil, "::1"},
+		{"Only trusted proxies", "10.0.0.1:80", http.Header{"X-Forwarded-For": {"172.16.4.2"}}, "172.16.4.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &http.Request{RemoteAddr: tt.remoteAddr, Header: tt.header}
+			testza.AssertEqual(t, tt.want, throttle.ClientIP(r))
+		})
+	}
+
+	_, err = ParseTrustedProxies([]string{"not-an-ip"})
+	testza.AssertNotNil(t, err)
+}
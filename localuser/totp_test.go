@@ -0,0 +1,53 @@
+package localuser
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestTOTP(t *testing.T) {
+	// The test-vectors of RFC 6238, truncated to six digits
+	secret := b32.EncodeToString([]byte("12345678901234567890"))
+	for unix, expected := range map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	} {
+		code, err := TOTPCode(secret, TOTPStep(time.Unix(unix, 0)))
+		testza.AssertNoError(t, err)
+		testza.AssertEqual(t, expected, code, unix)
+	}
+
+	now := time.Unix(1111111109, 0)
+	step, ok := ValidateTOTP(secret, "081804", now)
+	testza.AssertTrue(t, ok)
+	testza.AssertEqual(t, TOTPStep(now), step)
+	_, ok = ValidateTOTP(secret, "081804", now.Add(TOTPPeriod*time.Second))
+	testza.AssertTrue(t, ok, "Codes from the previous step should be accepted, to allow for clock-drift")
+	_, ok = ValidateTOTP(secret, "081804", now.Add(3*TOTPPeriod*time.Second))
+	testza.AssertFalse(t, ok)
+	_, ok = ValidateTOTP(secret, "000000", now)
+	testza.AssertFalse(t, ok)
+	_, ok = ValidateTOTP(secret, "", now)
+	testza.AssertFalse(t, ok)
+
+	uri := TOTPURI("Skiver", "jane doe", secret)
+	testza.AssertTrue(t, strings.HasPrefix(uri, "otpauth://totp/Skiver:jane%20doe?"), uri)
+	testza.AssertContains(t, uri, "secret="+secret)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes(10)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, codes, 10)
+	testza.AssertLen(t, hashes, 10)
+	testza.AssertEqual(t, hashes[0], HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(codes[0], "-", " "))),
+		"Case, spaces and dashes should not matter")
+	testza.AssertNotEqual(t, hashes[0], hashes[1])
+}
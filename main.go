@@ -15,7 +15,6 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"expvar"
-	"flag"
 	"fmt"
 	"log"
 	"math/rand"
@@ -45,15 +44,19 @@ import (
 	"github.com/runar-rkmedia/skiver/bboltStorage"
 	cfg "github.com/runar-rkmedia/skiver/config"
 	"github.com/runar-rkmedia/skiver/frontend"
+	"github.com/runar-rkmedia/skiver/glossary"
 	"github.com/runar-rkmedia/skiver/handlers"
 	"github.com/runar-rkmedia/skiver/importexport"
 	"github.com/runar-rkmedia/skiver/localuser"
 	"github.com/runar-rkmedia/skiver/models"
+	"github.com/runar-rkmedia/skiver/oidc"
 	"github.com/runar-rkmedia/skiver/requestContext"
+	"github.com/runar-rkmedia/skiver/translationmemory"
 	"github.com/runar-rkmedia/skiver/translator"
 	"github.com/runar-rkmedia/skiver/types"
 	"github.com/runar-rkmedia/skiver/uploader"
 	"github.com/runar-rkmedia/skiver/utils"
+	"github.com/runar-rkmedia/skiver/webhooks"
 	"github.com/zserge/metric"
 )
 
@@ -184,6 +187,12 @@ func (m *translationHook) Publish(kind, variant string, contents interface{}) {
 		}
 		return
 	}
+	if tv.Source == types.CreatorSourceTranslationAgency {
+		if debug {
+			m.l.Debug().Interface("content", contents).Msg("ignoring TranslationValue since it was sourced from a translation-agency")
+		}
+		return
+	}
 	if strings.TrimSpace(tv.Value) == "" {
 		m.l.Error().Interface("content", contents).Msg("Received TranslationValue, but the value appeared to be empty")
 		return
@@ -254,6 +263,14 @@ func (m *translationHook) Publish(kind, variant string, contents interface{}) {
 	for k, v := range tvs {
 		existingTranslations[v.LocaleID] = k
 	}
+	glossaryFilter := types.GlossaryEntry{}
+	glossaryFilter.OrganizationID = orgId
+	glossaryEntries, err := m.db.FindGlossaryEntries(0, glossaryFilter)
+	if err != nil {
+		m.l.Error().Err(err).Msg("failed to lookup glossary")
+		return
+	}
+	terms := glossary.New(glossaryEntries)
 	sourceLocale := locales[tv.LocaleID]
 	for _, l := range locales {
 		if l.ID == sourceLocale.ID {
@@ -279,11 +296,14 @@ func (m *translationHook) Publish(kind, variant string, contents interface{}) {
 		// TODO: implement for contexts too
 		source := sourceLocale.Iso639_1
 		target := l.Iso639_1
-		result, err := m.translator.Translate(tv.Value, source, target)
+		// Glossary-terms are replaced with placeholders, so that the approved translations are used.
+		text, restore := terms.Protect(tv.Value, l.ID)
+		result, err := m.translator.Translate(text, source, target)
 		if err != nil {
 			m.l.Error().Err(err).Str("source", source).Str("target", target).Msg("failed during translation")
 			continue
 		}
+		result = restore(result)
 		if result == "" {
 			m.l.Warn().Str("result", result).Msg("The translation returned a empty result")
 			continue
@@ -293,6 +313,7 @@ func (m *translationHook) Publish(kind, variant string, contents interface{}) {
 			LocaleID:      l.ID,
 			TranslationID: tv.TranslationID,
 			Source:        types.CreatorSourceTranslator,
+			State:         types.StateNeedsReview,
 		}
 		tv.CreatedBy = string(tv.Source)
 		tv.OrganizationID = orgId
@@ -333,17 +354,24 @@ func gethostNameHash() string {
 }
 
 func main() {
+	if err := runCli(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// Reads the configuration, and sets the defaults
+func loadConfig() (*cfg.Config, error) {
 	err := cfg.InitConfig()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	config := cfg.GetConfig()
-	apiConfig := config.Api
-	if apiConfig.Address == "" {
-		apiConfig.Address = "0.0.0.0"
+	if config.Api.Address == "" {
+		config.Api.Address = "0.0.0.0"
 	}
-	if apiConfig.Port == 0 {
-		apiConfig.Port = 80
+	if config.Api.Port == 0 {
+		config.Api.Port = 80
 	}
 	if config.LogFormat == "" {
 		config.LogFormat = "json"
@@ -351,9 +379,125 @@ func main() {
 	if config.LogLevel == "" {
 		config.LogLevel = "info"
 	}
-	if apiConfig.DBLocation == "" {
-		apiConfig.DBLocation = getDefaultDBLocation()
+	if config.Api.DBLocation == "" {
+		config.Api.DBLocation = getDefaultDBLocation()
 	}
+	if config.Authentication.SessionLifeTime == 0 {
+		config.Authentication.SessionLifeTime = cfg.Duration(time.Hour)
+	}
+	if config.Revisions.MaxCount == 0 {
+		config.Revisions.MaxCount = 100
+	}
+	if config.Webhooks.MaxDeliveries == 0 {
+		config.Webhooks.MaxDeliveries = 100
+	}
+	return config, nil
+}
+
+func openDatabase(l logger.AppLogger, config *cfg.Config, events bboltStorage.PubSubPublisher) (bboltStorage.BBolter, error) {
+	return bboltStorage.NewBbolt(l, config.Api.DBLocation, events, bboltStorage.BBoltOptions{
+		RevisionRetention: types.RevisionRetention{
+			MaxCount: config.Revisions.MaxCount,
+			MaxAge:   config.Revisions.MaxAge.Duration(),
+		},
+		WebhookDeliveryRetention: types.WebhookDeliveryRetention{
+			MaxCount: config.Webhooks.MaxDeliveries,
+			MaxAge:   config.Webhooks.MaxDeliveryAge.Duration(),
+		},
+	})
+}
+
+// Runs the migrations that the database needs, if any
+func migrateDatabase(db *bboltStorage.BBolter) (types.State, error) {
+	return db.Migrate(
+		func(state types.State, wantedMigrationPoint int) error {
+			if state.MigrationPoint == 0 {
+
+				orgs, err := db.GetOrganizations()
+				if err != nil {
+					return err
+				}
+				for _, org := range orgs {
+
+					o, err := importexport.CreateInterpolationMapForOrganization(db, org.ID)
+					if err != nil {
+						return err
+					}
+					projects, err := db.GetProjects()
+					if err != nil {
+						return err
+					}
+					for _, p := range projects {
+						ep, err := p.Extend(db)
+						if err != nil {
+							return err
+						}
+						for _, ec := range ep.Categories {
+							for _, et := range ec.Translations {
+								handlers.UpdateTranslationFromInferrence(db, et, []handlers.AdditionalValue{}, o.ByProject(p.ID))
+							}
+						}
+					}
+				}
+
+			}
+			return nil
+		},
+	)
+}
+
+func newUserSessions(config *cfg.Config, db *bboltStorage.BBolter) (localuser.Sessions, error) {
+	sessionOptions := types.UserSessionOptions{TTL: config.Authentication.SessionLifeTime.Duration()}
+	switch config.Authentication.SessionStore {
+	case "memory":
+		return localuser.NewUserSessionInMemory(sessionOptions, uuid.NewString, nil)
+	case "", "bbolt":
+		secret := []byte(config.Authentication.SessionSecret)
+		if len(secret) == 0 {
+			var err error
+			secret, err = db.GetSessionSecret()
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get the session-secret: %w", err)
+			}
+		}
+		return localuser.NewSignedSessions(sessionOptions, secret, uuid.NewString, db)
+	}
+	return nil, fmt.Errorf("Unknown session-store %s. Must be one of bbolt or memory", config.Authentication.SessionStore)
+}
+
+func newUploaders(l logger.AppLogger, config *cfg.Config) ([]uploader.FileUploader, error) {
+	var uploaders []uploader.FileUploader
+	for _, key := range utils.SortedMapKeys(config.UploadSnapShots) {
+		cu := config.UploadSnapShots[key]
+		if cu.S3 == nil {
+			return uploaders, fmt.Errorf("Config for UploadSnapShots.%s was invalid (empty)", key)
+		}
+		u := uploader.NewS3Uplaoder(
+			logger.GetLogger("snapshot-uploader-"+key),
+			key,
+			uploader.S3UploaderOptions{
+				UrlFormat:      cu.S3.UrlFormat,
+				CacheControl:   cu.S3.CacheControl,
+				Endpoint:       cu.S3.Endpoint,
+				Region:         cu.S3.Region,
+				Bucket:         cu.S3.BucketID,
+				ProviderName:   cu.S3.ProviderName,
+				AccessKey:      cu.S3.AccessKey,
+				ForcePathStyle: cu.S3.ForcePathStyle,
+			},
+			cu.S3.PrivateKey,
+		)
+		uploaders = append(uploaders, u)
+	}
+	if l.HasDebug() && len(uploaders) > 0 {
+		l.Debug().Interface("UploadSnapShots", utils.SortedMapKeys(config.UploadSnapShots)).Int("count", len(uploaders)).Msg("Uploaders initialized successfully")
+	}
+	return uploaders, nil
+}
+
+// Runs the api-server, until it receives a signal to shut down
+func serve(config *cfg.Config) {
+	apiConfig := config.Api
 	logger.InitLogger(logger.LogConfig{
 		Level:  config.LogLevel,
 		Format: config.LogFormat,
@@ -362,9 +506,6 @@ func main() {
 	})
 	l := logger.GetLogger("main")
 
-	if config.Authentication.SessionLifeTime == 0 {
-		config.Authentication.SessionLifeTime = cfg.Duration(time.Hour)
-	}
 	if config.Authentication.SessionLifeTime < cfg.Duration(time.Minute) {
 		l.Fatal().Str("Authentication.SessionLifeTime", config.Authentication.SessionLifeTime.String()).Msg("SessionLifeTime cannot be shorter than a minute. That would just be really annoying.")
 	}
@@ -394,45 +535,11 @@ func main() {
 		}
 	}
 	// IMPORTANT: database publishes changes, but for performance-reasons, it should not be used until the listener (ws) is started.
-	db, err := bboltStorage.NewBbolt(l, apiConfig.DBLocation, &events)
+	db, err := openDatabase(l, config, &events)
 	if err != nil {
 		l.Fatal().Err(err).Msg("Failed to initialize storage")
 	}
-	_, err = db.Migrate(
-		func(state types.State, wantedMigrationPoint int) error {
-			if state.MigrationPoint == 0 {
-
-				orgs, err := db.GetOrganizations()
-				if err != nil {
-					return err
-				}
-				for _, org := range orgs {
-
-					o, err := importexport.CreateInterpolationMapForOrganization(&db, org.ID)
-					if err != nil {
-						return err
-					}
-					projects, err := db.GetProjects()
-					if err != nil {
-						return err
-					}
-					for _, p := range projects {
-						ep, err := p.Extend(&db)
-						if err != nil {
-							return err
-						}
-						for _, ec := range ep.Categories {
-							for _, et := range ec.Translations {
-								handlers.UpdateTranslationFromInferrence(&db, et, []handlers.AdditionalValue{}, o.ByProject(p.ID))
-							}
-						}
-					}
-				}
-
-			}
-			return nil
-		},
-	)
+	_, err = migrateDatabase(&db)
 	if err != nil {
 		l.Fatal().Err(err).Msg("Failed during db-migration")
 	}
@@ -472,6 +579,13 @@ func main() {
 	}
 	pubsub := handlers.NewPubSubChannel()
 	events.AddSubscriber("msg", &pubsub)
+	tm := translationmemory.NewMemory(&db)
+	events.AddSubscriber("translation-memory", tm)
+	webhookDispatcher := webhooks.NewDispatcher(logger.GetLogger("webhooks"), &db, webhooks.Options{
+		AllowPrivateNetworks: config.Webhooks.AllowPrivateNetworks,
+	})
+	events.AddSubscriber("webhooks", webhookDispatcher)
+	webhookDispatcher.Start()
 
 	pw := localuser.NewPwHasher([]byte(pwsalt))
 
@@ -527,34 +641,9 @@ func main() {
 		handler.Handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
 		handler.Handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
 	}
-	var uploaders []uploader.FileUploader
-	if len(config.UploadSnapShots) > 0 {
-		for key, cu := range config.UploadSnapShots {
-			if cu.S3 != nil {
-				u := uploader.NewS3Uplaoder(
-					logger.GetLogger("snapshot-uploader-"+key),
-					key,
-					uploader.S3UploaderOptions{
-						UrlFormat:      cu.S3.UrlFormat,
-						CacheControl:   cu.S3.CacheControl,
-						Endpoint:       cu.S3.Endpoint,
-						Region:         cu.S3.Region,
-						Bucket:         cu.S3.BucketID,
-						ProviderName:   cu.S3.ProviderName,
-						AccessKey:      cu.S3.AccessKey,
-						ForcePathStyle: cu.S3.ForcePathStyle,
-					},
-					cu.S3.PrivateKey,
-				)
-				uploaders = append(uploaders, u)
-				continue
-			}
-			l.Fatal().Str("key", key).Msg("Config for UploadSnapShots was invalid (empty)")
-		}
-		if l.HasDebug() {
-			keys := utils.SortedMapKeys(config.UploadSnapShots)
-			l.Debug().Interface("UploadSnapShots", keys).Int("count", len(keys)).Msg("Uploaders initialized successfully")
-		}
+	uploaders, err := newUploaders(l, config)
+	if err != nil {
+		l.Fatal().Err(err).Msg("Failed to set up uploaders")
 	}
 
 	if config.Metrics.Enabled {
@@ -596,8 +685,6 @@ func main() {
 		}
 	}
 
-	// TODO: consider using a buffered channel.
-	handler.Handle("/ws/", handlers.NewWsHandler(logger.GetLoggerWithLevel("ws", "debug"), pubsub.Ch, handlers.WsOptions{}))
 	exportCache := cache.New(time.Hour, time.Hour)
 	events.AddSubscriberFunc("exportCache", func(kind, variant string, contents interface{}) {
 		//  TODO: delete only thoose belonging to a project, etc.
@@ -616,6 +703,11 @@ func main() {
 				l.Fatal().Err(err).Msg("Failed to seed Locale")
 			}
 		}
+		if err := tm.Build(); err != nil {
+			l.Error().Err(err).Msg("Failed to build translation-memory")
+		} else {
+			l.Debug().Int("size", tm.Size()).Msg("Translation-memory built")
+		}
 	}()
 	handler.Handle("/api/ping", handlers.PingHandler(handler))
 
@@ -703,87 +795,67 @@ func main() {
 			}
 		}
 	}()
-	p, ok := ctx.DB.(localuser.Persistor)
-	if !ok {
-		ctx.L.Fatal().Str("type", fmt.Sprintf("%T", ctx.DB)).Msg("DB does not implement the localUser.Persistor-interface")
-	}
-	userSessions, err := localuser.NewUserSessionInMemory(types.UserSessionOptions{TTL: config.Authentication.SessionLifeTime.Duration()}, uuid.NewString, p)
+	userSessions, err := newUserSessions(config, &db)
 	if err != nil {
 		ctx.L.Fatal().Err(err).Msg("Failed to set up userSessions")
 	}
-	// Temporary implementation for changing a users password
-	{
-		var username string
-		var password string
-		flag.StringVar(&password, "password", "", "Reset password to this value")
-		flag.StringVar(&username, "user", "", "Reset password to this user")
-		flag.Parse()
-		// Temporary implementation for getting a config-sample
-		if flag.Arg(0) == "config-sample" {
-			sample := cfg.CreateSampleComfig()
-			err := cfg.WriteToml(os.Stdout, sample)
-			if err != nil {
-				l.Fatal().Err(err).Msg("failed to output sample-config")
-			}
-			os.Exit(0)
+	tc := config.Authentication.LoginThrottle
+	trustedProxies, err := localuser.ParseTrustedProxies(tc.TrustedProxies)
+	if err != nil {
+		ctx.L.Fatal().Err(err).Msg("Invalid Authentication.LoginThrottle.TrustedProxies")
+	}
+	loginThrottle := localuser.NewLoginThrottle(localuser.LoginThrottleOptions{
+		Disabled:        tc.Disabled,
+		User:            localuser.LoginThrottleLimits{FreeAttempts: tc.FreeAttempts, LockoutThreshold: tc.LockoutThreshold},
+		IP:              localuser.LoginThrottleLimits{FreeAttempts: tc.IPFreeAttempts, LockoutThreshold: tc.IPLockoutThreshold},
+		BaseDelay:       tc.BaseDelay.Duration(),
+		MaxDelay:        tc.MaxDelay.Duration(),
+		LockoutDuration: tc.LockoutDuration.Duration(),
+		ResetAfter:      tc.ResetAfter.Duration(),
+		TrustedProxies:  trustedProxies,
+	}, &db)
+	if config.Metrics.Enabled {
+		metricsLoginFailures := promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "login_failures_total",
+			Help: "Failed and refused logins, by reason",
+		}, []string{"reason"})
+		metricsLoginLockouts := promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "login_lockouts_total",
+			Help: "Lockouts of usernames and ips, because of too many failed logins",
+		}, []string{"kind"})
+		loginThrottle.OnFailure = func(reason string) {
+			metricsLoginFailures.WithLabelValues(reason).Inc()
 		}
-		if password != "" || username != "" {
-			if password == "" {
-				l.Fatal().Msg("Password must also be set")
-			}
-			if username == "" {
-				l.Fatal().Msg("User must also be set")
-			}
-			l.Warn().Str("username", username).Msg("Request from cli to change password for user")
-			u := types.User{}
-			u.UserName = username
-			adminUser, err := db.FindOneUser(types.User{UserName: "admin"})
-			if err != nil {
-				l.Fatal().Err(err).Msg("Failed to find admin-user")
-			}
-			if adminUser == nil {
-				l.Fatal().Err(err).Msg("Admin-user not found")
-			}
-			if err != nil {
-				l.Fatal().Err(err).Msg("Failed to find users")
-			}
-			users, err := db.FindUsers(-1, u)
+		loginThrottle.OnLockout = func(kind types.LoginAttemptsKind) {
+			metricsLoginLockouts.WithLabelValues(string(kind)).Inc()
+		}
+	}
+	go func() {
+		interval := config.Authentication.SessionSweepInterval.Duration()
+		if interval <= 0 {
+			interval = 10 * time.Minute
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			count, err := userSessions.Sweep()
 			if err != nil {
-				l.Fatal().Err(err).Msg("Failed to find users")
-			}
-			if len(users) == 0 {
-				l.Fatal().Msg("Did not find any users")
-			}
-			if len(users) > 1 {
-				l.Fatal().Interface("users", users).Msg("Found multiple users")
-			}
-			var user types.User
-			for _, v := range users {
-				user = v
+				l.Error().Err(err).Msg("Failed to remove expired sessions")
+				continue
 			}
-			l.Warn().Interface("user", user).Msg("Changing password for user")
-			hashed, err := pw.Hash(password)
-			if err != nil {
-				l.Fatal().Err(err).Msg("failed to hash password")
+			if count > 0 {
+				l.Debug().Int("count", count).Msg("Removed expired sessions")
 			}
-			payload := types.UpdateUserPayload{PW: &hashed}
-			payload.UpdatedBy = adminUser.ID
-			t := true
-			payload.TemporaryPassword = &t
-			updated, err := db.UpdateUser(user.ID, payload)
+			count, err = loginThrottle.Sweep()
 			if err != nil {
-				l.Fatal().Err(err).Msg("Failed to update user")
+				l.Error().Err(err).Msg("Failed to remove old login-attempts")
+				continue
 			}
-			l.Info().Interface("user", updated).Msg("User was updated")
-			err = userSessions.ClearAllSessionsForUser(user.ID)
-			if err != nil {
-				l.Fatal().Err(err).Msg("Failed to clear sessions for user")
+			if count > 0 {
+				l.Debug().Int("count", count).Msg("Removed old login-attempts")
 			}
-			l.Info().Msg("All seassion for user was cleared")
-			os.Exit(0)
 		}
-	}
-
+	}()
 	router := httprouter.New()
 	router.HandleMethodNotAllowed = true
 	router.HandleOPTIONS = true
@@ -798,10 +870,20 @@ func main() {
 
 	// 	rc.WriteError("Internal error. I am terribly sorry, but I must have overlooked something.", "Internal panic")
 	// }
-	auth := handlers.NewAuthHandler(userSessions)
+	// Api-keys are resolved into sessions, with permissions restricted to the scopes of the key
+	sessions := handlers.NewAPIKeySessions(userSessions, &db)
+	auth := handlers.NewAuthHandler(sessions)
+	// TODO: consider using a buffered channel.
+	handler.Handle("/ws/", handlers.NewWsHandler(logger.GetLoggerWithLevel("ws", "debug"), pubsub.Ch, sessions, handlers.WsOptions{DB: &db}))
 
 	type routeOptions struct {
-		sessionRole func(s types.Session, r *http.Request) error
+		// If set, the user must be able to perform the action within at least one project.
+		// The handlers narrow this down to the project and locale, once they are known.
+		action        types.Action
+		requiresLogin bool
+		// Api-keys can only be used on routes with a scope, and only if the key has that scope.
+		// If the route has a project-parameter, the key must also be allowed to use that project.
+		apiKeyScope types.APIKeyScope
 	}
 
 	metricsHttpSeconds := promauto.NewHistogramVec(prometheus.HistogramOpts{
@@ -901,16 +983,24 @@ func main() {
 			}
 			r = _r
 
-			if options.sessionRole != nil {
+			if s, sErr := handlers.GetRequestSession(r); sErr == nil && s.APIKey != nil {
+				if err = handlers.AuthorizeAPIKey(&db, s, options.apiKeyScope, p.ByName("project")); err != nil {
+					rc.WriteErr(err, "")
+					return
+				}
+			}
+
+			if options.action != "" || options.requiresLogin {
 				s, err := handlers.GetRequestSession(r)
 				if err != nil {
 					rc.WriteError("Authentication required", requestContext.CodeErrAuthenticationRequired)
 					return
 				}
-				err = options.sessionRole(s, r)
-				if err != nil {
-					rc.WriteErr(err, requestContext.CodeErrAuthoriziation)
-					return
+				if options.action != "" {
+					if err := handlers.Authorize(&db, s, options.action, types.AccessScope{}); err != nil {
+						rc.WriteErr(err, "")
+						return
+					}
 				}
 			}
 
@@ -944,72 +1034,119 @@ func main() {
 	router.GET("/api/project/", pipeline("GetProjects", handlers.GetProjects(&db)))
 	router.POST("/api/project/", pipeline("CreateProject", handlers.CreateProject(&db)))
 	router.PUT("/api/project/", pipeline("UpdateProject", handlers.UpdateProject(&db)))
+	router.GET("/api/project/:id/lint", pipeline("GetLint", handlers.GetLint()))
 	router.GET("/api/join/:join-id", pipeline("GetOrgForJoinID", handlers.GetOrgForJoinID(&db)))
 	router.POST("/api/join/:join-id", pipeline("JoinOrgFromJoinID", handlers.JoinOrgFromJoinID(&db, &pw)))
 
+	canExport := routeOptions{apiKeyScope: types.APIKeyScopeExport}
 	// Replaced route
-	router.GET("/api/export/:org/:project/:params", pipeline("GetExport", handlers.GetExport(exportCache)))
+	router.GET("/api/export/:org/:project/:params", pipeline("GetExport", handlers.GetExport(exportCache), canExport))
 	// Replaced route
-	router.GET("/api/export/:org/:project", pipeline("GetExportx", handlers.GetExport(exportCache)))
+	router.GET("/api/export/:org/:project", pipeline("GetExportx", handlers.GetExport(exportCache), canExport))
 
 	// Deprecated
-	router.GET("/api/export/:org", pipeline("DeprecatedGetExport", handlers.GetExport(exportCache)))
+	router.GET("/api/export/:org", pipeline("DeprecatedGetExport", handlers.GetExport(exportCache), canExport))
 	// Deprecated
-	router.GET("/api/export/", pipeline("DeprecatedGetExportx", handlers.GetExport(exportCache)))
+	router.GET("/api/export/", pipeline("DeprecatedGetExportx", handlers.GetExport(exportCache), canExport))
 
 	router.GET("/api/user/", pipeline("GetSimpleUsers", handlers.ListUsers(&db, true)))
 	router.GET("/api/missing/", pipeline("GetMissing", handlers.GetMissing(&db)))
-	router.POST("/api/missing/:locale/:project", pipeline("ReportMissing", handlers.PostMissing(&db)))
+	router.POST("/api/missing/:locale/:project", pipeline("ReportMissing", handlers.PostMissing(&db), routeOptions{apiKeyScope: types.APIKeyScopeReportMissing}))
+	router.POST("/api/interpolate/:org/:project/:locale/:key", pipeline("Interpolate", handlers.PostInterpolate()))
 	router.GET("/api/category/", pipeline("GetCategory", handlers.GetCategory(&db)))
-	router.POST("/api/category/", pipeline("PostCategory", handlers.PostCategory(&db), routeOptions{
-		sessionRole: func(s types.Session, r *http.Request) error {
-			if !s.User.CanCreateTranslations {
-				return fmt.Errorf("You are not authorized to manage translations")
-			}
-			return nil
-		},
-	}))
-	router.PUT("/api/category/", pipeline("UpdateCategory", handlers.UpdateCategory(&db), routeOptions{
-		sessionRole: func(s types.Session, r *http.Request) error {
-			if !s.User.CanCreateTranslations {
-				return fmt.Errorf("You are not authorized to manage translations")
-			}
-			return nil
-		},
-	}))
-	router.GET("/api/users/", pipeline("GetUsers", handlers.ListUsers(&db, false), routeOptions{
-		sessionRole: func(s types.Session, r *http.Request) error {
-			if !s.User.CanUpdateUsers {
-				return fmt.Errorf("You are not authorized to manage users")
-			}
-			return nil
-		},
-	}))
+	router.POST("/api/category/", pipeline("PostCategory", handlers.PostCategory(&db), routeOptions{action: types.ActionCreateTranslations}))
+	router.PUT("/api/category/", pipeline("UpdateCategory", handlers.UpdateCategory(&db), routeOptions{action: types.ActionCreateTranslations}))
+	router.GET("/api/users/", pipeline("GetUsers", handlers.ListUsers(&db, false), routeOptions{action: types.ActionUpdateUsers}))
+	router.PUT("/api/users/:id/roles", pipeline("SetUserRoles", handlers.SetUserRoles(&db, userSessions), routeOptions{action: types.ActionUpdateUsers}))
+	router.POST("/api/users/", pipeline("InviteUser", handlers.InviteUser(&db), routeOptions{action: types.ActionUpdateUsers}))
+	router.PUT("/api/users/:id", pipeline("UpdateUser", handlers.UpdateUser(&db, userSessions), routeOptions{action: types.ActionUpdateUsers}))
+	router.DELETE("/api/users/:id", pipeline("RemoveUser", handlers.RemoveUser(&db, userSessions), routeOptions{action: types.ActionUpdateUsers}))
+	router.POST("/api/users/:id/invite", pipeline("RenewInvite", handlers.RenewInvite(&db), routeOptions{action: types.ActionUpdateUsers}))
+	router.POST("/api/users/:id/password-reset", pipeline("ResetUserPassword", handlers.ResetUserPassword(&db, &pw, userSessions), routeOptions{action: types.ActionUpdateUsers}))
+	router.GET("/api/invite/:token", pipeline("GetInvite", handlers.GetInvite(&db)))
+	router.POST("/api/invite/:token", pipeline("AcceptInvite", handlers.AcceptInvite(&db, &pw)))
+	router.GET("/api/lockouts", pipeline("GetLockouts", handlers.GetLockouts(loginThrottle), routeOptions{action: types.ActionUpdateUsers}))
+	router.DELETE("/api/lockouts/:key", pipeline("DeleteLockout", handlers.DeleteLockout(loginThrottle), routeOptions{action: types.ActionUpdateUsers}))
 	router.POST("/api/user/password", pipeline("ChangePassword", handlers.ChangePassword(&db, &pw, userSessions)))
 	router.POST("/api/user/token", pipeline("CreateToken", handlers.CreateToken(userSessions)))
+	requiresLogin := routeOptions{requiresLogin: true}
+	router.GET("/api/user/sessions", pipeline("GetSessions", handlers.GetSessions(userSessions), requiresLogin))
+	router.DELETE("/api/user/sessions", pipeline("RevokeOtherSessions", handlers.RevokeOtherSessions(userSessions), requiresLogin))
+	router.DELETE("/api/user/sessions/:id", pipeline("RevokeSession", handlers.RevokeSession(userSessions), requiresLogin))
+	mfa := handlers.NewMFA(&db, userSessions, loginThrottle)
+	router.POST("/api/auth/mfa", pipeline("MFALogin", mfa.Login()))
+	router.POST("/api/user/mfa", pipeline("EnrollMFA", mfa.Enroll(), requiresLogin))
+	router.POST("/api/user/mfa/verify", pipeline("VerifyMFA", mfa.VerifyEnrollment(), requiresLogin))
+	router.POST("/api/user/mfa/disable", pipeline("DisableMFA", mfa.Disable(), requiresLogin))
+	router.POST("/api/user/mfa/recovery-codes", pipeline("RegenerateRecoveryCodes", mfa.RegenerateRecoveryCodes(), requiresLogin))
+	router.GET("/api/apiKey/", pipeline("GetAPIKeys", handlers.GetAPIKeys(), requiresLogin))
+	router.POST("/api/apiKey/", pipeline("CreateAPIKey", handlers.CreateAPIKey(), requiresLogin))
+	router.DELETE("/api/apiKey/:id", pipeline("DeleteAPIKey", handlers.DeleteAPIKey(), requiresLogin))
+	if oc := config.Authentication.OIDC; oc != nil {
+		scopes := oc.Scopes
+		if len(scopes) == 0 {
+			scopes = []string{"profile", "email"}
+		}
+		provider, err := oidc.NewProvider(context.Background(), oidc.Config{
+			Issuer:       oc.Issuer,
+			ClientID:     oc.ClientID,
+			ClientSecret: oc.ClientSecret,
+			RedirectURL:  oc.RedirectURL,
+			Scopes:       scopes,
+		}, nil)
+		if err != nil {
+			l.Fatal().Err(err).Str("issuer", oc.Issuer).Msg("Failed to set up login through OpenID Connect")
+		}
+		groups := map[string][]handlers.OIDCGroupRole{}
+		for group, roles := range oc.Groups {
+			for _, r := range roles {
+				if !types.Role(r.Role).Valid() {
+					l.Fatal().Str("group", group).Str("role", r.Role).Msg("Invalid role in Authentication.OIDC.Groups")
+				}
+				groups[group] = append(groups[group], handlers.OIDCGroupRole{Role: types.Role(r.Role), Project: r.Project, Locales: r.Locales})
+			}
+		}
+		oidcHandler := handlers.NewOIDCHandler(provider, userSessions, userSessions, handlers.OIDCOptions{
+			UserNameClaim:       oc.UserNameClaim,
+			OrganizationClaim:   oc.OrganizationClaim,
+			Organizations:       oc.Organizations,
+			DefaultOrganization: oc.DefaultOrganization,
+			GroupsClaim:         oc.GroupsClaim,
+			Groups:              groups,
+		})
+		router.GET("/api/auth/oidc/login", pipeline("OIDCLogin", oidcHandler.Login()))
+		router.GET("/api/auth/oidc/callback", pipeline("OIDCCallback", oidcHandler.Callback(&db)))
+		l.Info().Str("issuer", oc.Issuer).Msg("Login through OpenID Connect is enabled")
+	}
 	router.POST("/api/project/snapshotdiff/", pipeline("DiffSnapshot", handlers.GetDiff(exportCache)))
 	router.DELETE("/api/translation/:id/", pipeline("DeleteTranslation", handlers.DeleteTranslation(),
-		routeOptions{sessionRole: func(s types.Session, r *http.Request) error {
-			if !s.User.CanUpdateTranslations {
-				return fmt.Errorf("You are not authorized to delete translations")
-			}
-			return nil
-		}}))
+		routeOptions{action: types.ActionUpdateTranslations}))
 	router.PUT("/api/translation/", pipeline("UpdateTranslation", handlers.UpdateTranslation(),
-		routeOptions{sessionRole: func(s types.Session, r *http.Request) error {
-			if !s.User.CanUpdateTranslations {
-				return fmt.Errorf("You are not authorized to update translations")
-			}
-			return nil
-		}}))
+		routeOptions{action: types.ActionUpdateTranslations}))
 	router.POST("/api/translation/", pipeline("CreateTranslation", handlers.CreateTranslation(),
-		routeOptions{sessionRole: func(s types.Session, r *http.Request) error {
-			if !s.User.CanCreateTranslations {
-				return fmt.Errorf("You are not authorized to create translations")
-			}
-			return nil
-		}}))
+		routeOptions{action: types.ActionCreateTranslations}))
 	router.GET("/api/translation/", pipeline("GetTranslation", handlers.GetTranslations()))
+	router.GET("/api/translation/:id/suggestions", pipeline("GetSuggestions", handlers.GetSuggestions(tm)))
+	router.GET("/api/translation/:id/glossary", pipeline("GetTranslationGlossary", handlers.GetTranslationGlossary()))
+	canUpdateGlossary := routeOptions{action: types.ActionUpdateGlossary}
+	router.PUT("/api/translationValue/:id/state", pipeline("SetTranslationValueState", handlers.SetTranslationValueState(), requiresLogin))
+	router.GET("/api/translationValue/:id/history", pipeline("GetTranslationValueHistory", handlers.GetTranslationValueHistory()))
+	router.POST("/api/translationValue/:id/revert/:revision", pipeline("RevertTranslationValue", handlers.RevertTranslationValue(),
+		routeOptions{action: types.ActionEditTranslationValues}))
+	canViewAuditLog := routeOptions{action: types.ActionUpdateOrganization}
+	router.GET("/api/audit", pipeline("GetAuditLog", handlers.GetAuditLog(), canViewAuditLog))
+	router.GET("/api/audit/export", pipeline("ExportAuditLog", handlers.ExportAuditLog(), canViewAuditLog))
+	canManageWebhooks := routeOptions{action: types.ActionUpdateOrganization}
+	router.GET("/api/webhook/", pipeline("GetWebhooks", handlers.GetWebhooks(), canManageWebhooks))
+	router.POST("/api/webhook/", pipeline("CreateWebhook", handlers.CreateWebhook(), canManageWebhooks))
+	router.PUT("/api/webhook/:id", pipeline("UpdateWebhook", handlers.UpdateWebhook(), canManageWebhooks))
+	router.DELETE("/api/webhook/:id", pipeline("DeleteWebhook", handlers.DeleteWebhook(), canManageWebhooks))
+	router.GET("/api/webhook/:id/deliveries", pipeline("GetWebhookDeliveries", handlers.GetWebhookDeliveries(), canManageWebhooks))
+	router.POST("/api/webhook/:id/deliveries/:delivery/replay", pipeline("ReplayWebhookDelivery", handlers.ReplayWebhookDelivery(webhookDispatcher), canManageWebhooks))
+	router.GET("/api/glossary/", pipeline("GetGlossary", handlers.GetGlossary()))
+	router.POST("/api/glossary/", pipeline("CreateGlossaryEntry", handlers.CreateGlossaryEntry(), canUpdateGlossary))
+	router.PUT("/api/glossary/:id", pipeline("UpdateGlossaryEntry", handlers.UpdateGlossaryEntry(), canUpdateGlossary))
+	router.DELETE("/api/glossary/:id", pipeline("DeleteGlossaryEntry", handlers.DeleteGlossaryEntry(), canUpdateGlossary))
 	serverInfoRetriever := func() *types.ServerInfo {
 		info.RLock()
 		defer info.RUnlock()
@@ -1017,15 +1154,10 @@ func main() {
 
 	}
 	router.GET("/api/serverInfo/", pipeline("GetServerInfo", handlers.GetServerInfo(&db, serverInfoRetriever)))
-	router.POST("/api/project/snapshot/", pipeline("PostSnapshot", handlers.PostSnapshot(uploaders), routeOptions{sessionRole: func(s types.Session, _ *http.Request) error {
-		if !s.User.CanUpdateProjects {
-			return fmt.Errorf("You are not authorized to create snapshots")
-		}
-		return nil
-	}}))
+	router.POST("/api/project/snapshot/", pipeline("PostSnapshot", handlers.PostSnapshot(uploaders), routeOptions{action: types.ActionCreateSnapshots}))
 
 	apiHandler := http.StripPrefix("/api/",
-		handlers.EndpointsHandler(ctx, userSessions, pw, []byte(swaggerYml)),
+		handlers.EndpointsHandler(ctx, sessions, pw, mfa, loginThrottle, []byte(swaggerYml)),
 	)
 	if config.Gzip {
 		apiHandler = gziphandler.GzipHandler(apiHandler)
@@ -1039,6 +1171,7 @@ func main() {
 	// 	)
 	)
 	handler.Handle("/api/join/", router)
+	handler.Handle("/api/invite/", router)
 	handler.Handle("/api/project/", router)
 	handler.Handle("/api/organization/", router)
 	handler.Handle("/api/export/", router)
@@ -1047,8 +1180,26 @@ func main() {
 	handler.Handle("/api/user/", router)
 	handler.Handle("/api/wordcloud/", router)
 	handler.Handle("/api/missing/", router)
+	handler.Handle("/api/interpolate/", router)
 	handler.Handle("/api/serverInfo/", router)
 	handler.Handle("/api/category/", router)
+	handler.Handle("/api/glossary/", router)
+	handler.Handle("/api/audit", router)
+	handler.Handle("/api/audit/", router)
+	handler.Handle("/api/lockouts", router)
+	handler.Handle("/api/lockouts/", router)
+	handler.Handle("/api/webhook/", router)
+	handler.Handle("/api/apiKey/", router)
+	handler.Handle("/api/auth/", router)
+	// The translation-values are only partially migrated to the router.
+	// Sub-resources of a translation-value, like /api/translationValue/:id/state, are handled by the router.
+	handler.Handle("/api/translationValue/", http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if strings.Contains(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/translationValue/"), "/"), "/") {
+			router.ServeHTTP(rw, r)
+			return
+		}
+		apiHandler.ServeHTTP(rw, r)
+	}))
 	useCert := false
 	if apiConfig.CertFile != "" {
 		_, err := os.Stat(apiConfig.CertFile)
@@ -1134,6 +1285,12 @@ func main() {
 
 		ctx, cancel := context.WithTimeout(context.Background(), apiConfig.ShutdownTimeout.Duration())
 		defer cancel()
+		// Webhook-deliveries that are still pending are resumed on the next start
+		defer func() {
+			if err := webhookDispatcher.Stop(ctx); err != nil {
+				l.Warn().Err(err).Msg("Timed out waiting for webhook-deliveries to complete")
+			}
+		}()
 
 		if err := srv.Shutdown(ctx); err != nil {
 			srv.Close()
@@ -24,6 +24,10 @@ type CreateSnapshotInput struct {
 	// Min Length: 3
 	Description string `json:"description,omitempty"`
 
+	// If set, the snapshot is created even if the project's release-gate would refuse it.
+	// Requires the user to be able to manage snapshots.
+	Force bool `json:"force,omitempty"`
+
 	// project id
 	// Required: true
 	// Max Length: 36
@@ -40,6 +40,9 @@ type LoginResponse struct {
 	// can manage snapshots
 	CanManageSnapshots bool `json:"can_manage_snapshots,omitempty"`
 
+	// can review translations
+	CanReviewTranslations bool `json:"can_review_translations,omitempty"`
+
 	// can update locales
 	CanUpdateLocales bool `json:"can_update_locales,omitempty"`
 
@@ -35,6 +35,9 @@ type UpdateOrganizationInput struct {
 	// Min Length: 1
 	// Format: date-time
 	JoinIDExpires *strfmt.DateTime `json:"join_id_expires,omitempty"`
+
+	// If set, all local users in the organization must use multi-factor authentication
+	RequireMfa *bool `json:"require_mfa,omitempty"`
 }
 
 // Validate validates this update organization input
@@ -30,9 +30,15 @@ type UpdateProjectInput struct {
 	// Min Length: 3
 	ID *string `json:"id"`
 
+	// Settings for the lint-rules, by the rule-id
+	LintRules map[string]LintRuleSettingInput `json:"lint_rules,omitempty"`
+
 	// locales
 	Locales map[string]LocaleSettingInput `json:"locales,omitempty"`
 
+	// release gate
+	ReleaseGate *ReleaseGateSettingInput `json:"release_gate,omitempty"`
+
 	// short name
 	// Max Length: 20
 	// Min Length: 1
@@ -57,10 +63,18 @@ func (m *UpdateProjectInput) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateLintRules(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateLocales(formats); err != nil {
 		res = append(res, err)
 	}
 
+	if err := m.validateReleaseGate(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateShortName(formats); err != nil {
 		res = append(res, err)
 	}
@@ -108,6 +122,32 @@ func (m *UpdateProjectInput) validateID(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *UpdateProjectInput) validateLintRules(formats strfmt.Registry) error {
+	if swag.IsZero(m.LintRules) { // not required
+		return nil
+	}
+
+	for k := range m.LintRules {
+
+		if err := validate.Required("lint_rules"+"."+k, "body", m.LintRules[k]); err != nil {
+			return err
+		}
+		if val, ok := m.LintRules[k]; ok {
+			if err := val.Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("lint_rules" + "." + k)
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("lint_rules" + "." + k)
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *UpdateProjectInput) validateLocales(formats strfmt.Registry) error {
 	if swag.IsZero(m.Locales) { // not required
 		return nil
@@ -134,6 +174,25 @@ func (m *UpdateProjectInput) validateLocales(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *UpdateProjectInput) validateReleaseGate(formats strfmt.Registry) error {
+	if swag.IsZero(m.ReleaseGate) { // not required
+		return nil
+	}
+
+	if m.ReleaseGate != nil {
+		if err := m.ReleaseGate.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("release_gate")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("release_gate")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (m *UpdateProjectInput) validateShortName(formats strfmt.Registry) error {
 	if swag.IsZero(m.ShortName) { // not required
 		return nil
@@ -174,16 +233,39 @@ func (m *UpdateProjectInput) validateTitle(formats strfmt.Registry) error {
 func (m *UpdateProjectInput) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.contextValidateLintRules(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.contextValidateLocales(ctx, formats); err != nil {
 		res = append(res, err)
 	}
 
+	if err := m.contextValidateReleaseGate(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
 	return nil
 }
 
+func (m *UpdateProjectInput) contextValidateLintRules(ctx context.Context, formats strfmt.Registry) error {
+
+	for k := range m.LintRules {
+
+		if val, ok := m.LintRules[k]; ok {
+			if err := val.ContextValidate(ctx, formats); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *UpdateProjectInput) contextValidateLocales(ctx context.Context, formats strfmt.Registry) error {
 
 	for k := range m.Locales {
@@ -199,6 +281,22 @@ func (m *UpdateProjectInput) contextValidateLocales(ctx context.Context, formats
 	return nil
 }
 
+func (m *UpdateProjectInput) contextValidateReleaseGate(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.ReleaseGate != nil {
+		if err := m.ReleaseGate.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("release_gate")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("release_gate")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 // MarshalBinary interface implementation
 func (m *UpdateProjectInput) MarshalBinary() ([]byte, error) {
 	if m == nil {
@@ -43,6 +43,10 @@ type UpdateTranslationInput struct {
 
 	// variables
 	Variables interface{} `json:"variables,omitempty"`
+
+	// The version that the update is based on. If set, and the entity has been changed since, the update is rejected with a conflict.
+	// Can also be set with the If-Match-header.
+	Version int64 `json:"version,omitempty"`
 }
 
 // Validate validates this update translation input
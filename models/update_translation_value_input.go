@@ -35,6 +35,10 @@ type UpdateTranslationValueInput struct {
 	// Max Length: 8000
 	// Min Length: 0
 	Value *string `json:"value,omitempty"`
+
+	// The version that the update is based on. If set, and the entity has been changed since, the update is rejected with a conflict.
+	// Can also be set with the If-Match-header.
+	Version int64 `json:"version,omitempty"`
 }
 
 // Validate validates this update translation value input
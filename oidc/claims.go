@@ -0,0 +1,82 @@
+package oidc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Claims are the claims of a verified id-token
+type Claims map[string]interface{}
+
+// Allows for some difference between the clocks of the servers
+const clockSkew = time.Minute
+
+func (c Claims) validate(issuer, clientID, nonce string, now time.Time) error {
+	if strings.TrimSuffix(c.String("iss"), "/") != strings.TrimSuffix(issuer, "/") {
+		return fmt.Errorf("unexpected issuer %s", c.String("iss"))
+	}
+	if !c.hasAudience(clientID) {
+		return fmt.Errorf("the token is not issued for this client")
+	}
+	if c.Subject() == "" {
+		return fmt.Errorf("the token has no subject")
+	}
+	exp, ok := c.time("exp")
+	if !ok {
+		return fmt.Errorf("the token has no expiry")
+	}
+	if now.After(exp.Add(clockSkew)) {
+		return fmt.Errorf("the token has expired")
+	}
+	if c.String("nonce") != nonce {
+		return fmt.Errorf("the nonce does not match")
+	}
+	return nil
+}
+
+func (c Claims) hasAudience(clientID string) bool {
+	for _, aud := range c.Strings("aud") {
+		if aud == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Claims) time(name string) (time.Time, bool) {
+	f, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(f), 0), true
+}
+
+// Subject is the unique, stable identifier of the user at the identity-provider
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+// String returns the claim, if it is a string
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Strings returns the claim as a list, which is useful for claims like groups,
+// which some providers set to a single string if there is only one value.
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var s []string
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				s = append(s, str)
+			}
+		}
+		return s
+	}
+	return nil
+}
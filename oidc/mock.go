@@ -0,0 +1,168 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+// MockIdP is a minimal identity-provider, for use in tests.
+type MockIdP struct {
+	*httptest.Server
+	ClientID string
+	key      *rsa.PrivateKey
+	sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	claims        Claims
+	nonce         string
+	codeChallenge string
+	redirectURI   string
+}
+
+const mockKeyID = "mock"
+
+func NewMockIdP(t *testing.T, clientID string) *MockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &MockIdP{ClientID: clientID, key: key, codes: map[string]mockAuthorization{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(rw http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(rw).Encode(Discovery{
+			Issuer:                m.URL,
+			AuthorizationEndpoint: m.URL + "/authorize",
+			TokenEndpoint:         m.URL + "/token",
+			JWKSURI:               m.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(rw http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(rw).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": mockKeyID,
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", m.token)
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+// Login acts as the user logging in at the identity-provider, with the claims.
+// It returns the url that the identity-provider redirects the user back to.
+func (m *MockIdP) Login(authURL string, claims Claims) (*url.URL, error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	if q.Get("client_id") != m.ClientID {
+		return nil, fmt.Errorf("unknown client_id %s", q.Get("client_id"))
+	}
+	if q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		return nil, fmt.Errorf("expected the authorization-code flow with PKCE")
+	}
+	code, err := utils.GenerateSecret(16)
+	if err != nil {
+		return nil, err
+	}
+	m.Lock()
+	m.codes[code] = mockAuthorization{
+		claims:        claims,
+		nonce:         q.Get("nonce"),
+		codeChallenge: q.Get("code_challenge"),
+		redirectURI:   q.Get("redirect_uri"),
+	}
+	m.Unlock()
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		return nil, err
+	}
+	rq := redirect.Query()
+	rq.Set("code", code)
+	rq.Set("state", q.Get("state"))
+	redirect.RawQuery = rq.Encode()
+	return redirect, nil
+}
+
+func (m *MockIdP) token(rw http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m.Lock()
+	auth, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.Unlock()
+	switch {
+	case !ok:
+		http.Error(rw, "invalid_grant", http.StatusBadRequest)
+		return
+	case r.PostForm.Get("client_id") != m.ClientID:
+		http.Error(rw, "invalid_client", http.StatusUnauthorized)
+		return
+	case r.PostForm.Get("redirect_uri") != auth.redirectURI:
+		http.Error(rw, "invalid_grant: redirect_uri", http.StatusBadRequest)
+		return
+	case CodeChallenge(r.PostForm.Get("code_verifier")) != auth.codeChallenge:
+		http.Error(rw, "invalid_grant: code_verifier", http.StatusBadRequest)
+		return
+	}
+	claims := Claims{
+		"iss":   m.URL,
+		"aud":   m.ClientID,
+		"nonce": auth.nonce,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range auth.claims {
+		claims[k] = v
+	}
+	idToken, err := m.Sign(claims)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+}
+
+// Sign creates an id-token with the claims, signed by the identity-provider
+func (m *MockIdP) Sign(claims Claims) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": mockKeyID, "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	h := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, m.key, crypto.SHA256, h[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
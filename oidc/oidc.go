@@ -0,0 +1,276 @@
+// Package oidc implements the parts of OpenID Connect that are needed to log in users
+// through an external identity-provider, using the authorization-code flow with PKCE.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+var (
+	ErrInvalidToken = errors.New("The id-token is invalid")
+	ErrUnknownKey   = errors.New("The id-token is signed with an unknown key")
+)
+
+type Config struct {
+	// The url of the identity-provider, which must serve /.well-known/openid-configuration
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// The url that the identity-provider redirects back to after the user has logged in
+	RedirectURL string
+	// Scopes to request, in addition to openid
+	Scopes []string
+}
+
+// Discovery is the subset of the provider-metadata that is used
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type Provider struct {
+	Config
+	Discovery
+	client *http.Client
+
+	sync.RWMutex
+	keys map[string]*rsa.PublicKey
+}
+
+// NewProvider creates a provider from the metadata that the identity-provider publishes.
+func NewProvider(ctx context.Context, config Config, client *http.Client) (*Provider, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	p := &Provider{Config: config, client: client}
+	u := strings.TrimSuffix(config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, u, &p.Discovery); err != nil {
+		return nil, fmt.Errorf("failed to discover the openid-configuration: %w", err)
+	}
+	if strings.TrimSuffix(p.Discovery.Issuer, "/") != strings.TrimSuffix(config.Issuer, "/") {
+		return nil, fmt.Errorf("the issuer of the openid-configuration (%s) does not match the configured issuer (%s)", p.Discovery.Issuer, config.Issuer)
+	}
+	if p.AuthorizationEndpoint == "" || p.TokenEndpoint == "" || p.JWKSURI == "" {
+		return nil, fmt.Errorf("the openid-configuration is missing required endpoints")
+	}
+	return p, nil
+}
+
+// An AuthRequest holds the values that must be kept between the redirect to the identity-provider,
+// and the callback.
+type AuthRequest struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+	// The url that was passed to AuthCodeURL
+	URL string
+}
+
+// NewAuthRequest creates a new AuthRequest, with the url that the user should be redirected to.
+func (p *Provider) NewAuthRequest() (AuthRequest, error) {
+	var ar AuthRequest
+	var err error
+	if ar.State, err = utils.GenerateSecret(16); err != nil {
+		return ar, err
+	}
+	if ar.Nonce, err = utils.GenerateSecret(16); err != nil {
+		return ar, err
+	}
+	if ar.CodeVerifier, err = utils.GenerateSecret(32); err != nil {
+		return ar, err
+	}
+	ar.URL = p.AuthCodeURL(ar.State, ar.Nonce, CodeChallenge(ar.CodeVerifier))
+	return ar, nil
+}
+
+// CodeChallenge returns the S256 PKCE-challenge for the verifier
+func CodeChallenge(verifier string) string {
+	h := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
+
+func (p *Provider) AuthCodeURL(state, nonce, codeChallenge string) string {
+	scopes := append([]string{"openid"}, p.Scopes...)
+	v := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {p.RedirectURL},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(p.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.AuthorizationEndpoint + sep + v.Encode()
+}
+
+// Exchange exchanges the code for tokens, and returns the verified claims of the id-token.
+func (p *Provider) Exchange(ctx context.Context, code string, ar AuthRequest) (Claims, error) {
+	v := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURL},
+		"client_id":     {p.ClientID},
+		"code_verifier": {ar.CodeVerifier},
+	}
+	if p.ClientSecret != "" {
+		v.Set("client_secret", p.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenEndpoint, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange the code: %w", err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the identity-provider responded to the code-exchange with %d: %s", res.StatusCode, body)
+	}
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to decode the token-response: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("the token-response did not include an id-token")
+	}
+	return p.Verify(ctx, tokens.IDToken, ar.Nonce)
+}
+
+// Verify verifies the signature and the standard claims of the id-token
+func (p *Provider) Verify(ctx context.Context, rawIDToken, nonce string) (Claims, error) {
+	parts := strings.Split(rawIDToken, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("%w: unsupported algorithm %s", ErrInvalidToken, header.Alg)
+	}
+	key, err := p.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	h := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, h[:], signature); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := claims.validate(p.Discovery.Issuer, p.ClientID, nonce, time.Now()); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+// Returns the key with the id. The keys are refetched if the key is not known,
+// since providers rotate their keys.
+func (p *Provider) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	p.RLock()
+	key, ok := p.keys[kid]
+	p.RUnlock()
+	if ok {
+		return key, nil
+	}
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := p.getJSON(ctx, p.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch the keys of the identity-provider: %w", err)
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	p.Lock()
+	p.keys = keys
+	p.Unlock()
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+func (p *Provider) getJSON(ctx context.Context, u string, j interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with %d", u, res.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(j)
+}
+
+func decodeSegment(s string, j interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, j)
+}
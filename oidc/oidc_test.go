@@ -0,0 +1,82 @@
+package oidc
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestProvider(t *testing.T) {
+	idp := NewMockIdP(t, "skiver")
+	ctx := context.Background()
+	p, err := NewProvider(ctx, Config{
+		Issuer:      idp.URL,
+		ClientID:    "skiver",
+		RedirectURL: "https://skiver.example/api/auth/oidc/callback",
+		Scopes:      []string{"profile", "groups"},
+	}, nil)
+	testza.AssertNoError(t, err)
+
+	ar, err := p.NewAuthRequest()
+	testza.AssertNoError(t, err)
+	testza.AssertContains(t, ar.URL, "scope=openid+profile+groups")
+	testza.AssertContains(t, ar.URL, "code_challenge_method=S256")
+	testza.AssertNotContains(t, ar.URL, ar.CodeVerifier, "The verifier must not be sent in the redirect")
+
+	callback, err := idp.Login(ar.URL, Claims{"sub": "abc", "groups": []string{"translators"}})
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, ar.State, callback.Query().Get("state"))
+
+	wrongVerifier := ar
+	wrongVerifier.CodeVerifier = "wrong"
+	_, err = p.Exchange(ctx, callback.Query().Get("code"), wrongVerifier)
+	testza.AssertNotNil(t, err, "The code-verifier must match the challenge")
+
+	callback, err = idp.Login(ar.URL, Claims{"sub": "abc", "groups": []string{"translators"}})
+	testza.AssertNoError(t, err)
+	claims, err := p.Exchange(ctx, callback.Query().Get("code"), ar)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "abc", claims.Subject())
+	testza.AssertEqual(t, []string{"translators"}, claims.Strings("groups"))
+
+	_, err = p.Exchange(ctx, callback.Query().Get("code"), ar)
+	testza.AssertNotNil(t, err, "Codes can only be used once")
+
+	valid := func() Claims {
+		return Claims{
+			"iss":   idp.URL,
+			"aud":   []string{"other", "skiver"},
+			"sub":   "abc",
+			"nonce": "n",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}
+	}
+	token, err := idp.Sign(valid())
+	testza.AssertNoError(t, err)
+	_, err = p.Verify(ctx, token, "n")
+	testza.AssertNoError(t, err)
+
+	for name, modify := range map[string]func(c Claims){
+		"wrong issuer":   func(c Claims) { c["iss"] = "https://evil.example" },
+		"wrong audience": func(c Claims) { c["aud"] = "other" },
+		"wrong nonce":    func(c Claims) { c["nonce"] = "x" },
+		"expired":        func(c Claims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"no subject":     func(c Claims) { delete(c, "sub") },
+	} {
+		c := valid()
+		modify(c)
+		token, err := idp.Sign(c)
+		testza.AssertNoError(t, err)
+		_, err = p.Verify(ctx, token, "n")
+		testza.AssertErrorIs(t, err, ErrInvalidToken, name)
+	}
+
+	parts := strings.Split(token, ".")
+	tampered, err := idp.Sign(Claims{"sub": "someone-else"})
+	testza.AssertNoError(t, err)
+	_, err = p.Verify(ctx, parts[0]+"."+strings.Split(tampered, ".")[1]+"."+parts[2], "n")
+	testza.AssertErrorIs(t, err, ErrInvalidToken, "The signature must match the payload")
+}
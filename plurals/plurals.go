@@ -0,0 +1,164 @@
+// Package plurals resolves the CLDR plural-rules for locales.
+//
+// The rules themselves are from the CLDR-data in golang.org/x/text, while this package
+// adds the lookup by locale, and the set of plural-categories that each locale uses.
+// The categories are the same as the ones i18next uses as key-suffixes, like `Items_one` and `Items_other`.
+package plurals
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/runar-rkmedia/skiver/types"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+const (
+	Zero  = "zero"
+	One   = "one"
+	Two   = "two"
+	Few   = "few"
+	Many  = "many"
+	Other = "other"
+)
+
+// Categories are all the CLDR plural-categories, in the CLDR-order
+var Categories = []string{Zero, One, Two, Few, Many, Other}
+
+var formCategories = map[plural.Form]string{
+	plural.Zero:  Zero,
+	plural.One:   One,
+	plural.Two:   Two,
+	plural.Few:   Few,
+	plural.Many:  Many,
+	plural.Other: Other,
+}
+
+// Rules are the cardinal plural-rules for a single language
+type Rules struct {
+	Tag language.Tag
+	// The categories used by the language, in the CLDR-order. Always includes `other`.
+	Categories []string
+}
+
+var cache sync.Map
+
+// ForLanguage returns the rules for a BCP 47-tag, like `pl` or `nb-NO`.
+func ForLanguage(tag string) (Rules, bool) {
+	if tag == "" {
+		return Rules{}, false
+	}
+	if r, ok := cache.Load(tag); ok {
+		return r.(Rules), true
+	}
+	t, err := language.Parse(tag)
+	if err != nil {
+		return Rules{}, false
+	}
+	r := Rules{Tag: t, Categories: categoriesFor(t)}
+	cache.Store(tag, r)
+	return r, true
+}
+
+// ForLocale returns the rules for the locale, by its IETF-tag, or its Iso639_1-code.
+func ForLocale(locale types.Locale) (Rules, bool) {
+	if r, ok := ForLanguage(locale.IETF); ok {
+		return r, true
+	}
+	return ForLanguage(locale.Iso639_1)
+}
+
+// Category returns the plural-category for the count, like `one` or `other`
+func (r Rules) Category(count float64) string {
+	return r.CategoryOf(strconv.FormatFloat(count, 'f', -1, 64))
+}
+
+// CategoryOf returns the plural-category for a decimal number, like `1` or `1.50`.
+// Unlike Category, any trailing zeros are significant, as they are in CLDR.
+func (r Rules) CategoryOf(number string) string {
+	i, v, w, f, t := operands(number)
+	return formCategories[plural.Cardinal.MatchPlural(r.Tag, i, v, w, f, t)]
+}
+
+// Has returns true if the language uses the category.
+func (r Rules) Has(category string) bool {
+	for _, c := range r.Categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Missing returns the categories used by the language that are not in the list, in the CLDR-order.
+func (r Rules) Missing(categories []string) []string {
+	var missing []string
+outer:
+	for _, c := range r.Categories {
+		for _, p := range categories {
+			if p == c {
+				continue outer
+			}
+		}
+		missing = append(missing, c)
+	}
+	return missing
+}
+
+// Returns the CLDR-operands for a decimal number.
+// Large values are reduced modulo 10,000,000, which the plural-package allows.
+func operands(number string) (i, v, w, f, t int) {
+	number = strings.TrimPrefix(number, "-")
+	intPart, fracPart, _ := strings.Cut(number, ".")
+	i = atoiMod(intPart)
+	v = len(fracPart)
+	f = atoiMod(fracPart)
+	trimmed := strings.TrimRight(fracPart, "0")
+	w = len(trimmed)
+	t = atoiMod(trimmed)
+	return
+}
+
+func atoiMod(s string) int {
+	if len(s) > 7 {
+		s = s[len(s)-7:]
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// The categories are found by matching a range of samples, which covers the categories of all the languages in CLDR.
+func categoriesFor(tag language.Tag) []string {
+	found := map[string]bool{}
+	match := func(i, v, f int) {
+		fs := ""
+		if v > 0 {
+			fs = strconv.Itoa(f)
+			fs = strings.Repeat("0", v-len(fs)) + fs
+		}
+		trimmed := strings.TrimRight(fs, "0")
+		t, _ := strconv.Atoi(trimmed)
+		found[formCategories[plural.Cardinal.MatchPlural(tag, i, v, len(trimmed), f, t)]] = true
+	}
+	for i := 0; i <= 1100; i++ {
+		match(i, 0, 0)
+	}
+	match(1000000, 0, 0)
+	for i := 0; i <= 110; i++ {
+		for f := 0; f <= 9; f++ {
+			match(i, 1, f)
+		}
+		for _, f := range []int{0, 1, 10, 11, 50} {
+			match(i, 2, f)
+		}
+	}
+	var categories []string
+	for _, c := range Categories {
+		if found[c] {
+			categories = append(categories, c)
+		}
+	}
+	return categories
+}
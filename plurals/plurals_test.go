@@ -0,0 +1,64 @@
+package plurals
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestCategories(t *testing.T) {
+	tests := map[string][]string{
+		"en":    {One, Other},
+		"en-GB": {One, Other},
+		"nb-NO": {One, Other},
+		"pl":    {One, Few, Many, Other},
+		"ru":    {One, Few, Many, Other},
+		"ar":    {Zero, One, Two, Few, Many, Other},
+		"ja":    {Other},
+		"fr":    {One, Other},
+		"cy":    {Zero, One, Two, Few, Many, Other},
+	}
+	for tag, want := range tests {
+		t.Run(tag, func(t *testing.T) {
+			r, ok := ForLanguage(tag)
+			testza.AssertTrue(t, ok)
+			testza.AssertEqual(t, want, r.Categories)
+		})
+	}
+}
+
+func TestCategory(t *testing.T) {
+	pl, _ := ForLanguage("pl")
+	tests := []struct {
+		count float64
+		want  string
+	}{
+		{1, One},
+		{2, Few},
+		{4, Few},
+		{5, Many},
+		{12, Many},
+		{22, Few},
+		{0, Many},
+		{1.5, Other},
+	}
+	for _, tt := range tests {
+		testza.AssertEqual(t, tt.want, pl.Category(tt.count), tt.count)
+	}
+	en, _ := ForLanguage("en")
+	testza.AssertEqual(t, One, en.Category(1))
+	testza.AssertEqual(t, Other, en.Category(0))
+	// Trailing zeros are visible fraction-digits in CLDR
+	testza.AssertEqual(t, Other, en.CategoryOf("1.0"))
+}
+
+func TestForLocale(t *testing.T) {
+	r, ok := ForLocale(types.Locale{Iso639_1: "pl"})
+	testza.AssertTrue(t, ok)
+	testza.AssertEqual(t, []string{Few, Many}, r.Missing([]string{One, Other}))
+	testza.AssertTrue(t, r.Has(Few))
+
+	_, ok = ForLocale(types.Locale{})
+	testza.AssertFalse(t, ok)
+}
@@ -77,6 +77,7 @@ const (
 	CodeErrUser                 ErrorCodes = "Error: User error"
 	CodeErrProject              ErrorCodes = "Error: Project error"
 	CodeErrSnapshot             ErrorCodes = "Error: Snapshot error"
+	CodeErrReleaseGate          ErrorCodes = "Error: Release gate"
 	CodeErrReportMissing        ErrorCodes = "Error: Report missing"
 	CodeErrTranslation          ErrorCodes = "Error: Translation error"
 	CodeErrCategory             ErrorCodes = "Error: Category error"
@@ -84,6 +85,10 @@ const (
 	CodeErrOrganization         ErrorCodes = "Error: Organization error"
 	CodeErrOrganizationNotFound ErrorCodes = "Error: Organization not found"
 	CodeErrImport               ErrorCodes = "Error: Import error"
+	CodeErrGlossary             ErrorCodes = "Error: Glossary error"
+	CodeErrWebhook              ErrorCodes = "Error: Webhook error"
+	CodeErrVersionConflict      ErrorCodes = "Error: Version conflict"
+	CodeErrLoginThrottled       ErrorCodes = "Error: Too many failed logins"
 
 	CodeErrNotFoundLocale      ErrorCodes = "Error: Locale not found"
 	CodeErrNotFoundProject     ErrorCodes = "Error: Project not found"
@@ -0,0 +1,309 @@
+// Package translationmemory suggests values for a translation, based on the other translations within the organization.
+//
+// The suggestions are the values of other translations, whose source-value is similar to the source-value of
+// the translation being edited. The source-value of a translation is the value that was created first,
+// which is normally the one the other values were translated from.
+//
+// The memory is an in-memory index of all the translation-values, which is kept up to date from the events
+// published by the database.
+package translationmemory
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runar-rkmedia/skiver/types"
+	"github.com/runar-rkmedia/skiver/utils"
+)
+
+var (
+	ErrNoSource = errors.New("The translation does not have a value that can be used as source for suggestions")
+)
+
+// Suggestions are the suggestions for a translation within a locale
+// swagger:model TranslationSuggestions
+type Suggestions struct {
+	// The value that the suggestions were matched against
+	Source         string       `json:"source"`
+	SourceLocaleID string       `json:"source_locale_id"`
+	Suggestions    []Suggestion `json:"suggestions"`
+}
+
+// Suggestion is a value from another translation
+// swagger:model TranslationSuggestion
+type Suggestion struct {
+	// The suggested value
+	Value string `json:"value"`
+	// The source-value of the other translation, which is similar to the source-value of the translation
+	Source string `json:"source"`
+	// How similar the source-values are, in percent
+	Match              int    `json:"match"`
+	TranslationID      string `json:"translation_id"`
+	TranslationValueID string `json:"translation_value_id"`
+	// The full key of the other translation, like `General.Welcome`
+	Key          string `json:"key"`
+	ProjectID    string `json:"project_id"`
+	ProjectTitle string `json:"project_title"`
+}
+
+type SuggestOptions struct {
+	// The minimum similarity for a suggestion, from 0 to 1. Defaults to 0.5
+	MinMatch float64
+	// The maximum number of suggestions. Defaults to 10
+	Limit int
+}
+
+type entry struct {
+	id            string
+	translationID string
+	localeID      string
+	value         string
+	createdAt     time.Time
+}
+
+type Memory struct {
+	db types.Storage
+	sync.RWMutex
+	// If set, the memory will be rebuilt from the database on next use
+	stale bool
+	// The entries by organization-id, then by translation-value-id
+	entries map[string]map[string]entry
+	// The ids of the translation-values by translation-id
+	byTranslation map[string]map[string]bool
+}
+
+func NewMemory(db types.Storage) *Memory {
+	return &Memory{
+		db:            db,
+		stale:         true,
+		entries:       map[string]map[string]entry{},
+		byTranslation: map[string]map[string]bool{},
+	}
+}
+
+// Build (re)builds the memory from all the translation-values in the database.
+func (m *Memory) Build() error {
+	m.Lock()
+	defer m.Unlock()
+	return m.build()
+}
+
+func (m *Memory) build() error {
+	tvs, err := m.db.GetTranslationValues()
+	if err != nil {
+		return err
+	}
+	m.entries = map[string]map[string]entry{}
+	m.byTranslation = map[string]map[string]bool{}
+	for _, tv := range tvs {
+		m.set(tv)
+	}
+	m.stale = false
+	return nil
+}
+
+// Size returns the number of translation-values in the memory
+func (m *Memory) Size() int {
+	m.RLock()
+	defer m.RUnlock()
+	n := 0
+	for _, e := range m.entries {
+		n += len(e)
+	}
+	return n
+}
+
+// Publish implements the PubSubPublisher, and updates the memory incrementally as translation-values change.
+// It does not access the database, since it may be called within a database-transaction.
+func (m *Memory) Publish(kind, variant string, contents interface{}) {
+	if kind != string(types.PubTypeTranslationValue) {
+		return
+	}
+	m.Lock()
+	defer m.Unlock()
+	switch types.PubVerb(variant) {
+	case types.PubVerbClean:
+		m.stale = true
+	case types.PubVerbCreate, types.PubVerbUpdate, types.PubVerbSoftDelete:
+		switch tv := contents.(type) {
+		case types.TranslationValue:
+			m.set(tv)
+		case *types.TranslationValue:
+			if tv != nil {
+				m.set(*tv)
+			}
+		}
+	}
+}
+
+// Adds, updates or removes the value from the memory
+func (m *Memory) set(tv types.TranslationValue) {
+	if tv.ID == "" {
+		return
+	}
+	org := m.entries[tv.OrganizationID]
+	if tv.Deleted != nil {
+		if org != nil {
+			delete(org, tv.ID)
+		}
+		delete(m.byTranslation[tv.TranslationID], tv.ID)
+		return
+	}
+	if org == nil {
+		org = map[string]entry{}
+		m.entries[tv.OrganizationID] = org
+	}
+	org[tv.ID] = entry{
+		id:            tv.ID,
+		translationID: tv.TranslationID,
+		localeID:      tv.LocaleID,
+		value:         tv.Value,
+		createdAt:     tv.CreatedAt,
+	}
+	if m.byTranslation[tv.TranslationID] == nil {
+		m.byTranslation[tv.TranslationID] = map[string]bool{}
+	}
+	m.byTranslation[tv.TranslationID][tv.ID] = true
+}
+
+// Returns the values of the translation by locale-id
+func (m *Memory) valuesOf(org map[string]entry, translationID string) map[string]entry {
+	values := map[string]entry{}
+	for id := range m.byTranslation[translationID] {
+		if e, ok := org[id]; ok && e.value != "" {
+			values[e.localeID] = e
+		}
+	}
+	return values
+}
+
+// Suggest returns suggestions for the translation in the locale, from the other translations within the organization.
+func (m *Memory) Suggest(organizationID, translationID, localeID string, options ...SuggestOptions) (Suggestions, error) {
+	opts := SuggestOptions{MinMatch: 0.5, Limit: 10}
+	if len(options) > 0 {
+		if options[0].MinMatch > 0 {
+			opts.MinMatch = options[0].MinMatch
+		}
+		if options[0].Limit > 0 {
+			opts.Limit = options[0].Limit
+		}
+	}
+	m.RLock()
+	if m.stale {
+		m.RUnlock()
+		m.Lock()
+		if m.stale {
+			if err := m.build(); err != nil {
+				m.Unlock()
+				return Suggestions{}, err
+			}
+		}
+		m.Unlock()
+		m.RLock()
+	}
+	org := m.entries[organizationID]
+	var source *entry
+	for _, e := range m.valuesOf(org, translationID) {
+		e := e
+		if e.localeID == localeID {
+			continue
+		}
+		if source == nil || e.createdAt.Before(source.createdAt) || (e.createdAt.Equal(source.createdAt) && e.localeID < source.localeID) {
+			source = &e
+		}
+	}
+	if source == nil {
+		m.RUnlock()
+		return Suggestions{}, ErrNoSource
+	}
+	var suggestions []Suggestion
+	seen := map[string]bool{translationID: true}
+	for _, e := range org {
+		if e.localeID != source.localeID || seen[e.translationID] {
+			continue
+		}
+		seen[e.translationID] = true
+		match := similarity(source.value, e.value, opts.MinMatch)
+		if match < opts.MinMatch {
+			continue
+		}
+		target, ok := m.valuesOf(org, e.translationID)[localeID]
+		if !ok {
+			continue
+		}
+		suggestions = append(suggestions, Suggestion{
+			Value:              target.value,
+			Source:             e.value,
+			Match:              int(math.Round(match * 100)),
+			TranslationID:      e.translationID,
+			TranslationValueID: target.id,
+		})
+	}
+	m.RUnlock()
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Match != b.Match {
+			return a.Match > b.Match
+		}
+		if a.Value != b.Value {
+			return a.Value < b.Value
+		}
+		return a.TranslationID < b.TranslationID
+	})
+	result := Suggestions{Source: source.value, SourceLocaleID: source.localeID, Suggestions: []Suggestion{}}
+	for _, s := range suggestions {
+		if len(result.Suggestions) >= opts.Limit {
+			break
+		}
+		// Translations that are deleted, or no longer part of a project, are not suggested
+		ok, err := m.resolveOrigin(&s)
+		if err != nil {
+			return result, err
+		}
+		if ok {
+			result.Suggestions = append(result.Suggestions, s)
+		}
+	}
+	return result, nil
+}
+
+// Sets the key and project of the suggestion, and returns false if the translation is deleted.
+func (m *Memory) resolveOrigin(s *Suggestion) (bool, error) {
+	t, err := m.db.GetTranslation(s.TranslationID)
+	if err != nil || t == nil || t.Deleted != nil {
+		return false, err
+	}
+	c, err := m.db.GetCategory(t.CategoryID)
+	if err != nil || c == nil || c.Deleted != nil {
+		return false, err
+	}
+	p, err := m.db.GetProject(c.ProjectID)
+	if err != nil || p == nil || p.Deleted != nil {
+		return false, err
+	}
+	s.Key = t.Key
+	if !c.IsRoot() {
+		s.Key = c.Key + "." + t.Key
+	}
+	s.ProjectID = p.ID
+	s.ProjectTitle = p.Title
+	return true, nil
+}
+
+// Returns the similarity of the strings, or 0 if it cannot reach the minimum.
+// Since the Levenshtein-distance is at least the difference in length, most candidates can be skipped cheaply.
+func similarity(a, b string, min float64) float64 {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	la, lb := float64(len(a)), float64(len(b))
+	if la > lb {
+		la, lb = lb, la
+	}
+	if lb > 0 && la/lb < min {
+		return 0
+	}
+	return utils.Similarity(a, b)
+}
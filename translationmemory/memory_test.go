@@ -0,0 +1,222 @@
+package translationmemory
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/internal"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		min  float64
+		want float64
+	}{
+		{"Equal strings", "Welcome", "Welcome", 0.5, 1},
+		{"Case is ignored", "Welcome", "WELCOME", 0.5, 1},
+		{"Surrounding whitespace is ignored", " Welcome\n", "Welcome", 0.5, 1},
+		{"Partial match", "Welcome back", "Welcome home", 0.5, 0.67},
+		{"Candidates that cannot reach the minimum are skipped", "Hi", "Welcome to the application", 0.5, 0},
+		{"Both empty", "", "", 0.5, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testza.AssertEqual(t, tt.want, math.Round(similarity(tt.a, tt.b, tt.min)*100)/100)
+		})
+	}
+}
+
+type memoryFixture struct {
+	t  *testing.T
+	db types.Storage
+	m  *Memory
+	// The root-category of the project within each organization
+	categories map[string]types.Category
+	now        time.Time
+	count      int
+}
+
+func newMemoryFixture(t *testing.T) *memoryFixture {
+	t.Helper()
+	internal.NewMockTimeNow()
+	db := bboltStorage.NewMockDB(t)
+	f := &memoryFixture{
+		t:          t,
+		db:         db,
+		m:          NewMemory(db),
+		categories: map[string]types.Category{},
+		now:        time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	testza.AssertNoError(t, f.m.Build())
+	return f
+}
+
+// Creates a translation within a project in the organization
+func (f *memoryFixture) translation(organizationID, key string) types.Translation {
+	f.t.Helper()
+	category, ok := f.categories[organizationID]
+	if !ok {
+		p := types.Project{Title: "Project for " + organizationID, ShortName: organizationID}
+		p.CreatedBy = "jim"
+		p.OrganizationID = organizationID
+		project, err := f.db.CreateProject(p)
+		testza.AssertNoError(f.t, err)
+		c := types.Category{}
+		c.CreatedBy = "jim"
+		c.ProjectID = project.ID
+		c.OrganizationID = organizationID
+		category, err = f.db.CreateCategory(c)
+		testza.AssertNoError(f.t, err)
+		f.categories[organizationID] = category
+	}
+	tr := types.Translation{Key: key, CategoryID: category.ID}
+	tr.CreatedBy = "jim"
+	tr.OrganizationID = organizationID
+	tr, err := f.db.CreateTranslation(tr)
+	testza.AssertNoError(f.t, err)
+	return tr
+}
+
+// Publishes a new value to the memory. Every value is created a minute after the previous one.
+func (f *memoryFixture) value(tr types.Translation, localeID, value string) types.TranslationValue {
+	f.count++
+	tv := types.TranslationValue{TranslationID: tr.ID, LocaleID: localeID, Value: value}
+	tv.ID = tr.ID + "-" + localeID
+	tv.OrganizationID = tr.OrganizationID
+	tv.CreatedAt = f.now.Add(time.Duration(f.count) * time.Minute)
+	f.m.Publish(string(types.PubTypeTranslationValue), string(types.PubVerbCreate), tv)
+	return tv
+}
+
+func TestSuggest(t *testing.T) {
+	f := newMemoryFixture(t)
+	welcome := f.translation("org-a", "Welcome")
+	f.value(welcome, "en", "Welcome to the application")
+	back := f.translation("org-a", "WelcomeBack")
+	f.value(back, "en", "Welcome back to the application")
+	f.value(back, "nb", "Velkommen tilbake til applikasjonen")
+	goodbye := f.translation("org-a", "Goodbye")
+	f.value(goodbye, "en", "Goodbye")
+	f.value(goodbye, "nb", "Ha det")
+	testza.AssertEqual(t, 5, f.m.Size())
+
+	s, err := f.m.Suggest("org-a", welcome.ID, "nb")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Welcome to the application", s.Source)
+	testza.AssertEqual(t, "en", s.SourceLocaleID)
+	testza.AssertLen(t, s.Suggestions, 1)
+	testza.AssertEqual(t, "Velkommen tilbake til applikasjonen", s.Suggestions[0].Value)
+	testza.AssertEqual(t, "Welcome back to the application", s.Suggestions[0].Source)
+	testza.AssertEqual(t, 84, s.Suggestions[0].Match)
+	testza.AssertEqual(t, "WelcomeBack", s.Suggestions[0].Key)
+	testza.AssertEqual(t, "Project for org-a", s.Suggestions[0].ProjectTitle)
+
+	s, err = f.m.Suggest("org-a", welcome.ID, "nb", SuggestOptions{MinMatch: 0.9})
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, s.Suggestions, 0, "Suggestions below the minimum match are excluded")
+
+	_, err = f.m.Suggest("org-a", f.translation("org-a", "Empty").ID, "nb")
+	testza.AssertErrorIs(t, err, ErrNoSource)
+}
+
+func TestSuggestUsesEarliestCreatedSource(t *testing.T) {
+	f := newMemoryFixture(t)
+	// The german value is created first, and is therefore the source, even though english sorts first
+	welcome := f.translation("org-a", "Welcome")
+	f.value(welcome, "de", "Willkommen zur Anwendung")
+	f.value(welcome, "en", "Welcome to the application")
+	back := f.translation("org-a", "WelcomeBack")
+	f.value(back, "en", "Welcome back to the application")
+	f.value(back, "nb", "Velkommen tilbake til applikasjonen")
+	back2 := f.translation("org-a", "WelcomeBackDe")
+	f.value(back2, "de", "Willkommen zurück zur Anwendung")
+	f.value(back2, "nb", "Velkommen tilbake")
+
+	s, err := f.m.Suggest("org-a", welcome.ID, "nb")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "de", s.SourceLocaleID)
+	testza.AssertEqual(t, "Willkommen zur Anwendung", s.Source)
+	testza.AssertLen(t, s.Suggestions, 1)
+	testza.AssertEqual(t, "Velkommen tilbake", s.Suggestions[0].Value)
+
+	// The locale being translated is never used as source
+	s, err = f.m.Suggest("org-a", welcome.ID, "de")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "en", s.SourceLocaleID)
+}
+
+func TestPublishUpdatesMemory(t *testing.T) {
+	f := newMemoryFixture(t)
+	welcome := f.translation("org-a", "Welcome")
+	f.value(welcome, "en", "Welcome to the application")
+	back := f.translation("org-a", "WelcomeBack")
+	f.value(back, "en", "Something else entirely")
+	nb := f.value(back, "nb", "Velkommen tilbake til applikasjonen")
+
+	s, err := f.m.Suggest("org-a", welcome.ID, "nb")
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, s.Suggestions, 0)
+
+	// Updating the source makes it similar
+	en := f.value(back, "en", "Welcome back to the application")
+	testza.AssertEqual(t, 3, f.m.Size(), "An update replaces the value")
+	s, err = f.m.Suggest("org-a", welcome.ID, "nb")
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, s.Suggestions, 1)
+
+	nb.Value = "Velkommen tilbake"
+	f.m.Publish(string(types.PubTypeTranslationValue), string(types.PubVerbUpdate), &nb)
+	s, err = f.m.Suggest("org-a", welcome.ID, "nb")
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, s.Suggestions, 1)
+	testza.AssertEqual(t, "Velkommen tilbake", s.Suggestions[0].Value, "Pointers are accepted too")
+
+	// Other kinds of events are ignored
+	en.Value = "Welcome to the application"
+	f.m.Publish(string(types.PubTypeTranslation), string(types.PubVerbUpdate), en)
+	testza.AssertEqual(t, 3, f.m.Size())
+
+	deleted := time.Now()
+	en.Deleted = &deleted
+	f.m.Publish(string(types.PubTypeTranslationValue), string(types.PubVerbSoftDelete), en)
+	testza.AssertEqual(t, 2, f.m.Size())
+	s, err = f.m.Suggest("org-a", welcome.ID, "nb")
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, s.Suggestions, 0, "Deleted values are not suggested")
+
+	// Cleaning the database rebuilds the memory from it, and these values only exist in the memory
+	f.m.Publish(string(types.PubTypeTranslationValue), string(types.PubVerbClean), nil)
+	_, err = f.m.Suggest("org-a", welcome.ID, "nb")
+	testza.AssertErrorIs(t, err, ErrNoSource)
+	testza.AssertEqual(t, 0, f.m.Size())
+}
+
+func TestSuggestIsIsolatedToOrganization(t *testing.T) {
+	f := newMemoryFixture(t)
+	welcome := f.translation("org-a", "Welcome")
+	f.value(welcome, "en", "Welcome to the application")
+
+	// An identical translation in another organization
+	other := f.translation("org-b", "Welcome")
+	f.value(other, "en", "Welcome to the application")
+	f.value(other, "nb", "Velkommen til applikasjonen")
+	testza.AssertEqual(t, 3, f.m.Size())
+
+	s, err := f.m.Suggest("org-a", welcome.ID, "nb")
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, s.Suggestions, 0, "Values from other organizations must never be suggested")
+
+	// Nor can a translation from another organization be used as source
+	_, err = f.m.Suggest("org-a", other.ID, "de")
+	testza.AssertErrorIs(t, err, ErrNoSource)
+
+	s, err = f.m.Suggest("org-b", other.ID, "de")
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, s.Suggestions, 0)
+}
@@ -0,0 +1,114 @@
+package types
+
+import "time"
+
+// APIKeyScope is an action that an api-key can be used for
+type APIKeyScope string
+
+const (
+	// Allows exporting translations
+	APIKeyScopeExport APIKeyScope = "export"
+	// Allows importing translations
+	APIKeyScopeImport APIKeyScope = "import"
+	// Allows reporting missing translations
+	APIKeyScopeReportMissing APIKeyScope = "report-missing"
+)
+
+var APIKeyScopes = []APIKeyScope{
+	APIKeyScopeExport,
+	APIKeyScopeImport,
+	APIKeyScopeReportMissing,
+}
+
+func (s APIKeyScope) Valid() bool {
+	for _, v := range APIKeyScopes {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// An APIKey is a named, long-lived credential for use in integrations, like CI-pipelines.
+// It acts on behalf of the user who created it, but is limited to its scopes and projects.
+// The key itself is only returned when it is created. Only a hash of it is stored.
+// Revoked keys are soft-deleted.
+
+// swagger:model APIKey
+type APIKey struct {
+	Entity
+	// A name to recognize the key by, like the name of the pipeline it is used in
+	Name string `json:"name"`
+	// The actions that the key can be used for
+	Scopes []APIKeyScope `json:"scopes"`
+	// The projects that the key can be used with. If empty, all projects within the organization are allowed.
+	ProjectIDs []string `json:"project_ids,omitempty"`
+	// If set, the key cannot be used after this time
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// The last time the key was used, with a precision of about a minute
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	// The sha256-hash of the secret part of the key
+	Hash []byte `json:"-"`
+}
+
+func (e APIKey) Namespace() string {
+	return e.Kind()
+}
+func (e APIKey) Kind() string {
+	return string(PubTypeAPIKey)
+}
+
+// Returns true if the key has been revoked, or has expired
+func (k APIKey) Invalid(now time.Time) bool {
+	return k.Deleted != nil || (k.ExpiresAt != nil && now.After(*k.ExpiresAt))
+}
+
+// Allows returns true if the key can be used for the action
+func (k APIKey) Allows(scope APIKeyScope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsProject returns true if the key can be used with the project
+func (k APIKey) AllowsProject(projectID string) bool {
+	if len(k.ProjectIDs) == 0 {
+		return true
+	}
+	for _, id := range k.ProjectIDs {
+		if id == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAction returns true if the scopes of the key allow the action.
+// Exports and reports of missing translations are not actions that require authorization.
+func (k APIKey) AllowsAction(a Action) bool {
+	switch a {
+	case ActionCreateTranslations, ActionEditTranslationValues:
+		return k.Allows(APIKeyScopeImport)
+	}
+	return false
+}
+
+// RestrictUser returns the user with only the permissions that are needed for the scopes of the key,
+// and that the user already has.
+// The roles of the user are kept for imports, since they may allow creating translations within some projects.
+func (k APIKey) RestrictUser(u User) User {
+	restricted := User{
+		Entity:   u.Entity,
+		UserName: u.UserName,
+		Active:   u.Active,
+		Store:    u.Store,
+	}
+	if k.Allows(APIKeyScopeImport) {
+		restricted.CanCreateTranslations = u.CanCreateTranslations
+		restricted.Roles = u.Roles
+	}
+	return restricted
+}
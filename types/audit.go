@@ -0,0 +1,60 @@
+package types
+
+import (
+	"time"
+
+	"github.com/r3labs/diff/v2"
+)
+
+// AuditEntry is a record of a single change to an entity
+// swagger:model AuditEntry
+type AuditEntry struct {
+	// Incrementing identifier, the newest entry has the highest id
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	// The user that made the change, if known
+	UserID         string `json:"user_id,omitempty"`
+	OrganizationID string `json:"organization_id,omitempty"`
+	// The kind of the changed entity, like translation or project
+	EntityKind string `json:"entity_kind"`
+	EntityID   string `json:"entity_id,omitempty"`
+	// The kind of change, like create, update or soft-delete
+	Verb string `json:"verb"`
+	// Field-level changes, compared to the previous version of the entity
+	Changes diff.Changelog `json:"changes,omitempty"`
+}
+
+type AuditFilter struct {
+	OrganizationID string
+	// Matches either the id or the kind of the entity
+	Entity string
+	UserID string
+	// If set, only entries created at or after this time are included
+	Since time.Time
+}
+
+func (f AuditFilter) Matches(e AuditEntry) bool {
+	if f.OrganizationID != "" && f.OrganizationID != e.OrganizationID {
+		return false
+	}
+	if f.Entity != "" && f.Entity != e.EntityID && f.Entity != e.EntityKind {
+		return false
+	}
+	if f.UserID != "" && f.UserID != e.UserID {
+		return false
+	}
+	if !f.Since.IsZero() && e.CreatedAt.Before(f.Since) {
+		return false
+	}
+	return true
+}
+
+// GetEntity returns the entity itself.
+// Used to get at the embedded entity of any of the stored types.
+func (e Entity) GetEntity() Entity {
+	return e
+}
+
+type EntityGetter interface {
+	GetEntity() Entity
+}
@@ -142,11 +142,12 @@ func (p Project) Extend(db Storage, options ...ExtendOptions) (ep ExtendedProjec
 	outer:
 		for k, v := range locales {
 			for _, key := range opts.LocaleFilter {
-				if key == k || v.IETF == key || v.Iso639_3 == key || v.Iso639_2 == key || v.Iso639_1 == key {
+				if key == k || v.Matches(key) {
 					continue outer
 				}
-				delete(locales, k)
 			}
+			// Only locales that do not match any of the filters are removed
+			delete(locales, k)
 		}
 	}
 	if opts.LocaleFilterFunc != nil {
@@ -0,0 +1,25 @@
+package types
+
+// A GlossaryEntry is a term within the organization's glossary, with its approved translations.
+// The glossary is used to keep terminology consistent across all projects within the organization.
+
+// swagger:model GlossaryEntry
+type GlossaryEntry struct {
+	Entity
+	// The term, as written in the source-language
+	Term        string `json:"term"`
+	Description string `json:"description,omitempty"`
+	// The approved translations of the term, by locale-id
+	Translations map[string]string `json:"translations,omitempty"`
+	// If set, the term should be kept as is in all locales, like product-names
+	DoNotTranslate bool `json:"do_not_translate,omitempty"`
+	// If set, the term, and its translations, only match with the same casing
+	CaseSensitive bool `json:"case_sensitive,omitempty"`
+}
+
+func (e GlossaryEntry) Namespace() string {
+	return e.Kind()
+}
+func (e GlossaryEntry) Kind() string {
+	return string(PubTypeGlossary)
+}
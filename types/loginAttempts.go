@@ -0,0 +1,44 @@
+package types
+
+import (
+	"time"
+)
+
+type LoginAttemptsKind string
+
+const (
+	LoginAttemptsUser LoginAttemptsKind = "user"
+	LoginAttemptsIP   LoginAttemptsKind = "ip"
+)
+
+// LoginAttempts tracks the failed logins for a username, or from an ip, so that further logins can be throttled.
+// swagger:model
+type LoginAttempts struct {
+	// Identifies the attempts, like user:jane or ip:127.0.0.1
+	Key  string            `json:"key"`
+	Kind LoginAttemptsKind `json:"kind"`
+	// The username or ip
+	Value string `json:"value"`
+	// Set if the username belongs to a user
+	UserID         string `json:"user_id,omitempty"`
+	OrganizationID string `json:"organization_id,omitempty"`
+	// Failed logins since the last successful login, or since the last lockout
+	Failures int `json:"failures"`
+	// Lockouts since the last successful login. Each lockout lasts twice as long as the previous
+	Lockouts    int       `json:"lockouts"`
+	LastFailure time.Time `json:"last_failure"`
+	// Logins are refused until this time
+	NextAttempt time.Time `json:"next_attempt"`
+	// Set if logins are refused because of too many failed logins, until an admin unlocks it, or the time has passed
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+}
+
+// Locked returns true if logins are locked at the time
+func (a LoginAttempts) Locked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
+
+// Empty returns true if there is nothing to track, so that the attempts can be removed
+func (a LoginAttempts) Empty() bool {
+	return a.Failures == 0 && a.Lockouts == 0 && a.LockedUntil == nil
+}
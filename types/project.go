@@ -15,6 +15,24 @@ type Project struct {
 	CategoryIDs  []string                       `json:"category_ids,omitempty"`
 	LocaleIDs    map[string]LocaleSetting       `json:"locales,omitempty"`
 	Snapshots    map[string]ProjectSnapshotMeta `json:"snapshots,omitempty" diff:"-"`
+	// Settings for the lint-rules, by the rule-id. Rules without settings use their defaults.
+	LintRules map[string]LintRuleSetting `json:"lint_rules,omitempty"`
+	// If enabled, snapshots are refused while the project has translations that would break the release.
+	ReleaseGate *ReleaseGateSetting `json:"release_gate,omitempty"`
+}
+
+type ReleaseGateSetting struct {
+	// If set, snapshots are refused if any published locale has values that are missing or empty,
+	// or if the variables differ between the locales.
+	// Users that can manage snapshots may still force the snapshot.
+	Enabled bool `json:"enabled"`
+}
+
+type LintRuleSetting struct {
+	// If set, the rule will not be checked for this project.
+	Disabled bool `json:"disabled"`
+	// Overrides the default severity of the rule, and must be one of `info`, `warning` or `error`.
+	Severity string `json:"severity,omitempty"`
 }
 
 type ProjectSnapshotMeta struct {
@@ -13,6 +13,9 @@ const (
 	PubTypeLocale             PubType = "locale"
 	PubTypeProject            PubType = "project"
 	PubTypeOrganization       PubType = "organization"
+	PubTypeGlossary           PubType = "glossary"
+	PubTypeWebhook            PubType = "webhook"
+	PubTypeAPIKey             PubType = "apiKey"
 
 	PubVerbCreate PubVerb = "create"
 	PubVerbUpdate PubVerb = "update"
@@ -22,3 +25,28 @@ const (
 	PubVerbClean       PubVerb = "clean"
 	PubVerbConnectItem PubVerb = "connect"
 )
+
+// All the kinds of entities that changes are published for
+var PubTypes = []PubType{
+	PubTypeUser,
+	PubTypeTranslation,
+	PubTypeMissingTranslation,
+	PubTypeTranslationValue,
+	PubTypeCategory,
+	PubTypeSnapshot,
+	PubTypeLocale,
+	PubTypeProject,
+	PubTypeOrganization,
+	PubTypeGlossary,
+	PubTypeWebhook,
+	PubTypeAPIKey,
+}
+
+// All the kinds of changes that are published
+var PubVerbs = []PubVerb{
+	PubVerbCreate,
+	PubVerbUpdate,
+	PubVerbSoftDelete,
+	PubVerbClean,
+	PubVerbConnectItem,
+}
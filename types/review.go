@@ -0,0 +1,113 @@
+package types
+
+import "time"
+
+// The workflow-state of a TranslationValue
+type TranslationValueState string
+
+const (
+	// The value is being worked on, and is not ready for review
+	StateDraft TranslationValueState = "draft"
+	// The value is ready, but must be reviewed before it is approved.
+	// This is the state of new values, and values with changed content, whatever their source,
+	// unless they are approved by a reviewer, like when a reviewer imports them.
+	StateNeedsReview TranslationValueState = "needs-review"
+	StateApproved    TranslationValueState = "approved"
+	StateRejected    TranslationValueState = "rejected"
+)
+
+var TranslationValueStates = []TranslationValueState{StateDraft, StateNeedsReview, StateApproved, StateRejected}
+
+func (s TranslationValueState) Valid() bool {
+	for _, v := range TranslationValueStates {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if only reviewers may set the state
+func (s TranslationValueState) RequiresReviewer() bool {
+	return s == StateApproved || s == StateRejected
+}
+
+// A TranslationValueReview is a change of the workflow-state of a value
+type TranslationValueReview struct {
+	State TranslationValueState `json:"state"`
+	// User id refering to the user who changed the state
+	By      string    `json:"by"`
+	At      time.Time `json:"at"`
+	Comment string    `json:"comment,omitempty"`
+}
+
+// ApprovedRevision is the content of a value, as it was when it was last approved
+type ApprovedRevision struct {
+	Value      string            `json:"value,omitempty"`
+	Context    map[string]string `json:"context,omitempty"`
+	ApprovedAt time.Time         `json:"approved_at"`
+	// User id refering to the user who approved the value
+	ApprovedBy string `json:"approved_by,omitempty"`
+}
+
+// EffectiveState returns the workflow-state of the value.
+// Values created before the workflow was introduced do not have a state, and are considered approved.
+func (tv TranslationValue) EffectiveState() TranslationValueState {
+	if tv.State == "" {
+		return StateApproved
+	}
+	return tv.State
+}
+
+// Approved returns the value as it was when it was last approved.
+// If the value is approved, it is returned as is.
+// The second return-value is false if the value has never been approved.
+func (tv TranslationValue) Approved() (TranslationValue, bool) {
+	if tv.EffectiveState() == StateApproved {
+		return tv, true
+	}
+	if tv.LastApproved == nil {
+		return tv, false
+	}
+	tv.Value = tv.LastApproved.Value
+	tv.Context = tv.LastApproved.Context
+	return tv, true
+}
+
+// Returns the current content of the value, as an approved revision
+func (tv TranslationValue) approvedRevision(at time.Time, by string) *ApprovedRevision {
+	r := ApprovedRevision{Value: tv.Value, ApprovedAt: at, ApprovedBy: by}
+	if len(tv.Context) > 0 {
+		r.Context = make(map[string]string, len(tv.Context))
+		for k, v := range tv.Context {
+			r.Context[k] = v
+		}
+	}
+	return &r
+}
+
+// SetState changes the workflow-state of the value.
+// When the value becomes approved, its content is kept as the last approved revision.
+func (tv *TranslationValue) SetState(review TranslationValueReview) {
+	tv.State = review.State
+	tv.Review = &review
+	if review.State == StateApproved {
+		tv.LastApproved = tv.approvedRevision(review.At, review.By)
+	}
+}
+
+// ContentChanged should be called before the content of a value is changed, and sets the state of the changed value.
+// If the value is approved, its current content is kept as the last approved revision.
+func (tv *TranslationValue) ContentChanged(state TranslationValueState) {
+	if tv.EffectiveState() == StateApproved {
+		switch {
+		case tv.Review != nil && tv.Review.State == StateApproved:
+			tv.LastApproved = tv.approvedRevision(tv.Review.At, tv.Review.By)
+		case tv.UpdatedAt != nil:
+			tv.LastApproved = tv.approvedRevision(*tv.UpdatedAt, tv.UpdatedBy)
+		default:
+			tv.LastApproved = tv.approvedRevision(tv.CreatedAt, tv.CreatedBy)
+		}
+	}
+	tv.State = state
+}
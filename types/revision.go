@@ -0,0 +1,34 @@
+package types
+
+import "time"
+
+// Revision is a copy of a Translation or TranslationValue, as it was stored after a change.
+// swagger:model Revision
+type Revision struct {
+	// The id of the changed entity
+	EntityID string `json:"entity_id"`
+	// The kind of the changed entity
+	EntityKind string `json:"entity_kind"`
+	// Incrementing number for the entity, starting at 1
+	Revision int `json:"revision"`
+	// The kind of change, e.g. create, update, soft-delete or revert
+	Verb      string    `json:"verb"`
+	CreatedAt time.Time `json:"created_at"`
+	// The user that made the change
+	CreatedBy string `json:"created_by,omitempty"`
+	// Indicating from where the change came from, usually user, but could be a tranlator-service, like Bing.
+	Source CreatorSource `json:"source,omitempty"`
+	// Set if the change was a revert to an earlier revision
+	RevertedFrom     int               `json:"reverted_from,omitempty"`
+	TranslationValue *TranslationValue `json:"translation_value,omitempty"`
+	Translation      *Translation      `json:"translation,omitempty"`
+}
+
+// RevisionRetention limits how many revisions are kept for each entity.
+// The newest revision is always kept.
+type RevisionRetention struct {
+	// Maximum number of revisions per entity. Zero means no limit.
+	MaxCount int
+	// Revisions older than this are removed. Zero means no limit.
+	MaxAge time.Duration
+}
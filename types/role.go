@@ -0,0 +1,289 @@
+package types
+
+// A Role is a named set of actions, that can be assigned to a user for the whole organization,
+// or for a single project, optionally limited to some locales.
+type Role string
+
+const (
+	// Can do anything within the project. If assigned for the whole organization,
+	// also administers the organization, its users, projects and locales.
+	RoleAdmin Role = "admin"
+	// Can manage translations, categories and snapshots
+	RoleDeveloper Role = "developer"
+	// Can create and update translation-values
+	RoleTranslator Role = "translator"
+	// Can approve and reject translation-values
+	RoleReviewer Role = "reviewer"
+	// Can only view, which all users within the organization can
+	RoleViewer Role = "viewer"
+)
+
+var Roles = []Role{
+	RoleAdmin,
+	RoleDeveloper,
+	RoleTranslator,
+	RoleReviewer,
+	RoleViewer,
+}
+
+func (r Role) Valid() bool {
+	for _, v := range Roles {
+		if v == r {
+			return true
+		}
+	}
+	return false
+}
+
+// An Action is something a user may be authorized to do
+type Action string
+
+const (
+	// Organization-level actions. These are only granted by roles assigned for the whole organization.
+	ActionCreateOrganization Action = "create organizations"
+	ActionUpdateOrganization Action = "update the organization"
+	ActionCreateUsers        Action = "create users"
+	ActionUpdateUsers        Action = "manage users"
+	ActionCreateProjects     Action = "create projects"
+	ActionCreateLocales      Action = "create locales"
+	ActionUpdateGlossary     Action = "update the glossary"
+
+	// Project-level actions
+	ActionUpdateProjects     Action = "update projects"
+	ActionCreateSnapshots    Action = "create snapshots"
+	ActionManageSnapshots    Action = "force snapshots"
+	ActionCreateTranslations Action = "create translations"
+	ActionUpdateTranslations Action = "update translations"
+
+	// Locale-level actions. Roles limited to some locales only grant these for those locales.
+	ActionEditTranslationValues   Action = "edit translation-values"
+	ActionReviewTranslationValues Action = "review translation-values"
+)
+
+var Actions = []Action{
+	ActionCreateOrganization,
+	ActionUpdateOrganization,
+	ActionCreateUsers,
+	ActionUpdateUsers,
+	ActionCreateProjects,
+	ActionCreateLocales,
+	ActionUpdateGlossary,
+	ActionUpdateProjects,
+	ActionCreateSnapshots,
+	ActionManageSnapshots,
+	ActionCreateTranslations,
+	ActionUpdateTranslations,
+	ActionEditTranslationValues,
+	ActionReviewTranslationValues,
+}
+
+// Returns true if the action applies to the organization as a whole, and not to a single project
+func (a Action) OrganizationLevel() bool {
+	switch a {
+	case ActionCreateOrganization,
+		ActionUpdateOrganization,
+		ActionCreateUsers,
+		ActionUpdateUsers,
+		ActionCreateProjects,
+		ActionCreateLocales,
+		ActionUpdateGlossary:
+		return true
+	}
+	return false
+}
+
+// Returns true if the action applies to a single locale within a project
+func (a Action) LocaleLevel() bool {
+	return a == ActionEditTranslationValues || a == ActionReviewTranslationValues
+}
+
+// Grants returns true if the role includes the action
+func (r Role) Grants(a Action) bool {
+	switch r {
+	case RoleAdmin:
+		return a != ActionCreateOrganization
+	case RoleDeveloper:
+		switch a {
+		case ActionCreateTranslations,
+			ActionUpdateTranslations,
+			ActionEditTranslationValues,
+			ActionCreateSnapshots:
+			return true
+		}
+	case RoleTranslator:
+		return a == ActionEditTranslationValues
+	case RoleReviewer:
+		return a == ActionReviewTranslationValues
+	}
+	return false
+}
+
+// A RoleAssignment gives a user a role for the whole organization, or for a single project.
+// swagger:model RoleAssignment
+type RoleAssignment struct {
+	Role Role `json:"role"`
+	// If set, the role only applies to this project.
+	ProjectID string `json:"project_id,omitempty"`
+	// If set, the role only applies to translation-values of these locales.
+	LocaleIDs []string `json:"locale_ids,omitempty"`
+}
+
+// AccessScope is what an action is performed on.
+// An empty ProjectID or LocaleID matches any project or locale. This is used to check
+// whether a user can perform the action at all, before the project or locale is known.
+type AccessScope struct {
+	ProjectID string
+	LocaleID  string
+}
+
+// Allows returns true if the assignment grants the action within the scope
+func (ra RoleAssignment) Allows(a Action, scope AccessScope) bool {
+	if !ra.Role.Grants(a) {
+		return false
+	}
+	if a.OrganizationLevel() {
+		return ra.ProjectID == ""
+	}
+	if ra.ProjectID != "" && scope.ProjectID != "" && ra.ProjectID != scope.ProjectID {
+		return false
+	}
+	if !a.LocaleLevel() || len(ra.LocaleIDs) == 0 || scope.LocaleID == "" {
+		return true
+	}
+	for _, id := range ra.LocaleIDs {
+		if id == scope.LocaleID {
+			return true
+		}
+	}
+	return false
+}
+
+// Can returns true if the user can perform the action within the scope,
+// either through its permissions for the whole organization, or through one of its roles.
+func (u User) Can(a Action, scope AccessScope) bool {
+	if u.hasPermission(a) {
+		return true
+	}
+	for _, ra := range u.Roles {
+		if ra.Allows(a, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanView returns true if the user has a role for the project, or the whole organization, including the viewer-role,
+// or any of the permissions for the whole organization.
+func (u User) CanView(projectID string) bool {
+	for _, a := range Actions {
+		if u.hasPermission(a) {
+			return true
+		}
+	}
+	for _, ra := range u.Roles {
+		if ra.ProjectID == "" || ra.ProjectID == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// CanGrant returns true if the user can perform the action everywhere the assignment applies.
+// Project-roles do not grant organization-level actions, and roles limited to some locales
+// only cover assignments limited to those locales, or fewer.
+func (u User) CanGrant(a Action, ra RoleAssignment) bool {
+	if u.hasPermission(a) {
+		return true
+	}
+	for _, own := range u.Roles {
+		if own.covers(a, ra) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the assignment grants the action everywhere the other assignment applies
+func (ra RoleAssignment) covers(a Action, other RoleAssignment) bool {
+	if !ra.Role.Grants(a) {
+		return false
+	}
+	if ra.ProjectID != "" && ra.ProjectID != other.ProjectID {
+		return false
+	}
+	if a.OrganizationLevel() && ra.ProjectID != "" {
+		return false
+	}
+	if !a.LocaleLevel() || len(ra.LocaleIDs) == 0 {
+		return true
+	}
+	if len(other.LocaleIDs) == 0 {
+		return false
+	}
+	for _, id := range other.LocaleIDs {
+		found := false
+		for _, own := range ra.LocaleIDs {
+			if own == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Actions returns the actions that the assignment grants. Project-roles do not grant organization-level actions.
+func (ra RoleAssignment) Actions() []Action {
+	var actions []Action
+	for _, a := range Actions {
+		if ra.Role.Grants(a) && (ra.ProjectID == "" || !a.OrganizationLevel()) {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+// PermissionActions returns the actions that the permissions of the user grant, which apply to the whole organization.
+func (u User) PermissionActions() []Action {
+	var actions []Action
+	for _, a := range Actions {
+		if u.hasPermission(a) {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+// The permissions that predate roles, which apply to the whole organization
+func (u User) hasPermission(a Action) bool {
+	switch a {
+	case ActionCreateOrganization:
+		return u.CanCreateOrganization
+	case ActionUpdateOrganization:
+		return u.CanUpdateOrganization
+	case ActionCreateUsers:
+		return u.CanCreateUsers
+	case ActionUpdateUsers:
+		return u.CanUpdateUsers
+	case ActionCreateProjects:
+		return u.CanCreateProjects
+	case ActionCreateLocales:
+		return u.CanCreateLocales || u.CanCreateTranslations
+	case ActionUpdateGlossary, ActionUpdateProjects, ActionCreateSnapshots:
+		return u.CanUpdateProjects
+	case ActionManageSnapshots:
+		return u.CanManageSnapshots
+	case ActionCreateTranslations:
+		return u.CanCreateTranslations
+	case ActionUpdateTranslations:
+		return u.CanUpdateTranslations
+	case ActionEditTranslationValues:
+		return u.CanCreateTranslations || u.CanUpdateTranslations
+	case ActionReviewTranslationValues:
+		return u.CanReviewTranslations
+	}
+	return false
+}
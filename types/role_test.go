@@ -0,0 +1,125 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestUserCan(t *testing.T) {
+	swedishTranslator := User{Roles: []RoleAssignment{{Role: RoleTranslator, ProjectID: "x", LocaleIDs: []string{"sv"}}}}
+	projectAdmin := User{Roles: []RoleAssignment{{Role: RoleAdmin, ProjectID: "x"}}}
+	tests := []struct {
+		name   string
+		user   User
+		action Action
+		scope  AccessScope
+		want   bool
+	}{
+		{"Translator can edit values of its locale", swedishTranslator, ActionEditTranslationValues, AccessScope{"x", "sv"}, true},
+		{"Translator cannot edit values of other locales", swedishTranslator, ActionEditTranslationValues, AccessScope{"x", "no"}, false},
+		{"Translator cannot edit values in other projects", swedishTranslator, ActionEditTranslationValues, AccessScope{"y", "sv"}, false},
+		{"Translator can edit values before the project is known", swedishTranslator, ActionEditTranslationValues, AccessScope{}, true},
+		{"Translator cannot update translations", swedishTranslator, ActionUpdateTranslations, AccessScope{"x", ""}, false},
+		{"Translator cannot review", swedishTranslator, ActionReviewTranslationValues, AccessScope{"x", "sv"}, false},
+		{"Project-admin can update its project", projectAdmin, ActionUpdateProjects, AccessScope{"x", ""}, true},
+		{"Project-admin cannot update other projects", projectAdmin, ActionUpdateProjects, AccessScope{"y", ""}, false},
+		{"Project-admin cannot manage users", projectAdmin, ActionUpdateUsers, AccessScope{}, false},
+		{"Organization-admin can manage users", User{Roles: []RoleAssignment{{Role: RoleAdmin}}}, ActionUpdateUsers, AccessScope{}, true},
+		{"Organization-admin cannot create organizations", User{Roles: []RoleAssignment{{Role: RoleAdmin}}}, ActionCreateOrganization, AccessScope{}, false},
+		{"Reviewer can review its locale", User{Roles: []RoleAssignment{{Role: RoleReviewer, LocaleIDs: []string{"sv"}}}}, ActionReviewTranslationValues, AccessScope{"x", "sv"}, true},
+		{"Viewer cannot edit", User{Roles: []RoleAssignment{{Role: RoleViewer}}}, ActionEditTranslationValues, AccessScope{"x", "sv"}, false},
+		{"Permissions apply to all projects", User{CanUpdateTranslations: true}, ActionUpdateTranslations, AccessScope{"y", ""}, true},
+		{"Permissions apply to all locales", User{CanCreateTranslations: true}, ActionEditTranslationValues, AccessScope{"y", "no"}, true},
+		{"No permissions or roles", User{}, ActionEditTranslationValues, AccessScope{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.user.Can(tt.action, tt.scope); got != tt.want {
+				t.Errorf("User.Can(%s, %v) = %v, want %v", tt.action, tt.scope, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserCanView(t *testing.T) {
+	tests := []struct {
+		name      string
+		user      User
+		projectID string
+		want      bool
+	}{
+		{"Viewer of the organization can view all projects", User{Roles: []RoleAssignment{{Role: RoleViewer}}}, "x", true},
+		{"Viewer of a project can view the project", User{Roles: []RoleAssignment{{Role: RoleViewer, ProjectID: "x"}}}, "x", true},
+		{"Translator of a project cannot view other projects", User{Roles: []RoleAssignment{{Role: RoleTranslator, ProjectID: "x"}}}, "y", false},
+		{"Permissions apply to all projects", User{CanCreateTranslations: true}, "y", true},
+		{"No permissions or roles", User{}, "x", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.user.CanView(tt.projectID); got != tt.want {
+				t.Errorf("User.CanView(%s) = %v, want %v", tt.projectID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserCanGrant(t *testing.T) {
+	swedishTranslator := User{Roles: []RoleAssignment{{Role: RoleTranslator, ProjectID: "x", LocaleIDs: []string{"sv"}}}}
+	projectAdmin := User{Roles: []RoleAssignment{{Role: RoleAdmin, ProjectID: "x"}}}
+	orgAdmin := User{Roles: []RoleAssignment{{Role: RoleAdmin}}}
+	tests := []struct {
+		name   string
+		user   User
+		action Action
+		ra     RoleAssignment
+		want   bool
+	}{
+		{"Translator can grant its own locale", swedishTranslator, ActionEditTranslationValues, RoleAssignment{ProjectID: "x", LocaleIDs: []string{"sv"}}, true},
+		{"Translator cannot grant all locales", swedishTranslator, ActionEditTranslationValues, RoleAssignment{ProjectID: "x"}, false},
+		{"Translator cannot grant other locales", swedishTranslator, ActionEditTranslationValues, RoleAssignment{ProjectID: "x", LocaleIDs: []string{"sv", "no"}}, false},
+		{"Translator cannot grant the whole organization", swedishTranslator, ActionEditTranslationValues, RoleAssignment{LocaleIDs: []string{"sv"}}, false},
+		{"Project-admin can grant its project", projectAdmin, ActionUpdateProjects, RoleAssignment{ProjectID: "x"}, true},
+		{"Project-admin cannot grant other projects", projectAdmin, ActionUpdateProjects, RoleAssignment{ProjectID: "y"}, false},
+		{"Project-admin cannot grant the whole organization", projectAdmin, ActionUpdateProjects, RoleAssignment{}, false},
+		{"Organization-admin can grant the whole organization", orgAdmin, ActionUpdateUsers, RoleAssignment{}, true},
+		{"Organization-admin cannot grant creating organizations", orgAdmin, ActionCreateOrganization, RoleAssignment{}, false},
+		{"Permissions cover the whole organization", User{CanUpdateProjects: true}, ActionUpdateProjects, RoleAssignment{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.user.CanGrant(tt.action, tt.ra); got != tt.want {
+				t.Errorf("User.CanGrant(%s, %v) = %v, want %v", tt.action, tt.ra, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserPermissionActions(t *testing.T) {
+	got := User{CanUpdateProjects: true}.PermissionActions()
+	want := []Action{ActionUpdateProjects, ActionUpdateGlossary, ActionCreateSnapshots}
+	if len(got) != len(want) {
+		t.Fatalf("User.PermissionActions() = %v, want %v", got, want)
+	}
+	for _, a := range want {
+		found := false
+		for _, g := range got {
+			found = found || g == a
+		}
+		if !found {
+			t.Errorf("User.PermissionActions() = %v, missing %s", got, a)
+		}
+	}
+}
+
+func TestRoleAssignmentActions(t *testing.T) {
+	for _, a := range (RoleAssignment{Role: RoleAdmin, ProjectID: "x"}).Actions() {
+		if a.OrganizationLevel() {
+			t.Errorf("Project-roles should not grant organization-level actions, got %s", a)
+		}
+	}
+	if got := len((RoleAssignment{Role: RoleAdmin}).Actions()); got != len(Actions)-1 {
+		t.Errorf("Organization-admin should grant every action except creating organizations, got %d actions", got)
+	}
+	if got := (RoleAssignment{Role: RoleViewer}).Actions(); len(got) != 0 {
+		t.Errorf("Viewers should not grant any actions, got %v", got)
+	}
+}
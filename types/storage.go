@@ -62,18 +62,56 @@ type Storage interface {
 	CreateTranslationValue(translationValue TranslationValue) (TranslationValue, error)
 	// TODO: this should take in a id as first parameter
 	UpdateTranslationValue(tv TranslationValue) (TranslationValue, error)
+	SetTranslationValueState(id string, review TranslationValueReview) (TranslationValue, error)
 	GetTranslationValues() (map[string]TranslationValue, error)
 	GetTranslationValueFilter(filter ...TranslationValue) (*TranslationValue, error)
 	GetTranslationValuesFilter(max int, filter ...TranslationValue) (map[string]TranslationValue, error)
+	RevertTranslationValue(id string, revision int, byUser string) (TranslationValue, error)
+
+	// Revisions are kept for changes to translations and translation-values, oldest first.
+	GetRevisions(entityID string) ([]Revision, error)
+	GetRevision(entityID string, revision int) (*Revision, error)
+
+	// Calls f for each entry in the audit-log that matches the filter, newest first.
+	// Returning true from f stops the iteration.
+	IterateAuditEntries(filter AuditFilter, f func(entry AuditEntry) bool) error
+
+	GetWebhook(id string) (*Webhook, error)
+	FindWebhooks(max int, filter ...Webhook) (map[string]Webhook, error)
+	CreateWebhook(webhook Webhook) (Webhook, error)
+	UpdateWebhook(id string, payload Webhook) (Webhook, error)
+	SoftDeleteWebhook(id string, byUser string, deleteDate *time.Time) (Webhook, error)
+	GetWebhookDelivery(id string) (*WebhookDelivery, error)
+	// Returns the deliveries of the webhook, newest first
+	FindWebhookDeliveries(webhookID string, max int) ([]WebhookDelivery, error)
+	SaveWebhookDelivery(delivery WebhookDelivery) (WebhookDelivery, error)
+	// Returns the pending deliveries that are due for an attempt at the time, oldest first
+	FindDueWebhookDeliveries(at time.Time) ([]WebhookDelivery, error)
+
+	GetAPIKey(id string) (*APIKey, error)
+	FindAPIKeys(max int, filter ...APIKey) (map[string]APIKey, error)
+	CreateAPIKey(key APIKey) (APIKey, error)
+	// Revokes the key
+	SoftDeleteAPIKey(id string, byUser string, deleteDate *time.Time) (APIKey, error)
+	// Records that the key was used. This is not published as a change.
+	SetAPIKeyLastUsed(id string, at time.Time) error
 
 	ReportMissing(key MissingTranslation) (*MissingTranslation, error)
 	GetMissingKeysFilter(max int, filter ...MissingTranslation) (map[string]MissingTranslation, error)
 	UpdateUser(id string, payload UpdateUserPayload) (User, error)
+	UpdateUserMFA(id string, f func(mfa *UserMFA) error) error
+	SoftDeleteUser(id string, byUser string, deleteDate *time.Time) (User, error)
 
 	GetSnapshot(snapshotId string) (*ProjectSnapshot, error)
 	FindSnapshots(max int, filter ...ProjectSnapshot) (map[string]ProjectSnapshot, error)
 	CreateSnapshot(snapshot ProjectSnapshot) (ProjectSnapshot, error)
 	FindOneSnapshot(filter ...ProjectSnapshot) (*ProjectSnapshot, error)
+
+	GetGlossaryEntry(id string) (*GlossaryEntry, error)
+	FindGlossaryEntries(max int, filter ...GlossaryEntry) (map[string]GlossaryEntry, error)
+	CreateGlossaryEntry(entry GlossaryEntry) (GlossaryEntry, error)
+	UpdateGlossaryEntry(id string, payload GlossaryEntry) (GlossaryEntry, error)
+	SoftDeleteGlossaryEntry(id string, byUser string, deleteDate *time.Time) (GlossaryEntry, error)
 }
 
 type State struct {
@@ -22,6 +22,8 @@ type Locale struct {
 // swagger:model Translation
 type Translation struct {
 	Entity
+	// Incremented on every change. Pass it back when updating, to detect changes made by others in the meantime.
+	Version             int                    `json:"version,omitempty"`
 	Aliases             []string               `json:"aliases,omitempty"`
 	ParentTranslationID string                 `json:"parent_translation,omitempty"`
 	Description         string                 `json:"description,omitempty"`
@@ -37,6 +39,8 @@ type Translation struct {
 // swagger:model TranslationValue
 type TranslationValue struct {
 	Entity
+	// Incremented on every change. Pass it back when updating, to detect changes made by others in the meantime.
+	Version int `json:"version,omitempty"`
 	// The pre-interpolated value to use  with translations
 	// Example: The {{productName}} fires up to {{count}} bullets of {{subject}}.
 	Value string `json:"value,omitempty"`
@@ -47,6 +51,13 @@ type TranslationValue struct {
 	// Indicating from where the value was created from, usually user, but could be a tranlator-service, like Bing.
 	Source  CreatorSource     `json:"source,omitempty"`
 	Context map[string]string `json:"context,omitempty"`
+	// The workflow-state of the value. Values without a state are considered approved.
+	State TranslationValueState `json:"state,omitempty"`
+	// The latest change of the workflow-state, with the reviewer and comment
+	Review *TranslationValueReview `json:"review,omitempty"`
+	// The content of the value when it was last approved.
+	// Exports of only approved values use this while the value is not approved.
+	LastApproved *ApprovedRevision `json:"last_approved,omitempty"`
 }
 
 func (e TranslationValue) Namespace() string {
@@ -61,6 +72,14 @@ func (e Translation) Namespace() string {
 func (e Translation) Kind() string {
 	return string(PubTypeTranslation)
 }
+
+// Matches returns true if the key is any of the locale's ID, IETF or ISO-639-codes
+func (l Locale) Matches(key string) bool {
+	if key == "" {
+		return false
+	}
+	return key == l.ID || key == l.IETF || key == l.Iso639_3 || key == l.Iso639_2 || key == l.Iso639_1
+}
 func (e Locale) Namespace() string {
 	return e.Kind()
 }
@@ -92,6 +111,8 @@ var (
 	CreatorSourceUser       CreatorSource = "user"
 	CreatorSourceTranslator CreatorSource = "system-translator"
 	CreatorSourceImport     CreatorSource = "user-import"
+	// Values imported from files returned from translation-agencies, like xliff
+	CreatorSourceTranslationAgency CreatorSource = "translation-agency"
 )
 
 // Locale represents a language, dialect etc.
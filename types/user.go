@@ -1,6 +1,7 @@
 package types
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -11,6 +12,8 @@ type User struct {
 	// If not active, the account cannot be used until any issues are resolved.
 	Active bool      `json:"active"`
 	Store  UserStore `json:"-"`
+	// For users that are not local, this identifies the user at the identity-provider
+	ExternalID string `json:"-"`
 	// If set, the user must change the password before the account can be used
 	TemporaryPassword bool   `json:"temporary_password,omitempty"`
 	PW                []byte `json:"-"`
@@ -27,12 +30,101 @@ type User struct {
 	CanManageSnapshots    bool `json:"can_manage_snapshots,omitempty"`
 	CanUpdateTranslations bool `json:"can_update_translations,omitempty"`
 	CanUpdateLocales      bool `json:"can_update_locales,omitempty"`
+	// Allows approving and rejecting translation-values
+	CanReviewTranslations bool `json:"can_review_translations,omitempty"`
+
+	// Roles for the whole organization, or for single projects and locales.
+	// These are in addition to the permissions above, which apply to the whole organization.
+	Roles []RoleAssignment `json:"roles,omitempty"`
+	// Multi-factor authentication, which local users can enroll in
+	MFA *UserMFA `json:"mfa,omitempty"`
+	// Set for users that have been invited, until they accept the invite, and choose a password
+	Invite *UserInvite `json:"invite,omitempty"`
+}
+
+// UserInvite is an invite-link for a single user, which lets the user choose a password
+type UserInvite struct {
+	Expires time.Time `json:"expires"`
+	// The sha256-hash of the secret part of the link
+	Hash []byte `json:"-"`
+}
+
+// UserPermissions are the permissions of a user that apply to the whole organization
+type UserPermissions struct {
+	CanCreateOrganization bool
+	CanCreateUsers        bool
+	CanCreateProjects     bool
+	CanCreateTranslations bool
+	CanCreateLocales      bool
+	CanUpdateOrganization bool
+	CanUpdateUsers        bool
+	CanUpdateProjects     bool
+	CanManageSnapshots    bool
+	CanUpdateTranslations bool
+	CanUpdateLocales      bool
+	CanReviewTranslations bool
+}
+
+func (u User) Permissions() UserPermissions {
+	return UserPermissions{
+		CanCreateOrganization: u.CanCreateOrganization,
+		CanCreateUsers:        u.CanCreateUsers,
+		CanCreateProjects:     u.CanCreateProjects,
+		CanCreateTranslations: u.CanCreateTranslations,
+		CanCreateLocales:      u.CanCreateLocales,
+		CanUpdateOrganization: u.CanUpdateOrganization,
+		CanUpdateUsers:        u.CanUpdateUsers,
+		CanUpdateProjects:     u.CanUpdateProjects,
+		CanManageSnapshots:    u.CanManageSnapshots,
+		CanUpdateTranslations: u.CanUpdateTranslations,
+		CanUpdateLocales:      u.CanUpdateLocales,
+		CanReviewTranslations: u.CanReviewTranslations,
+	}
+}
+
+func (u *User) SetPermissions(p UserPermissions) {
+	u.CanCreateOrganization = p.CanCreateOrganization
+	u.CanCreateUsers = p.CanCreateUsers
+	u.CanCreateProjects = p.CanCreateProjects
+	u.CanCreateTranslations = p.CanCreateTranslations
+	u.CanCreateLocales = p.CanCreateLocales
+	u.CanUpdateOrganization = p.CanUpdateOrganization
+	u.CanUpdateUsers = p.CanUpdateUsers
+	u.CanUpdateProjects = p.CanUpdateProjects
+	u.CanManageSnapshots = p.CanManageSnapshots
+	u.CanUpdateTranslations = p.CanUpdateTranslations
+	u.CanUpdateLocales = p.CanUpdateLocales
+	u.CanReviewTranslations = p.CanReviewTranslations
+}
+
+// UserMFA is the multi-factor authentication of a user, with time-based one-time passwords (TOTP)
+type UserMFA struct {
+	// Set once the user has verified a code, which enables MFA.
+	// Until then, the secret is only pending.
+	EnabledAt *time.Time `json:"enabled_at,omitempty"`
+	// Base32-encoded TOTP-secret
+	Secret string `json:"-"`
+	// Hashes of the recovery-codes that have not been used yet
+	RecoveryCodes [][]byte `json:"-"`
+	// The time-step of the last code that was used, so that codes cannot be reused
+	LastStep int64 `json:"-"`
+}
+
+func (m *UserMFA) Enabled() bool {
+	return m != nil && m.EnabledAt != nil
 }
 
 type UpdateUserPayload struct {
 	UserName          *string
 	PW                *[]byte
 	TemporaryPassword *bool
+	Roles             *[]RoleAssignment
+	// Set to an empty UserMFA to clear it
+	MFA         *UserMFA
+	Active      *bool
+	Permissions *UserPermissions
+	// Set to an empty UserInvite to clear it
+	Invite *UserInvite
 	Entity
 }
 
@@ -54,8 +146,20 @@ type UserStore int
 const (
 	// A local user, with password stored in the database.
 	UserStoreLocal UserStore = iota + 1
+	// A user that logs in through an OpenID Connect-provider, and has no password.
+	UserStoreOIDC
 )
 
+func (s UserStore) String() string {
+	switch s {
+	case UserStoreLocal:
+		return "local"
+	case UserStoreOIDC:
+		return "oidc"
+	}
+	return fmt.Sprintf("unknown (%d)", int(s))
+}
+
 // Locale represents a language, dialect etc.
 // swagger:response
 type loginResponse struct {
@@ -69,16 +173,60 @@ type LoginResponse struct {
 	Ok           bool         `json:"ok"`
 	Expires      time.Time    `json:"expires"`
 	ExpiresIn    string       `json:"expires_in"`
+	// Set if the password was correct, but the login must be completed with a code, through /api/auth/mfa
+	MFA *MFAChallenge `json:"mfa,omitempty"`
+	// Set if the user enrolled in multi-factor authentication during the login.
+	// The codes are only shown once.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// MFAChallenge is the second step of a login, for users with multi-factor authentication
+type MFAChallenge struct {
+	// Identifies the login, and must be passed along with the code
+	Token   string    `json:"token"`
+	Expires time.Time `json:"expires"`
+	// Set if the organization requires multi-factor authentication, and the user has not enrolled yet.
+	// The user must then add the secret to an authenticator-app, and complete the login with a code from it.
+	Enroll *MFAEnrollment `json:"enroll,omitempty"`
+}
+
+// MFAEnrollment is the secret that the user adds to an authenticator-app
+type MFAEnrollment struct {
+	// Base32-encoded secret, for entering into the app manually
+	Secret string `json:"secret"`
+	// otpauth-uri, which is usually shown as a QR-code
+	URI string `json:"uri"`
 }
 
 type Session struct {
+	// Identifies the session, for listing and revoking it. Unlike the token, it is not a secret.
+	ID           string
 	Token        string
 	User         User
 	Organization Organization
 	UserAgent    string
 	Issued       time.Time
 	Expires      time.Time
+	// Set if the session was created from an api-key.
+	// The permissions of the user are then restricted to the scopes of the key.
+	APIKey *APIKey
 }
+
+// SessionInfo describes a session of a user, without its token
+// swagger:model SessionInfo
+type SessionInfo struct {
+	// required: true
+	ID string `json:"id"`
+	// The device, or the description of the token
+	UserAgent string `json:"user_agent"`
+	// required: true
+	Issued time.Time `json:"issued"`
+	// required: true
+	Expires time.Time `json:"expires"`
+	// Set for the session that made the request
+	Current bool `json:"current"`
+}
+
 type UserSessionOptions struct {
 	TTL time.Duration
 }
@@ -97,12 +245,16 @@ type Organization struct {
 	// The first user to join, gets priviliges to administer the organization.
 	JoinID        string    `json:"join_id,omitempty"`
 	JoinIDExpires time.Time `json:"join_id_expires"`
+	// If set, all local users in the organization must use multi-factor authentication,
+	// and are asked to enroll on their next login.
+	RequireMFA bool `json:"require_mfa,omitempty"`
 }
 
 type UpdateOrganizationPayload struct {
 	UpdatedBy     string
 	JoinID        *string
 	JoinIDExpires *time.Time
+	RequireMFA    *bool
 }
 
 func (e Organization) IDString() string {
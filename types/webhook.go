@@ -0,0 +1,108 @@
+package types
+
+import (
+	"strings"
+	"time"
+)
+
+// A Webhook is an url within the organization's own systems, that is called on changes,
+// like when a snapshot is created.
+
+// swagger:model Webhook
+type Webhook struct {
+	Entity
+	// The url that deliveries are posted to
+	URL string `json:"url"`
+	// The events that trigger a delivery, as kind.verb, like snapshot.create.
+	// Either part can be a wildcard, like translation.*
+	Events []string `json:"events"`
+	// Used to sign the deliveries. It is only returned when the webhook is created.
+	Secret string `json:"-"`
+	// If set, no deliveries are made
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+func (e Webhook) Namespace() string {
+	return e.Kind()
+}
+func (e Webhook) Kind() string {
+	return string(PubTypeWebhook)
+}
+
+// WebhookEvent returns the event-name for the combination, like snapshot.create
+func WebhookEvent(kind, verb string) string {
+	return kind + "." + verb
+}
+
+// Matches returns true if the webhook should receive the event
+func (w Webhook) Matches(kind, verb string) bool {
+	if w.Disabled || w.Deleted != nil {
+		return false
+	}
+	for _, e := range w.Events {
+		k, v, _ := strings.Cut(e, ".")
+		if (k == "*" || k == kind) && (v == "" || v == "*" || v == verb) {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	WebhookDeliverySuccess WebhookDeliveryStatus = "success"
+	WebhookDeliveryFailed  WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is a single event sent to a webhook, with all the attempts to deliver it
+// swagger:model WebhookDelivery
+type WebhookDelivery struct {
+	ID             string    `json:"id"`
+	WebhookID      string    `json:"webhook_id"`
+	OrganizationID string    `json:"organization_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	// The event, like snapshot.create
+	Event string `json:"event"`
+	// The body that is posted to the webhook
+	Payload string `json:"payload"`
+	// Enum: [pending success failed]
+	Status   WebhookDeliveryStatus `json:"status"`
+	Attempts []WebhookAttempt      `json:"attempts,omitempty"`
+	// Time of the next attempt, while the delivery is pending.
+	// The deliveries are stored with this, so that retries survive a restart.
+	NextAttempt *time.Time `json:"next_attempt,omitempty"`
+	// Set if the delivery is a replay of an earlier delivery
+	ReplayOf string `json:"replay_of,omitempty"`
+}
+
+// WebhookDeliveryRetention limits how many completed deliveries are kept for each webhook.
+// Pending deliveries are always kept.
+type WebhookDeliveryRetention struct {
+	// Maximum number of deliveries per webhook. Zero means no limit.
+	MaxCount int
+	// Deliveries older than this are removed. Zero means no limit.
+	MaxAge time.Duration
+}
+
+// WebhookAttempt is a single request to the url of a webhook
+type WebhookAttempt struct {
+	At time.Time `json:"at"`
+	// The status-code of the response, if any
+	StatusCode int `json:"status_code,omitempty"`
+	// Set if the request failed, or the response was not successful
+	Error string `json:"error,omitempty"`
+	// Duration of the request, in milliseconds
+	Duration int64 `json:"duration"`
+}
+
+func (e WebhookDelivery) IDString() string {
+	return e.ID
+}
+func (e WebhookDelivery) Namespace() string {
+	return e.Kind()
+}
+func (e WebhookDelivery) Kind() string {
+	return "webhookDelivery"
+}
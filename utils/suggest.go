@@ -129,3 +129,16 @@ func ld(s, t string, ignoreCase bool) int {
 	}
 	return d[len(s)][len(t)]
 }
+
+// Similarity returns how similar the strings are, from 0 (nothing in common) to 1 (equal), ignoring case.
+// It is based on the Levenshtein-distance, relative to the length of the longest string.
+func Similarity(s, t string) float64 {
+	max := len(s)
+	if len(t) > max {
+		max = len(t)
+	}
+	if max == 0 {
+		return 1
+	}
+	return 1 - float64(ld(s, t, true))/float64(max)
+}
@@ -0,0 +1,445 @@
+// Package webhooks delivers the changes published by the database to the webhooks of the organization.
+//
+// Each delivery is posted as json, signed with the secret of the webhook, and retried with backoff
+// until it succeeds, or there are no more attempts. All deliveries are stored, so they can be inspected
+// and replayed. The time of the next retry is stored with the delivery, and the dispatcher periodically
+// attempts the deliveries that are due, so that pending retries are resumed after a restart.
+// Unless private networks are allowed, deliveries are only made to public addresses.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/runar-rkmedia/go-common/logger"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+const (
+	// HMAC-SHA256 of the body, as sha256=<hex>
+	HeaderSignature = "X-Skiver-Signature"
+	HeaderEvent     = "X-Skiver-Event"
+	HeaderDelivery  = "X-Skiver-Delivery"
+)
+
+var (
+	ErrNotFound = errors.New("Not found")
+)
+
+// Payload is the body that is posted to the webhook
+type Payload struct {
+	// The id of the delivery
+	ID             string    `json:"id"`
+	Event          string    `json:"event"`
+	Kind           string    `json:"kind"`
+	Verb           string    `json:"verb"`
+	OrganizationID string    `json:"organization_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	// The changed entity
+	Data interface{} `json:"data"`
+}
+
+type Options struct {
+	// Delays before each retry. The delivery fails if the last retry fails.
+	// Defaults to 10 seconds, 1 minute, 10 minutes and 1 hour.
+	Backoff []time.Duration
+	// How often the deliveries that are due for a retry are attempted. Defaults to 5 seconds.
+	Interval time.Duration
+	// Defaults to a client with a 10 second timeout, that only connects to public addresses
+	Client *http.Client
+	// Allows the default client to connect to loopback-, link-local- and private addresses,
+	// for installations where the webhooks are within the same network.
+	AllowPrivateNetworks bool
+}
+
+type Dispatcher struct {
+	db       types.Storage
+	l        logger.AppLogger
+	client   *http.Client
+	backoff  []time.Duration
+	interval time.Duration
+	sync.Mutex
+	// The ids of the deliveries that are currently being attempted
+	inFlight map[string]bool
+	wg       sync.WaitGroup
+	stop     chan struct{}
+	stopped  bool
+}
+
+func NewDispatcher(l logger.AppLogger, db types.Storage, options ...Options) *Dispatcher {
+	d := &Dispatcher{
+		db:       db,
+		l:        l,
+		client:   newClient(false),
+		backoff:  []time.Duration{10 * time.Second, time.Minute, 10 * time.Minute, time.Hour},
+		interval: 5 * time.Second,
+		inFlight: map[string]bool{},
+		stop:     make(chan struct{}),
+	}
+	if len(options) > 0 {
+		if options[0].Backoff != nil {
+			d.backoff = options[0].Backoff
+		}
+		if options[0].Interval > 0 {
+			d.interval = options[0].Interval
+		}
+		if options[0].AllowPrivateNetworks {
+			d.client = newClient(true)
+		}
+		if options[0].Client != nil {
+			d.client = options[0].Client
+		}
+	}
+	return d
+}
+
+// ErrPrivateAddress is returned when a webhook resolves to an address that is not public
+var ErrPrivateAddress = errors.New("the address of the webhook is not public")
+
+// Addresses that are not public, in addition to loopback-, link-local-, private- and multicast-addresses
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// PublicAddress returns true if the address is reachable on the internet,
+// and not within the network of the server, like 127.0.0.1, 10.0.0.1 or 169.254.169.254
+func PublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, p := range reservedPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Unless private networks are allowed, the address is checked when connecting, after it is resolved,
+// so that it also applies to redirects, and to hostnames that resolve to private addresses.
+// Proxies from the environment are not used, since the address of the webhook would then not be checked.
+func newClient(allowPrivateNetworks bool) *http.Client {
+	if allowPrivateNetworks {
+		return &http.Client{Timeout: 10 * time.Second}
+	}
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !PublicAddress(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// Start resumes the deliveries that are due, like those that were pending when the server stopped,
+// and keeps attempting the deliveries that are due for a retry until Stop is called.
+func (d *Dispatcher) Start() {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			d.deliverDue()
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops new attempts, and waits for the attempts in flight to complete, or for the context to be done.
+// Deliveries that are still pending are resumed on the next Start.
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	d.Lock()
+	if !d.stopped {
+		d.stopped = true
+		close(d.stop)
+	}
+	d.Unlock()
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Attempts the deliveries that are due
+func (d *Dispatcher) deliverDue() {
+	deliveries, err := d.db.FindDueWebhookDeliveries(time.Now())
+	if err != nil {
+		d.l.Error().Err(err).Msg("Failed to find webhook-deliveries that are due")
+		return
+	}
+	for _, delivery := range deliveries {
+		hook, err := d.db.GetWebhook(delivery.WebhookID)
+		if err != nil {
+			d.l.Error().Err(err).Str("delivery", delivery.ID).Msg("Failed to get the webhook of the delivery")
+			continue
+		}
+		if hook == nil || hook.Deleted != nil || hook.Disabled {
+			delivery.Status = types.WebhookDeliveryFailed
+			delivery.NextAttempt = nil
+			if _, err := d.db.SaveWebhookDelivery(delivery); err != nil {
+				d.l.Error().Err(err).Str("delivery", delivery.ID).Msg("Failed to store webhook-delivery")
+			}
+			continue
+		}
+		d.dispatch(*hook, delivery)
+	}
+}
+
+// Attempts the delivery in the background, unless it is already being attempted, or the dispatcher is stopped.
+func (d *Dispatcher) dispatch(hook types.Webhook, delivery types.WebhookDelivery) {
+	d.Lock()
+	defer d.Unlock()
+	if d.stopped || d.inFlight[delivery.ID] {
+		return
+	}
+	d.inFlight[delivery.ID] = true
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		if current := d.due(delivery.ID); current != nil {
+			d.deliver(hook, *current)
+		}
+		d.Lock()
+		delete(d.inFlight, delivery.ID)
+		d.Unlock()
+	}()
+}
+
+// Sign returns the signature of the body, as used in the signature-header
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if the signature matches the body.
+// Receivers can use this to verify that the delivery came from Skiver.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Publish creates a delivery for each of the organization's webhooks that match the event,
+// and delivers them in the background.
+func (d *Dispatcher) Publish(kind, variant string, contents interface{}) {
+	e, ok := contents.(types.EntityGetter)
+	if !ok {
+		return
+	}
+	entity := e.GetEntity()
+	organizationID := entity.OrganizationID
+	if kind == string(types.PubTypeOrganization) {
+		organizationID = entity.ID
+	}
+	if organizationID == "" {
+		return
+	}
+	filter := types.Webhook{}
+	filter.OrganizationID = organizationID
+	hooks, err := d.db.FindWebhooks(0, filter)
+	if err != nil {
+		d.l.Error().Err(err).Msg("Failed to find webhooks")
+		return
+	}
+	for _, hook := range hooks {
+		if !hook.Matches(kind, variant) {
+			continue
+		}
+		delivery, err := d.newDelivery(hook, kind, variant, organizationID, contents)
+		if err != nil {
+			d.l.Error().Err(err).Str("webhook", hook.ID).Msg("Failed to create webhook-delivery")
+			continue
+		}
+		d.dispatch(hook, delivery)
+	}
+}
+
+func (d *Dispatcher) newDelivery(hook types.Webhook, kind, verb, organizationID string, contents interface{}) (types.WebhookDelivery, error) {
+	delivery, err := d.db.SaveWebhookDelivery(types.WebhookDelivery{
+		WebhookID:      hook.ID,
+		OrganizationID: organizationID,
+		Event:          types.WebhookEvent(kind, verb),
+		Status:         types.WebhookDeliveryPending,
+	})
+	if err != nil {
+		return delivery, err
+	}
+	body, err := json.Marshal(Payload{
+		ID:             delivery.ID,
+		Event:          delivery.Event,
+		Kind:           kind,
+		Verb:           verb,
+		OrganizationID: organizationID,
+		CreatedAt:      delivery.CreatedAt,
+		Data:           contents,
+	})
+	if err != nil {
+		return delivery, err
+	}
+	delivery.Payload = string(body)
+	// The delivery is only picked up for attempts once the payload is set
+	delivery.NextAttempt = &delivery.CreatedAt
+	return d.db.SaveWebhookDelivery(delivery)
+}
+
+// Replay creates a new delivery with the same payload as an earlier delivery, and delivers it in the background.
+func (d *Dispatcher) Replay(deliveryID string) (types.WebhookDelivery, error) {
+	original, err := d.db.GetWebhookDelivery(deliveryID)
+	if err != nil {
+		return types.WebhookDelivery{}, err
+	}
+	if original == nil {
+		return types.WebhookDelivery{}, ErrNotFound
+	}
+	hook, err := d.db.GetWebhook(original.WebhookID)
+	if err != nil {
+		return types.WebhookDelivery{}, err
+	}
+	if hook == nil || hook.Deleted != nil {
+		return types.WebhookDelivery{}, ErrNotFound
+	}
+	now := time.Now()
+	delivery, err := d.db.SaveWebhookDelivery(types.WebhookDelivery{
+		WebhookID:      original.WebhookID,
+		OrganizationID: original.OrganizationID,
+		Event:          original.Event,
+		Payload:        original.Payload,
+		Status:         types.WebhookDeliveryPending,
+		ReplayOf:       original.ID,
+		NextAttempt:    &now,
+	})
+	if err != nil {
+		return delivery, err
+	}
+	d.dispatch(*hook, delivery)
+	return delivery, nil
+}
+
+// Returns the stored delivery if it is still due.
+// An attempt may have completed since the delivery was found to be due.
+func (d *Dispatcher) due(id string) *types.WebhookDelivery {
+	delivery, err := d.db.GetWebhookDelivery(id)
+	if err != nil {
+		d.l.Error().Err(err).Str("delivery", id).Msg("Failed to get webhook-delivery")
+		return nil
+	}
+	if delivery == nil || delivery.Status != types.WebhookDeliveryPending || delivery.NextAttempt == nil || delivery.NextAttempt.After(time.Now()) {
+		return nil
+	}
+	return delivery
+}
+
+// Attempts the delivery once, and schedules the next retry if it failed and there are more retries.
+func (d *Dispatcher) deliver(hook types.Webhook, delivery types.WebhookDelivery) types.WebhookDelivery {
+	attempt := d.attempt(hook, delivery)
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	delivery.NextAttempt = nil
+	retries := len(delivery.Attempts) - 1
+	switch {
+	case attempt.Error == "":
+		delivery.Status = types.WebhookDeliverySuccess
+	case retries >= len(d.backoff):
+		delivery.Status = types.WebhookDeliveryFailed
+	default:
+		next := attempt.At.Add(d.backoff[retries])
+		delivery.NextAttempt = &next
+	}
+	if _, err := d.db.SaveWebhookDelivery(delivery); err != nil {
+		d.l.Error().Err(err).Str("delivery", delivery.ID).Msg("Failed to store webhook-delivery")
+	}
+	if delivery.Status == types.WebhookDeliveryFailed {
+		d.l.Warn().Str("delivery", delivery.ID).Str("webhook", hook.ID).Str("error", attempt.Error).Msg("Webhook-delivery failed")
+	}
+	return delivery
+}
+
+func (d *Dispatcher) attempt(hook types.Webhook, delivery types.WebhookDelivery) types.WebhookAttempt {
+	body := []byte(delivery.Payload)
+	attempt := types.WebhookAttempt{At: time.Now()}
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Skiver-Webhook")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, body))
+	res, err := d.client.Do(req)
+	attempt.Duration = time.Since(attempt.At).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer res.Body.Close()
+	attempt.StatusCode = res.StatusCode
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		attempt.Error = fmt.Sprintf("Unexpected status-code %d", res.StatusCode)
+	}
+	return attempt
+}
+
+// ValidEvent returns true if the event is a known combination of kind and verb, or wildcards
+func ValidEvent(event string) bool {
+	kind, verb, _ := strings.Cut(event, ".")
+	return validPart(kind, types.PubTypes) && (verb == "" || validPart(verb, types.PubVerbs))
+}
+
+func validPart[T ~string](s string, valid []T) bool {
+	if s == "*" {
+		return true
+	}
+	for _, v := range valid {
+		if string(v) == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/runar-rkmedia/go-common/logger"
+	"github.com/runar-rkmedia/skiver/bboltStorage"
+	"github.com/runar-rkmedia/skiver/types"
+)
+
+type received struct {
+	body      []byte
+	signature string
+	event     string
+	delivery  string
+}
+
+// A receiver that responds with the status-codes in order, and then 200
+func newReceiver(t *testing.T, statusCodes ...int) (*httptest.Server, <-chan received) {
+	ch := make(chan received, 10)
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		ch <- received{b, r.Header.Get(HeaderSignature), r.Header.Get(HeaderEvent), r.Header.Get(HeaderDelivery)}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(statusCodes) > 0 {
+			rw.WriteHeader(statusCodes[0])
+			statusCodes = statusCodes[1:]
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, ch
+}
+
+func receive(t *testing.T, ch <-chan received) received {
+	t.Helper()
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for delivery")
+	}
+	return received{}
+}
+
+// Waits for the delivery to either succeed or fail
+func waitForDelivery(t *testing.T, db types.Storage, id string) types.WebhookDelivery {
+	t.Helper()
+	for i := 0; i < 500; i++ {
+		d, err := db.GetWebhookDelivery(id)
+		testza.AssertNoError(t, err)
+		if d != nil && d.Status != types.WebhookDeliveryPending {
+			return *d
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Timed out waiting for delivery to complete")
+	return types.WebhookDelivery{}
+}
+
+func setup(t *testing.T, url string, events ...string) (*Dispatcher, types.Storage, types.Webhook) {
+	db := bboltStorage.NewMockDB(t)
+	hook := types.Webhook{URL: url, Events: events, Secret: "s3cret"}
+	hook.CreatedBy = "jim"
+	hook.OrganizationID = "org-abc"
+	hook, err := db.CreateWebhook(hook)
+	testza.AssertNoError(t, err)
+	d := newDispatcher(t, db)
+	return d, db, hook
+}
+
+func newDispatcher(t *testing.T, db types.Storage) *Dispatcher {
+	// The receivers of the tests are on the loopback-address
+	d := NewDispatcher(logger.GetLoggerWithLevel("test", "fatal"), db, Options{Backoff: []time.Duration{time.Millisecond, time.Millisecond}, Interval: 5 * time.Millisecond, AllowPrivateNetworks: true})
+	d.Start()
+	t.Cleanup(func() { d.Stop(context.Background()) })
+	return d
+}
+
+func snapshot(organizationID string) types.ProjectSnapshot {
+	s := types.ProjectSnapshot{}
+	s.ID = "snap-1"
+	s.OrganizationID = organizationID
+	return s
+}
+
+func TestDeliveryIsSignedAndRetried(t *testing.T) {
+	srv, ch := newReceiver(t, http.StatusInternalServerError)
+	d, db, hook := setup(t, srv.URL, "snapshot.create")
+
+	d.Publish("translation", "update", snapshot("org-abc"))
+	d.Publish("snapshot", "create", snapshot("org-other"))
+	d.Publish("snapshot", "create", snapshot("org-abc"))
+
+	first := receive(t, ch)
+	second := receive(t, ch)
+	testza.AssertEqual(t, first.body, second.body, "The retry should have the same body")
+	testza.AssertEqual(t, "snapshot.create", second.event)
+	testza.AssertTrue(t, Verify("s3cret", second.body, second.signature))
+	testza.AssertFalse(t, Verify("wrong", second.body, second.signature))
+
+	var payload Payload
+	testza.AssertNoError(t, json.Unmarshal(second.body, &payload))
+	testza.AssertEqual(t, "org-abc", payload.OrganizationID)
+	testza.AssertEqual(t, second.delivery, payload.ID)
+
+	delivery := waitForDelivery(t, db, second.delivery)
+	testza.AssertEqual(t, types.WebhookDeliverySuccess, delivery.Status)
+	testza.AssertLen(t, delivery.Attempts, 2)
+	testza.AssertEqual(t, http.StatusInternalServerError, delivery.Attempts[0].StatusCode)
+	testza.AssertNotEqual(t, "", delivery.Attempts[0].Error)
+	testza.AssertEqual(t, http.StatusOK, delivery.Attempts[1].StatusCode)
+
+	deliveries, err := db.FindWebhookDeliveries(hook.ID, 0)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, deliveries, 1, "Only the matching event within the organization should be delivered")
+
+	replay, err := d.Replay(delivery.ID)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, delivery.ID, replay.ReplayOf)
+	replayed := receive(t, ch)
+	testza.AssertEqual(t, first.body, replayed.body)
+	testza.AssertEqual(t, replay.ID, replayed.delivery)
+	testza.AssertEqual(t, types.WebhookDeliverySuccess, waitForDelivery(t, db, replay.ID).Status)
+
+	_, err = d.Replay("does-not-exist")
+	testza.AssertErrorIs(t, err, ErrNotFound)
+}
+
+func TestDeliveryFailsAfterRetries(t *testing.T) {
+	srv, ch := newReceiver(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	d, db, _ := setup(t, srv.URL, "snapshot.*")
+
+	d.Publish("snapshot", "update", snapshot("org-abc"))
+	r := receive(t, ch)
+	delivery := waitForDelivery(t, db, r.delivery)
+	testza.AssertEqual(t, types.WebhookDeliveryFailed, delivery.Status)
+	testza.AssertLen(t, delivery.Attempts, 3)
+}
+
+func TestPendingDeliveriesAreResumed(t *testing.T) {
+	srv, ch := newReceiver(t)
+	db := bboltStorage.NewMockDB(t)
+	hook := types.Webhook{URL: srv.URL, Events: []string{"*"}, Secret: "s3cret"}
+	hook.CreatedBy = "jim"
+	hook.OrganizationID = "org-abc"
+	hook, err := db.CreateWebhook(hook)
+	testza.AssertNoError(t, err)
+
+	// Deliveries that were pending when the server stopped
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	due, err := db.SaveWebhookDelivery(types.WebhookDelivery{WebhookID: hook.ID, OrganizationID: "org-abc", Event: "snapshot.create", Payload: `{"due":true}`, Status: types.WebhookDeliveryPending, NextAttempt: &past})
+	testza.AssertNoError(t, err)
+	later, err := db.SaveWebhookDelivery(types.WebhookDelivery{WebhookID: hook.ID, OrganizationID: "org-abc", Event: "snapshot.create", Payload: `{"due":false}`, Status: types.WebhookDeliveryPending, NextAttempt: &future})
+	testza.AssertNoError(t, err)
+
+	newDispatcher(t, db)
+	r := receive(t, ch)
+	testza.AssertEqual(t, due.ID, r.delivery)
+	testza.AssertEqual(t, `{"due":true}`, string(r.body))
+	delivery := waitForDelivery(t, db, due.ID)
+	testza.AssertEqual(t, types.WebhookDeliverySuccess, delivery.Status)
+	testza.AssertNil(t, delivery.NextAttempt)
+
+	select {
+	case r := <-ch:
+		t.Fatalf("Delivery %s was attempted before it was due", r.delivery)
+	case <-time.After(50 * time.Millisecond):
+	}
+	stored, err := db.GetWebhookDelivery(later.ID)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, types.WebhookDeliveryPending, stored.Status)
+	testza.AssertLen(t, stored.Attempts, 0)
+}
+
+func TestStopWaitsForDeliveries(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	}))
+	t.Cleanup(srv.Close)
+	d, db, hook := setup(t, srv.URL, "snapshot.create")
+
+	d.Publish("snapshot", "create", snapshot("org-abc"))
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	testza.AssertErrorIs(t, d.Stop(ctx), context.DeadlineExceeded, "The delivery is still in flight")
+
+	close(release)
+	testza.AssertNoError(t, d.Stop(context.Background()))
+	deliveries, err := db.FindWebhookDeliveries(hook.ID, 0)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, deliveries, 1)
+	testza.AssertEqual(t, types.WebhookDeliverySuccess, deliveries[0].Status)
+
+	// Once stopped, new deliveries are stored, but not attempted until the next start
+	d.Publish("snapshot", "create", snapshot("org-abc"))
+	deliveries, err = db.FindWebhookDeliveries(hook.ID, 0)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, deliveries, 2)
+	testza.AssertEqual(t, types.WebhookDeliveryPending, deliveries[0].Status)
+	testza.AssertNotNil(t, deliveries[0].NextAttempt)
+}
+
+func TestValidEvent(t *testing.T) {
+	for event, want := range map[string]bool{
+		"snapshot.create": true,
+		"snapshot.*":      true,
+		"snapshot":        true,
+		"*":               true,
+		"*.soft-delete":   true,
+		"snapshot.eaten":  false,
+		"fish.create":     false,
+		"":                false,
+	} {
+		testza.AssertEqual(t, want, ValidEvent(event), event)
+	}
+}
+
+func TestDeliveryToPrivateAddressFails(t *testing.T) {
+	srv, ch := newReceiver(t)
+	db := bboltStorage.NewMockDB(t)
+	hook := types.Webhook{URL: srv.URL, Events: []string{"snapshot.*"}, Secret: "s3cret"}
+	hook.CreatedBy = "jim"
+	hook.OrganizationID = "org-abc"
+	hook, err := db.CreateWebhook(hook)
+	testza.AssertNoError(t, err)
+	d := NewDispatcher(logger.GetLoggerWithLevel("test", "fatal"), db, Options{Backoff: []time.Duration{time.Millisecond}, Interval: 5 * time.Millisecond})
+	d.Start()
+	t.Cleanup(func() { d.Stop(context.Background()) })
+
+	d.Publish("snapshot", "create", snapshot("org-abc"))
+	var deliveries []types.WebhookDelivery
+	for i := 0; i < 500 && len(deliveries) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		deliveries, err = db.FindWebhookDeliveries(hook.ID, 0)
+		testza.AssertNoError(t, err)
+	}
+	testza.AssertLen(t, deliveries, 1)
+	delivery := waitForDelivery(t, db, deliveries[0].ID)
+	testza.AssertEqual(t, types.WebhookDeliveryFailed, delivery.Status)
+	testza.AssertContains(t, delivery.Attempts[0].Error, ErrPrivateAddress.Error())
+	select {
+	case <-ch:
+		t.Fatal("The webhook on the loopback-address should not receive the delivery")
+	default:
+	}
+}
+
+func TestPublicAddress(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"93.184.216.34":          true,
+		"2606:2800:220:1::":      true,
+		"127.0.0.1":              false,
+		"::1":                    false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"fe80::1":                false,
+		"fd00::1":                false,
+		"0.0.0.0":                false,
+		"100.64.0.1":             false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+	} {
+		testza.AssertEqual(t, public, PublicAddress(netip.MustParseAddr(addr)), addr)
+	}
+}